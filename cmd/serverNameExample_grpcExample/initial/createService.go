@@ -2,8 +2,11 @@ package initial
 
 import (
 	"strconv"
+	"time"
 
 	"github.com/go-dev-frame/sponge/pkg/app"
+	"github.com/go-dev-frame/sponge/pkg/kafka"
+	"github.com/go-dev-frame/sponge/pkg/logger"
 
 	"github.com/go-dev-frame/sponge/internal/config"
 	"github.com/go-dev-frame/sponge/internal/server"
@@ -15,20 +18,67 @@ func CreateServices() []app.IServer {
 	var servers []app.IServer
 	var grpcAddr = ":" + strconv.Itoa(cfg.Grpc.Port)
 
+	// create a kafka consumer group lag exporter if kafka.lag.enable is true,
+	// the lag gauge is exposed together with the grpc server metrics
+	var grpcOpts []server.GrpcOption
+	lagExporter := newKafkaLagExporter(cfg)
+	if lagExporter != nil {
+		grpcOpts = append(grpcOpts, server.WithGrpcGaugeMetrics(lagExporter.GaugeVec()))
+	}
+
 	// case 1, create a grpc service without registry
-	grpcServer := server.NewGRPCServer(grpcAddr)
+	grpcServer := server.NewGRPCServer(grpcAddr, grpcOpts...)
 
 	// case 2, create a grpc service and register it with consul or etcd or nacos
 	//grpcRegistry, grpcInstance := registerService("grpc", cfg.App.Host, cfg.Grpc.Port)
-	//grpcServer := server.NewGRPCServer(grpcAddr,
-	//	server.WithGrpcRegistry(grpcRegistry, grpcInstance),
-	//)
+	//grpcOpts = append(grpcOpts, server.WithGrpcRegistry(grpcRegistry, grpcInstance))
+	//grpcServer := server.NewGRPCServer(grpcAddr, grpcOpts...)
 
 	servers = append(servers, grpcServer)
 
+	// create a message bus subscriber service, the subscriber can be kafka, rabbitmq or memory, e.g.
+	//     subscriber := msgbus.NewKafkaSubscriber(consumerGroup, "topic")
+	//     servers = append(servers, msgbus.NewServer("topic consumer", subscriber, handler))
+
+	// the kafka consumer group lag exporter service
+	if lagExporter != nil {
+		servers = append(servers, lagExporter)
+	}
+
 	return servers
 }
 
+// create a kafka consumer group lag exporter if kafka.lag.enable is true, log a warning
+// when the total lag of a group on a topic exceeds the threshold.
+func newKafkaLagExporter(cfg *config.Config) *kafka.LagExporter {
+	lagCfg := cfg.Kafka.Lag
+	if !lagCfg.Enable {
+		return nil
+	}
+
+	opts := []kafka.LagOption{
+		kafka.LagWithInterval(time.Duration(lagCfg.Interval) * time.Second),
+		kafka.LagWithZapLogger(logger.Get()),
+		kafka.LagWithAlert(int64(lagCfg.Threshold), func(lag *kafka.Lag, isExceeded bool) {
+			fields := []logger.Field{logger.String("group", lag.GroupID), logger.String("topic", lag.Topic), logger.Int64("lag", lag.Total)}
+			if isExceeded {
+				logger.Warn("kafka consumer group lag exceeds the threshold", fields...)
+			} else {
+				logger.Info("kafka consumer group lag falls back to the threshold or below", fields...)
+			}
+		}),
+	}
+	for _, gt := range lagCfg.GroupTopics {
+		opts = append(opts, kafka.LagWithGroupTopics(gt.GroupID, gt.Topics...))
+	}
+
+	lagExporter, err := kafka.InitLagExporter(cfg.Kafka.Addrs, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return lagExporter
+}
+
 // register service with consul or etcd or nacos, select one of them to use
 //func registerService(scheme string, host string, port int) (registry.Registry, *registry.ServiceInstance) {
 //	var (
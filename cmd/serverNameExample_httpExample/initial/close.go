@@ -27,7 +27,7 @@ func Close(servers []app.IServer) []app.Close {
 	})
 
 	// close redis
-	if config.Get().App.CacheType == "redis" {
+	if cType := config.Get().App.CacheType; cType == "redis" || cType == "multilevel" {
 		closes = append(closes, func() error {
 			return database.CloseRedis()
 		})
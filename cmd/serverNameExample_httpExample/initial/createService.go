@@ -2,11 +2,16 @@ package initial
 
 import (
 	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/go-dev-frame/sponge/internal/config"
 	"github.com/go-dev-frame/sponge/internal/server"
 
 	"github.com/go-dev-frame/sponge/pkg/app"
+	"github.com/go-dev-frame/sponge/pkg/kafka"
+	"github.com/go-dev-frame/sponge/pkg/logger"
 )
 
 // CreateServices create http service
@@ -21,5 +26,47 @@ func CreateServices() []app.IServer {
 	)
 	servers = append(servers, httpServer)
 
+	// create a message bus subscriber service, the subscriber can be kafka, rabbitmq or memory, e.g.
+	//     subscriber := msgbus.NewKafkaSubscriber(consumerGroup, "topic")
+	//     servers = append(servers, msgbus.NewServer("topic consumer", subscriber, handler))
+
+	// create a kafka consumer group lag exporter service if kafka.lag.enable is true,
+	// the lag gauge is exposed by the metrics endpoint of http server
+	if lagExporter := newKafkaLagExporter(cfg); lagExporter != nil {
+		prometheus.MustRegister(lagExporter.GaugeVec())
+		servers = append(servers, lagExporter)
+	}
+
 	return servers
 }
+
+// create a kafka consumer group lag exporter if kafka.lag.enable is true, log a warning
+// when the total lag of a group on a topic exceeds the threshold.
+func newKafkaLagExporter(cfg *config.Config) *kafka.LagExporter {
+	lagCfg := cfg.Kafka.Lag
+	if !lagCfg.Enable {
+		return nil
+	}
+
+	opts := []kafka.LagOption{
+		kafka.LagWithInterval(time.Duration(lagCfg.Interval) * time.Second),
+		kafka.LagWithZapLogger(logger.Get()),
+		kafka.LagWithAlert(int64(lagCfg.Threshold), func(lag *kafka.Lag, isExceeded bool) {
+			fields := []logger.Field{logger.String("group", lag.GroupID), logger.String("topic", lag.Topic), logger.Int64("lag", lag.Total)}
+			if isExceeded {
+				logger.Warn("kafka consumer group lag exceeds the threshold", fields...)
+			} else {
+				logger.Info("kafka consumer group lag falls back to the threshold or below", fields...)
+			}
+		}),
+	}
+	for _, gt := range lagCfg.GroupTopics {
+		opts = append(opts, kafka.LagWithGroupTopics(gt.GroupID, gt.Topics...))
+	}
+
+	lagExporter, err := kafka.InitLagExporter(cfg.Kafka.Addrs, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return lagExporter
+}
@@ -82,13 +82,20 @@ var (
 
 	cacheFile = "cache/cacheNameExample.go"
 
-	daoFile     = "dao/userExample.go"
-	daoMgoFile  = "dao/userExample.go.mgo"
-	daoFileMark = "// todo generate the update fields code to here"
-	daoTestFile = "dao/userExample_test.go"
+	daoFile             = "dao/userExample.go"
+	daoMgoFile          = "dao/userExample.go.mgo"
+	daoFileMark         = "// todo generate the update fields code to here"
+	daoRelationFileMark = "// todo generate the relation code to here"
+	daoWhitelistMark    = "// todo generate the whitelist code to here"
+	daoTestFile         = "dao/userExample_test.go"
+
+	ecodeHTTPFile = "ecode/userExample_http.go"
+	ecodeRPCFile  = "ecode/userExample_rpc.go"
+	routersFile   = "routers/userExample.go"
 
 	typesFile         = "types/userExample_types.go"
 	typesMgoFile      = "types/userExample_types.go.mgo"
+	handlerFile       = "handler/userExample.go"
 	handlerFileMark   = "// todo generate the request and response struct to here"
 	handlerTestFile   = "handler/userExample_test.go"
 	handlerPbFile     = "handler/userExample_logic.go"
@@ -141,6 +148,43 @@ var (
 
 	expectedSQLForDeletion = "expectedSQLForDeletion := \"UPDATE .*\""
 
+	// the optional code between marks "// <name> code start" and "// <name> code end" is kept only
+	// if the table supports it, e.g. soft delete by column deleted_at, optimistic lock by version column
+	softDeleteMark     = "soft delete"
+	optimisticLockMark = "optimistic lock"
+	optionalCodeFiles  = []string{
+		daoFile, daoFile + expSuffix, daoTestFile, daoTestFile + expSuffix,
+		ecodeHTTPFile, ecodeHTTPFile + expSuffix, ecodeRPCFile, ecodeRPCFile + expSuffix,
+		routersFile + expSuffix, typesFile + expSuffix,
+		handlerFile, handlerFile + expSuffix, handlerTestFile, handlerTestFile + expSuffix, handlerFile + ".service" + expSuffix,
+		handlerLogicFile, handlerLogicFile + expSuffix, handlerPbTestFile, handlerPbTestFile + expSuffix,
+		serviceFile, serviceFile + expSuffix, serviceClientFile + expSuffix,
+	}
+
+	// compare-and-swap on the version column when updating a record
+	daoUpdateCode        = "\treturn db.WithContext(ctx).Model(table).Updates(update).Error\n"
+	daoUpdateArgsRegexp  = regexp.MustCompile(`(func Test_\w+_UpdateBy(?:ID|Tx)\(t \*testing\.T\) \{[\s\S]*?WithArgs\([\w.]+AnyTime, )(testData\.I[Dd]\))`)
+	daoOptimisticLockTpl = `	// optimistic lock, the record is updated only if its version has not been changed
+	update["{{.VersionColumnName}}"] = gorm.Expr("{{.VersionColumnName}} + 1")
+	result := db.WithContext(ctx).Model(table).Where("{{.VersionColumnName}} = ?", table.{{.VersionColumnNameCamel}}).Updates(update)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		// the record does not exist or its version has been changed
+		var count int64
+		err := db.WithContext(ctx).Model(&model.{{.TableNameCamel}}{}).Where({{.GetKeyFieldWhere "table"}}).Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return database.ErrRecordNotFound
+		}
+		return database.ErrVersionConflict
+	}
+	return nil
+`
+
 	//deploymentConfigFile     = "kubernetes/serverNameExample-configmap.yml"
 	//deploymentConfigFileMark = "# todo generate the database configuration for deployment here"
 
@@ -547,22 +591,105 @@ func getEmbedTimeCode(isEmbed bool) string {
 	return ""
 }
 
-func getExpectedSQLForDeletion(isEmbed bool) string {
-	if !isEmbed {
+func getExpectedSQLForDeletion(isSoftDelete bool) string {
+	if !isSoftDelete {
 		return strings.ReplaceAll(expectedSQLForDeletion, "UPDATE", "DELETE")
 	}
 
 	return expectedSQLForDeletion
 }
 
-func getExpectedSQLForDeletionField(isEmbed bool) []replacer.Field {
+// keep or delete the optional code between marks according to the table, and adjust the update code
+// of dao and its tests for optimistic lock, each file is processed at once and replaced as a whole.
+func optionalCodeFields(r replacer.Replacer, crudInfo *parser.CrudInfo, dbDriver string) []replacer.Field {
+	var fields []replacer.Field
+
+	for _, file := range optionalCodeFiles {
+		data, err := r.ReadFile(file)
+		if err != nil {
+			continue
+		}
+
+		content := replaceOptionalCodes(data, crudInfo, dbDriver)
+		if !bytes.Equal(content, data) {
+			fields = append(fields, replacer.Field{ // replace the whole file content
+				Old: string(data),
+				New: string(content),
+			})
+		}
+	}
+
+	return fields
+}
+
+// keep or delete the optional code of soft delete and optimistic lock in data
+func replaceOptionalCodes(data []byte, crudInfo *parser.CrudInfo, dbDriver string) []byte {
+	isOptimisticLock := crudInfo.CheckOptimisticLock()
+	optionalCodes := []struct {
+		mark   string
+		isKeep bool
+	}{
+		{softDeleteMark, crudInfo.CheckSoftDelete() || strings.ToLower(dbDriver) == DBDriverMongodb},
+		{optimisticLockMark, isOptimisticLock},
+	}
+
+	content := data
+	for _, code := range optionalCodes {
+		content = replaceOptionalCode(content, code.mark, code.isKeep)
+	}
+	if isOptimisticLock {
+		content = replaceOptimisticLockCode(content, crudInfo)
+	}
+	return content
+}
+
+// the mark lines are always deleted, if a mark or the deleted code is surrounded by blank lines,
+// only one blank line is kept, and the blank line is not kept before the closing bracket or at the end of the file.
+func replaceOptionalCode(data []byte, mark string, isKeep bool) []byte {
+	if !bytes.Contains(data, []byte("// "+mark+" code start")) {
+		return data
+	}
+
+	re := regexp.MustCompile(`(?m)(^\n)?^[ \t]*// ` + mark + ` code start\n[\s\S]*?^[ \t]*// ` + mark + ` code end\n(\n)?`)
+	if isKeep {
+		re = regexp.MustCompile(`(?m)(^\n)?^[ \t]*// ` + mark + ` code (?:start|end)\n(\n)?`)
+	}
+
+	content := []byte{}
+	lastIndex := 0
+	for _, loc := range re.FindAllIndex(data, -1) {
+		content = append(content, data[lastIndex:loc[0]]...)
+		match, next := data[loc[0]:loc[1]], bytes.TrimLeft(data[loc[1]:], " \t\n")
+		isSurrounded := bytes.HasPrefix(match, []byte("\n")) || bytes.HasSuffix(match, []byte("\n\n"))
+		if isSurrounded && len(next) > 0 && next[0] != '}' && next[0] != ')' {
+			content = append(content, '\n')
+		}
+		lastIndex = loc[1]
+	}
+	return append(content, data[lastIndex:]...)
+}
+
+// the record is updated by compare-and-swap on version column, the version is one of the update args in tests.
+func replaceOptimisticLockCode(data []byte, crudInfo *parser.CrudInfo) []byte {
+	if bytes.Contains(data, []byte(daoUpdateCode)) {
+		buf := new(bytes.Buffer)
+		tpl := template.Must(template.New("optimisticLock").Parse(daoOptimisticLockTpl))
+		if err := tpl.Execute(buf, crudInfo); err == nil {
+			data = bytes.ReplaceAll(data, []byte(daoUpdateCode), buf.Bytes())
+		}
+	}
+
+	return daoUpdateArgsRegexp.ReplaceAll(data, []byte("${1}testData."+crudInfo.VersionColumnNameCamel+", ${2}"))
+}
+
+func getExpectedSQLForDeletionField(isSoftDelete bool) []replacer.Field {
 	var fields []replacer.Field
-	esql := getExpectedSQLForDeletion(isEmbed)
+	esql := getExpectedSQLForDeletion(isSoftDelete)
 	if esql != expectedSQLForDeletion {
 		fields = append(fields, []replacer.Field{
 			{
 				Old: expectedSQLForDeletion,
-				New: getExpectedSQLForDeletion(isEmbed),
+				New: getExpectedSQLForDeletion(isSoftDelete),
 			},
 			{
 				Old: "expectedArgsForDeletionTime := d.AnyTime",
@@ -984,7 +1111,7 @@ func replaceTemplateFileContent(r replacer.Replacer, file string, crudInfo *pars
 
 	content := string(data)
 	if strings.Contains(content, "{{{.ColumnNameCamelFCL}}}") {
-		content = strings.ReplaceAll(content, "{{{.ColumnNameCamelFCL}}}", fmt.Sprintf("{%s}", crudInfo.ColumnNameCamelFCL))
+		content = strings.ReplaceAll(content, "{{{.ColumnNameCamelFCL}}}", crudInfo.GetKeySwaggerPath())
 	}
 
 	defer func() {
@@ -999,7 +1126,8 @@ func replaceTemplateFileContent(r replacer.Replacer, file string, crudInfo *pars
 		return field, err
 	}
 
-	dstContent := buf.String()
+	// the templates are only used by sql database
+	dstContent := string(replaceOptionalCodes(buf.Bytes(), crudInfo, ""))
 	if !strings.Contains(dstContent, "utils.") {
 		dstContent = strings.ReplaceAll(dstContent, `"github.com/go-dev-frame/sponge/pkg/utils"`, "")
 	}
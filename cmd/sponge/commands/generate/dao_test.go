@@ -0,0 +1,247 @@
+package generate
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-dev-frame/sponge/pkg/sgorm/sqlite"
+)
+
+// generate the dao code of a table with column deleted_at, then build and run the generated dao tests
+// in the packages of this repository by go test -overlay.
+func TestDaoCommand_SoftDelete(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping building the generated code in short mode")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+
+	rootDir, err := filepath.Abs("../../../..")
+	require.NoError(t, err)
+	spongeDir := SpongeDir
+	SpongeDir = rootDir
+	defer func() { SpongeDir = spongeDir }()
+
+	dbFile := filepath.Join(t.TempDir(), "test.db")
+	db, err := sqlite.Init(dbFile)
+	require.NoError(t, err)
+	err = db.Exec(`create table soft_user (id integer primary key autoincrement, created_at datetime, updated_at datetime,
+deleted_at datetime, name text not null, age integer not null);`).Error
+	require.NoError(t, err)
+	_ = sqlite.Close(db)
+
+	tests := []struct {
+		name         string
+		args         []string
+		isSoftDelete bool
+	}{
+		{name: "basic api", args: []string{"--soft-delete"}, isSoftDelete: true},
+		{name: "extended api", args: []string{"--soft-delete", "--extended-api"}, isSoftDelete: true},
+		{name: "extended api without soft delete", args: []string{"--extended-api"}, isSoftDelete: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outDir := t.TempDir()
+			cmd := DaoCommand("web")
+			cmd.SetArgs(append([]string{
+				"--module-name=" + selfPackageName,
+				"--db-driver=sqlite",
+				"--db-dsn=" + dbFile,
+				"--db-table=soft_user",
+				"--out=" + outDir,
+			}, tt.args...))
+			require.NoError(t, cmd.Execute())
+
+			model, err := os.ReadFile(filepath.Join(outDir, "internal", "model", "softUser.go"))
+			require.NoError(t, err)
+			assert.Equal(t, tt.isSoftDelete, strings.Contains(string(model), "gorm.DeletedAt"))
+
+			overlayFile := writeOverlay(t, rootDir, outDir, "internal/model", "internal/cache", "internal/dao")
+			out, err := exec.Command(goBin, "test", "-count=1", "-overlay="+overlayFile,
+				"-run=Test_softUserDao", filepath.Join(rootDir, "internal", "dao")).CombinedOutput()
+			assert.NoError(t, err, string(out))
+		})
+	}
+}
+
+// generate the dao code of the tables with composite primary key and string primary key from the database,
+// then build and run the generated dao tests.
+func TestDaoCommand_PrimaryKey(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping building the generated code in short mode")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+
+	rootDir, err := filepath.Abs("../../../..")
+	require.NoError(t, err)
+	spongeDir := SpongeDir
+	SpongeDir = rootDir
+	defer func() { SpongeDir = spongeDir }()
+
+	dbFile := filepath.Join(t.TempDir(), "test.db")
+	db, err := sqlite.Init(dbFile)
+	require.NoError(t, err)
+	err = db.Exec(`create table tenant_user (code text not null, tenant_id integer not null, created_at datetime,
+updated_at datetime, name text not null, primary key (tenant_id, code));`).Error
+	require.NoError(t, err)
+	err = db.Exec(`create table device (uuid text not null primary key, created_at datetime, updated_at datetime,
+name text not null);`).Error
+	require.NoError(t, err)
+	_ = sqlite.Close(db)
+
+	tests := []struct {
+		table    string
+		daoFile  string
+		testName string
+		keyFunc  string
+	}{
+		{table: "tenant_user", daoFile: "tenantUser.go", testName: "Test_tenantUserDao", keyFunc: "DeleteByTenantIDAndCode("},
+		{table: "device", daoFile: "device.go", testName: "Test_deviceDao", keyFunc: "DeleteByUuid("},
+	}
+	for _, tt := range tests {
+		t.Run(tt.table, func(t *testing.T) {
+			outDir := t.TempDir()
+			cmd := DaoCommand("web")
+			cmd.SetArgs([]string{
+				"--module-name=" + selfPackageName,
+				"--db-driver=sqlite",
+				"--db-dsn=" + dbFile,
+				"--db-table=" + tt.table,
+				"--out=" + outDir,
+			})
+			require.NoError(t, cmd.Execute())
+
+			dao, err := os.ReadFile(filepath.Join(outDir, "internal", "dao", tt.daoFile))
+			require.NoError(t, err)
+			assert.True(t, strings.Contains(string(dao), tt.keyFunc), "%s not found in %s", tt.keyFunc, tt.daoFile)
+
+			overlayFile := writeOverlay(t, rootDir, outDir, "internal/model", "internal/cache", "internal/dao")
+			out, err := exec.Command(goBin, "test", "-count=1", "-overlay="+overlayFile,
+				"-run="+tt.testName, filepath.Join(rootDir, "internal", "dao")).CombinedOutput()
+			assert.NoError(t, err, string(out))
+		})
+	}
+}
+
+// generate the dao code of a table with column version, update the records by the generated dao with a stale
+// version and a missing id, the errors are ErrVersionConflict and ErrRecordNotFound.
+func TestDaoCommand_OptimisticLock(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping building the generated code in short mode")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+
+	rootDir, err := filepath.Abs("../../../..")
+	require.NoError(t, err)
+	spongeDir := SpongeDir
+	SpongeDir = rootDir
+	defer func() { SpongeDir = spongeDir }()
+
+	dbFile := filepath.Join(t.TempDir(), "test.db")
+	db, err := sqlite.Init(dbFile)
+	require.NoError(t, err)
+	err = db.Exec(`create table version_user (id integer primary key autoincrement, created_at datetime, updated_at datetime,
+name text not null, version integer not null default 0);`).Error
+	require.NoError(t, err)
+	_ = sqlite.Close(db)
+
+	outDir := t.TempDir()
+	cmd := DaoCommand("web")
+	cmd.SetArgs([]string{
+		"--module-name=" + selfPackageName,
+		"--db-driver=sqlite",
+		"--db-dsn=" + dbFile,
+		"--db-table=version_user",
+		"--out=" + outDir,
+	})
+	require.NoError(t, cmd.Execute())
+
+	dao, err := os.ReadFile(filepath.Join(outDir, "internal", "dao", "versionUser.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(dao), "database.ErrVersionConflict")
+
+	testCode := `package dao
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-dev-frame/sponge/internal/database"
+	"github.com/go-dev-frame/sponge/internal/model"
+	"github.com/go-dev-frame/sponge/pkg/sgorm/sqlite"
+)
+
+func Test_versionUserDao_OptimisticLock(t *testing.T) {
+	db, err := sqlite.Init(` + "`" + dbFile + "`" + `)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sqlite.Close(db)
+	ctx := context.Background()
+	d := NewVersionUserDao(db, nil)
+
+	record := &model.VersionUser{Name: "foo"}
+	if err = d.Create(ctx, record); err != nil {
+		t.Fatal(err)
+	}
+	stale := *record
+	record.Name = "bar"
+	if err = d.UpdateByID(ctx, record); err != nil {
+		t.Fatal(err)
+	}
+
+	stale.Name = "baz"
+	if err = d.UpdateByID(ctx, &stale); !errors.Is(err, database.ErrVersionConflict) {
+		t.Fatalf("expected version conflict, got %v", err)
+	}
+	stale.ID = record.ID + 100
+	if err = d.UpdateByID(ctx, &stale); !errors.Is(err, database.ErrRecordNotFound) {
+		t.Fatalf("expected record not found, got %v", err)
+	}
+}
+`
+	err = os.WriteFile(filepath.Join(outDir, "internal", "dao", "versionUser_lock_test.go"), []byte(testCode), 0o666)
+	require.NoError(t, err)
+
+	overlayFile := writeOverlay(t, rootDir, outDir, "internal/model", "internal/cache", "internal/dao")
+	out, err := exec.Command(goBin, "test", "-count=1", "-overlay="+overlayFile,
+		"-run=Test_versionUserDao", filepath.Join(rootDir, "internal", "dao")).CombinedOutput()
+	assert.NoError(t, err, string(out))
+}
+
+// add the generated go files to the packages of this repository without writing them into the source tree
+func writeOverlay(t *testing.T, rootDir string, outDir string, dirs ...string) string {
+	replace := map[string]string{}
+	for _, dir := range dirs {
+		files, err := filepath.Glob(filepath.Join(outDir, dir, "*.go"))
+		require.NoError(t, err)
+		for _, file := range files {
+			dstFile := filepath.Join(rootDir, dir, filepath.Base(file))
+			_, err = os.Stat(dstFile)
+			require.True(t, os.IsNotExist(err), "generated file %s already exists in %s", filepath.Base(file), dir)
+			replace[dstFile] = file
+		}
+	}
+
+	data, err := json.Marshal(map[string]interface{}{"Replace": replace})
+	require.NoError(t, err)
+	overlayFile := filepath.Join(outDir, "overlay.json")
+	require.NoError(t, os.WriteFile(overlayFile, data, 0o666))
+	return overlayFile
+}
@@ -125,7 +125,10 @@ using help:
 	cmd.Flags().StringVarP(&dbTables, "db-table", "t", "", "table name, multiple names separated by commas")
 	_ = cmd.MarkFlagRequired("db-table")
 	cmd.Flags().BoolVarP(&sqlArgs.IsEmbed, "embed", "e", false, "whether to embed gorm.model struct")
-	cmd.Flags().BoolVarP(&sqlArgs.IsExtendedAPI, "extended-api", "a", false, "whether to generate extended crud api, additional includes: DeleteByIDs, GetByCondition, ListByIDs, ListByLatestID")
+	cmd.Flags().BoolVarP(&sqlArgs.IsSoftDelete, "soft-delete", "", false, "whether to soft delete records by column deleted_at, it is always true if embed gorm.model struct")
+	cmd.Flags().BoolVarP(&sqlArgs.IsExtendedAPI, "extended-api", "a", false, "whether to generate extended crud api, additional includes: DeleteByIDs, GetByCondition, ListByIDs, ListByLatestID, ListByCursor, RestoreByID, ListDeleted, PurgeByID")
+	cmd.Flags().BoolVarP(&sqlArgs.IsRelation, "relation", "", false, "whether to generate association code based on foreign keys, the associated tables also need to be generated")
+	cmd.Flags().StringVarP(&sqlArgs.VersionColumn, "version-column", "", "version", "version column name of optimistic lock, the record is updated by compare-and-swap on this column if the table has it")
 	cmd.Flags().BoolVarP(&suitedMonoRepo, "suited-mono-repo", "l", false, "whether the generated code is suitable for mono-repo")
 	cmd.Flags().IntVarP(&sqlArgs.JSONNamedType, "json-name-type", "j", 1, "json tags name type, 0:snake case, 1:camel case")
 	cmd.Flags().StringVarP(&outPath, "out", "o", "", "output directory, default is ./handler-pb_<time>, "+flagTip("module-name", "server-name"))
@@ -184,6 +187,10 @@ func (g *handlerPbGenerator) generateCode() (string, error) {
 
 	info := g.codes[parser.CodeTypeCrudInfo]
 	crudInfo, _ := unmarshalCrudInfo(info)
+	g.fields = append(g.fields, optionalCodeFields(r, crudInfo, g.dbDriver)...)
+	if crudInfo.CheckCompositeKey() {
+		g.isExtendedAPI = false // the extended api is not supported for composite primary key
+	}
 	if crudInfo.CheckCommonType() {
 		selectFiles = map[string][]string{
 			"api/serverNameExample/v1": {
@@ -225,7 +232,7 @@ func (g *handlerPbGenerator) generateCode() (string, error) {
 	replaceFiles := make(map[string][]string)
 	switch strings.ToLower(g.dbDriver) {
 	case DBDriverMysql, DBDriverPostgresql, DBDriverTidb, DBDriverSqlite:
-		g.fields = append(g.fields, getExpectedSQLForDeletionField(g.isEmbed)...)
+		g.fields = append(g.fields, getExpectedSQLForDeletionField(g.isEmbed || crudInfo.CheckSoftDelete())...)
 		if g.isExtendedAPI {
 			var fields []replacer.Field
 			if !crudInfo.CheckCommonType() {
@@ -280,8 +287,8 @@ func (g *handlerPbGenerator) addFields(r replacer.Replacer) []replacer.Field {
 	var fields []replacer.Field
 	fields = append(fields, g.fields...)
 	fields = append(fields, deleteFieldsMark(r, modelFile, startMark, endMark)...)
-	fields = append(fields, deleteFieldsMark(r, daoFile, startMark, endMark)...)
-	fields = append(fields, deleteFieldsMark(r, daoMgoFile, startMark, endMark)...)
+	fields = append(fields, deleteAllFieldsMark(r, daoFile, startMark, endMark)...)
+	fields = append(fields, deleteAllFieldsMark(r, daoMgoFile, startMark, endMark)...)
 	fields = append(fields, deleteFieldsMark(r, daoTestFile, startMark, endMark)...)
 	fields = append(fields, deleteFieldsMark(r, handlerLogicFile, startMark, endMark)...)
 	fields = append(fields, deleteFieldsMark(r, handlerPbTestFile, startMark, endMark)...)
@@ -295,6 +302,14 @@ func (g *handlerPbGenerator) addFields(r replacer.Replacer) []replacer.Field {
 			Old: daoFileMark,
 			New: g.codes[parser.CodeTypeDAO],
 		},
+		{
+			Old: daoRelationFileMark,
+			New: g.codes[parser.CodeTypeDAORelation],
+		},
+		{
+			Old: daoWhitelistMark,
+			New: g.codes[parser.CodeTypeWhitelist],
+		},
 		{ // replace the contents of the handler/userExample_logic.go file
 			Old: embedTimeMark,
 			New: getEmbedTimeCode(g.isEmbed),
@@ -392,7 +407,7 @@ func handlerPbExtendedAPI(r replacer.Replacer) (map[string][]string, []replacer.
 
 	var fields []replacer.Field
 
-	fields = append(fields, deleteFieldsMark(r, daoFile+expSuffix, startMark, endMark)...)
+	fields = append(fields, deleteAllFieldsMark(r, daoFile+expSuffix, startMark, endMark)...)
 	fields = append(fields, deleteFieldsMark(r, daoTestFile+expSuffix, startMark, endMark)...)
 	fields = append(fields, deleteFieldsMark(r, handlerLogicFile+expSuffix, startMark, endMark)...)
 
@@ -440,7 +455,7 @@ func handlerPbMongoDBExtendedAPI(r replacer.Replacer) (map[string][]string, []re
 
 	var fields []replacer.Field
 
-	fields = append(fields, deleteFieldsMark(r, daoMgoFile+expSuffix, startMark, endMark)...)
+	fields = append(fields, deleteAllFieldsMark(r, daoMgoFile+expSuffix, startMark, endMark)...)
 	fields = append(fields, deleteFieldsMark(r, handlerLogicFile+".mgo.exp", startMark, endMark)...)
 
 	fields = append(fields, []replacer.Field{
@@ -468,7 +483,7 @@ func handlerPbMongoDBExtendedAPI(r replacer.Replacer) (map[string][]string, []re
 func commonHandlerPbFields(r replacer.Replacer) []replacer.Field {
 	var fields []replacer.Field
 
-	fields = append(fields, deleteFieldsMark(r, daoFile+tplSuffix, startMark, endMark)...)
+	fields = append(fields, deleteAllFieldsMark(r, daoFile+tplSuffix, startMark, endMark)...)
 	fields = append(fields, deleteFieldsMark(r, handlerPbFile+tplSuffix, startMark, endMark)...)
 	fields = append(fields, []replacer.Field{
 		{
@@ -491,7 +506,7 @@ func commonHandlerPbFields(r replacer.Replacer) []replacer.Field {
 func commonHandlerPbExtendedFields(r replacer.Replacer) []replacer.Field {
 	var fields []replacer.Field
 
-	fields = append(fields, deleteFieldsMark(r, daoFile+expSuffix+tplSuffix, startMark, endMark)...)
+	fields = append(fields, deleteAllFieldsMark(r, daoFile+expSuffix+tplSuffix, startMark, endMark)...)
 	fields = append(fields, deleteFieldsMark(r, handlerPbFile+expSuffix+tplSuffix, startMark, endMark)...)
 
 	fields = append(fields, []replacer.Field{
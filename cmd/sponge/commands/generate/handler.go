@@ -121,7 +121,10 @@ using help:
 	cmd.Flags().StringVarP(&dbTables, "db-table", "t", "", "table name, multiple names separated by commas")
 	_ = cmd.MarkFlagRequired("db-table")
 	cmd.Flags().BoolVarP(&sqlArgs.IsEmbed, "embed", "e", false, "whether to embed gorm.model struct")
-	cmd.Flags().BoolVarP(&sqlArgs.IsExtendedAPI, "extended-api", "a", false, "whether to generate extended crud api, additional includes: DeleteByIDs, GetByCondition, ListByIDs, ListByLatestID")
+	cmd.Flags().BoolVarP(&sqlArgs.IsSoftDelete, "soft-delete", "", false, "whether to soft delete records by column deleted_at, it is always true if embed gorm.model struct")
+	cmd.Flags().BoolVarP(&sqlArgs.IsExtendedAPI, "extended-api", "a", false, "whether to generate extended crud api, additional includes: DeleteByIDs, GetByCondition, ListByIDs, ListByLatestID, ListByCursor, RestoreByID, ListDeleted, PurgeByID")
+	cmd.Flags().BoolVarP(&sqlArgs.IsRelation, "relation", "", false, "whether to generate association code based on foreign keys, the associated tables also need to be generated")
+	cmd.Flags().StringVarP(&sqlArgs.VersionColumn, "version-column", "", "version", "version column name of optimistic lock, the record is updated by compare-and-swap on this column if the table has it")
 	cmd.Flags().BoolVarP(&suitedMonoRepo, "suited-mono-repo", "l", false, "whether the generated code is suitable for mono-repo")
 	cmd.Flags().IntVarP(&sqlArgs.JSONNamedType, "json-name-type", "j", 1, "json tags name type, 0:snake case, 1:camel case")
 	cmd.Flags().StringVarP(&outPath, "out", "o", "", "output directory, default is ./handler_<time>, "+flagTip("module-name"))
@@ -180,6 +183,10 @@ func (g *handlerGenerator) generateCode() (string, error) {
 
 	info := g.codes[parser.CodeTypeCrudInfo]
 	crudInfo, _ := unmarshalCrudInfo(info)
+	g.fields = append(g.fields, optionalCodeFields(r, crudInfo, g.dbDriver)...)
+	if crudInfo.CheckCompositeKey() {
+		g.isExtendedAPI = false // the extended api is not supported for composite primary key
+	}
 	if crudInfo.CheckCommonType() {
 		g.isCommonStyle = true
 		selectFiles = map[string][]string{
@@ -227,7 +234,7 @@ func (g *handlerGenerator) generateCode() (string, error) {
 	replaceFiles := make(map[string][]string)
 	switch strings.ToLower(g.dbDriver) {
 	case DBDriverMysql, DBDriverPostgresql, DBDriverTidb, DBDriverSqlite:
-		g.fields = append(g.fields, getExpectedSQLForDeletionField(g.isEmbed)...)
+		g.fields = append(g.fields, getExpectedSQLForDeletionField(g.isEmbed || crudInfo.CheckSoftDelete())...)
 		if g.isExtendedAPI {
 			var fields []replacer.Field
 			if !crudInfo.CheckCommonType() {
@@ -279,8 +286,8 @@ func (g *handlerGenerator) addFields(r replacer.Replacer) []replacer.Field {
 	var fields []replacer.Field
 	fields = append(fields, g.fields...)
 	fields = append(fields, deleteFieldsMark(r, modelFile, startMark, endMark)...)
-	fields = append(fields, deleteFieldsMark(r, daoFile, startMark, endMark)...)
-	fields = append(fields, deleteFieldsMark(r, daoMgoFile, startMark, endMark)...)
+	fields = append(fields, deleteAllFieldsMark(r, daoFile, startMark, endMark)...)
+	fields = append(fields, deleteAllFieldsMark(r, daoMgoFile, startMark, endMark)...)
 	fields = append(fields, deleteFieldsMark(r, daoTestFile, startMark, endMark)...)
 	fields = append(fields, deleteFieldsMark(r, typesFile, startMark, endMark)...)
 	fields = append(fields, deleteFieldsMark(r, typesMgoFile, startMark, endMark)...)
@@ -294,6 +301,14 @@ func (g *handlerGenerator) addFields(r replacer.Replacer) []replacer.Field {
 			Old: daoFileMark,
 			New: g.codes[parser.CodeTypeDAO],
 		},
+		{
+			Old: daoRelationFileMark,
+			New: g.codes[parser.CodeTypeDAORelation],
+		},
+		{
+			Old: daoWhitelistMark,
+			New: g.codes[parser.CodeTypeWhitelist],
+		},
 		{ // replace the contents of the handler/userExample.go file
 			Old: handlerFileMark,
 			New: adjustmentOfIDType(g.codes[parser.CodeTypeHandler], g.dbDriver, g.isCommonStyle),
@@ -367,7 +382,7 @@ func handlerExtendedAPI(r replacer.Replacer, codeName string) (map[string][]stri
 
 	var fields []replacer.Field
 
-	fields = append(fields, deleteFieldsMark(r, daoFile+expSuffix, startMark, endMark)...)
+	fields = append(fields, deleteAllFieldsMark(r, daoFile+expSuffix, startMark, endMark)...)
 	fields = append(fields, deleteFieldsMark(r, daoTestFile+expSuffix, startMark, endMark)...)
 	fields = append(fields, deleteFieldsMark(r, typesFile+expSuffix, startMark, endMark)...)
 	fields = append(fields, deleteFieldsMark(r, handlerTestFile+expSuffix, startMark, endMark)...)
@@ -423,7 +438,7 @@ func handlerMongoDBExtendedAPI(r replacer.Replacer, codeName string) (map[string
 
 	var fields []replacer.Field
 
-	fields = append(fields, deleteFieldsMark(r, daoMgoFile+expSuffix, startMark, endMark)...)
+	fields = append(fields, deleteAllFieldsMark(r, daoMgoFile+expSuffix, startMark, endMark)...)
 	fields = append(fields, deleteFieldsMark(r, typesMgoFile+expSuffix, startMark, endMark)...)
 
 	fields = append(fields, []replacer.Field{
@@ -451,7 +466,7 @@ func handlerMongoDBExtendedAPI(r replacer.Replacer, codeName string) (map[string
 func commonHandlerFields(r replacer.Replacer) []replacer.Field {
 	var fields []replacer.Field
 
-	fields = append(fields, deleteFieldsMark(r, daoFile+tplSuffix, startMark, endMark)...)
+	fields = append(fields, deleteAllFieldsMark(r, daoFile+tplSuffix, startMark, endMark)...)
 	fields = append(fields, deleteFieldsMark(r, daoTestFile+tplSuffix, startMark, endMark)...)
 	fields = append(fields, deleteFieldsMark(r, typesFile+tplSuffix, startMark, endMark)...)
 
@@ -476,7 +491,7 @@ func commonHandlerFields(r replacer.Replacer) []replacer.Field {
 func commonHandlerExtendedFields(r replacer.Replacer) []replacer.Field {
 	var fields []replacer.Field
 
-	fields = append(fields, deleteFieldsMark(r, daoFile+expSuffix+tplSuffix, startMark, endMark)...)
+	fields = append(fields, deleteAllFieldsMark(r, daoFile+expSuffix+tplSuffix, startMark, endMark)...)
 	fields = append(fields, deleteFieldsMark(r, daoTestFile+expSuffix+tplSuffix, startMark, endMark)...)
 	fields = append(fields, deleteFieldsMark(r, typesFile+expSuffix+tplSuffix, startMark, endMark)...)
 
@@ -158,7 +158,10 @@ using help:
 	cmd.Flags().StringVarP(&dbTables, "db-table", "t", "", "table name, multiple names separated by commas")
 	_ = cmd.MarkFlagRequired("db-table")
 	cmd.Flags().BoolVarP(&sqlArgs.IsEmbed, "embed", "e", false, "whether to embed gorm.model struct")
-	cmd.Flags().BoolVarP(&sqlArgs.IsExtendedAPI, "extended-api", "a", false, "whether to generate extended crud api, additional includes: DeleteByIDs, GetByCondition, ListByIDs, ListByLatestID")
+	cmd.Flags().BoolVarP(&sqlArgs.IsSoftDelete, "soft-delete", "", false, "whether to soft delete records by column deleted_at, it is always true if embed gorm.model struct")
+	cmd.Flags().BoolVarP(&sqlArgs.IsExtendedAPI, "extended-api", "a", false, "whether to generate extended crud api, additional includes: DeleteByIDs, GetByCondition, ListByIDs, ListByLatestID, ListByCursor, RestoreByID, ListDeleted, PurgeByID")
+	cmd.Flags().BoolVarP(&sqlArgs.IsRelation, "relation", "", false, "whether to generate association code based on foreign keys, the associated tables also need to be generated")
+	cmd.Flags().StringVarP(&sqlArgs.VersionColumn, "version-column", "", "version", "version column name of optimistic lock, the record is updated by compare-and-swap on this column if the table has it")
 	cmd.Flags().BoolVarP(&suitedMonoRepo, "suited-mono-repo", "l", false, "whether the generated code is suitable for mono-repo")
 	cmd.Flags().IntVarP(&sqlArgs.JSONNamedType, "json-name-type", "j", 1, "json tags name type, 0:snake case, 1:camel case")
 	cmd.Flags().StringVarP(&repoAddr, "repo-addr", "r", "", "docker image repository address, excluding http and repository names")
@@ -245,6 +248,10 @@ func (g *httpGenerator) generateCode() (string, error) {
 
 	info := g.codes[parser.CodeTypeCrudInfo]
 	crudInfo, _ := unmarshalCrudInfo(info)
+	g.fields = append(g.fields, optionalCodeFields(r, crudInfo, g.dbDriver)...)
+	if crudInfo.CheckCompositeKey() {
+		g.isExtendedAPI = false // the extended api is not supported for composite primary key
+	}
 	if crudInfo.CheckCommonType() {
 		g.isCommonStyle = true
 		selectFiles["internal/cache"] = []string{"userExample.go.tpl"}
@@ -275,7 +282,7 @@ func (g *httpGenerator) generateCode() (string, error) {
 	replaceFiles := make(map[string][]string)
 	switch strings.ToLower(g.dbDriver) {
 	case DBDriverMysql, DBDriverPostgresql, DBDriverTidb, DBDriverSqlite:
-		g.fields = append(g.fields, getExpectedSQLForDeletionField(g.isEmbed)...)
+		g.fields = append(g.fields, getExpectedSQLForDeletionField(g.isEmbed || crudInfo.CheckSoftDelete())...)
 		if g.isExtendedAPI {
 			var fields []replacer.Field
 			if !crudInfo.CheckCommonType() {
@@ -338,8 +345,8 @@ func (g *httpGenerator) addFields(r replacer.Replacer) []replacer.Field {
 	fields = append(fields, g.fields...)
 	fields = append(fields, deleteFieldsMark(r, modelFile, startMark, endMark)...)
 	fields = append(fields, deleteFieldsMark(r, databaseInitDBFile, startMark, endMark)...)
-	fields = append(fields, deleteFieldsMark(r, daoFile, startMark, endMark)...)
-	fields = append(fields, deleteFieldsMark(r, daoMgoFile, startMark, endMark)...)
+	fields = append(fields, deleteAllFieldsMark(r, daoFile, startMark, endMark)...)
+	fields = append(fields, deleteAllFieldsMark(r, daoMgoFile, startMark, endMark)...)
 	fields = append(fields, deleteFieldsMark(r, daoTestFile, startMark, endMark)...)
 	fields = append(fields, deleteFieldsMark(r, typesFile, startMark, endMark)...)
 	fields = append(fields, deleteFieldsMark(r, typesMgoFile, startMark, endMark)...)
@@ -381,6 +388,14 @@ func (g *httpGenerator) addFields(r replacer.Replacer) []replacer.Field {
 			Old: daoFileMark,
 			New: g.codes[parser.CodeTypeDAO],
 		},
+		{
+			Old: daoRelationFileMark,
+			New: g.codes[parser.CodeTypeDAORelation],
+		},
+		{
+			Old: daoWhitelistMark,
+			New: g.codes[parser.CodeTypeWhitelist],
+		},
 		{ // replace the contents of the handler/userExample.go file
 			Old: handlerFileMark,
 			New: adjustmentOfIDType(g.codes[parser.CodeTypeHandler], g.dbDriver, g.isCommonStyle),
@@ -83,6 +83,8 @@ using help:
 	cmd.Flags().StringVarP(&dbTables, "db-table", "t", "", "table name, multiple names separated by commas")
 	_ = cmd.MarkFlagRequired("db-table")
 	cmd.Flags().BoolVarP(&sqlArgs.IsEmbed, "embed", "e", false, "whether to embed gorm.model struct")
+	cmd.Flags().BoolVarP(&sqlArgs.IsSoftDelete, "soft-delete", "", false, "whether to soft delete records by column deleted_at, it is always true if embed gorm.model struct")
+	cmd.Flags().BoolVarP(&sqlArgs.IsRelation, "relation", "", false, "whether to generate association code based on foreign keys, the associated tables also need to be generated")
 	cmd.Flags().IntVarP(&sqlArgs.JSONNamedType, "json-name-type", "j", 1, "json tags name type, 0:snake case, 1:camel case")
 	cmd.Flags().StringVarP(&outPath, "out", "o", "", "output directory, default is ./model_<time>")
 
@@ -0,0 +1,28 @@
+package commands
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/go-dev-frame/sponge/cmd/sponge/commands/migrate"
+)
+
+// MigrateCommand schema migration command
+func MigrateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Generate and apply schema migrations based on DDL diffs",
+		Long: `Generate versioned up/down migration files by comparing two versions of DDL, or a DDL file against a database,
+and apply or rollback the migration files.`,
+		SilenceErrors: true,
+		SilenceUsage:  true,
+	}
+
+	cmd.AddCommand(
+		migrate.DiffCommand(),
+		migrate.UpCommand(),
+		migrate.DownCommand(),
+		migrate.StatusCommand(),
+	)
+
+	return cmd
+}
@@ -0,0 +1,57 @@
+package migrate
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"github.com/go-dev-frame/sponge/pkg/sgorm"
+	"github.com/go-dev-frame/sponge/pkg/sgorm/mysql"
+	"github.com/go-dev-frame/sponge/pkg/sgorm/postgresql"
+	"github.com/go-dev-frame/sponge/pkg/sgorm/sqlite"
+	"github.com/go-dev-frame/sponge/pkg/utils"
+)
+
+const defaultMigrationDir = "./migrations"
+
+func adaptDsn(dbDriver string, dsn string) string {
+	switch strings.ToLower(dbDriver) {
+	case sgorm.DBDriverMysql, sgorm.DBDriverTidb:
+		return utils.AdaptiveMysqlDsn(dsn)
+	case sgorm.DBDriverPostgresql:
+		return utils.AdaptivePostgresqlDsn(dsn)
+	}
+	return dsn
+}
+
+func openDB(dbDriver string, dsn string) (*gorm.DB, func(), error) {
+	var (
+		db  *gorm.DB
+		err error
+	)
+	dsn = adaptDsn(dbDriver, dsn)
+
+	switch strings.ToLower(dbDriver) {
+	case sgorm.DBDriverMysql:
+		db, err = mysql.Init(dsn)
+	case sgorm.DBDriverTidb:
+		db, err = mysql.InitTidb(dsn)
+	case sgorm.DBDriverPostgresql:
+		db, err = postgresql.Init(dsn)
+	case sgorm.DBDriverSqlite:
+		db, err = sqlite.Init(dsn)
+	default:
+		return nil, nil, fmt.Errorf("unsupported database driver %s, only mysql, tidb, postgresql and sqlite are supported", dbDriver)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	closeDB := func() {
+		if sqlDB, e := db.DB(); e == nil {
+			_ = sqlDB.Close()
+		}
+	}
+	return db, closeDB, nil
+}
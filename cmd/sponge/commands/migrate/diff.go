@@ -0,0 +1,104 @@
+// Package migrate is the subcommands of schema migration.
+package migrate
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/go-dev-frame/sponge/pkg/sgorm/migrate"
+	"github.com/go-dev-frame/sponge/pkg/sql2code/parser"
+)
+
+// DiffCommand generate migration files by comparing DDL
+func DiffCommand() *cobra.Command {
+	var (
+		dbDriver string
+		dbDsn    string
+		oldFile  string
+		newFile  string
+		name     string
+		outPath  string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Generate up/down migration files by comparing DDL",
+		Long:  "Generate up/down migration files by comparing two versions of DDL file, or a DDL file against the tables in database.",
+		Example: color.HiBlackString(`  # Generate migration files by comparing two versions of mysql DDL file.
+  sponge migrate diff --old=v1.sql --new=v2.sql --name=add_user_phone
+
+  # Generate migration files by comparing the DDL file with the tables in database, only the tables in DDL file are compared.
+  sponge migrate diff --db-driver=mysql --db-dsn=root:123456@(192.168.3.37:3306)/test --new=user.sql --name=add_user_phone
+
+  # Generate migration files of postgresql and specify the output directory.
+  sponge migrate diff --db-driver=postgresql --old=v1.sql --new=v2.sql --out=./migrations`),
+		SilenceErrors: true,
+		SilenceUsage:  true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			newData, err := os.ReadFile(newFile)
+			if err != nil {
+				return fmt.Errorf("read %s failed, %v", newFile, err)
+			}
+			newTables, err := parser.ParseTableSchemas(string(newData), dbDriver)
+			if err != nil {
+				return fmt.Errorf("parse %s failed, %v", newFile, err)
+			}
+
+			var oldTables []*parser.TableSchema
+			switch {
+			case oldFile != "":
+				oldData, err := os.ReadFile(oldFile)
+				if err != nil {
+					return fmt.Errorf("read %s failed, %v", oldFile, err)
+				}
+				oldTables, err = parser.ParseTableSchemas(string(oldData), dbDriver)
+				if err != nil {
+					return fmt.Errorf("parse %s failed, %v", oldFile, err)
+				}
+			case dbDsn != "":
+				dsn := adaptDsn(dbDriver, dbDsn)
+				for _, table := range newTables {
+					oldTable, err := parser.GetTableSchema(dbDriver, dsn, table.Name)
+					if err != nil {
+						return fmt.Errorf("get table %s from db failed, %v", table.Name, err)
+					}
+					if oldTable != nil {
+						oldTables = append(oldTables, oldTable)
+					}
+				}
+			default:
+				return errors.New("you must specify the old DDL file (--old) or database (--db-dsn)")
+			}
+
+			diff, err := parser.DiffTableSchemas(oldTables, newTables, dbDriver)
+			if err != nil {
+				return err
+			}
+			if diff.IsEmpty() {
+				fmt.Println("no schema changes found, ignore generating migration files.")
+				return nil
+			}
+
+			upFile, downFile, err := migrate.WriteFiles(outPath, name, diff.Up, diff.Down)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("generate migration files successfully:\n    %s\n    %s\n", upFile, downFile)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&dbDriver, "db-driver", "k", "mysql", "database driver, support mysql, tidb, postgresql, sqlite")
+	cmd.Flags().StringVarP(&dbDsn, "db-dsn", "d", "", "database content address, e.g. user:password@(host:port)/database. Note: if db-driver=sqlite, db-dsn must be a local sqlite db file") //nolint
+	cmd.Flags().StringVarP(&oldFile, "old", "", "", "old version of DDL file, if it is empty, compare with the tables in database specified by --db-dsn")
+	cmd.Flags().StringVarP(&newFile, "new", "f", "", "new version of DDL file")
+	_ = cmd.MarkFlagRequired("new")
+	cmd.Flags().StringVarP(&name, "name", "n", "migration", "migration name, used as the suffix of migration file name")
+	cmd.Flags().StringVarP(&outPath, "out", "o", defaultMigrationDir, "directory of migration files")
+
+	return cmd
+}
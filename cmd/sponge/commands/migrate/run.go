@@ -0,0 +1,162 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/go-dev-frame/sponge/pkg/sgorm/migrate"
+)
+
+type runArgs struct {
+	dbDriver  string
+	dbDsn     string
+	dir       string
+	tableName string
+}
+
+func (a *runArgs) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&a.dbDriver, "db-driver", "k", "mysql", "database driver, support mysql, tidb, postgresql, sqlite")
+	cmd.Flags().StringVarP(&a.dbDsn, "db-dsn", "d", "", "database content address, e.g. user:password@(host:port)/database. Note: if db-driver=sqlite, db-dsn must be a local sqlite db file") //nolint
+	_ = cmd.MarkFlagRequired("db-dsn")
+	cmd.Flags().StringVarP(&a.dir, "dir", "", defaultMigrationDir, "directory of migration files")
+	cmd.Flags().StringVarP(&a.tableName, "table", "", "schema_migrations", "name of the table that records the applied migrations")
+}
+
+// run the function with migrator, the db is closed after running
+func (a *runArgs) run(fn func(m *migrate.Migrator) error) error {
+	db, closeDB, err := openDB(a.dbDriver, a.dbDsn)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	m, err := migrate.New(db, a.dir, migrate.WithTableName(a.tableName))
+	if err != nil {
+		return err
+	}
+	return fn(m)
+}
+
+// UpCommand apply the pending migrations
+func UpCommand() *cobra.Command {
+	var (
+		args  = &runArgs{}
+		steps int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "up",
+		Short: "Apply the pending migrations",
+		Long:  "Apply the pending migrations in ascending order of version.",
+		Example: color.HiBlackString(`  # Apply all pending migrations.
+  sponge migrate up --db-driver=mysql --db-dsn=root:123456@(192.168.3.37:3306)/test
+
+  # Apply the next pending migration, and specify the directory of migration files.
+  sponge migrate up --db-driver=sqlite --db-dsn=/tmp/test.db --dir=./migrations --steps=1`),
+		SilenceErrors: true,
+		SilenceUsage:  true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return args.run(func(m *migrate.Migrator) error {
+				done, err := m.Up(context.Background(), steps)
+				for _, migration := range done {
+					fmt.Printf("applied    %s_%s\n", migration.Version, migration.Name)
+				}
+				if err != nil {
+					return err
+				}
+				if len(done) == 0 {
+					fmt.Println("no pending migrations.")
+				}
+				return nil
+			})
+		},
+	}
+
+	args.addFlags(cmd)
+	cmd.Flags().IntVarP(&steps, "steps", "s", 0, "number of migrations to apply, 0 means all pending migrations")
+
+	return cmd
+}
+
+// DownCommand rollback the applied migrations
+func DownCommand() *cobra.Command {
+	var (
+		args  = &runArgs{}
+		steps int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "down",
+		Short: "Rollback the applied migrations",
+		Long:  "Rollback the applied migrations in descending order of version.",
+		Example: color.HiBlackString(`  # Rollback the latest applied migration.
+  sponge migrate down --db-driver=mysql --db-dsn=root:123456@(192.168.3.37:3306)/test
+
+  # Rollback the latest 2 applied migrations.
+  sponge migrate down --db-driver=mysql --db-dsn=root:123456@(192.168.3.37:3306)/test --steps=2`),
+		SilenceErrors: true,
+		SilenceUsage:  true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return args.run(func(m *migrate.Migrator) error {
+				done, err := m.Down(context.Background(), steps)
+				for _, migration := range done {
+					fmt.Printf("rolled back    %s_%s\n", migration.Version, migration.Name)
+				}
+				if err != nil {
+					return err
+				}
+				if len(done) == 0 {
+					fmt.Println("no applied migrations.")
+				}
+				return nil
+			})
+		},
+	}
+
+	args.addFlags(cmd)
+	cmd.Flags().IntVarP(&steps, "steps", "s", 1, "number of migrations to rollback")
+
+	return cmd
+}
+
+// StatusCommand show the status of migrations
+func StatusCommand() *cobra.Command {
+	args := &runArgs{}
+
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show the status of migrations",
+		Long:  "Show the status of migrations.",
+		Example: color.HiBlackString(`  # Show the status of migrations.
+  sponge migrate status --db-driver=mysql --db-dsn=root:123456@(192.168.3.37:3306)/test`),
+		SilenceErrors: true,
+		SilenceUsage:  true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return args.run(func(m *migrate.Migrator) error {
+				list, err := m.Status(context.Background())
+				if err != nil {
+					return err
+				}
+				if len(list) == 0 {
+					fmt.Printf("no migration files found in %s\n", args.dir)
+					return nil
+				}
+				for _, status := range list {
+					appliedAt := "pending"
+					if status.Applied {
+						appliedAt = "applied at " + status.AppliedAt.Format("2006-01-02 15:04:05")
+					}
+					fmt.Printf("%s_%s    %s\n", status.Version, status.Name, appliedAt)
+				}
+				return nil
+			})
+		},
+	}
+
+	args.addFlags(cmd)
+
+	return cmd
+}
@@ -38,21 +38,32 @@ type cacheNameExampleCache struct {
 	cache cache.Cache
 }
 
-// NewCacheNameExampleCache create a new cache
-func NewCacheNameExampleCache(cacheType *database.CacheType) CacheNameExampleCache {
+// NewCacheNameExampleCache create a new cache, the value is encoded by json by default, pass in valueEncoding to change it,
+// e.g. encoding.NewCompressEncoding(encoding.JSONEncoding{}) compresses the large value,
+// encoding.GetCodec(proto.Name) encodes the value if the value type is a protobuf message.
+func NewCacheNameExampleCache(cacheType *database.CacheType, valueEncoding ...encoding.Encoding) CacheNameExampleCache {
 	newObject := func() interface{} {
 		return ""
 	}
 	cachePrefix := ""
-	jsonEncoding := encoding.JSONEncoding{}
+	var enc encoding.Encoding = encoding.JSONEncoding{}
+	if len(valueEncoding) > 0 && valueEncoding[0] != nil {
+		enc = valueEncoding[0]
+	}
 
 	cType := strings.ToLower(cacheType.CType)
 	switch cType {
 	case "redis":
-		c := cache.NewRedisCache(cacheType.Rdb, cachePrefix, jsonEncoding, newObject)
+		c := cache.NewRedisCache(cacheType.Rdb, cachePrefix, enc, newObject)
+		c = cacheType.WithMetrics(c)
+		return &cacheNameExampleCache{cache: c}
+	case "multilevel":
+		c := cache.NewMultiLevelCache(cacheType.Rdb, cachePrefix, enc, newObject)
+		c = cacheType.WithMetrics(c)
 		return &cacheNameExampleCache{cache: c}
 	case "memory":
-		c := cache.NewMemoryCache(cachePrefix, jsonEncoding, newObject)
+		c := cache.NewMemoryCache(cachePrefix, enc, newObject)
+		c = cacheType.WithMetrics(c)
 		return &cacheNameExampleCache{cache: c}
 	}
 
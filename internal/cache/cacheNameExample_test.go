@@ -89,6 +89,14 @@ func TestNewCacheNameExampleCache(t *testing.T) {
 	})
 	assert.NotNil(t, c)
 
+	rc := gotest.NewCache(nil)
+	defer rc.Close()
+	c = NewCacheNameExampleCache(&database.CacheType{
+		CType: "multilevel",
+		Rdb:   rc.RedisClient,
+	})
+	assert.NotNil(t, c)
+
 	defer func() {
 		_ = recover()
 	}()
@@ -2,13 +2,11 @@ package cache
 
 import (
 	"context"
-	"errors"
-	"strings"
 	"time"
 
 	"github.com/go-dev-frame/sponge/pkg/cache"
 	"github.com/go-dev-frame/sponge/pkg/encoding"
-	"github.com/go-dev-frame/sponge/pkg/utils"
+	"github.com/go-dev-frame/sponge/pkg/sgorm/query"
 
 	"github.com/go-dev-frame/sponge/internal/database"
 	"github.com/go-dev-frame/sponge/internal/model"
@@ -19,6 +17,13 @@ const (
 	userExampleCachePrefixKey = "userExample:"
 	// UserExampleExpireTime expire time
 	UserExampleExpireTime = 5 * time.Minute
+
+	// cache prefix key of paging records, must end with a colon
+	userExampleListCachePrefixKey = "userExampleList:"
+	// tag of paging records, all paging records are deleted by the tag when the table is changed
+	userExampleListCacheTag = "userExampleList"
+	// UserExampleListExpireTime expire time of paging records
+	UserExampleListExpireTime = time.Minute
 )
 
 var _ UserExampleCache = (*userExampleCache)(nil)
@@ -27,127 +32,39 @@ var _ UserExampleCache = (*userExampleCache)(nil)
 type UserExampleCache interface {
 	Set(ctx context.Context, id uint64, data *model.UserExample, duration time.Duration) error
 	Get(ctx context.Context, id uint64) (*model.UserExample, error)
+	GetWithRefresh(ctx context.Context, id uint64) (*model.UserExample, bool, error)
+	Refresh(id uint64, loader func(ctx context.Context) (*model.UserExample, error))
 	MultiGet(ctx context.Context, ids []uint64) (map[uint64]*model.UserExample, error)
 	MultiSet(ctx context.Context, data []*model.UserExample, duration time.Duration) error
 	Del(ctx context.Context, id uint64) error
+	GetList(ctx context.Context, params *query.Params) ([]*model.UserExample, int64, error)
+	SetList(ctx context.Context, params *query.Params, records []*model.UserExample, total int64, duration time.Duration) error
+	DelList(ctx context.Context) error
 	SetPlaceholder(ctx context.Context, id uint64) error
 	IsPlaceholderErr(err error) bool
 }
 
-// userExampleCache define a cache struct
+// userExampleCache define a cache struct, all methods are provided by the embedded cache.DaoCache
 type userExampleCache struct {
-	cache cache.Cache
-}
-
-// NewUserExampleCache new a cache
-func NewUserExampleCache(cacheType *database.CacheType) UserExampleCache {
-	jsonEncoding := encoding.JSONEncoding{}
-	cachePrefix := ""
-
-	cType := strings.ToLower(cacheType.CType)
-	switch cType {
-	case "redis":
-		c := cache.NewRedisCache(cacheType.Rdb, cachePrefix, jsonEncoding, func() interface{} {
-			return &model.UserExample{}
-		})
-		return &userExampleCache{cache: c}
-	case "memory":
-		c := cache.NewMemoryCache(cachePrefix, jsonEncoding, func() interface{} {
-			return &model.UserExample{}
-		})
-		return &userExampleCache{cache: c}
-	}
-
-	return nil // no cache
-}
-
-// GetUserExampleCacheKey cache key
-func (c *userExampleCache) GetUserExampleCacheKey(id uint64) string {
-	return userExampleCachePrefixKey + utils.Uint64ToStr(id)
+	*cache.DaoCache[uint64, *model.UserExample, *query.Params]
 }
 
-// Set write to cache
-func (c *userExampleCache) Set(ctx context.Context, id uint64, data *model.UserExample, duration time.Duration) error {
-	if data == nil || id == 0 {
-		return nil
-	}
-	cacheKey := c.GetUserExampleCacheKey(id)
-	err := c.cache.Set(ctx, cacheKey, data, duration)
-	if err != nil {
-		return err
-	}
-	return nil
-}
-
-// Get cache value
-func (c *userExampleCache) Get(ctx context.Context, id uint64) (*model.UserExample, error) {
-	var data *model.UserExample
-	cacheKey := c.GetUserExampleCacheKey(id)
-	err := c.cache.Get(ctx, cacheKey, &data)
-	if err != nil {
-		return nil, err
-	}
-	return data, nil
-}
-
-// MultiSet multiple set cache
-func (c *userExampleCache) MultiSet(ctx context.Context, data []*model.UserExample, duration time.Duration) error {
-	valMap := make(map[string]interface{})
-	for _, v := range data {
-		cacheKey := c.GetUserExampleCacheKey(v.ID)
-		valMap[cacheKey] = v
+// NewUserExampleCache new a cache, the value is encoded by json by default, pass in valueEncoding to change it,
+// e.g. encoding.NewCompressEncoding(encoding.JSONEncoding{}, encoding.WithCompressType(encoding.CompressZstd))
+func NewUserExampleCache(cacheType *database.CacheType, valueEncoding ...encoding.Encoding) UserExampleCache {
+	c := cacheType.NewCache(func() interface{} {
+		return cache.NewSWREntry(&model.UserExample{})
+	}, valueEncoding...)
+	if c == nil {
+		return nil // no cache
 	}
 
-	err := c.cache.MultiSet(ctx, valMap, duration)
-	if err != nil {
-		return err
+	return &userExampleCache{
+		DaoCache: cache.NewDaoCache[uint64, *model.UserExample, *query.Params](c, userExampleCachePrefixKey,
+			func(v *model.UserExample) uint64 { return v.ID },
+			cache.WithDaoExpiration(UserExampleExpireTime),
+			cache.WithDaoList(userExampleListCachePrefixKey, userExampleListCacheTag),
+			cache.WithDaoNotFoundErrors(database.ErrRecordNotFound),
+		),
 	}
-
-	return nil
-}
-
-// MultiGet multiple get cache, return key in map is id value
-func (c *userExampleCache) MultiGet(ctx context.Context, ids []uint64) (map[uint64]*model.UserExample, error) {
-	var keys []string
-	for _, v := range ids {
-		cacheKey := c.GetUserExampleCacheKey(v)
-		keys = append(keys, cacheKey)
-	}
-
-	itemMap := make(map[string]*model.UserExample)
-	err := c.cache.MultiGet(ctx, keys, itemMap)
-	if err != nil {
-		return nil, err
-	}
-
-	retMap := make(map[uint64]*model.UserExample)
-	for _, id := range ids {
-		val, ok := itemMap[c.GetUserExampleCacheKey(id)]
-		if ok {
-			retMap[id] = val
-		}
-	}
-
-	return retMap, nil
-}
-
-// Del delete cache
-func (c *userExampleCache) Del(ctx context.Context, id uint64) error {
-	cacheKey := c.GetUserExampleCacheKey(id)
-	err := c.cache.Del(ctx, cacheKey)
-	if err != nil {
-		return err
-	}
-	return nil
-}
-
-// SetPlaceholder set placeholder value to cache
-func (c *userExampleCache) SetPlaceholder(ctx context.Context, id uint64) error {
-	cacheKey := c.GetUserExampleCacheKey(id)
-	return c.cache.SetCacheWithNotFound(ctx, cacheKey)
-}
-
-// IsPlaceholderErr check if cache is placeholder error
-func (c *userExampleCache) IsPlaceholderErr(err error) bool {
-	return errors.Is(err, cache.ErrPlaceholder)
 }
@@ -2,12 +2,11 @@ package cache
 
 import (
 	"context"
-	"errors"
-	"strings"
 	"time"
 
 	"github.com/go-dev-frame/sponge/pkg/cache"
 	"github.com/go-dev-frame/sponge/pkg/encoding"
+	"github.com/go-dev-frame/sponge/pkg/sgorm/query"
 	"github.com/go-dev-frame/sponge/pkg/utils"
 
 	"github.com/go-dev-frame/sponge/internal/database"
@@ -19,135 +18,94 @@ const (
 	{{.TableNameCamelFCL}}CachePrefixKey = "{{.TableNameCamelFCL}}:"
 	// {{.TableNameCamel}}ExpireTime expire time
 	{{.TableNameCamel}}ExpireTime = 5 * time.Minute
+
+	// cache prefix key of paging records, must end with a colon
+	{{.TableNameCamelFCL}}ListCachePrefixKey = "{{.TableNameCamelFCL}}List:"
+	// tag of paging records, all paging records are deleted by the tag when the table is changed
+	{{.TableNameCamelFCL}}ListCacheTag = "{{.TableNameCamelFCL}}List"
+	// {{.TableNameCamel}}ListExpireTime expire time of paging records
+	{{.TableNameCamel}}ListExpireTime = time.Minute
 )
 
 var _ {{.TableNameCamel}}Cache = (*{{.TableNameCamelFCL}}Cache)(nil)
 
 // {{.TableNameCamel}}Cache cache interface
 type {{.TableNameCamel}}Cache interface {
-	Set(ctx context.Context, {{.ColumnNameCamelFCL}} {{.GoType}}, data *model.{{.TableNameCamel}}, duration time.Duration) error
-	Get(ctx context.Context, {{.ColumnNameCamelFCL}} {{.GoType}}) (*model.{{.TableNameCamel}}, error)
+	Set(ctx context.Context, {{.GetKeyParams}}, data *model.{{.TableNameCamel}}, duration time.Duration) error
+	Get(ctx context.Context, {{.GetKeyParams}}) (*model.{{.TableNameCamel}}, error)
+	GetWithRefresh(ctx context.Context, {{.GetKeyParams}}) (*model.{{.TableNameCamel}}, bool, error)
+	Refresh({{.GetKeyParams}}, loader func(ctx context.Context) (*model.{{.TableNameCamel}}, error))
+{{- if not .IsCompositeKey}}
 	MultiGet(ctx context.Context, {{.ColumnNamePluralCamelFCL}} []{{.GoType}}) (map[{{.GoType}}]*model.{{.TableNameCamel}}, error)
+{{- end}}
 	MultiSet(ctx context.Context, data []*model.{{.TableNameCamel}}, duration time.Duration) error
-	Del(ctx context.Context, {{.ColumnNameCamelFCL}} {{.GoType}}) error
-	SetPlaceholder(ctx context.Context, {{.ColumnNameCamelFCL}} {{.GoType}}) error
+	Del(ctx context.Context, {{.GetKeyParams}}) error
+	GetList(ctx context.Context, params *query.Params) ([]*model.{{.TableNameCamel}}, int64, error)
+	SetList(ctx context.Context, params *query.Params, records []*model.{{.TableNameCamel}}, total int64, duration time.Duration) error
+	DelList(ctx context.Context) error
+	SetPlaceholder(ctx context.Context, {{.GetKeyParams}}) error
 	IsPlaceholderErr(err error) bool
 }
 
-// {{.TableNameCamelFCL}}Cache define a cache struct
+// {{.TableNameCamelFCL}}Cache define a cache struct, {{if .IsCompositeKey}}the methods of composite primary key convert the key
+// and call the embedded cache.DaoCache{{else}}all methods are provided by the embedded cache.DaoCache{{end}}
 type {{.TableNameCamelFCL}}Cache struct {
-	cache cache.Cache
+	*cache.DaoCache[{{if .IsCompositeKey}}string{{else}}{{.GoType}}{{end}}, *model.{{.TableNameCamel}}, *query.Params]
 }
 
-// New{{.TableNameCamel}}Cache new a cache
-func New{{.TableNameCamel}}Cache(cacheType *database.CacheType) {{.TableNameCamel}}Cache {
-	jsonEncoding := encoding.JSONEncoding{}
-	cachePrefix := ""
-
-	cType := strings.ToLower(cacheType.CType)
-	switch cType {
-	case "redis":
-		c := cache.NewRedisCache(cacheType.Rdb, cachePrefix, jsonEncoding, func() interface{} {
-			return &model.{{.TableNameCamel}}{}
-		})
-		return &{{.TableNameCamelFCL}}Cache{cache: c}
-	case "memory":
-		c := cache.NewMemoryCache(cachePrefix, jsonEncoding, func() interface{} {
-			return &model.{{.TableNameCamel}}{}
-		})
-		return &{{.TableNameCamelFCL}}Cache{cache: c}
+// New{{.TableNameCamel}}Cache new a cache, the value is encoded by json by default, pass in valueEncoding to change it,
+// e.g. encoding.NewCompressEncoding(encoding.JSONEncoding{}, encoding.WithCompressType(encoding.CompressZstd))
+func New{{.TableNameCamel}}Cache(cacheType *database.CacheType, valueEncoding ...encoding.Encoding) {{.TableNameCamel}}Cache {
+	c := cacheType.NewCache(func() interface{} {
+		return cache.NewSWREntry(&model.{{.TableNameCamel}}{})
+	}, valueEncoding...)
+	if c == nil {
+		return nil // no cache
 	}
 
-	return nil // no cache
+	return &{{.TableNameCamelFCL}}Cache{
+		DaoCache: cache.NewDaoCache[{{if .IsCompositeKey}}string{{else}}{{.GoType}}{{end}}, *model.{{.TableNameCamel}}, *query.Params](c, {{.TableNameCamelFCL}}CachePrefixKey,
+			func(v *model.{{.TableNameCamel}}) {{if .IsCompositeKey}}string{{else}}{{.GoType}}{{end}} { return {{if .IsCompositeKey}}{{.TableNameCamelFCL}}CacheKey({{.GetKeyFieldArgs "v"}}){{else}}{{.GetKeyFieldArgs "v"}}{{end}} },
+			cache.WithDaoExpiration({{.TableNameCamel}}ExpireTime),
+			cache.WithDaoList({{.TableNameCamelFCL}}ListCachePrefixKey, {{.TableNameCamelFCL}}ListCacheTag),
+			cache.WithDaoNotFoundErrors(database.ErrRecordNotFound),
+		),
+	}
 }
-
-// Get{{.TableNameCamel}}CacheKey cache key
-func (c *{{.TableNameCamelFCL}}Cache) Get{{.TableNameCamel}}CacheKey({{.ColumnNameCamelFCL}} {{.GoType}}) string {
-	{{if .IsStringType}}return {{.TableNameCamelFCL}}CachePrefixKey + {{.ColumnNameCamelFCL}}{{else}}return {{.TableNameCamelFCL}}CachePrefixKey + utils.{{.GoTypeFCU}}ToStr({{.ColumnNameCamelFCL}}){{end}}
+{{if .IsCompositeKey}}
+// {{.TableNameCamelFCL}}CacheKey key of the composite primary key, without the prefix
+func {{.TableNameCamelFCL}}CacheKey({{.GetKeyParams}}) string {
+	return {{.GetKeyStr}}
 }
 
 // Set write to cache
-func (c *{{.TableNameCamelFCL}}Cache) Set(ctx context.Context, {{.ColumnNameCamelFCL}} {{.GoType}}, data *model.{{.TableNameCamel}}, duration time.Duration) error {
-	if data == nil {
-		return nil
-	}
-	cacheKey := c.Get{{.TableNameCamel}}CacheKey({{.ColumnNameCamelFCL}})
-	err := c.cache.Set(ctx, cacheKey, data, duration)
-	if err != nil {
-		return err
-	}
-	return nil
+func (c *{{.TableNameCamelFCL}}Cache) Set(ctx context.Context, {{.GetKeyParams}}, data *model.{{.TableNameCamel}}, duration time.Duration) error {
+	return c.DaoCache.Set(ctx, {{.TableNameCamelFCL}}CacheKey({{.GetKeyArgs}}), data, duration)
 }
 
 // Get cache value
-func (c *{{.TableNameCamelFCL}}Cache) Get(ctx context.Context, {{.ColumnNameCamelFCL}} {{.GoType}}) (*model.{{.TableNameCamel}}, error) {
-	var data *model.{{.TableNameCamel}}
-	cacheKey := c.Get{{.TableNameCamel}}CacheKey({{.ColumnNameCamelFCL}})
-	err := c.cache.Get(ctx, cacheKey, &data)
-	if err != nil {
-		return nil, err
-	}
-	return data, nil
+func (c *{{.TableNameCamelFCL}}Cache) Get(ctx context.Context, {{.GetKeyParams}}) (*model.{{.TableNameCamel}}, error) {
+	return c.DaoCache.Get(ctx, {{.TableNameCamelFCL}}CacheKey({{.GetKeyArgs}}))
 }
 
-// MultiSet multiple set cache
-func (c *{{.TableNameCamelFCL}}Cache) MultiSet(ctx context.Context, data []*model.{{.TableNameCamel}}, duration time.Duration) error {
-	valMap := make(map[string]interface{})
-	for _, v := range data {
-		cacheKey := c.Get{{.TableNameCamel}}CacheKey(v.{{.ColumnNameCamel}})
-		valMap[cacheKey] = v
-	}
-
-	err := c.cache.MultiSet(ctx, valMap, duration)
-	if err != nil {
-		return err
-	}
-
-	return nil
+// GetWithRefresh get cache value, the stale value is also returned, and report whether it should be refreshed
+func (c *{{.TableNameCamelFCL}}Cache) GetWithRefresh(ctx context.Context, {{.GetKeyParams}}) (*model.{{.TableNameCamel}}, bool, error) {
+	return c.DaoCache.GetWithRefresh(ctx, {{.TableNameCamelFCL}}CacheKey({{.GetKeyArgs}}))
 }
 
-// MultiGet multiple get cache, return key in map is {{.ColumnNameCamelFCL}} value
-func (c *{{.TableNameCamelFCL}}Cache) MultiGet(ctx context.Context, {{.ColumnNamePluralCamelFCL}} []{{.GoType}}) (map[{{.GoType}}]*model.{{.TableNameCamel}}, error) {
-	var keys []string
-	for _, v := range {{.ColumnNamePluralCamelFCL}} {
-		cacheKey := c.Get{{.TableNameCamel}}CacheKey(v)
-		keys = append(keys, cacheKey)
-	}
-
-	itemMap := make(map[string]*model.{{.TableNameCamel}})
-	err := c.cache.MultiGet(ctx, keys, itemMap)
-	if err != nil {
-		return nil, err
-	}
-
-	retMap := make(map[{{.GoType}}]*model.{{.TableNameCamel}})
-	for _, {{.ColumnNameCamelFCL}} := range {{.ColumnNamePluralCamelFCL}} {
-		val, ok := itemMap[c.Get{{.TableNameCamel}}CacheKey({{.ColumnNameCamelFCL}})]
-		if ok {
-			retMap[{{.ColumnNameCamelFCL}}] = val
-		}
-	}
-
-	return retMap, nil
+// Refresh reload the value by loader in the background and write to cache,
+// if the record is not found, set placeholder value to cache
+func (c *{{.TableNameCamelFCL}}Cache) Refresh({{.GetKeyParams}}, loader func(ctx context.Context) (*model.{{.TableNameCamel}}, error)) {
+	c.DaoCache.Refresh({{.TableNameCamelFCL}}CacheKey({{.GetKeyArgs}}), loader)
 }
 
 // Del delete cache
-func (c *{{.TableNameCamelFCL}}Cache) Del(ctx context.Context, {{.ColumnNameCamelFCL}} {{.GoType}}) error {
-	cacheKey := c.Get{{.TableNameCamel}}CacheKey({{.ColumnNameCamelFCL}})
-	err := c.cache.Del(ctx, cacheKey)
-	if err != nil {
-		return err
-	}
-	return nil
+func (c *{{.TableNameCamelFCL}}Cache) Del(ctx context.Context, {{.GetKeyParams}}) error {
+	return c.DaoCache.Del(ctx, {{.TableNameCamelFCL}}CacheKey({{.GetKeyArgs}}))
 }
 
 // SetPlaceholder set placeholder value to cache
-func (c *{{.TableNameCamelFCL}}Cache) SetPlaceholder(ctx context.Context, {{.ColumnNameCamelFCL}} {{.GoType}}) error {
-	cacheKey := c.Get{{.TableNameCamel}}CacheKey({{.ColumnNameCamelFCL}})
-	return c.cache.SetCacheWithNotFound(ctx, cacheKey)
-}
-
-// IsPlaceholderErr check if cache is placeholder error
-func (c *{{.TableNameCamelFCL}}Cache) IsPlaceholderErr(err error) bool {
-	return errors.Is(err, cache.ErrPlaceholder)
+func (c *{{.TableNameCamelFCL}}Cache) SetPlaceholder(ctx context.Context, {{.GetKeyParams}}) error {
+	return c.DaoCache.SetPlaceholder(ctx, {{.TableNameCamelFCL}}CacheKey({{.GetKeyArgs}}))
 }
+{{end -}}
@@ -1,12 +1,15 @@
 package cache
 
 import (
+	"context"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/go-dev-frame/sponge/pkg/encoding"
 	"github.com/go-dev-frame/sponge/pkg/gotest"
+	"github.com/go-dev-frame/sponge/pkg/sgorm/query"
 	"github.com/go-dev-frame/sponge/pkg/utils"
 
 	"github.com/go-dev-frame/sponge/internal/database"
@@ -67,6 +70,48 @@ func Test_userExampleCache_Get(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func Test_userExampleCache_GetWithRefresh(t *testing.T) {
+	c := newUserExampleCache()
+	defer c.Close()
+
+	record := c.TestDataSlice[0].(*model.UserExample)
+	err := c.ICache.(UserExampleCache).Set(c.Ctx, record.ID, record, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond * 5)
+
+	// stale record is returned
+	got, isRefresh, err := c.ICache.(UserExampleCache).GetWithRefresh(c.Ctx, record.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, isRefresh)
+	assert.Equal(t, record, got)
+
+	// refresh in the background
+	refreshed := &model.UserExample{}
+	refreshed.ID = 100
+	c.ICache.(UserExampleCache).Refresh(record.ID, func(ctx context.Context) (*model.UserExample, error) {
+		return refreshed, nil
+	})
+	time.Sleep(time.Millisecond * 50)
+	got, isRefresh, err = c.ICache.(UserExampleCache).GetWithRefresh(c.Ctx, record.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.False(t, isRefresh)
+	assert.Equal(t, refreshed.ID, got.ID)
+
+	// record not found, set placeholder
+	c.ICache.(UserExampleCache).Refresh(record.ID, func(ctx context.Context) (*model.UserExample, error) {
+		return nil, database.ErrRecordNotFound
+	})
+	time.Sleep(time.Millisecond * 50)
+	_, _, err = c.ICache.(UserExampleCache).GetWithRefresh(c.Ctx, record.ID)
+	assert.True(t, c.ICache.(UserExampleCache).IsPlaceholderErr(err))
+}
+
 func Test_userExampleCache_MultiGet(t *testing.T) {
 	c := newUserExampleCache()
 	defer c.Close()
@@ -118,6 +163,39 @@ func Test_userExampleCache_Del(t *testing.T) {
 	}
 }
 
+func Test_userExampleCache_List(t *testing.T) {
+	c := newUserExampleCache()
+	defer c.Close()
+
+	var records []*model.UserExample
+	for _, data := range c.TestDataSlice {
+		records = append(records, data.(*model.UserExample))
+	}
+	params := &query.Params{Page: 0, Limit: 10}
+
+	_, _, err := c.ICache.(UserExampleCache).GetList(c.Ctx, params)
+	assert.Error(t, err)
+
+	err = c.ICache.(UserExampleCache).SetList(c.Ctx, params, records, int64(len(records)), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, total, err := c.ICache.(UserExampleCache).GetList(c.Ctx, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, len(records), len(got))
+	assert.Equal(t, int64(len(records)), total)
+
+	// all paging records are deleted
+	err = c.ICache.(UserExampleCache).DelList(c.Ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = c.ICache.(UserExampleCache).GetList(c.Ctx, params)
+	assert.Error(t, err)
+}
+
 func Test_userExampleCache_SetCacheWithNotFound(t *testing.T) {
 	c := newUserExampleCache()
 	defer c.Close()
@@ -144,4 +222,25 @@ func TestNewUserExampleCache(t *testing.T) {
 		CType: "redis",
 	})
 	assert.NotNil(t, c)
+
+	rc := gotest.NewCache(nil)
+	defer rc.Close()
+	c = NewUserExampleCache(&database.CacheType{
+		CType: "multilevel",
+		Rdb:   rc.RedisClient,
+	})
+	assert.NotNil(t, c)
+
+	// custom value encoding
+	c = NewUserExampleCache(&database.CacheType{
+		CType: "redis",
+		Rdb:   rc.RedisClient,
+	}, encoding.NewCompressEncoding(encoding.JSONEncoding{}, encoding.WithCompressThreshold(0)))
+	record := &model.UserExample{}
+	record.ID = 1
+	err := c.Set(rc.Ctx, record.ID, record, time.Minute)
+	assert.NoError(t, err)
+	got, err := c.Get(rc.Ctx, record.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, record.ID, got.ID)
 }
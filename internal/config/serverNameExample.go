@@ -37,6 +37,7 @@ type Config struct {
 	GrpcClient []GrpcClient `yaml:"grpcClient" json:"grpcClient"`
 	HTTP       HTTP         `yaml:"http" json:"http"`
 	Jaeger     Jaeger       `yaml:"jaeger" json:"jaeger"`
+	Kafka      Kafka        `yaml:"kafka" json:"kafka"`
 	Logger     Logger       `yaml:"logger" json:"logger"`
 	NacosRd    NacosRd      `yaml:"nacosRd" json:"nacosRd"`
 	Redis      Redis        `yaml:"redis" json:"redis"`
@@ -50,6 +51,23 @@ type Etcd struct {
 	Addrs []string `yaml:"addrs" json:"addrs"`
 }
 
+type GroupTopics struct {
+	GroupID string   `yaml:"groupID" json:"groupID"`
+	Topics  []string `yaml:"topics" json:"topics"`
+}
+
+type Lag struct {
+	Enable      bool          `yaml:"enable" json:"enable"`
+	GroupTopics []GroupTopics `yaml:"groupTopics" json:"groupTopics"`
+	Interval    int           `yaml:"interval" json:"interval"`
+	Threshold   int           `yaml:"threshold" json:"threshold"`
+}
+
+type Kafka struct {
+	Addrs []string `yaml:"addrs" json:"addrs"`
+	Lag   Lag      `yaml:"lag" json:"lag"`
+}
+
 type Jaeger struct {
 	AgentHost string `yaml:"agentHost" json:"agentHost"`
 	AgentPort int    `yaml:"agentPort" json:"agentPort"`
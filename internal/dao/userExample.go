@@ -51,14 +51,30 @@ func NewUserExampleDao(db *gorm.DB, xCache cache.UserExampleCache) UserExampleDa
 
 func (d *userExampleDao) deleteCache(ctx context.Context, id uint64) error {
 	if d.cache != nil {
+		_ = d.cache.DelList(ctx)
 		return d.cache.Del(ctx, id)
 	}
 	return nil
 }
 
+// deleteListCache delete the cached paging records, the paging records are changed after creating a record
+func (d *userExampleDao) deleteListCache(ctx context.Context) {
+	if d.cache != nil {
+		_ = d.cache.DelList(ctx)
+	}
+}
+
 // Create a record, insert the record and the id value is written back to the table
 func (d *userExampleDao) Create(ctx context.Context, table *model.UserExample) error {
-	return d.db.WithContext(ctx).Create(table).Error
+	err := d.db.WithContext(ctx).Create(table).Error
+	if err != nil {
+		return err
+	}
+
+	// delete cache of paging records
+	d.deleteListCache(ctx)
+
+	return nil
 }
 
 // DeleteByID delete a record by id
@@ -77,11 +93,14 @@ func (d *userExampleDao) DeleteByID(ctx context.Context, id uint64) error {
 // UpdateByID update a record by id
 func (d *userExampleDao) UpdateByID(ctx context.Context, table *model.UserExample) error {
 	err := d.updateDataByID(ctx, d.db, table)
+	if err != nil {
+		return err
+	}
 
 	// delete cache
 	_ = d.deleteCache(ctx, table.ID)
 
-	return err
+	return nil
 }
 
 func (d *userExampleDao) updateDataByID(ctx context.Context, db *gorm.DB, table *model.UserExample) error {
@@ -130,9 +149,16 @@ func (d *userExampleDao) GetByID(ctx context.Context, id uint64) (*model.UserExa
 		return record, err
 	}
 
-	// get from cache
-	record, err := d.cache.Get(ctx, id)
+	// get from cache, the stale record is also returned and refreshed in the background
+	record, isRefresh, err := d.cache.GetWithRefresh(ctx, id)
 	if err == nil {
+		if isRefresh {
+			d.cache.Refresh(id, func(ctx context.Context) (*model.UserExample, error) {
+				table := &model.UserExample{}
+				err := d.db.WithContext(ctx).Where("id = ?", id).First(table).Error
+				return table, err
+			})
+		}
 		return record, nil
 	}
 
@@ -175,6 +201,19 @@ func (d *userExampleDao) GetByID(ctx context.Context, id uint64) (*model.UserExa
 	return nil, err
 }
 
+// todo generate the whitelist code to here
+// delete the templates code start
+
+// userExampleWhitelist columns allowed to be used by the query parameters of userExample,
+// the sensitive columns are excluded, modify the columns as needed.
+var userExampleWhitelist = &query.Whitelist{
+	Filter: []string{"id", "email"},                                                                                               // columns allowed in query conditions, only indexed columns
+	Sort:   []string{"id", "email"},                                                                                               // columns allowed in sort, only indexed columns
+	Return: []string{"id", "created_at", "updated_at", "name", "email", "phone", "avatar", "age", "gender", "status", "login_at"}, // columns allowed to be returned
+}
+
+// delete the templates code end
+
 // GetByColumns get paging records by column information,
 // Note: query performance degrades when table rows are very large because of the use of offset.
 //
@@ -188,9 +227,13 @@ func (d *userExampleDao) GetByID(ctx context.Context, id uint64) (*model.UserExa
 // query parameters (not required):
 //
 //	name: column name
-//	exp: expressions, which default is "=",  support =, !=, >, >=, <, <=, like, in
-//	value: column value, if exp=in, multiple values are separated by commas
+//	exp: expressions, which default is "=",  support =, !=, >, >=, <, <=, like, in, notin, between, isnull, notnull, notlike, prefix, suffix
+//	value: column value, if exp=in, notin or between, multiple values are separated by commas
 //	logic: logical type, default value is "and", support &, and, ||, or
+//	where: conditions of tree structure, e.g. (a=1 OR b=2) AND (c=3 OR d=4), it is combined with the columns by "and"
+//	fields: fields to be returned, multiple fields separated by comma
+//
+// the columns of query conditions, sort and fields are restricted by userExampleWhitelist
 //
 // example: search for a male over 20 years of age
 //
@@ -209,10 +252,21 @@ func (d *userExampleDao) GetByID(ctx context.Context, id uint64) (*model.UserExa
 //		},
 //	}
 func (d *userExampleDao) GetByColumns(ctx context.Context, params *query.Params) ([]*model.UserExample, int64, error) {
-	queryStr, args, err := params.ConvertToGormConditions()
+	queryStr, args, err := params.ConvertToGormConditions(query.WithWhitelist(userExampleWhitelist))
 	if err != nil {
 		return nil, 0, errors.New("query params error: " + err.Error())
 	}
+	fields, err := params.ConvertToFields(query.WithWhitelist(userExampleWhitelist))
+	if err != nil {
+		return nil, 0, errors.New("query params error: " + err.Error())
+	}
+
+	// get from cache
+	if d.cache != nil {
+		if records, total, cacheErr := d.cache.GetList(ctx, params); cacheErr == nil {
+			return records, total, nil
+		}
+	}
 
 	var total int64
 	if params.Sort != "ignore count" { // determine if count is required
@@ -227,17 +281,32 @@ func (d *userExampleDao) GetByColumns(ctx context.Context, params *query.Params)
 
 	records := []*model.UserExample{}
 	order, limit, offset := params.ConvertToPage()
-	err = d.db.WithContext(ctx).Order(order).Limit(limit).Offset(offset).Where(queryStr, args...).Find(&records).Error
+	db := d.db.WithContext(ctx)
+	if len(fields) > 0 {
+		db = db.Select(fields)
+	}
+	err = db.Order(order).Limit(limit).Offset(offset).Where(queryStr, args...).Find(&records).Error
 	if err != nil {
 		return nil, 0, err
 	}
 
-	return records, total, err
+	// set cache of paging records
+	if d.cache != nil {
+		if err = d.cache.SetList(ctx, params, records, total, cache.UserExampleListExpireTime); err != nil {
+			logger.Warn("cache.SetList error", logger.Err(err), logger.Any("params", params))
+		}
+	}
+
+	return records, total, nil
 }
 
 // CreateByTx create a record in the database using the provided transaction
 func (d *userExampleDao) CreateByTx(ctx context.Context, tx *gorm.DB, table *model.UserExample) (uint64, error) {
 	err := tx.WithContext(ctx).Create(table).Error
+	if err == nil {
+		// delete cache of paging records
+		d.deleteListCache(ctx)
+	}
 	return table.ID, err
 }
 
@@ -257,9 +326,14 @@ func (d *userExampleDao) DeleteByTx(ctx context.Context, tx *gorm.DB, id uint64)
 // UpdateByTx update a record by id in the database using the provided transaction
 func (d *userExampleDao) UpdateByTx(ctx context.Context, tx *gorm.DB, table *model.UserExample) error {
 	err := d.updateDataByID(ctx, tx, table)
+	if err != nil {
+		return err
+	}
 
 	// delete cache
 	_ = d.deleteCache(ctx, table.ID)
 
-	return err
+	return nil
 }
+
+// todo generate the relation code to here
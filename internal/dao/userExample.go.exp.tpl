@@ -3,7 +3,6 @@ package dao
 import (
 	"context"
 	"errors"
-	"time"
 
 	"golang.org/x/sync/singleflight"
 	"gorm.io/gorm"
@@ -31,6 +30,11 @@ type {{.TableNameCamel}}Dao interface {
 	GetByCondition(ctx context.Context, condition *query.Conditions) (*model.{{.TableNameCamel}}, error)
 	GetBy{{.ColumnNamePluralCamel}}(ctx context.Context, {{.ColumnNamePluralCamelFCL}} []{{.GoType}}) (map[{{.GoType}}]*model.{{.TableNameCamel}}, error)
 	GetByLast{{.ColumnNameCamel}}(ctx context.Context, last{{.ColumnNameCamel}} {{.GoType}}, limit int, sort string) ([]*model.{{.TableNameCamel}}, error)
+	// soft delete code start
+	RestoreBy{{.ColumnNameCamel}}(ctx context.Context, {{.ColumnNameCamelFCL}} {{.GoType}}) error
+	GetDeletedByColumns(ctx context.Context, params *query.Params) ([]*model.{{.TableNameCamel}}, int64, error)
+	PurgeBy{{.ColumnNameCamel}}(ctx context.Context, {{.ColumnNameCamelFCL}} {{.GoType}}) error
+	// soft delete code end
 
 	CreateByTx(ctx context.Context, tx *gorm.DB, table *model.{{.TableNameCamel}}) ({{.GoType}}, error)
 	DeleteByTx(ctx context.Context, tx *gorm.DB, {{.ColumnNameCamelFCL}} {{.GoType}}) error
@@ -57,14 +61,30 @@ func New{{.TableNameCamel}}Dao(db *gorm.DB, xCache cache.{{.TableNameCamel}}Cach
 
 func (d *{{.TableNameCamelFCL}}Dao) deleteCache(ctx context.Context, {{.ColumnNameCamelFCL}} {{.GoType}}) error {
 	if d.cache != nil {
+		_ = d.cache.DelList(ctx)
 		return d.cache.Del(ctx, {{.ColumnNameCamelFCL}})
 	}
 	return nil
 }
 
+// deleteListCache delete the cached paging records, the paging records are changed after creating a record
+func (d *{{.TableNameCamelFCL}}Dao) deleteListCache(ctx context.Context) {
+	if d.cache != nil {
+		_ = d.cache.DelList(ctx)
+	}
+}
+
 // Create a record, insert the record and the {{.ColumnNameCamelFCL}} value is written back to the table
 func (d *{{.TableNameCamelFCL}}Dao) Create(ctx context.Context, table *model.{{.TableNameCamel}}) error {
-	return d.db.WithContext(ctx).Create(table).Error
+	err := d.db.WithContext(ctx).Create(table).Error
+	if err != nil {
+		return err
+	}
+
+	// delete cache of paging records
+	d.deleteListCache(ctx)
+
+	return nil
 }
 
 // DeleteBy{{.ColumnNameCamel}} delete a record by {{.ColumnNameCamelFCL}}
@@ -140,9 +160,16 @@ func (d *{{.TableNameCamelFCL}}Dao) GetBy{{.ColumnNameCamel}}(ctx context.Contex
 		return record, err
 	}
 
-	// get from cache
-	record, err := d.cache.Get(ctx, {{.ColumnNameCamelFCL}})
+	// get from cache, the stale record is also returned and refreshed in the background
+	record, isRefresh, err := d.cache.GetWithRefresh(ctx, {{.ColumnNameCamelFCL}})
 	if err == nil {
+		if isRefresh {
+			d.cache.Refresh({{.ColumnNameCamelFCL}}, func(ctx context.Context) (*model.{{.TableNameCamel}}, error) {
+				table := &model.{{.TableNameCamel}}{}
+				err := d.db.WithContext(ctx).Where("{{.ColumnName}} = ?", {{.ColumnNameCamelFCL}}).First(table).Error
+				return table, err
+			})
+		}
 		return record, nil
 	}
 
@@ -187,6 +214,8 @@ func (d *{{.TableNameCamelFCL}}Dao) GetBy{{.ColumnNameCamel}}(ctx context.Contex
 	return nil, err
 }
 
+// todo generate the whitelist code to here
+
 // GetByColumns get paging records by column information,
 // Note: query performance degrades when table rows are very large because of the use of offset.
 //
@@ -200,9 +229,13 @@ func (d *{{.TableNameCamelFCL}}Dao) GetBy{{.ColumnNameCamel}}(ctx context.Contex
 // query parameters (not required):
 //
 //	name: column name
-//	exp: expressions, which default is "=",  support =, !=, >, >=, <, <=, like, in
-//	value: column value, if exp=in, multiple values are separated by commas
+//	exp: expressions, which default is "=",  support =, !=, >, >=, <, <=, like, in, notin, between, isnull, notnull, notlike, prefix, suffix
+//	value: column value, if exp=in, notin or between, multiple values are separated by commas
 //	logic: logical type, default value is "and", support &, and, ||, or
+//	where: conditions of tree structure, e.g. (a=1 OR b=2) AND (c=3 OR d=4), it is combined with the columns by "and"
+//	fields: fields to be returned, multiple fields separated by comma
+//
+// the columns of query conditions, sort and fields are restricted by {{.TableNameCamelFCL}}Whitelist
 //
 // example: search for a male over 20 years of age
 //
@@ -224,11 +257,22 @@ func (d *{{.TableNameCamelFCL}}Dao) GetByColumns(ctx context.Context, params *qu
 	if params.Sort == "" {
 		params.Sort = "-{{.ColumnName}}"
 	}
-	queryStr, args, err := params.ConvertToGormConditions()
+	queryStr, args, err := params.ConvertToGormConditions(query.WithWhitelist({{.TableNameCamelFCL}}Whitelist))
+	if err != nil {
+		return nil, 0, errors.New("query params error: " + err.Error())
+	}
+	fields, err := params.ConvertToFields(query.WithWhitelist({{.TableNameCamelFCL}}Whitelist))
 	if err != nil {
 		return nil, 0, errors.New("query params error: " + err.Error())
 	}
 
+	// get from cache
+	if d.cache != nil {
+		if records, total, cacheErr := d.cache.GetList(ctx, params); cacheErr == nil {
+			return records, total, nil
+		}
+	}
+
 	var total int64
 	if params.Sort != "ignore count" { // determine if count is required
 		err = d.db.WithContext(ctx).Model(&model.{{.TableNameCamel}}{}).Where(queryStr, args...).Count(&total).Error
@@ -242,12 +286,23 @@ func (d *{{.TableNameCamelFCL}}Dao) GetByColumns(ctx context.Context, params *qu
 
 	records := []*model.{{.TableNameCamel}}{}
 	order, limit, offset := params.ConvertToPage()
-	err = d.db.WithContext(ctx).Order(order).Limit(limit).Offset(offset).Where(queryStr, args...).Find(&records).Error
+	db := d.db.WithContext(ctx)
+	if len(fields) > 0 {
+		db = db.Select(fields)
+	}
+	err = db.Order(order).Limit(limit).Offset(offset).Where(queryStr, args...).Find(&records).Error
 	if err != nil {
 		return nil, 0, err
 	}
 
-	return records, total, err
+	// set cache of paging records
+	if d.cache != nil {
+		if err = d.cache.SetList(ctx, params, records, total, cache.{{.TableNameCamel}}ListExpireTime); err != nil {
+			logger.Warn("cache.SetList error", logger.Err(err), logger.Any("params", params))
+		}
+	}
+
+	return records, total, nil
 }
 
 // DeleteBy{{.ColumnNamePluralCamel}} delete records by batch {{.ColumnNameCamelFCL}}
@@ -269,8 +324,8 @@ func (d *{{.TableNameCamelFCL}}Dao) DeleteBy{{.ColumnNamePluralCamel}}(ctx conte
 // query conditions:
 //
 //	name: column name
-//	exp: expressions, which default is "=",  support =, !=, >, >=, <, <=, like, in
-//	value: column value, if exp=in, multiple values are separated by commas
+//	exp: expressions, which default is "=",  support =, !=, >, >=, <, <=, like, in, notin, between, isnull, notnull, notlike, prefix, suffix
+//	value: column value, if exp=in, notin or between, multiple values are separated by commas
 //	logic: logical type, default value is "and", support &, and, ||, or
 //
 // example: find a male aged 20
@@ -391,18 +446,92 @@ func (d *{{.TableNameCamelFCL}}Dao) GetByLast{{.ColumnNameCamel}}(ctx context.Co
 	return records, nil
 }
 
+// soft delete code start
+
+// RestoreBy{{.ColumnNameCamel}} restore a soft deleted record by {{.ColumnNameCamelFCL}}
+func (d *{{.TableNameCamelFCL}}Dao) RestoreBy{{.ColumnNameCamel}}(ctx context.Context, {{.ColumnNameCamelFCL}} {{.GoType}}) error {
+	result := d.db.WithContext(ctx).Unscoped().Model(&model.{{.TableNameCamel}}{}).
+		Where("{{.ColumnName}} = ?", {{.ColumnNameCamelFCL}}).Where("deleted_at IS NOT NULL").Update("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return database.ErrRecordNotFound
+	}
+
+	// delete cache
+	_ = d.deleteCache(ctx, {{.ColumnNameCamelFCL}})
+
+	return nil
+}
+
+// GetDeletedByColumns get paging records that have been soft deleted by column information,
+// the params are the same as GetByColumns
+func (d *{{.TableNameCamelFCL}}Dao) GetDeletedByColumns(ctx context.Context, params *query.Params) ([]*model.{{.TableNameCamel}}, int64, error) {
+	queryStr, args, err := params.ConvertToGormConditions(query.WithWhitelist({{.TableNameCamelFCL}}Whitelist))
+	if err != nil {
+		return nil, 0, errors.New("query params error: " + err.Error())
+	}
+	fields, err := params.ConvertToFields(query.WithWhitelist({{.TableNameCamelFCL}}Whitelist))
+	if err != nil {
+		return nil, 0, errors.New("query params error: " + err.Error())
+	}
+
+	var total int64
+	if params.Sort != "ignore count" { // determine if count is required
+		err = d.db.WithContext(ctx).Unscoped().Model(&model.{{.TableNameCamel}}{}).
+			Where("deleted_at IS NOT NULL").Where(queryStr, args...).Count(&total).Error
+		if err != nil {
+			return nil, 0, err
+		}
+		if total == 0 {
+			return nil, total, nil
+		}
+	}
+
+	records := []*model.{{.TableNameCamel}}{}
+	order, limit, offset := params.ConvertToPage()
+	db := d.db.WithContext(ctx).Unscoped()
+	if len(fields) > 0 {
+		db = db.Select(fields)
+	}
+	err = db.Order(order).Limit(limit).Offset(offset).
+		Where("deleted_at IS NOT NULL").Where(queryStr, args...).Find(&records).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return records, total, err
+}
+
+// PurgeBy{{.ColumnNameCamel}} permanently delete a record by {{.ColumnNameCamelFCL}}, whether it has been soft deleted or not
+func (d *{{.TableNameCamelFCL}}Dao) PurgeBy{{.ColumnNameCamel}}(ctx context.Context, {{.ColumnNameCamelFCL}} {{.GoType}}) error {
+	err := d.db.WithContext(ctx).Unscoped().Where("{{.ColumnName}} = ?", {{.ColumnNameCamelFCL}}).Delete(&model.{{.TableNameCamel}}{}).Error
+	if err != nil {
+		return err
+	}
+
+	// delete cache
+	_ = d.deleteCache(ctx, {{.ColumnNameCamelFCL}})
+
+	return nil
+}
+
+// soft delete code end
+
 // CreateByTx create a record in the database using the provided transaction
 func (d *{{.TableNameCamelFCL}}Dao) CreateByTx(ctx context.Context, tx *gorm.DB, table *model.{{.TableNameCamel}}) ({{.GoType}}, error) {
 	err := tx.WithContext(ctx).Create(table).Error
+	if err == nil {
+		// delete cache of paging records
+		d.deleteListCache(ctx)
+	}
 	return table.{{.ColumnNameCamel}}, err
 }
 
 // DeleteByTx delete a record by {{.ColumnNameCamelFCL}} in the database using the provided transaction
 func (d *{{.TableNameCamelFCL}}Dao) DeleteByTx(ctx context.Context, tx *gorm.DB, {{.ColumnNameCamelFCL}} {{.GoType}}) error {
-	update := map[string]interface{}{
-		"deleted_at": time.Now(),
-	}
-	err := tx.WithContext(ctx).Model(&model.{{.TableNameCamel}}{}).Where("{{.ColumnName}} = ?", {{.ColumnNameCamelFCL}}).Updates(update).Error
+	err := tx.WithContext(ctx).Where("{{.ColumnName}} = ?", {{.ColumnNameCamelFCL}}).Delete(&model.{{.TableNameCamel}}{}).Error
 	if err != nil {
 		return err
 	}
@@ -422,3 +551,5 @@ func (d *{{.TableNameCamelFCL}}Dao) UpdateByTx(ctx context.Context, tx *gorm.DB,
 
 	return err
 }
+
+// todo generate the relation code to here
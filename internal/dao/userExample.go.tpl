@@ -21,13 +21,18 @@ var _ {{.TableNameCamel}}Dao = (*{{.TableNameCamelFCL}}Dao)(nil)
 // {{.TableNameCamel}}Dao defining the dao interface
 type {{.TableNameCamel}}Dao interface {
 	Create(ctx context.Context, table *model.{{.TableNameCamel}}) error
-	DeleteBy{{.ColumnNameCamel}}(ctx context.Context, {{.ColumnNameCamelFCL}} {{.GoType}}) error
+	DeleteBy{{.ColumnNameCamel}}(ctx context.Context, {{.GetKeyParams}}) error
 	UpdateBy{{.ColumnNameCamel}}(ctx context.Context, table *model.{{.TableNameCamel}}) error
-	GetBy{{.ColumnNameCamel}}(ctx context.Context, {{.ColumnNameCamelFCL}} {{.GoType}}) (*model.{{.TableNameCamel}}, error)
+	GetBy{{.ColumnNameCamel}}(ctx context.Context, {{.GetKeyParams}}) (*model.{{.TableNameCamel}}, error)
 	GetByColumns(ctx context.Context, params *query.Params) ([]*model.{{.TableNameCamel}}, int64, error)
-
-	CreateByTx(ctx context.Context, tx *gorm.DB, table *model.{{.TableNameCamel}}) ({{.GoType}}, error)
-	DeleteByTx(ctx context.Context, tx *gorm.DB, {{.ColumnNameCamelFCL}} {{.GoType}}) error
+	// soft delete code start
+	RestoreBy{{.ColumnNameCamel}}(ctx context.Context, {{.GetKeyParams}}) error
+	GetDeletedByColumns(ctx context.Context, params *query.Params) ([]*model.{{.TableNameCamel}}, int64, error)
+	PurgeBy{{.ColumnNameCamel}}(ctx context.Context, {{.GetKeyParams}}) error
+	// soft delete code end
+
+	CreateByTx(ctx context.Context, tx *gorm.DB, table *model.{{.TableNameCamel}}) {{if .IsCompositeKey}}error{{else}}({{.GoType}}, error){{end}}
+	DeleteByTx(ctx context.Context, tx *gorm.DB, {{.GetKeyParams}}) error
 	UpdateByTx(ctx context.Context, tx *gorm.DB, table *model.{{.TableNameCamel}}) error
 }
 
@@ -49,27 +54,43 @@ func New{{.TableNameCamel}}Dao(db *gorm.DB, xCache cache.{{.TableNameCamel}}Cach
 	}
 }
 
-func (d *{{.TableNameCamelFCL}}Dao) deleteCache(ctx context.Context, {{.ColumnNameCamelFCL}} {{.GoType}}) error {
+func (d *{{.TableNameCamelFCL}}Dao) deleteCache(ctx context.Context, {{.GetKeyParams}}) error {
 	if d.cache != nil {
-		return d.cache.Del(ctx, {{.ColumnNameCamelFCL}})
+		_ = d.cache.DelList(ctx)
+		return d.cache.Del(ctx, {{.GetKeyArgs}})
 	}
 	return nil
 }
 
+// deleteListCache delete the cached paging records, the paging records are changed after creating a record
+func (d *{{.TableNameCamelFCL}}Dao) deleteListCache(ctx context.Context) {
+	if d.cache != nil {
+		_ = d.cache.DelList(ctx)
+	}
+}
+
 // Create a record, insert the record and the {{.ColumnNameCamelFCL}} value is written back to the table
 func (d *{{.TableNameCamelFCL}}Dao) Create(ctx context.Context, table *model.{{.TableNameCamel}}) error {
-	return d.db.WithContext(ctx).Create(table).Error
+	err := d.db.WithContext(ctx).Create(table).Error
+	if err != nil {
+		return err
+	}
+
+	// delete cache of paging records
+	d.deleteListCache(ctx)
+
+	return nil
 }
 
 // DeleteBy{{.ColumnNameCamel}} delete a record by {{.ColumnNameCamelFCL}}
-func (d *{{.TableNameCamelFCL}}Dao) DeleteBy{{.ColumnNameCamel}}(ctx context.Context, {{.ColumnNameCamelFCL}} {{.GoType}}) error {
-	err := d.db.WithContext(ctx).Where("{{.ColumnName}} = ?", {{.ColumnNameCamelFCL}}).Delete(&model.{{.TableNameCamel}}{}).Error
+func (d *{{.TableNameCamelFCL}}Dao) DeleteBy{{.ColumnNameCamel}}(ctx context.Context, {{.GetKeyParams}}) error {
+	err := d.db.WithContext(ctx).Where({{.GetKeyWhere}}).Delete(&model.{{.TableNameCamel}}{}).Error
 	if err != nil {
 		return err
 	}
 
 	// delete cache
-	_ = d.deleteCache(ctx, {{.ColumnNameCamelFCL}})
+	_ = d.deleteCache(ctx, {{.GetKeyArgs}})
 
 	return nil
 }
@@ -79,19 +100,19 @@ func (d *{{.TableNameCamelFCL}}Dao) UpdateBy{{.ColumnNameCamel}}(ctx context.Con
 	err := d.updateDataBy{{.ColumnNameCamel}}(ctx, d.db, table)
 
 	// delete cache
-	_ = d.deleteCache(ctx, table.{{.ColumnNameCamel}})
+	_ = d.deleteCache(ctx, {{.GetKeyFieldArgs "table"}})
 
 	return err
 }
 
 func (d *{{.TableNameCamelFCL}}Dao) updateDataBy{{.ColumnNameCamel}}(ctx context.Context, db *gorm.DB, table *model.{{.TableNameCamel}}) error {
-	{{if .IsStringType}}if table.{{.ColumnNameCamel}} == "" {
+{{- range .GetPrimaryKeys}}
+{{if .IsStringType}}	if table.{{.ColumnNameCamel}} == "" {
 		return errors.New("{{.ColumnNameCamelFCL}} cannot be empty")
-	}
-{{else}}	if table.{{.ColumnNameCamel}} < 1 {
+	}{{else}}	if table.{{.ColumnNameCamel}} < 1 {
 		return errors.New("{{.ColumnNameCamelFCL}} cannot be 0")
-	}
-{{end}}
+	}{{end}}
+{{- end}}
 
 	update := map[string]interface{}{}
 	// todo generate the update fields code to here
@@ -126,41 +147,46 @@ func (d *{{.TableNameCamelFCL}}Dao) updateDataBy{{.ColumnNameCamel}}(ctx context
 }
 
 // GetBy{{.ColumnNameCamel}} get a record by {{.ColumnNameCamelFCL}}
-func (d *{{.TableNameCamelFCL}}Dao) GetBy{{.ColumnNameCamel}}(ctx context.Context, {{.ColumnNameCamelFCL}} {{.GoType}}) (*model.{{.TableNameCamel}}, error) {
+func (d *{{.TableNameCamelFCL}}Dao) GetBy{{.ColumnNameCamel}}(ctx context.Context, {{.GetKeyParams}}) (*model.{{.TableNameCamel}}, error) {
 	// no cache
 	if d.cache == nil {
 		record := &model.{{.TableNameCamel}}{}
-		err := d.db.WithContext(ctx).Where("{{.ColumnName}} = ?", {{.ColumnNameCamelFCL}}).First(record).Error
+		err := d.db.WithContext(ctx).Where({{.GetKeyWhere}}).First(record).Error
 		return record, err
 	}
 
-	// get from cache
-	record, err := d.cache.Get(ctx, {{.ColumnNameCamelFCL}})
+	// get from cache, the stale record is also returned and refreshed in the background
+	record, isRefresh, err := d.cache.GetWithRefresh(ctx, {{.GetKeyArgs}})
 	if err == nil {
+		if isRefresh {
+			d.cache.Refresh({{.GetKeyArgs}}, func(ctx context.Context) (*model.{{.TableNameCamel}}, error) {
+				table := &model.{{.TableNameCamel}}{}
+				err := d.db.WithContext(ctx).Where({{.GetKeyWhere}}).First(table).Error
+				return table, err
+			})
+		}
 		return record, nil
 	}
 
 	// get from database
 	if errors.Is(err, database.ErrCacheNotFound) {
 		// for the same {{.ColumnNameCamelFCL}}, prevent high concurrent simultaneous access to database
-		{{if .IsStringType}}val, err, _ := d.sfg.Do({{.ColumnNameCamelFCL}}, func() (interface{}, error) {
-{{else}}		val, err, _ := d.sfg.Do(utils.{{.GoTypeFCU}}ToStr({{.ColumnNameCamelFCL}}), func() (interface{}, error) {
-{{end}}
+		val, err, _ := d.sfg.Do({{.GetKeyStr}}, func() (interface{}, error) {
 			table := &model.{{.TableNameCamel}}{}
-			err = d.db.WithContext(ctx).Where("{{.ColumnName}} = ?", {{.ColumnNameCamelFCL}}).First(table).Error
+			err = d.db.WithContext(ctx).Where({{.GetKeyWhere}}).First(table).Error
 			if err != nil {
 				// set placeholder cache to prevent cache penetration, default expiration time 10 minutes
 				if errors.Is(err, database.ErrRecordNotFound) {
-					if err = d.cache.SetPlaceholder(ctx, {{.ColumnNameCamelFCL}}); err != nil {
-						logger.Warn("cache.SetPlaceholder error", logger.Err(err), logger.Any("{{.ColumnNameCamelFCL}}", {{.ColumnNameCamelFCL}}))
+					if err = d.cache.SetPlaceholder(ctx, {{.GetKeyArgs}}); err != nil {
+						logger.Warn("cache.SetPlaceholder error", logger.Err(err), {{.GetKeyLogFields}})
 					}
 					return nil, database.ErrRecordNotFound
 				}
 				return nil, err
 			}
 			// set cache
-			if err = d.cache.Set(ctx, {{.ColumnNameCamelFCL}}, table, cache.{{.TableNameCamel}}ExpireTime); err != nil {
-				logger.Warn("cache.Set error", logger.Err(err), logger.Any("{{.ColumnNameCamelFCL}}", {{.ColumnNameCamelFCL}}))
+			if err = d.cache.Set(ctx, {{.GetKeyArgs}}, table, cache.{{.TableNameCamel}}ExpireTime); err != nil {
+				logger.Warn("cache.Set error", logger.Err(err), {{.GetKeyLogFields}})
 			}
 			return table, nil
 		})
@@ -181,6 +207,8 @@ func (d *{{.TableNameCamelFCL}}Dao) GetBy{{.ColumnNameCamel}}(ctx context.Contex
 	return nil, err
 }
 
+// todo generate the whitelist code to here
+
 // GetByColumns get paging records by column information,
 // Note: query performance degrades when table rows are very large because of the use of offset.
 //
@@ -194,9 +222,13 @@ func (d *{{.TableNameCamelFCL}}Dao) GetBy{{.ColumnNameCamel}}(ctx context.Contex
 // query parameters (not required):
 //
 //	name: column name
-//	exp: expressions, which default is "=",  support =, !=, >, >=, <, <=, like, in
-//	value: column value, if exp=in, multiple values are separated by commas
+//	exp: expressions, which default is "=",  support =, !=, >, >=, <, <=, like, in, notin, between, isnull, notnull, notlike, prefix, suffix
+//	value: column value, if exp=in, notin or between, multiple values are separated by commas
 //	logic: logical type, default value is "and", support &, and, ||, or
+//	where: conditions of tree structure, e.g. (a=1 OR b=2) AND (c=3 OR d=4), it is combined with the columns by "and"
+//	fields: fields to be returned, multiple fields separated by comma
+//
+// the columns of query conditions, sort and fields are restricted by {{.TableNameCamelFCL}}Whitelist
 //
 // example: search for a male over 20 years of age
 //
@@ -216,13 +248,24 @@ func (d *{{.TableNameCamelFCL}}Dao) GetBy{{.ColumnNameCamel}}(ctx context.Contex
 //	}
 func (d *{{.TableNameCamelFCL}}Dao) GetByColumns(ctx context.Context, params *query.Params) ([]*model.{{.TableNameCamel}}, int64, error) {
 	if params.Sort == "" {
-		params.Sort = "-{{.ColumnName}}"
+		params.Sort = "{{.GetKeySort}}"
+	}
+	queryStr, args, err := params.ConvertToGormConditions(query.WithWhitelist({{.TableNameCamelFCL}}Whitelist))
+	if err != nil {
+		return nil, 0, errors.New("query params error: " + err.Error())
 	}
-	queryStr, args, err := params.ConvertToGormConditions()
+	fields, err := params.ConvertToFields(query.WithWhitelist({{.TableNameCamelFCL}}Whitelist))
 	if err != nil {
 		return nil, 0, errors.New("query params error: " + err.Error())
 	}
 
+	// get from cache
+	if d.cache != nil {
+		if records, total, cacheErr := d.cache.GetList(ctx, params); cacheErr == nil {
+			return records, total, nil
+		}
+	}
+
 	var total int64
 	if params.Sort != "ignore count" { // determine if count is required
 		err = d.db.WithContext(ctx).Model(&model.{{.TableNameCamel}}{}).Where(queryStr, args...).Count(&total).Error
@@ -236,7 +279,76 @@ func (d *{{.TableNameCamelFCL}}Dao) GetByColumns(ctx context.Context, params *qu
 
 	records := []*model.{{.TableNameCamel}}{}
 	order, limit, offset := params.ConvertToPage()
-	err = d.db.WithContext(ctx).Order(order).Limit(limit).Offset(offset).Where(queryStr, args...).Find(&records).Error
+	db := d.db.WithContext(ctx)
+	if len(fields) > 0 {
+		db = db.Select(fields)
+	}
+	err = db.Order(order).Limit(limit).Offset(offset).Where(queryStr, args...).Find(&records).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// set cache of paging records
+	if d.cache != nil {
+		if err = d.cache.SetList(ctx, params, records, total, cache.{{.TableNameCamel}}ListExpireTime); err != nil {
+			logger.Warn("cache.SetList error", logger.Err(err), logger.Any("params", params))
+		}
+	}
+
+	return records, total, nil
+}
+
+// soft delete code start
+
+// RestoreBy{{.ColumnNameCamel}} restore a soft deleted record by {{.ColumnNameCamelFCL}}
+func (d *{{.TableNameCamelFCL}}Dao) RestoreBy{{.ColumnNameCamel}}(ctx context.Context, {{.GetKeyParams}}) error {
+	result := d.db.WithContext(ctx).Unscoped().Model(&model.{{.TableNameCamel}}{}).
+		Where({{.GetKeyWhere}}).Where("deleted_at IS NOT NULL").Update("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return database.ErrRecordNotFound
+	}
+
+	// delete cache
+	_ = d.deleteCache(ctx, {{.GetKeyArgs}})
+
+	return nil
+}
+
+// GetDeletedByColumns get paging records that have been soft deleted by column information,
+// the params are the same as GetByColumns
+func (d *{{.TableNameCamelFCL}}Dao) GetDeletedByColumns(ctx context.Context, params *query.Params) ([]*model.{{.TableNameCamel}}, int64, error) {
+	queryStr, args, err := params.ConvertToGormConditions(query.WithWhitelist({{.TableNameCamelFCL}}Whitelist))
+	if err != nil {
+		return nil, 0, errors.New("query params error: " + err.Error())
+	}
+	fields, err := params.ConvertToFields(query.WithWhitelist({{.TableNameCamelFCL}}Whitelist))
+	if err != nil {
+		return nil, 0, errors.New("query params error: " + err.Error())
+	}
+
+	var total int64
+	if params.Sort != "ignore count" { // determine if count is required
+		err = d.db.WithContext(ctx).Unscoped().Model(&model.{{.TableNameCamel}}{}).
+			Where("deleted_at IS NOT NULL").Where(queryStr, args...).Count(&total).Error
+		if err != nil {
+			return nil, 0, err
+		}
+		if total == 0 {
+			return nil, total, nil
+		}
+	}
+
+	records := []*model.{{.TableNameCamel}}{}
+	order, limit, offset := params.ConvertToPage()
+	db := d.db.WithContext(ctx).Unscoped()
+	if len(fields) > 0 {
+		db = db.Select(fields)
+	}
+	err = db.Order(order).Limit(limit).Offset(offset).
+		Where("deleted_at IS NOT NULL").Where(queryStr, args...).Find(&records).Error
 	if err != nil {
 		return nil, 0, err
 	}
@@ -244,21 +356,40 @@ func (d *{{.TableNameCamelFCL}}Dao) GetByColumns(ctx context.Context, params *qu
 	return records, total, err
 }
 
+// PurgeBy{{.ColumnNameCamel}} permanently delete a record by {{.ColumnNameCamelFCL}}, whether it has been soft deleted or not
+func (d *{{.TableNameCamelFCL}}Dao) PurgeBy{{.ColumnNameCamel}}(ctx context.Context, {{.GetKeyParams}}) error {
+	err := d.db.WithContext(ctx).Unscoped().Where({{.GetKeyWhere}}).Delete(&model.{{.TableNameCamel}}{}).Error
+	if err != nil {
+		return err
+	}
+
+	// delete cache
+	_ = d.deleteCache(ctx, {{.GetKeyArgs}})
+
+	return nil
+}
+
+// soft delete code end
+
 // CreateByTx create a record in the database using the provided transaction
-func (d *{{.TableNameCamelFCL}}Dao) CreateByTx(ctx context.Context, tx *gorm.DB, table *model.{{.TableNameCamel}}) ({{.GoType}}, error) {
+func (d *{{.TableNameCamelFCL}}Dao) CreateByTx(ctx context.Context, tx *gorm.DB, table *model.{{.TableNameCamel}}) {{if .IsCompositeKey}}error{{else}}({{.GoType}}, error){{end}} {
 	err := tx.WithContext(ctx).Create(table).Error
-	return table.{{.ColumnNameCamel}}, err
+	if err == nil {
+		// delete cache of paging records
+		d.deleteListCache(ctx)
+	}
+	{{if .IsCompositeKey}}return err{{else}}return table.{{.ColumnNameCamel}}, err{{end}}
 }
 
 // DeleteByTx delete a record by {{.ColumnNameCamelFCL}} in the database using the provided transaction
-func (d *{{.TableNameCamelFCL}}Dao) DeleteByTx(ctx context.Context, tx *gorm.DB, {{.ColumnNameCamelFCL}} {{.GoType}}) error {
-	err := tx.WithContext(ctx).Where("{{.ColumnName}} = ?", {{.ColumnNameCamelFCL}}).Delete(&model.{{.TableNameCamel}}{}).Error
+func (d *{{.TableNameCamelFCL}}Dao) DeleteByTx(ctx context.Context, tx *gorm.DB, {{.GetKeyParams}}) error {
+	err := tx.WithContext(ctx).Where({{.GetKeyWhere}}).Delete(&model.{{.TableNameCamel}}{}).Error
 	if err != nil {
 		return err
 	}
 
 	// delete cache
-	_ = d.deleteCache(ctx, {{.ColumnNameCamelFCL}})
+	_ = d.deleteCache(ctx, {{.GetKeyArgs}})
 
 	return nil
 }
@@ -268,7 +399,9 @@ func (d *{{.TableNameCamelFCL}}Dao) UpdateByTx(ctx context.Context, tx *gorm.DB,
 	err := d.updateDataBy{{.ColumnNameCamel}}(ctx, tx, table)
 
 	// delete cache
-	_ = d.deleteCache(ctx, table.{{.ColumnNameCamel}})
+	_ = d.deleteCache(ctx, {{.GetKeyFieldArgs "table"}})
 
 	return err
 }
+
+// todo generate the relation code to here
@@ -176,6 +176,14 @@ func Test_userExampleDao_GetByColumns(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	// get from cache, no query is sent to the database
+	_, _, err = d.IDao.(UserExampleDao).GetByColumns(d.Ctx, &query.Params{
+		Page:  0,
+		Limit: 10,
+		Sort:  "ignore count",
+	})
+	assert.NoError(t, err)
+
 	// err test
 	_, _, err = d.IDao.(UserExampleDao).GetByColumns(d.Ctx, &query.Params{
 		Page:  0,
@@ -14,7 +14,8 @@ var (
 	gdb     *sgorm.DB
 	gdbOnce sync.Once
 
-	ErrRecordNotFound = sgorm.ErrRecordNotFound
+	ErrRecordNotFound  = sgorm.ErrRecordNotFound
+	ErrVersionConflict = sgorm.ErrVersionConflict
 )
 
 // todo generate initialisation database code here
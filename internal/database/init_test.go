@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/go-dev-frame/sponge/configs"
+	"github.com/go-dev-frame/sponge/pkg/cache"
 	"github.com/go-dev-frame/sponge/pkg/sgorm"
 	"github.com/go-dev-frame/sponge/pkg/utils"
 
@@ -109,15 +110,18 @@ func TestInitRedis(t *testing.T) {
 }
 
 func TestGetCacheType(t *testing.T) {
-	InitCache("memory")
-	ct := GetCacheType()
-	assert.NotNil(t, ct)
-
 	err := config.Init(configs.Path("serverNameExample.yml"))
 	if err != nil {
 		panic(err)
 	}
 
+	InitCache("memory")
+	ct := GetCacheType()
+	assert.NotNil(t, ct)
+	assert.NotNil(t, ct.WithMetrics(cache.NewMemoryCache("", nil, nil)))
+	ct.EnableMetrics = true
+	assert.NotNil(t, ct.WithMetrics(cache.NewMemoryCache("", nil, nil)))
+
 	cacheType = nil
 	defer func() { recover() }()
 	ct = GetCacheType()
@@ -127,4 +131,8 @@ func TestGetCacheType(t *testing.T) {
 	InitCache("redis")
 	ct = GetCacheType()
 	assert.NotNil(t, ct)
+
+	InitCache("multilevel")
+	ct = GetCacheType()
+	assert.NotNil(t, ct)
 }
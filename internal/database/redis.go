@@ -1,9 +1,12 @@
 package database
 
 import (
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/go-dev-frame/sponge/pkg/cache"
+	"github.com/go-dev-frame/sponge/pkg/encoding"
 	"github.com/go-dev-frame/sponge/pkg/goredis"
 	"github.com/go-dev-frame/sponge/pkg/tracer"
 
@@ -25,17 +28,51 @@ var (
 
 // CacheType cache type
 type CacheType struct {
-	CType string          // cache type  memory or redis
-	Rdb   *goredis.Client // if CType=redis, Rdb cannot be empty
+	CType         string          // cache type  memory, redis or multilevel
+	Rdb           *goredis.Client // if CType=redis or multilevel, Rdb cannot be empty
+	EnableMetrics bool            // if true, record the cache metrics
+}
+
+// WithMetrics wrap the cache to record metrics if EnableMetrics is true
+func (c *CacheType) WithMetrics(ch cache.Cache) cache.Cache {
+	if c.EnableMetrics {
+		return cache.NewMetricsCache(ch)
+	}
+	return ch
+}
+
+// NewCache create a cache of the cache type without key prefix, the value is encoded by json by default,
+// pass in valueEncoding to change it, return nil if the cache type is not supported.
+func (c *CacheType) NewCache(newObject func() interface{}, valueEncoding ...encoding.Encoding) cache.Cache {
+	var enc encoding.Encoding = encoding.JSONEncoding{}
+	if len(valueEncoding) > 0 && valueEncoding[0] != nil {
+		enc = valueEncoding[0]
+	}
+	cachePrefix := ""
+
+	var ch cache.Cache
+	switch strings.ToLower(c.CType) {
+	case "redis":
+		ch = cache.NewRedisCache(c.Rdb, cachePrefix, enc, newObject)
+	case "multilevel":
+		ch = cache.NewMultiLevelCache(c.Rdb, cachePrefix, enc, newObject)
+	case "memory":
+		ch = cache.NewMemoryCache(cachePrefix, enc, newObject)
+	default:
+		return nil
+	}
+
+	return c.WithMetrics(ch)
 }
 
 // InitCache initial cache
 func InitCache(cType string) {
 	cacheType = &CacheType{
-		CType: cType,
+		CType:         cType,
+		EnableMetrics: config.Get().App.EnableMetrics,
 	}
 
-	if cType == "redis" {
+	if cType == "redis" || cType == "multilevel" {
 		cacheType.Rdb = GetRedisCli()
 	}
 }
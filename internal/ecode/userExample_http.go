@@ -17,5 +17,9 @@ var (
 	ErrGetByIDUserExample    = errcode.NewError(userExampleBaseCode+4, "failed to get "+userExampleName+" details")
 	ErrListUserExample       = errcode.NewError(userExampleBaseCode+5, "failed to list of "+userExampleName)
 
+	// optimistic lock code start
+	ErrUpdateConflictUserExample = errcode.Conflict.RewriteMsg(userExampleName + " has been modified by others, please reload and retry")
+	// optimistic lock code end
+
 	// error codes are globally unique, adding 1 to the previous error code
 )
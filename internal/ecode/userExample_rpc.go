@@ -17,5 +17,9 @@ var (
 	StatusGetByIDUserExample    = errcode.NewRPCStatus(_userExampleBaseCode+4, "failed to get "+_userExampleName+" details")
 	StatusListUserExample       = errcode.NewRPCStatus(_userExampleBaseCode+5, "failed to list of "+_userExampleName)
 
+	// optimistic lock code start
+	StatusUpdateConflictUserExample = errcode.StatusAborted.RewriteMsg(_userExampleName + " has been modified by others, please reload and retry")
+	// optimistic lock code end
+
 	// error codes are globally unique, adding 1 to the previous error code
 )
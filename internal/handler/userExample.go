@@ -148,6 +148,18 @@ func (h *userExampleHandler) UpdateByID(c *gin.Context) {
 	ctx := middleware.WrapCtx(c)
 	err = h.iDao.UpdateByID(ctx, userExample)
 	if err != nil {
+		// optimistic lock code start
+		if errors.Is(err, database.ErrVersionConflict) {
+			logger.Warn("UpdateByID conflict", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+			response.Out(c, ecode.ErrUpdateConflictUserExample)
+			return
+		}
+		if errors.Is(err, database.ErrRecordNotFound) {
+			logger.Warn("UpdateByID not found", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.NotFound)
+			return
+		}
+		// optimistic lock code end
 		logger.Error("UpdateByID error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
 		response.Output(c, ecode.InternalServerError.ToHTTPCode())
 		return
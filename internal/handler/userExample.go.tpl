@@ -79,7 +79,7 @@ func (h *{{.TableNameCamelFCL}}Handler) Create(c *gin.Context) {
 		return
 	}
 
-	response.Success(c, gin.H{"{{.ColumnNameCamelFCL}}": {{.TableNameCamelFCL}}.{{.ColumnNameCamel}}})
+	response.Success(c, gin.H{ {{- range $i, $v := .GetPrimaryKeys}}{{if $i}}, {{end}}"{{$v.ColumnNameCamelFCL}}": {{$.TableNameCamelFCL}}.{{$v.ColumnNameCamel}}{{end}}})
 }
 
 // DeleteBy{{.ColumnNameCamel}} delete a record by {{.ColumnNameCamelFCL}}
@@ -88,21 +88,23 @@ func (h *{{.TableNameCamelFCL}}Handler) Create(c *gin.Context) {
 // @Tags {{.TableNameCamelFCL}}
 // @accept json
 // @Produce json
+{{- range .GetPrimaryKeys}}
 // @Param {{.ColumnNameCamelFCL}} path string true "{{.ColumnNameCamelFCL}}"
+{{- end}}
 // @Success 200 {object} types.Delete{{.TableNameCamel}}By{{.ColumnNameCamel}}Reply{}
 // @Router /api/v1/{{.TableNameCamelFCL}}/{{{.ColumnNameCamelFCL}}} [delete]
 // @Security BearerAuth
 func (h *{{.TableNameCamelFCL}}Handler) DeleteBy{{.ColumnNameCamel}}(c *gin.Context) {
-	{{.ColumnNameCamelFCL}}, isAbort := get{{.TableNameCamel}}{{.ColumnNameCamel}}FromPath(c)
+	{{.GetKeyArgs}}, isAbort := get{{.TableNameCamel}}{{.ColumnNameCamel}}FromPath(c)
 	if isAbort {
 		response.Error(c, ecode.InvalidParams)
 		return
 	}
 
 	ctx := middleware.WrapCtx(c)
-	err := h.iDao.DeleteBy{{.ColumnNameCamel}}(ctx, {{.ColumnNameCamelFCL}})
+	err := h.iDao.DeleteBy{{.ColumnNameCamel}}(ctx, {{.GetKeyArgs}})
 	if err != nil {
-		logger.Error("DeleteBy{{.ColumnNameCamel}} error", logger.Err(err), logger.Any("{{.ColumnNameCamelFCL}}", {{.ColumnNameCamelFCL}}), middleware.GCtxRequestIDField(c))
+		logger.Error("DeleteBy{{.ColumnNameCamel}} error", logger.Err(err), {{.GetKeyLogFields}}, middleware.GCtxRequestIDField(c))
 		response.Output(c, ecode.InternalServerError.ToHTTPCode())
 		return
 	}
@@ -116,13 +118,15 @@ func (h *{{.TableNameCamelFCL}}Handler) DeleteBy{{.ColumnNameCamel}}(c *gin.Cont
 // @Tags {{.TableNameCamelFCL}}
 // @accept json
 // @Produce json
+{{- range .GetPrimaryKeys}}
 // @Param {{.ColumnNameCamelFCL}} path string true "{{.ColumnNameCamelFCL}}"
+{{- end}}
 // @Param data body types.Update{{.TableNameCamel}}By{{.ColumnNameCamel}}Request true "{{.TableNameCamelFCL}} information"
 // @Success 200 {object} types.Update{{.TableNameCamel}}By{{.ColumnNameCamel}}Reply{}
 // @Router /api/v1/{{.TableNameCamelFCL}}/{{{.ColumnNameCamelFCL}}} [put]
 // @Security BearerAuth
 func (h *{{.TableNameCamelFCL}}Handler) UpdateBy{{.ColumnNameCamel}}(c *gin.Context) {
-	{{.ColumnNameCamelFCL}}, isAbort := get{{.TableNameCamel}}{{.ColumnNameCamel}}FromPath(c)
+	{{.GetKeyArgs}}, isAbort := get{{.TableNameCamel}}{{.ColumnNameCamel}}FromPath(c)
 	if isAbort {
 		response.Error(c, ecode.InvalidParams)
 		return
@@ -135,7 +139,9 @@ func (h *{{.TableNameCamelFCL}}Handler) UpdateBy{{.ColumnNameCamel}}(c *gin.Cont
 		response.Error(c, ecode.InvalidParams)
 		return
 	}
+{{- range .GetPrimaryKeys}}
 	form.{{.ColumnNameCamel}} = {{.ColumnNameCamelFCL}}
+{{- end}}
 
 	{{.TableNameCamelFCL}} := &model.{{.TableNameCamel}}{}
 	err = copier.Copy({{.TableNameCamelFCL}}, form)
@@ -160,27 +166,29 @@ func (h *{{.TableNameCamelFCL}}Handler) UpdateBy{{.ColumnNameCamel}}(c *gin.Cont
 // @Summary get {{.TableNameCamelFCL}} detail
 // @Description get {{.TableNameCamelFCL}} detail by {{.ColumnNameCamelFCL}}
 // @Tags {{.TableNameCamelFCL}}
+{{- range .GetPrimaryKeys}}
 // @Param {{.ColumnNameCamelFCL}} path string true "{{.ColumnNameCamelFCL}}"
+{{- end}}
 // @Accept json
 // @Produce json
 // @Success 200 {object} types.Get{{.TableNameCamel}}By{{.ColumnNameCamel}}Reply{}
 // @Router /api/v1/{{.TableNameCamelFCL}}/{{{.ColumnNameCamelFCL}}} [get]
 // @Security BearerAuth
 func (h *{{.TableNameCamelFCL}}Handler) GetBy{{.ColumnNameCamel}}(c *gin.Context) {
-	{{.ColumnNameCamelFCL}}, isAbort := get{{.TableNameCamel}}{{.ColumnNameCamel}}FromPath(c)
+	{{.GetKeyArgs}}, isAbort := get{{.TableNameCamel}}{{.ColumnNameCamel}}FromPath(c)
 	if isAbort {
 		response.Error(c, ecode.InvalidParams)
 		return
 	}
 
 	ctx := middleware.WrapCtx(c)
-	{{.TableNameCamelFCL}}, err := h.iDao.GetBy{{.ColumnNameCamel}}(ctx, {{.ColumnNameCamelFCL}})
+	{{.TableNameCamelFCL}}, err := h.iDao.GetBy{{.ColumnNameCamel}}(ctx, {{.GetKeyArgs}})
 	if err != nil {
 		if errors.Is(err, database.ErrRecordNotFound) {
-			logger.Warn("GetBy{{.ColumnNameCamel}} not found", logger.Err(err), logger.Any("{{.ColumnNameCamelFCL}}", {{.ColumnNameCamelFCL}}), middleware.GCtxRequestIDField(c))
+			logger.Warn("GetBy{{.ColumnNameCamel}} not found", logger.Err(err), {{.GetKeyLogFields}}, middleware.GCtxRequestIDField(c))
 			response.Error(c, ecode.NotFound)
 		} else {
-			logger.Error("GetBy{{.ColumnNameCamel}} error", logger.Err(err), logger.Any("{{.ColumnNameCamelFCL}}", {{.ColumnNameCamelFCL}}), middleware.GCtxRequestIDField(c))
+			logger.Error("GetBy{{.ColumnNameCamel}} error", logger.Err(err), {{.GetKeyLogFields}}, middleware.GCtxRequestIDField(c))
 			response.Output(c, ecode.InternalServerError.ToHTTPCode())
 		}
 		return
@@ -236,7 +244,24 @@ func (h *{{.TableNameCamelFCL}}Handler) List(c *gin.Context) {
 	})
 }
 
-func get{{.TableNameCamel}}{{.ColumnNameCamel}}FromPath(c *gin.Context) ({{.GoType}}, bool) {
+{{if .IsCompositeKey}}func get{{.TableNameCamel}}{{.ColumnNameCamel}}FromPath(c *gin.Context) ({{.GetKeyParams}}, isAbort bool) {
+{{- range .GetPrimaryKeys}}
+{{if .IsStringType}}	{{.ColumnNameCamelFCL}} = c.Param("{{.ColumnNameCamelFCL}}")
+	if {{.ColumnNameCamelFCL}} == "" {
+		logger.Warn("{{.ColumnNameCamelFCL}} is empty", middleware.GCtxRequestIDField(c))
+		return {{$.GetKeyArgs}}, true
+	}
+{{else}}	{{.ColumnNameCamelFCL}}Str := c.Param("{{.ColumnNameCamelFCL}}")
+	{{.ColumnNameCamelFCL}} = utils.StrTo{{.GoTypeFCU}}({{.ColumnNameCamelFCL}}Str)
+	if {{.ColumnNameCamelFCL}} == 0 {
+		logger.Warn("StrTo{{.GoTypeFCU}} error: ", logger.String("{{.ColumnNameCamelFCL}}Str", {{.ColumnNameCamelFCL}}Str), middleware.GCtxRequestIDField(c))
+		return {{$.GetKeyArgs}}, true
+	}
+{{end}}
+{{- end}}
+	return {{.GetKeyArgs}}, false
+}
+{{else}}func get{{.TableNameCamel}}{{.ColumnNameCamel}}FromPath(c *gin.Context) ({{.GoType}}, bool) {
 	{{.ColumnNameCamelFCL}}Str := c.Param("{{.ColumnNameCamelFCL}}")
 {{if .IsStringType}}
 	if {{.ColumnNameCamelFCL}}Str == "" {
@@ -253,7 +278,7 @@ func get{{.TableNameCamel}}{{.ColumnNameCamel}}FromPath(c *gin.Context) ({{.GoTy
 	return {{.ColumnNameCamelFCL}}, false
 {{end}}
 }
-
+{{end}}
 func convert{{.TableNameCamel}}({{.TableNameCamelFCL}} *model.{{.TableNameCamel}}) (*types.{{.TableNameCamel}}ObjDetail, error) {
 	data := &types.{{.TableNameCamel}}ObjDetail{}
 	err := copier.Copy(data, {{.TableNameCamelFCL}})
@@ -96,6 +96,16 @@ func (h *userExamplePbHandler) UpdateByID(ctx context.Context, req *serverNameEx
 
 	err = h.userExampleDao.UpdateByID(ctx, userExample)
 	if err != nil {
+		// optimistic lock code start
+		if errors.Is(err, database.ErrVersionConflict) {
+			logger.Warn("UpdateByID conflict", logger.Err(err), logger.Any("userExample", userExample), middleware.CtxRequestIDField(ctx))
+			return nil, ecode.ErrUpdateConflictUserExample.ErrToHTTP()
+		}
+		if errors.Is(err, database.ErrRecordNotFound) {
+			logger.Warn("UpdateByID not found", logger.Err(err), logger.Any("userExample", userExample), middleware.CtxRequestIDField(ctx))
+			return nil, ecode.NotFound.Err()
+		}
+		// optimistic lock code end
 		logger.Error("UpdateByID error", logger.Err(err), logger.Any("userExample", userExample), middleware.CtxRequestIDField(ctx))
 		return nil, ecode.InternalServerError.Err()
 	}
@@ -58,7 +58,7 @@ func (h *{{.TableNameCamelFCL}}Handler) Create(ctx context.Context, req *serverN
 		return nil, ecode.InternalServerError.Err()
 	}
 
-	return &serverNameExampleV1.Create{{.TableNameCamel}}Reply{ {{if .IsStandardPrimaryKey}}Id{{else}}{{.ColumnNameCamel}}{{end}}: {{.TableNameCamelFCL}}.{{.ColumnNameCamel}} }, nil
+	return &serverNameExampleV1.Create{{.TableNameCamel}}Reply{ {{- range $i, $v := .GetPrimaryKeys}}{{if $i}}, {{end}}{{$v.GetProtoFieldName}}: {{$.TableNameCamelFCL}}.{{$v.ColumnNameCamel}}{{end}} }, nil
 }
 
 // DeleteBy{{.ColumnNameCamel}} delete a record by {{.ColumnNameCamelFCL}}
@@ -69,7 +69,7 @@ func (h *{{.TableNameCamelFCL}}Handler) DeleteBy{{.ColumnNameCamel}}(ctx context
 		return nil, ecode.InvalidParams.Err()
 	}
 
-	err = h.{{.TableNameCamelFCL}}Dao.DeleteBy{{.ColumnNameCamel}}(ctx, req.{{if .IsStandardPrimaryKey}}Id{{else}}{{.ColumnNameCamel}}{{end}})
+	err = h.{{.TableNameCamelFCL}}Dao.DeleteBy{{.ColumnNameCamel}}(ctx, {{.GetKeyProtoFieldArgs "req"}})
 	if err != nil {
 		logger.Warn("DeleteBy{{.ColumnNameCamel}} error", logger.Err(err), middleware.CtxRequestIDField(ctx))
 		return nil, ecode.InternalServerError.Err()
@@ -92,7 +92,9 @@ func (h *{{.TableNameCamelFCL}}Handler) UpdateBy{{.ColumnNameCamel}}(ctx context
 		return nil, ecode.ErrUpdateBy{{.ColumnNameCamel}}{{.TableNameCamel}}.Err()
 	}
 	// Note: if copier.Copy cannot assign a value to a field, add it here
-	{{.TableNameCamelFCL}}.{{.ColumnNameCamel}} = req.{{if .IsStandardPrimaryKey}}Id{{else}}{{.ColumnNameCamel}}{{end}}
+{{- range .GetPrimaryKeys}}
+	{{$.TableNameCamelFCL}}.{{.ColumnNameCamel}} = req.{{.GetProtoFieldName}}
+{{- end}}
 
 	err = h.{{.TableNameCamelFCL}}Dao.UpdateBy{{.ColumnNameCamel}}(ctx, {{.TableNameCamelFCL}})
 	if err != nil {
@@ -111,13 +113,13 @@ func (h *{{.TableNameCamelFCL}}Handler) GetBy{{.ColumnNameCamel}}(ctx context.Co
 		return nil, ecode.InvalidParams.Err()
 	}
 
-	record, err := h.{{.TableNameCamelFCL}}Dao.GetBy{{.ColumnNameCamel}}(ctx, req.{{if .IsStandardPrimaryKey}}Id{{else}}{{.ColumnNameCamel}}{{end}})
+	record, err := h.{{.TableNameCamelFCL}}Dao.GetBy{{.ColumnNameCamel}}(ctx, {{.GetKeyProtoFieldArgs "req"}})
 	if err != nil {
 		if errors.Is(err, database.ErrRecordNotFound) {
-			logger.Warn("GetBy{{.ColumnNameCamel}} error", logger.Err(err), logger.Any("{{.ColumnNameCamelFCL}}", req.{{if .IsStandardPrimaryKey}}Id{{else}}{{.ColumnNameCamel}}{{end}}), middleware.CtxRequestIDField(ctx))
+			logger.Warn("GetBy{{.ColumnNameCamel}} error", logger.Err(err), logger.Any("req", req), middleware.CtxRequestIDField(ctx))
 			return nil, ecode.NotFound.Err()
 		}
-		logger.Error("GetBy{{.ColumnNameCamel}} error", logger.Err(err), logger.Any("{{.ColumnNameCamelFCL}}", req.{{if .IsStandardPrimaryKey}}Id{{else}}{{.ColumnNameCamel}}{{end}}), middleware.CtxRequestIDField(ctx))
+		logger.Error("GetBy{{.ColumnNameCamel}} error", logger.Err(err), logger.Any("req", req), middleware.CtxRequestIDField(ctx))
 		return nil, ecode.InternalServerError.Err()
 	}
 
@@ -161,7 +163,7 @@ func (h *{{.TableNameCamelFCL}}Handler) List(ctx context.Context, req *serverNam
 	for _, record := range records {
 		data, err := convert{{.TableNameCamel}}Pb(record)
 		if err != nil {
-			logger.Warn("convert{{.TableNameCamel}} error", logger.Err(err), logger.Any("{{.ColumnNameCamelFCL}}", record.{{.ColumnNameCamel}}), middleware.CtxRequestIDField(ctx))
+			logger.Warn("convert{{.TableNameCamel}} error", logger.Err(err), logger.Any("{{.TableNameCamelFCL}}", record), middleware.CtxRequestIDField(ctx))
 			continue
 		}
 		{{.TableNamePluralCamelFCL}} = append({{.TableNamePluralCamelFCL}}, data)
@@ -180,7 +182,9 @@ func convert{{.TableNameCamel}}Pb(record *model.{{.TableNameCamel}}) (*serverNam
 		return nil, err
 	}
 	// Note: if copier.Copy cannot assign a value to a field, add it here, e.g. CreatedAt, UpdatedAt
-	value.{{if .IsStandardPrimaryKey}}Id{{else}}{{.ColumnNameCamel}}{{end}} = record.{{.ColumnNameCamel}}
+{{- range .GetPrimaryKeys}}
+	value.{{.GetProtoFieldName}} = record.{{.ColumnNameCamel}}
+{{- end}}
 	// todo generate the conversion createdAt and updatedAt code here
 	// delete the templates code start
 	value.CreatedAt = record.CreatedAt.Format(time.RFC3339)
@@ -98,6 +98,8 @@ func NewRouter_pbExample() *gin.Engine { //nolint
 	r.GET("/health", handlerfunc.CheckHealth)
 	r.GET("/ping", handlerfunc.Ping)
 	r.GET("/codes", handlerfunc.ListCodes)
+	// manage the scheduled tasks of gocron, list tasks, view the latest runs, trigger, pause and resume tasks
+	//cronadmin.Register(r, cronadmin.WithMiddlewares(middleware.Auth()))
 
 	if config.Get().App.Env != "prod" {
 		r.GET("/config", gin.WrapF(errcode.ShowConfig([]byte(config.Show()))))
@@ -22,8 +22,8 @@ func {{.TableNameCamelFCL}}Router(group *gin.RouterGroup, h handler.{{.TableName
 	// separately for only certain routes. In this case, g.Use(middleware.Auth()) above should not be used.
 
 	g.POST("/", h.Create)          // [post] /api/v1/{{.TableNameCamelFCL}}
-	g.DELETE("/:{{.ColumnNameCamelFCL}}", h.DeleteBy{{.ColumnNameCamel}}) // [delete] /api/v1/{{.TableNameCamelFCL}}/:{{.ColumnNameCamelFCL}}
-	g.PUT("/:{{.ColumnNameCamelFCL}}", h.UpdateBy{{.ColumnNameCamel}})    // [put] /api/v1/{{.TableNameCamelFCL}}/:{{.ColumnNameCamelFCL}}
-	g.GET("/:{{.ColumnNameCamelFCL}}", h.GetBy{{.ColumnNameCamel}})       // [get] /api/v1/{{.TableNameCamelFCL}}/:{{.ColumnNameCamelFCL}}
+	g.DELETE("{{.GetKeyRoutePath}}", h.DeleteBy{{.ColumnNameCamel}}) // [delete] /api/v1/{{.TableNameCamelFCL}}{{.GetKeyRoutePath}}
+	g.PUT("{{.GetKeyRoutePath}}", h.UpdateBy{{.ColumnNameCamel}})    // [put] /api/v1/{{.TableNameCamelFCL}}{{.GetKeyRoutePath}}
+	g.GET("{{.GetKeyRoutePath}}", h.GetBy{{.ColumnNameCamel}})       // [get] /api/v1/{{.TableNameCamelFCL}}{{.GetKeyRoutePath}}
 	g.POST("/list", h.List)        // [post] /api/v1/{{.TableNameCamelFCL}}/list
 }
@@ -8,11 +8,13 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
 	"github.com/go-dev-frame/sponge/pkg/app"
+	"github.com/go-dev-frame/sponge/pkg/cache"
 	"github.com/go-dev-frame/sponge/pkg/errcode"
 	"github.com/go-dev-frame/sponge/pkg/grpc/gtls"
 	"github.com/go-dev-frame/sponge/pkg/grpc/interceptor"
@@ -44,6 +46,8 @@ type grpcServer struct {
 
 	iRegistry registry.Registry
 	instance  *registry.ServiceInstance
+
+	gaugeMetrics []*prometheus.GaugeVec
 }
 
 // Start grpc service
@@ -180,7 +184,12 @@ func (s *grpcServer) unaryServerOptions() grpc.ServerOption {
 
 	// metrics interceptor
 	if config.Get().App.EnableMetrics {
-		unaryServerInterceptors = append(unaryServerInterceptors, interceptor.UnaryServerMetrics())
+		unaryServerInterceptors = append(unaryServerInterceptors, interceptor.UnaryServerMetrics(
+			// cache metrics are exposed together with grpc server metrics
+			metrics.WithCounterMetrics(cache.MetricsCounters()...),
+			metrics.WithHistogramMetrics(cache.MetricsHistograms()...),
+			metrics.WithGaugeMetrics(s.gaugeMetrics...),
+		))
 		s.registerMetricsMuxAndMethodFunc = s.registerMetricsMuxAndMethod()
 	}
 
@@ -314,9 +323,10 @@ func NewGRPCServer(addr string, opts ...GrpcOption) app.IServer {
 	o := defaultGrpcOptions()
 	o.apply(opts...)
 	s := &grpcServer{
-		addr:      addr,
-		iRegistry: o.iRegistry,
-		instance:  o.instance,
+		addr:         addr,
+		iRegistry:    o.iRegistry,
+		instance:     o.instance,
+		gaugeMetrics: o.gaugeMetrics,
 	}
 	s.addHTTPRouter()
 	if config.Get().App.EnableHTTPProfile {
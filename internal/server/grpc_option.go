@@ -1,6 +1,8 @@
 package server
 
 import (
+	"github.com/prometheus/client_golang/prometheus"
+
 	"github.com/go-dev-frame/sponge/pkg/servicerd/registry"
 )
 
@@ -8,8 +10,9 @@ import (
 type GrpcOption func(*grpcOptions)
 
 type grpcOptions struct {
-	instance  *registry.ServiceInstance
-	iRegistry registry.Registry
+	instance     *registry.ServiceInstance
+	iRegistry    registry.Registry
+	gaugeMetrics []*prometheus.GaugeVec
 }
 
 func defaultGrpcOptions() *grpcOptions {
@@ -32,3 +35,10 @@ func WithGrpcRegistry(iRegistry registry.Registry, instance *registry.ServiceIns
 		o.instance = instance
 	}
 }
+
+// WithGrpcGaugeMetrics add gauge metrics exposed together with grpc server metrics, e.g. kafka consumer group lag
+func WithGrpcGaugeMetrics(metrics ...*prometheus.GaugeVec) GrpcOption {
+	return func(o *grpcOptions) {
+		o.gaugeMetrics = append(o.gaugeMetrics, metrics...)
+	}
+}
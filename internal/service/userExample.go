@@ -108,6 +108,16 @@ func (s *userExample) UpdateByID(ctx context.Context, req *serverNameExampleV1.U
 
 	err = s.iDao.UpdateByID(ctx, record)
 	if err != nil {
+		// optimistic lock code start
+		if errors.Is(err, database.ErrVersionConflict) {
+			logger.Warn("UpdateByID conflict", logger.Err(err), logger.Any("userExample", record), interceptor.ServerCtxRequestIDField(ctx))
+			return nil, ecode.StatusUpdateConflictUserExample.ToRPCErr()
+		}
+		if errors.Is(err, database.ErrRecordNotFound) {
+			logger.Warn("UpdateByID not found", logger.Err(err), logger.Any("userExample", record), interceptor.ServerCtxRequestIDField(ctx))
+			return nil, ecode.StatusNotFound.Err()
+		}
+		// optimistic lock code end
 		logger.Error("UpdateByID error", logger.Err(err), logger.Any("userExample", record), interceptor.ServerCtxRequestIDField(ctx))
 		return nil, ecode.StatusInternalServerError.ToRPCErr()
 	}
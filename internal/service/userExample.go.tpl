@@ -68,7 +68,7 @@ func (s *{{.TableNameCamelFCL}}) Create(ctx context.Context, req *serverNameExam
 		return nil, ecode.StatusInternalServerError.ToRPCErr()
 	}
 
-	return &serverNameExampleV1.Create{{.TableNameCamel}}Reply{ {{if .IsStandardPrimaryKey}}Id{{else}}{{.ColumnNameCamel}}{{end}}: record.{{.ColumnNameCamel}} }, nil
+	return &serverNameExampleV1.Create{{.TableNameCamel}}Reply{ {{- range $i, $v := .GetPrimaryKeys}}{{if $i}}, {{end}}{{$v.GetProtoFieldName}}: record.{{$v.ColumnNameCamel}}{{end}} }, nil
 }
 
 // DeleteBy{{.ColumnNameCamel}} delete a record by {{.ColumnNameCamelFCL}}
@@ -80,9 +80,9 @@ func (s *{{.TableNameCamelFCL}}) DeleteBy{{.ColumnNameCamel}}(ctx context.Contex
 	}
 	ctx = interceptor.WrapServerCtx(ctx)
 
-	err = s.iDao.DeleteBy{{.ColumnNameCamel}}(ctx, req.{{if .IsStandardPrimaryKey}}Id{{else}}{{.ColumnNameCamel}}{{end}})
+	err = s.iDao.DeleteBy{{.ColumnNameCamel}}(ctx, {{.GetKeyProtoFieldArgs "req"}})
 	if err != nil {
-		logger.Error("DeleteBy{{.ColumnNameCamel}} error", logger.Err(err), logger.Any("{{.ColumnNameCamelFCL}}", req.{{if .IsStandardPrimaryKey}}Id{{else}}{{.ColumnNameCamel}}{{end}}), interceptor.ServerCtxRequestIDField(ctx))
+		logger.Error("DeleteBy{{.ColumnNameCamel}} error", logger.Err(err), logger.Any("req", req), interceptor.ServerCtxRequestIDField(ctx))
 		return nil, ecode.StatusInternalServerError.ToRPCErr()
 	}
 
@@ -104,7 +104,9 @@ func (s *{{.TableNameCamelFCL}}) UpdateBy{{.ColumnNameCamel}}(ctx context.Contex
 		return nil, ecode.StatusUpdateBy{{.ColumnNameCamel}}{{.TableNameCamel}}.Err()
 	}
 	// Note: if copier.Copy cannot assign a value to a field, add it here
-	record.{{.ColumnNameCamel}} = req.{{if .IsStandardPrimaryKey}}Id{{else}}{{.ColumnNameCamel}}{{end}}
+{{- range .GetPrimaryKeys}}
+	record.{{.ColumnNameCamel}} = req.{{.GetProtoFieldName}}
+{{- end}}
 
 	err = s.iDao.UpdateBy{{.ColumnNameCamel}}(ctx, record)
 	if err != nil {
@@ -124,13 +126,13 @@ func (s *{{.TableNameCamelFCL}}) GetBy{{.ColumnNameCamel}}(ctx context.Context,
 	}
 	ctx = interceptor.WrapServerCtx(ctx)
 
-	record, err := s.iDao.GetBy{{.ColumnNameCamel}}(ctx, req.{{if .IsStandardPrimaryKey}}Id{{else}}{{.ColumnNameCamel}}{{end}})
+	record, err := s.iDao.GetBy{{.ColumnNameCamel}}(ctx, {{.GetKeyProtoFieldArgs "req"}})
 	if err != nil {
 		if errors.Is(err, database.ErrRecordNotFound) {
-			logger.Warn("GetBy{{.ColumnNameCamel}} error", logger.Err(err), logger.Any("{{.ColumnNameCamelFCL}}", req.{{if .IsStandardPrimaryKey}}Id{{else}}{{.ColumnNameCamel}}{{end}}), interceptor.ServerCtxRequestIDField(ctx))
+			logger.Warn("GetBy{{.ColumnNameCamel}} error", logger.Err(err), logger.Any("req", req), interceptor.ServerCtxRequestIDField(ctx))
 			return nil, ecode.StatusNotFound.Err()
 		}
-		logger.Error("GetBy{{.ColumnNameCamel}} error", logger.Err(err), logger.Any("{{.ColumnNameCamelFCL}}", req.{{if .IsStandardPrimaryKey}}Id{{else}}{{.ColumnNameCamel}}{{end}}), interceptor.ServerCtxRequestIDField(ctx))
+		logger.Error("GetBy{{.ColumnNameCamel}} error", logger.Err(err), logger.Any("req", req), interceptor.ServerCtxRequestIDField(ctx))
 		return nil, ecode.StatusInternalServerError.ToRPCErr()
 	}
 
@@ -173,7 +175,7 @@ func (s *{{.TableNameCamelFCL}}) List(ctx context.Context, req *serverNameExampl
 	for _, record := range records {
 		data, err := convert{{.TableNameCamel}}(record)
 		if err != nil {
-			logger.Warn("convert{{.TableNameCamel}} error", logger.Err(err), logger.Any("{{.ColumnNameCamelFCL}}", record.{{.ColumnNameCamel}}), interceptor.ServerCtxRequestIDField(ctx))
+			logger.Warn("convert{{.TableNameCamel}} error", logger.Err(err), logger.Any("{{.TableNameCamelFCL}}", record), interceptor.ServerCtxRequestIDField(ctx))
 			continue
 		}
 		{{.TableNamePluralCamelFCL}} = append({{.TableNamePluralCamelFCL}}, data)
@@ -192,7 +194,9 @@ func convert{{.TableNameCamel}}(record *model.{{.TableNameCamel}}) (*serverNameE
 		return nil, err
 	}
 	// Note: if copier.Copy cannot assign a value to a field, add it here, e.g. CreatedAt, UpdatedAt
-	value.{{if .IsStandardPrimaryKey}}Id{{else}}{{.ColumnNameCamel}}{{end}} = record.{{.ColumnNameCamel}}
+{{- range .GetPrimaryKeys}}
+	value.{{.GetProtoFieldName}} = record.{{.ColumnNameCamel}}
+{{- end}}
 	// todo generate the conversion createdAt and updatedAt code here
 	// delete the templates code start
 	value.CreatedAt = record.CreatedAt.Format(time.RFC3339)
@@ -201,6 +201,26 @@ func Test_userExampleService_List(t *testing.T) {
 	assert.NoError(t, err)
 	t.Log(reply.String())
 
+	// tree-structured conditions and fields projection
+	s.MockDao.SQLMock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(testData.ID))
+	reply, err = s.IServiceClient.(serverNameExampleV1.UserExampleClient).List(s.Ctx, &serverNameExampleV1.ListUserExampleRequest{
+		Params: &types.Params{
+			Page:   0,
+			Limit:  10,
+			Sort:   "ignore count", // ignore test count
+			Fields: "id",
+			Where: &types.Group{
+				Logic: "or",
+				Columns: []*types.Column{
+					{Name: "id", Value: "1"},
+					{Name: "email", Value: "foo@bar.com"},
+				},
+			},
+		},
+	})
+	assert.NoError(t, err)
+	t.Log(reply.String())
+
 	// get error test
 	reply, err = s.IServiceClient.(serverNameExampleV1.UserExampleClient).List(s.Ctx, &serverNameExampleV1.ListUserExampleRequest{
 		Params: &types.Params{
@@ -58,7 +58,9 @@ type Create{{.TableNameCamel}}Reply struct {
 	Code int    `json:"code"` // return code
 	Msg  string `json:"msg"`  // return information description
 	Data struct {
+{{- range .GetPrimaryKeys}}
 		{{.ColumnNameCamel}} {{.GoType}} `json:"{{.ColumnNameCamelFCL}}"`
+{{- end}}
 	} `json:"data"` // return data
 }
 
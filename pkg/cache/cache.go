@@ -1,9 +1,11 @@
-// Package cache is memory and redis cache libraries.
+// Package cache is memory, redis and multilevel(memory + redis) cache libraries.
 package cache
 
 import (
 	"context"
 	"errors"
+	"fmt"
+	"reflect"
 	"time"
 )
 
@@ -18,6 +20,10 @@ var (
 	NotFoundPlaceholder      = "*"
 	NotFoundPlaceholderBytes = []byte(NotFoundPlaceholder)
 	ErrPlaceholder           = errors.New("cache: placeholder")
+	// ErrEncode encoding value error, check by errors.Is
+	ErrEncode = errors.New("cache: encode error")
+	// ErrDecode decoding value error, check by errors.Is
+	ErrDecode = errors.New("cache: decode error")
 
 	// DefaultClient generate a cache client, where keyPrefix is generally the business prefix
 	DefaultClient Cache
@@ -31,6 +37,13 @@ type Cache interface {
 	MultiGet(ctx context.Context, keys []string, valueMap interface{}) error
 	Del(ctx context.Context, keys ...string) error
 	SetCacheWithNotFound(ctx context.Context, key string) error
+
+	// SetWithTags set data and associate it with tags, the data can be deleted in groups by InvalidateTags
+	SetWithTags(ctx context.Context, key string, val interface{}, expiration time.Duration, tags ...string) error
+	// InvalidateTags delete all data associated with the tags
+	InvalidateTags(ctx context.Context, tags ...string) error
+	// DelByPattern delete all data whose key matches the pattern, e.g. "user:*"
+	DelByPattern(ctx context.Context, pattern string) error
 }
 
 // Set data
@@ -62,3 +75,58 @@ func Del(ctx context.Context, keys ...string) error {
 func SetCacheWithNotFound(ctx context.Context, key string) error {
 	return DefaultClient.SetCacheWithNotFound(ctx, key)
 }
+
+// SetWithTags set data and associate it with tags
+func SetWithTags(ctx context.Context, key string, val interface{}, expiration time.Duration, tags ...string) error {
+	return DefaultClient.SetWithTags(ctx, key, val, expiration, tags...)
+}
+
+// InvalidateTags delete all data associated with the tags
+func InvalidateTags(ctx context.Context, tags ...string) error {
+	return DefaultClient.InvalidateTags(ctx, tags...)
+}
+
+// DelByPattern delete all data whose key matches the pattern
+func DelByPattern(ctx context.Context, pattern string) error {
+	return DefaultClient.DelByPattern(ctx, pattern)
+}
+
+// codecError encoding or decoding value error, the message is kept as it is
+type codecError struct {
+	err error // ErrEncode or ErrDecode
+	msg string
+}
+
+func newCodecError(err error, format string, a ...interface{}) error {
+	return &codecError{err: err, msg: fmt.Sprintf(format, a...)}
+}
+
+func (e *codecError) Error() string {
+	return e.msg
+}
+
+func (e *codecError) Unwrap() error {
+	return e.err
+}
+
+// setMapValue set the value to the map of MultiGet, map[string]interface{} is set directly without reflection
+func setMapValue(valueMap interface{}, key string, val interface{}) {
+	if m, ok := valueMap.(map[string]interface{}); ok {
+		m[key] = val
+		return
+	}
+	reflect.ValueOf(valueMap).SetMapIndex(reflect.ValueOf(key), reflect.ValueOf(val))
+}
+
+// getMapValue get the value from the map of MultiGet
+func getMapValue(valueMap interface{}, key string) (interface{}, bool) {
+	if m, ok := valueMap.(map[string]interface{}); ok {
+		val, ok := m[key]
+		return val, ok
+	}
+	v := reflect.ValueOf(valueMap).MapIndex(reflect.ValueOf(key))
+	if !v.IsValid() {
+		return nil, false
+	}
+	return v.Interface(), true
+}
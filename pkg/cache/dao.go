@@ -0,0 +1,191 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// DefaultDaoExpireTime default expiry time of the records reloaded by DaoCache.Refresh
+var DefaultDaoExpireTime = 5 * time.Minute
+
+// DaoCacheOption set the dao cache options.
+type DaoCacheOption func(*daoCacheOptions)
+
+type daoCacheOptions struct {
+	expiration     time.Duration
+	listKeyPrefix  string
+	listTag        string
+	notFoundErrors []error
+}
+
+func defaultDaoCacheOptions() *daoCacheOptions {
+	return &daoCacheOptions{
+		expiration: DefaultDaoExpireTime,
+	}
+}
+
+func (o *daoCacheOptions) apply(opts ...DaoCacheOption) {
+	for _, opt := range opts {
+		opt(o)
+	}
+}
+
+// WithDaoExpiration set the expiry time of the records reloaded by Refresh
+func WithDaoExpiration(d time.Duration) DaoCacheOption {
+	return func(o *daoCacheOptions) {
+		if d > 0 {
+			o.expiration = d
+		}
+	}
+}
+
+// WithDaoList set the key prefix and tag of paging records, all paging records are deleted by the tag,
+// the default key prefix is the key prefix of records with "List:" suffix, e.g. "user:" --> "userList:",
+// the default tag is the key prefix of paging records without colon.
+func WithDaoList(keyPrefix string, tag string) DaoCacheOption {
+	return func(o *daoCacheOptions) {
+		o.listKeyPrefix = keyPrefix
+		o.listTag = tag
+	}
+}
+
+// WithDaoNotFoundErrors set the errors returned by the loader of Refresh when the record is not found,
+// the placeholder value is set to cache instead of the record, e.g. database.ErrRecordNotFound
+func WithDaoNotFoundErrors(errs ...error) DaoCacheOption {
+	return func(o *daoCacheOptions) {
+		o.notFoundErrors = append(o.notFoundErrors, errs...)
+	}
+}
+
+// DaoList paging records and total
+type DaoList[V any] struct {
+	Records []V   `json:"records"`
+	Total   int64 `json:"total"`
+}
+
+// DaoCache cache of the table records used by the generated dao, K is the type of primary key,
+// V is the type of record, P is the type of query parameters of paging records.
+//
+// The record is cached by primary key with stale-while-revalidate, the paging records are cached
+// by the digest of query parameters and associated with a tag, they are deleted together when the
+// table is changed. Get, GetWithRefresh, MultiGet, SetPlaceholder and IsPlaceholderErr are provided
+// by the embedded Typed.
+type DaoCache[K comparable, V any, P any] struct {
+	*Typed[K, V]
+	list *Typed[string, *DaoList[V]]
+
+	getKey         func(v V) K
+	expiration     time.Duration
+	listTag        string
+	notFoundErrors []error
+}
+
+// NewDaoCache create a dao cache, c is the cache whose newObject returns NewSWREntry of the record,
+// keyPrefix is the key prefix of records, e.g. "user:", getKey returns the primary key of the record.
+func NewDaoCache[K comparable, V any, P any](c Cache, keyPrefix string, getKey func(v V) K, opts ...DaoCacheOption) *DaoCache[K, V, P] {
+	o := defaultDaoCacheOptions()
+	o.apply(opts...)
+	if o.listKeyPrefix == "" {
+		o.listKeyPrefix = strings.TrimSuffix(keyPrefix, ":") + "List:"
+	}
+	if o.listTag == "" {
+		o.listTag = strings.TrimSuffix(o.listKeyPrefix, ":")
+	}
+
+	return &DaoCache[K, V, P]{
+		Typed:          NewTyped[K, V](NewSWRCache(c), keyPrefix),
+		list:           NewTyped[string, *DaoList[V]](c, o.listKeyPrefix),
+		getKey:         getKey,
+		expiration:     o.expiration,
+		listTag:        o.listTag,
+		notFoundErrors: o.notFoundErrors,
+	}
+}
+
+// Set write to cache, the zero key is ignored
+func (c *DaoCache[K, V, P]) Set(ctx context.Context, k K, v V, expiration time.Duration) error {
+	var zero K
+	if k == zero || isNil(v) {
+		return nil
+	}
+	return c.Typed.Set(ctx, k, v, expiration)
+}
+
+// Refresh reload the record by loader in the background and write to cache,
+// if the record is not found, set placeholder value to cache
+func (c *DaoCache[K, V, P]) Refresh(k K, loader func(ctx context.Context) (V, error)) {
+	c.Typed.Refresh(k, c.expiration, func(ctx context.Context) (V, error) {
+		v, err := loader(ctx)
+		if err != nil && c.isNotFoundErr(err) {
+			var zero V
+			return zero, c.SetPlaceholder(ctx, k)
+		}
+		return v, err
+	})
+}
+
+// MultiSet multiple set cache, the key of record is returned by getKey
+func (c *DaoCache[K, V, P]) MultiSet(ctx context.Context, records []V, expiration time.Duration) error {
+	valMap := make(map[K]V, len(records))
+	for _, v := range records {
+		valMap[c.getKey(v)] = v
+	}
+	return c.Typed.MultiSet(ctx, valMap, expiration)
+}
+
+// Del delete cache
+func (c *DaoCache[K, V, P]) Del(ctx context.Context, k K) error {
+	return c.Typed.Del(ctx, k)
+}
+
+// GetList get paging records and total from cache, the key is the digest of the query parameters
+func (c *DaoCache[K, V, P]) GetList(ctx context.Context, params P) ([]V, int64, error) {
+	digest, err := DigestKey(params)
+	if err != nil {
+		return nil, 0, err
+	}
+	data, err := c.list.Get(ctx, digest)
+	if err != nil {
+		return nil, 0, err
+	}
+	return data.Records, data.Total, nil
+}
+
+// SetList write paging records and total to cache, associated with the tag of paging records
+func (c *DaoCache[K, V, P]) SetList(ctx context.Context, params P, records []V, total int64, expiration time.Duration) error {
+	digest, err := DigestKey(params)
+	if err != nil {
+		return err
+	}
+	data := &DaoList[V]{Records: records, Total: total}
+	return c.list.SetWithTags(ctx, digest, data, expiration, c.listTag)
+}
+
+// DelList delete all paging records from cache
+func (c *DaoCache[K, V, P]) DelList(ctx context.Context) error {
+	return c.list.InvalidateTags(ctx, c.listTag)
+}
+
+func (c *DaoCache[K, V, P]) isNotFoundErr(err error) bool {
+	for _, e := range c.notFoundErrors {
+		if errors.Is(err, e) {
+			return true
+		}
+	}
+	return false
+}
+
+func isNil(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		return rv.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-dev-frame/sponge/pkg/encoding"
+	"github.com/go-dev-frame/sponge/pkg/gotest"
+)
+
+type daoParams struct {
+	Page  int    `json:"page"`
+	Limit int    `json:"limit"`
+	Sort  string `json:"sort"`
+}
+
+var errRecordNotFound = errors.New("record not found")
+
+func testDaoCache(t *testing.T, c Cache) {
+	dc := NewDaoCache[uint64, *redisUser, *daoParams](c, "user:",
+		func(v *redisUser) uint64 { return v.ID },
+		WithDaoExpiration(time.Minute),
+		WithDaoNotFoundErrors(errRecordNotFound),
+	)
+	ctx := context.Background()
+	u1 := &redisUser{ID: 1, Name: "foo"}
+	u2 := &redisUser{ID: 2, Name: "bar"}
+
+	// zero key and nil value are ignored
+	assert.NoError(t, dc.Set(ctx, 0, u1, time.Minute))
+	assert.NoError(t, dc.Set(ctx, 3, nil, time.Minute))
+	_, err := dc.Get(ctx, 3)
+	assert.Error(t, err)
+
+	err = dc.Set(ctx, u1.ID, u1, time.Minute)
+	assert.NoError(t, err)
+	time.Sleep(time.Millisecond * 10)
+	got, err := dc.Get(ctx, u1.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, u1.Name, got.Name)
+
+	err = dc.MultiSet(ctx, []*redisUser{u1, u2}, time.Minute)
+	assert.NoError(t, err)
+	time.Sleep(time.Millisecond * 10)
+	items, err := dc.MultiGet(ctx, []uint64{u1.ID, u2.ID})
+	assert.NoError(t, err)
+	assert.Len(t, items, 2)
+
+	err = dc.Del(ctx, u1.ID)
+	assert.NoError(t, err)
+	time.Sleep(time.Millisecond * 10)
+	_, err = dc.Get(ctx, u1.ID)
+	assert.Error(t, err)
+
+	// paging records
+	params := &daoParams{Page: 0, Limit: 10, Sort: "-id"}
+	err = dc.SetList(ctx, params, []*redisUser{u1, u2}, 2, time.Minute)
+	assert.NoError(t, err)
+	time.Sleep(time.Millisecond * 10)
+	records, total, err := dc.GetList(ctx, &daoParams{Page: 0, Limit: 10, Sort: "-id"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Len(t, records, 2)
+	_, _, err = dc.GetList(ctx, &daoParams{Page: 1, Limit: 10, Sort: "-id"})
+	assert.Error(t, err)
+
+	err = dc.DelList(ctx)
+	assert.NoError(t, err)
+	time.Sleep(time.Millisecond * 10)
+	_, _, err = dc.GetList(ctx, params)
+	assert.Error(t, err)
+
+	// the placeholder is set when the record is not found
+	dc.Refresh(u2.ID, func(ctx context.Context) (*redisUser, error) {
+		return nil, errRecordNotFound
+	})
+	time.Sleep(time.Millisecond * 50)
+	_, err = dc.Get(ctx, u2.ID)
+	assert.True(t, dc.IsPlaceholderErr(err))
+}
+
+func TestDaoCache(t *testing.T) {
+	newObject := func() interface{} {
+		return NewSWREntry(&redisUser{})
+	}
+
+	t.Run("memory", func(t *testing.T) {
+		testDaoCache(t, NewMemoryCache("", encoding.JSONEncoding{}, newObject))
+	})
+
+	t.Run("redis", func(t *testing.T) {
+		c := gotest.NewCache(nil)
+		defer c.Close()
+		testDaoCache(t, NewRedisCache(c.RedisClient, "", encoding.JSONEncoding{}, newObject))
+	})
+}
+
+func TestNewDaoCache(t *testing.T) {
+	dc := NewDaoCache[string, *redisUser, *daoParams](NewMemoryCache("", encoding.JSONEncoding{}, func() interface{} {
+		return NewSWREntry(&redisUser{})
+	}), "user:", func(v *redisUser) string { return v.Name })
+	assert.Equal(t, DefaultDaoExpireTime, dc.expiration)
+	assert.Equal(t, "userList", dc.listTag)
+	assert.Equal(t, "userList:foo", dc.list.Key("foo"))
+	assert.False(t, dc.isNotFoundErr(errRecordNotFound))
+
+	assert.True(t, isNil(nil))
+	assert.True(t, isNil((*redisUser)(nil)))
+	assert.False(t, isNil(1))
+}
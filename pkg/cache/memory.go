@@ -5,7 +5,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"reflect"
 	"time"
 
 	"github.com/dgraph-io/ristretto"
@@ -19,6 +18,7 @@ type memoryCache struct {
 	encoding          encoding.Encoding
 	DefaultExpireTime time.Duration
 	newObject         func() interface{}
+	index             *keyIndex
 }
 
 // NewMemoryCache create a memory cache
@@ -36,6 +36,7 @@ func NewMemoryCache(keyPrefix string, encode encoding.Encoding, newObject func()
 		KeyPrefix: keyPrefix,
 		encoding:  encode,
 		newObject: newObject,
+		index:     newKeyIndex(),
 	}
 }
 
@@ -43,7 +44,7 @@ func NewMemoryCache(keyPrefix string, encode encoding.Encoding, newObject func()
 func (m *memoryCache) Set(_ context.Context, key string, val interface{}, expiration time.Duration) error {
 	buf, err := encoding.Marshal(m.encoding, val)
 	if err != nil {
-		return fmt.Errorf("encoding.Marshal error: %v, key=%s, val=%+v ", err, key, val)
+		return newCodecError(ErrEncode, "encoding.Marshal error: %v, key=%s, val=%+v ", err, key, val)
 	}
 	if len(buf) == 0 {
 		buf = NotFoundPlaceholderBytes
@@ -56,6 +57,7 @@ func (m *memoryCache) Set(_ context.Context, key string, val interface{}, expira
 	if !ok {
 		return errors.New("SetWithTTL failed")
 	}
+	m.index.add(cacheKey, expiration)
 
 	return nil
 }
@@ -83,7 +85,7 @@ func (m *memoryCache) Get(_ context.Context, key string, val interface{}) error
 
 	err = encoding.Unmarshal(m.encoding, dataBytes, val)
 	if err != nil {
-		return fmt.Errorf("encoding.Unmarshal error: %v, key=%s, cacheKey=%s, type=%T, data=%s ",
+		return newCodecError(ErrDecode, "encoding.Unmarshal error: %v, key=%s, cacheKey=%s, type=%T, data=%s ",
 			err, key, cacheKey, val, dataBytes)
 	}
 	return nil
@@ -101,6 +103,7 @@ func (m *memoryCache) Del(_ context.Context, keys ...string) error {
 		return fmt.Errorf("build cache key error, err=%v, key=%s", err, key)
 	}
 	m.client.Del(cacheKey)
+	m.index.remove(cacheKey)
 	return nil
 }
 
@@ -118,7 +121,6 @@ func (m *memoryCache) MultiSet(ctx context.Context, valueMap map[string]interfac
 
 // MultiGet multiple get data
 func (m *memoryCache) MultiGet(ctx context.Context, keys []string, value interface{}) error {
-	valueMap := reflect.ValueOf(value)
 	var err error
 	for _, key := range keys {
 		object := m.newObject()
@@ -126,7 +128,7 @@ func (m *memoryCache) MultiGet(ctx context.Context, keys []string, value interfa
 		if err != nil {
 			continue
 		}
-		valueMap.SetMapIndex(reflect.ValueOf(key), reflect.ValueOf(object))
+		setMapValue(value, key, object)
 	}
 
 	return nil
@@ -143,6 +145,42 @@ func (m *memoryCache) SetCacheWithNotFound(_ context.Context, key string) error
 	if !ok {
 		return errors.New("SetWithTTL failed")
 	}
+	m.index.add(cacheKey, DefaultNotFoundExpireTime)
 
 	return nil
 }
+
+// SetWithTags set data and associate it with tags
+func (m *memoryCache) SetWithTags(ctx context.Context, key string, val interface{}, expiration time.Duration, tags ...string) error {
+	err := m.Set(ctx, key, val, expiration)
+	if err != nil {
+		return err
+	}
+	cacheKey, _ := BuildCacheKey(m.KeyPrefix, key)
+	m.index.add(cacheKey, expiration, tags...)
+	return nil
+}
+
+// InvalidateTags delete all data associated with the tags
+func (m *memoryCache) InvalidateTags(_ context.Context, tags ...string) error {
+	for _, cacheKey := range m.index.removeTags(tags...) {
+		m.client.Del(cacheKey)
+	}
+	return nil
+}
+
+// DelByPattern delete all data whose key matches the pattern, the pattern syntax is the same as path.Match
+func (m *memoryCache) DelByPattern(_ context.Context, pattern string) error {
+	match, err := BuildCacheKey(m.KeyPrefix, pattern)
+	if err != nil {
+		return fmt.Errorf("BuildCacheKey error: %v, pattern=%s", err, pattern)
+	}
+	cacheKeys, err := m.index.removeMatch(match)
+	if err != nil {
+		return fmt.Errorf("invalid pattern: %v, pattern=%s", err, pattern)
+	}
+	for _, cacheKey := range cacheKeys {
+		m.client.Del(cacheKey)
+	}
+	return nil
+}
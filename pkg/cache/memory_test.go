@@ -120,3 +120,56 @@ func TestMemoryCacheError(t *testing.T) {
 	err = iCache.SetCacheWithNotFound(c.Ctx, "")
 	assert.Error(t, err)
 }
+
+func TestMemoryCacheTags(t *testing.T) {
+	c := newMemoryCache()
+	defer c.Close()
+	iCache := c.ICache.(Cache)
+
+	for k, v := range c.TestDataMap {
+		err := iCache.SetWithTags(c.Ctx, "user:"+k, v, time.Minute, "users")
+		assert.NoError(t, err)
+	}
+	err := iCache.Set(c.Ctx, "order:1", c.TestDataSlice[0], time.Minute)
+	assert.NoError(t, err)
+	time.Sleep(time.Millisecond * 10)
+
+	err = iCache.InvalidateTags(c.Ctx, "users")
+	assert.NoError(t, err)
+	for k := range c.TestDataMap {
+		err = iCache.Get(c.Ctx, "user:"+k, &memoryUser{})
+		assert.Equal(t, CacheNotFound, err)
+	}
+	err = iCache.Get(c.Ctx, "order:1", &memoryUser{})
+	assert.NoError(t, err)
+
+	err = iCache.DelByPattern(c.Ctx, "order:*")
+	assert.NoError(t, err)
+	err = iCache.Get(c.Ctx, "order:1", &memoryUser{})
+	assert.Equal(t, CacheNotFound, err)
+
+	err = iCache.DelByPattern(c.Ctx, "[")
+	assert.Error(t, err)
+	err = iCache.DelByPattern(c.Ctx, "")
+	assert.Error(t, err)
+	err = iCache.SetWithTags(c.Ctx, "", c.TestDataSlice[0], time.Minute, "users")
+	assert.Error(t, err)
+}
+
+func TestKeyIndex(t *testing.T) {
+	x := newKeyIndex()
+	for i := 0; i < minPruneSize; i++ {
+		x.add(utils.IntToStr(i), time.Nanosecond, "tag1", "tag2")
+	}
+	time.Sleep(time.Millisecond)
+	x.add("foo", 0, "tag1")
+	x.add("foo", time.Minute, "tag2")
+	assert.Equal(t, minPruneSize, x.pruneSize)
+	assert.Equal(t, 1, len(x.keys))
+	assert.Equal(t, 2, len(x.tags))
+
+	keys := x.removeTags("tag1")
+	assert.Equal(t, []string{"foo"}, keys)
+	assert.Equal(t, 0, len(x.keys))
+	assert.Equal(t, 0, len(x.tags))
+}
@@ -0,0 +1,255 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	metricsNamespace = "cache"
+
+	// the part of the key before the first colon, e.g. the prefix of "userExample:1" is "userExample"
+	prefixLabels = []string{"prefix"}
+
+	cacheHits = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "hits_total",
+			Help:      "Total number of cache hits.",
+		}, prefixLabels,
+	)
+
+	cacheMisses = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "misses_total",
+			Help:      "Total number of cache misses.",
+		}, prefixLabels,
+	)
+
+	cachePlaceholderHits = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "placeholder_hits_total",
+			Help:      "Total number of cache hits on the placeholder of not found data.",
+		}, prefixLabels,
+	)
+
+	cacheErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "errors_total",
+			Help:      "Total number of cache errors, type is encode, decode or other.",
+		}, []string{"prefix", "op", "type"},
+	)
+
+	cacheDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "operation_duration_seconds",
+			Help:      "Cache operation latencies in seconds.",
+			Buckets:   []float64{.0001, .0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+		}, []string{"prefix", "op"},
+	)
+
+	metricsOnce sync.Once
+)
+
+// MetricsCounters counter metrics of cache, they are registered to the prometheus default registry
+// by NewMetricsCache, if metrics are exposed by another registry, e.g. grpc server metrics,
+// add them by metrics.WithCounterMetrics.
+func MetricsCounters() []*prometheus.CounterVec {
+	return []*prometheus.CounterVec{cacheHits, cacheMisses, cachePlaceholderHits, cacheErrors}
+}
+
+// MetricsHistograms histogram metrics of cache, add them by metrics.WithHistogramMetrics
+// if metrics are exposed by another registry.
+func MetricsHistograms() []*prometheus.HistogramVec {
+	return []*prometheus.HistogramVec{cacheDuration}
+}
+
+func registerMetrics() {
+	metricsOnce.Do(func() {
+		for _, c := range MetricsCounters() {
+			_ = prometheus.Register(c)
+		}
+		for _, h := range MetricsHistograms() {
+			_ = prometheus.Register(h)
+		}
+	})
+}
+
+// metricsCache record hits, misses, placeholder hits, errors and latency of the wrapped cache
+type metricsCache struct {
+	cache Cache
+}
+
+// NewMetricsCache wrap the cache to record metrics, labeled by the key prefix
+func NewMetricsCache(c Cache) Cache {
+	registerMetrics()
+	return &metricsCache{cache: c}
+}
+
+// Set data
+func (c *metricsCache) Set(ctx context.Context, key string, val interface{}, expiration time.Duration) error {
+	prefix := keyPrefixLabel(key)
+	defer c.observe(time.Now(), prefix, "set")
+	err := c.cache.Set(ctx, key, val, expiration)
+	c.recordError(prefix, "set", err)
+	return err
+}
+
+// Get data, record hit, miss or placeholder hit
+func (c *metricsCache) Get(ctx context.Context, key string, val interface{}) error {
+	prefix := keyPrefixLabel(key)
+	defer c.observe(time.Now(), prefix, "get")
+	err := c.cache.Get(ctx, key, val)
+
+	switch {
+	case err == nil:
+		cacheHits.WithLabelValues(prefix).Inc()
+	case errors.Is(err, CacheNotFound):
+		cacheMisses.WithLabelValues(prefix).Inc()
+	case errors.Is(err, ErrPlaceholder):
+		cachePlaceholderHits.WithLabelValues(prefix).Inc()
+	default:
+		c.recordError(prefix, "get", err)
+	}
+	return err
+}
+
+// MultiSet multiple set data
+func (c *metricsCache) MultiSet(ctx context.Context, valueMap map[string]interface{}, expiration time.Duration) error {
+	var key string
+	for k := range valueMap {
+		key = k
+		break
+	}
+	prefix := keyPrefixLabel(key)
+	defer c.observe(time.Now(), prefix, "multi_set")
+	err := c.cache.MultiSet(ctx, valueMap, expiration)
+	c.recordError(prefix, "multi_set", err)
+	return err
+}
+
+// MultiGet multiple get data, the keys are counted as hits if they are found in the map, otherwise as misses,
+// all keys are labeled by the prefix of the first key.
+func (c *metricsCache) MultiGet(ctx context.Context, keys []string, value interface{}) error {
+	if len(keys) == 0 {
+		return c.cache.MultiGet(ctx, keys, value)
+	}
+
+	key := keys[0]
+	prefix := keyPrefixLabel(key)
+	defer c.observe(time.Now(), prefix, "multi_get")
+
+	before := mapLen(value)
+	err := c.cache.MultiGet(ctx, keys, value)
+	if err != nil {
+		c.recordError(prefix, "multi_get", err)
+		return err
+	}
+
+	hits := mapLen(value) - before
+	if hits > len(keys) {
+		hits = len(keys)
+	}
+	cacheHits.WithLabelValues(prefix).Add(float64(hits))
+	cacheMisses.WithLabelValues(prefix).Add(float64(len(keys) - hits))
+	return nil
+}
+
+// Del delete data
+func (c *metricsCache) Del(ctx context.Context, keys ...string) error {
+	var key string
+	if len(keys) > 0 {
+		key = keys[0]
+	}
+	prefix := keyPrefixLabel(key)
+	defer c.observe(time.Now(), prefix, "del")
+	err := c.cache.Del(ctx, keys...)
+	c.recordError(prefix, "del", err)
+	return err
+}
+
+// SetCacheWithNotFound set not found
+func (c *metricsCache) SetCacheWithNotFound(ctx context.Context, key string) error {
+	prefix := keyPrefixLabel(key)
+	defer c.observe(time.Now(), prefix, "set_not_found")
+	err := c.cache.SetCacheWithNotFound(ctx, key)
+	c.recordError(prefix, "set_not_found", err)
+	return err
+}
+
+// SetWithTags set data and associate it with tags
+func (c *metricsCache) SetWithTags(ctx context.Context, key string, val interface{}, expiration time.Duration, tags ...string) error {
+	prefix := keyPrefixLabel(key)
+	defer c.observe(time.Now(), prefix, "set_with_tags")
+	err := c.cache.SetWithTags(ctx, key, val, expiration, tags...)
+	c.recordError(prefix, "set_with_tags", err)
+	return err
+}
+
+// InvalidateTags delete all data associated with the tags, labeled by the first tag itself
+func (c *metricsCache) InvalidateTags(ctx context.Context, tags ...string) error {
+	var tag string
+	if len(tags) > 0 {
+		tag = tags[0]
+	}
+	defer c.observe(time.Now(), tag, "invalidate_tags")
+	err := c.cache.InvalidateTags(ctx, tags...)
+	c.recordError(tag, "invalidate_tags", err)
+	return err
+}
+
+// DelByPattern delete all data whose key matches the pattern
+func (c *metricsCache) DelByPattern(ctx context.Context, pattern string) error {
+	prefix := keyPrefixLabel(pattern)
+	defer c.observe(time.Now(), prefix, "del_by_pattern")
+	err := c.cache.DelByPattern(ctx, pattern)
+	c.recordError(prefix, "del_by_pattern", err)
+	return err
+}
+
+func (c *metricsCache) observe(start time.Time, prefix string, op string) {
+	cacheDuration.WithLabelValues(prefix, op).Observe(time.Since(start).Seconds())
+}
+
+func (c *metricsCache) recordError(prefix string, op string, err error) {
+	if err == nil {
+		return
+	}
+	errType := "other"
+	if errors.Is(err, ErrEncode) {
+		errType = "encode"
+	} else if errors.Is(err, ErrDecode) {
+		errType = "decode"
+	}
+	cacheErrors.WithLabelValues(prefix, op, errType).Inc()
+}
+
+// the part of the key before the first colon is used as the label to limit the cardinality,
+// the key without colon is labeled as "other"
+func keyPrefixLabel(key string) string {
+	if i := strings.Index(key, ":"); i > 0 {
+		return key[:i]
+	}
+	return "other"
+}
+
+func mapLen(value interface{}) int {
+	if m, ok := value.(map[string]interface{}); ok {
+		return len(m)
+	}
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Map {
+		return 0
+	}
+	return v.Len()
+}
@@ -0,0 +1,76 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-dev-frame/sponge/pkg/encoding"
+)
+
+func TestMetricsCache(t *testing.T) {
+	ctx := context.Background()
+	c := NewMetricsCache(NewMemoryCache("", encoding.JSONEncoding{}, func() interface{} {
+		return &memoryUser{}
+	}))
+
+	hits := testutil.ToFloat64(cacheHits.WithLabelValues("metrics"))
+	misses := testutil.ToFloat64(cacheMisses.WithLabelValues("metrics"))
+	placeholderHits := testutil.ToFloat64(cachePlaceholderHits.WithLabelValues("metrics"))
+
+	err := c.Set(ctx, "metrics:1", &memoryUser{ID: 1, Name: "foo"}, time.Minute)
+	assert.NoError(t, err)
+	err = c.SetCacheWithNotFound(ctx, "metrics:2")
+	assert.NoError(t, err)
+	time.Sleep(time.Millisecond * 10)
+
+	err = c.Get(ctx, "metrics:1", &memoryUser{})
+	assert.NoError(t, err)
+	err = c.Get(ctx, "metrics:2", &memoryUser{})
+	assert.Equal(t, ErrPlaceholder, err)
+	err = c.Get(ctx, "metrics:3", &memoryUser{})
+	assert.Equal(t, CacheNotFound, err)
+
+	vals := make(map[string]*memoryUser)
+	err = c.MultiGet(ctx, []string{"metrics:1", "metrics:3"}, vals)
+	assert.NoError(t, err)
+
+	assert.Equal(t, hits+2, testutil.ToFloat64(cacheHits.WithLabelValues("metrics")))
+	assert.Equal(t, misses+2, testutil.ToFloat64(cacheMisses.WithLabelValues("metrics")))
+	assert.Equal(t, placeholderHits+1, testutil.ToFloat64(cachePlaceholderHits.WithLabelValues("metrics")))
+
+	// encode and decode errors
+	encodeErrors := testutil.ToFloat64(cacheErrors.WithLabelValues("metrics", "set", "encode"))
+	decodeErrors := testutil.ToFloat64(cacheErrors.WithLabelValues("metrics", "get", "decode"))
+	ch := make(chan int)
+	err = c.Set(ctx, "metrics:4", &ch, time.Minute)
+	assert.True(t, errors.Is(err, ErrEncode))
+	str := "foo"
+	err = c.Set(ctx, "metrics:5", &str, time.Minute)
+	assert.NoError(t, err)
+	time.Sleep(time.Millisecond * 10)
+	err = c.Get(ctx, "metrics:5", &memoryUser{})
+	assert.True(t, errors.Is(err, ErrDecode))
+	assert.Equal(t, encodeErrors+1, testutil.ToFloat64(cacheErrors.WithLabelValues("metrics", "set", "encode")))
+	assert.Equal(t, decodeErrors+1, testutil.ToFloat64(cacheErrors.WithLabelValues("metrics", "get", "decode")))
+
+	err = c.MultiSet(ctx, map[string]interface{}{"metrics:6": &memoryUser{ID: 6}}, time.Minute)
+	assert.NoError(t, err)
+	err = c.SetWithTags(ctx, "metrics:7", &memoryUser{ID: 7}, time.Minute, "metricsTag")
+	assert.NoError(t, err)
+	err = c.InvalidateTags(ctx, "metricsTag")
+	assert.NoError(t, err)
+	err = c.DelByPattern(ctx, "metrics:*")
+	assert.NoError(t, err)
+	err = c.Del(ctx, "metrics:1")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "userExample", keyPrefixLabel("userExample:1"))
+	assert.Equal(t, "other", keyPrefixLabel("userExample"))
+	assert.NotEmpty(t, MetricsCounters())
+	assert.NotEmpty(t, MetricsHistograms())
+}
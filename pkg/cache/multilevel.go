@@ -0,0 +1,490 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/go-dev-frame/sponge/pkg/encoding"
+	"github.com/go-dev-frame/sponge/pkg/krand"
+)
+
+var (
+	// DefaultLocalExpireTime the maximum expiry time of the local copy in multilevel cache
+	DefaultLocalExpireTime = time.Minute
+	// DefaultInvalidateChannel redis pub/sub channel used to invalidate local copies across instances
+	DefaultInvalidateChannel = "cache:multilevel:invalidate"
+)
+
+// MultiLevelOption set the multilevel cache options.
+type MultiLevelOption func(*multiLevelOptions)
+
+type multiLevelOptions struct {
+	localExpiration time.Duration
+	channel         string
+}
+
+func defaultMultiLevelOptions() *multiLevelOptions {
+	return &multiLevelOptions{
+		localExpiration: DefaultLocalExpireTime,
+		channel:         DefaultInvalidateChannel,
+	}
+}
+
+func (o *multiLevelOptions) apply(opts ...MultiLevelOption) {
+	for _, opt := range opts {
+		opt(o)
+	}
+}
+
+// WithLocalExpiration set the maximum expiry time of the local copy, the local copy
+// is expired with the smaller of this value and the expiry time passed to Set.
+func WithLocalExpiration(d time.Duration) MultiLevelOption {
+	return func(o *multiLevelOptions) {
+		if d > 0 {
+			o.localExpiration = d
+		}
+	}
+}
+
+// WithInvalidateChannel set the redis pub/sub channel name used to invalidate local copies.
+func WithInvalidateChannel(channel string) MultiLevelOption {
+	return func(o *multiLevelOptions) {
+		if channel != "" {
+			o.channel = channel
+		}
+	}
+}
+
+// invalidateMessage message published to other instances when data is changed
+type invalidateMessage struct {
+	InstanceID string   `json:"instanceID"`
+	Keys       []string `json:"keys"`
+	Pattern    string   `json:"pattern,omitempty"`
+}
+
+// multiLevelCache two-level cache, memory is the first level and redis is the second level
+type multiLevelCache struct {
+	local  Cache // key of local cache is the full cache key
+	remote *redisCache
+
+	client          *redis.Client
+	KeyPrefix       string
+	localExpiration time.Duration
+	channel         string
+	instanceID      string
+
+	closeOnce sync.Once
+}
+
+// NewMultiLevelCache create a two-level cache, reads the local memory cache first and redis second,
+// local copies of other instances are invalidated by redis pub/sub when data is changed, caches
+// using the same redis client and channel share one subscription.
+func NewMultiLevelCache(client *redis.Client, keyPrefix string, encode encoding.Encoding,
+	newObject func() interface{}, opts ...MultiLevelOption) Cache {
+	o := defaultMultiLevelOptions()
+	o.apply(opts...)
+
+	c := &multiLevelCache{
+		local:           NewMemoryCache("", encode, newObject),
+		remote:          NewRedisCache(client, keyPrefix, encode, newObject).(*redisCache),
+		client:          client,
+		KeyPrefix:       keyPrefix,
+		localExpiration: o.localExpiration,
+		channel:         o.channel,
+		instanceID:      krand.String(krand.R_All, 16),
+	}
+	subscribeInvalidate(c)
+
+	return c
+}
+
+// Set data, write to redis first, then local, and notify other instances to invalidate local copies
+func (c *multiLevelCache) Set(ctx context.Context, key string, val interface{}, expiration time.Duration) error {
+	cacheKey, err := BuildCacheKey(c.KeyPrefix, key)
+	if err != nil {
+		return fmt.Errorf("BuildCacheKey error: %v, key=%s", err, key)
+	}
+
+	err = c.remote.Set(ctx, key, val, expiration)
+	if err != nil {
+		return err
+	}
+	_ = c.local.Set(ctx, cacheKey, val, c.getLocalExpiration(expiration))
+	c.publish(ctx, cacheKey)
+
+	return nil
+}
+
+// Get data, read from local first, if not hit, read from redis and backfill local,
+// the local copy does not outlive the remaining expiry time of the redis key
+func (c *multiLevelCache) Get(ctx context.Context, key string, val interface{}) error {
+	cacheKey, err := BuildCacheKey(c.KeyPrefix, key)
+	if err != nil {
+		return fmt.Errorf("BuildCacheKey error: %v, key=%s", err, key)
+	}
+
+	err = c.local.Get(ctx, cacheKey, val)
+	if err == nil || err == ErrPlaceholder {
+		return err
+	}
+
+	err = c.remote.Get(ctx, key, val)
+	if err != nil {
+		if err == ErrPlaceholder {
+			_ = c.local.SetCacheWithNotFound(ctx, cacheKey)
+		}
+		return err
+	}
+	if expirations := c.getRemoteExpirations(ctx, cacheKey); expirations[0] > 0 {
+		_ = c.local.Set(ctx, cacheKey, val, expirations[0])
+	}
+
+	return nil
+}
+
+// MultiSet multiple set data
+func (c *multiLevelCache) MultiSet(ctx context.Context, valueMap map[string]interface{}, expiration time.Duration) error {
+	if len(valueMap) == 0 {
+		return nil
+	}
+
+	err := c.remote.MultiSet(ctx, valueMap, expiration)
+	if err != nil {
+		return err
+	}
+
+	cacheKeys := make([]string, 0, len(valueMap))
+	localExpiration := c.getLocalExpiration(expiration)
+	for key, value := range valueMap {
+		cacheKey, err := BuildCacheKey(c.KeyPrefix, key)
+		if err != nil {
+			continue
+		}
+		_ = c.local.Set(ctx, cacheKey, value, localExpiration)
+		cacheKeys = append(cacheKeys, cacheKey)
+	}
+	c.publish(ctx, cacheKeys...)
+
+	return nil
+}
+
+// MultiGet multiple get data, key in map is the full cache key, the same as redis cache
+func (c *multiLevelCache) MultiGet(ctx context.Context, keys []string, value interface{}) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	cacheKeys := make([]string, len(keys))
+	for index, key := range keys {
+		cacheKey, err := BuildCacheKey(c.KeyPrefix, key)
+		if err != nil {
+			return fmt.Errorf("BuildCacheKey error: %v, key=%s", err, key)
+		}
+		cacheKeys[index] = cacheKey
+	}
+
+	err := c.local.MultiGet(ctx, cacheKeys, value)
+	if err != nil {
+		return err
+	}
+
+	var missKeys []string
+	for index, cacheKey := range cacheKeys {
+		if _, ok := getMapValue(value, cacheKey); !ok {
+			missKeys = append(missKeys, keys[index])
+		}
+	}
+	if len(missKeys) == 0 {
+		return nil
+	}
+
+	err = c.remote.MultiGet(ctx, missKeys, value)
+	if err != nil {
+		return err
+	}
+
+	// backfill local
+	var hitKeys []string
+	var hitValues []interface{}
+	for _, key := range missKeys {
+		cacheKey, _ := BuildCacheKey(c.KeyPrefix, key)
+		if v, ok := getMapValue(value, cacheKey); ok {
+			hitKeys = append(hitKeys, cacheKey)
+			hitValues = append(hitValues, v)
+		}
+	}
+	if len(hitKeys) == 0 {
+		return nil
+	}
+	expirations := c.getRemoteExpirations(ctx, hitKeys...)
+	for i, cacheKey := range hitKeys {
+		if expirations[i] > 0 {
+			_ = c.local.Set(ctx, cacheKey, hitValues[i], expirations[i])
+		}
+	}
+
+	return nil
+}
+
+// Del delete data from redis and local, and notify other instances to invalidate local copies
+func (c *multiLevelCache) Del(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	err := c.remote.Del(ctx, keys...)
+	if err != nil {
+		return err
+	}
+
+	cacheKeys := make([]string, 0, len(keys))
+	for _, key := range keys {
+		cacheKey, err := BuildCacheKey(c.KeyPrefix, key)
+		if err != nil {
+			continue
+		}
+		_ = c.local.Del(ctx, cacheKey)
+		cacheKeys = append(cacheKeys, cacheKey)
+	}
+	c.publish(ctx, cacheKeys...)
+
+	return nil
+}
+
+// SetCacheWithNotFound set not found
+func (c *multiLevelCache) SetCacheWithNotFound(ctx context.Context, key string) error {
+	cacheKey, err := BuildCacheKey(c.KeyPrefix, key)
+	if err != nil {
+		return fmt.Errorf("BuildCacheKey error: %v, key=%s", err, key)
+	}
+
+	err = c.remote.SetCacheWithNotFound(ctx, key)
+	if err != nil {
+		return err
+	}
+	_ = c.local.SetCacheWithNotFound(ctx, cacheKey)
+	c.publish(ctx, cacheKey)
+
+	return nil
+}
+
+// SetWithTags set data and associate it with tags, and notify other instances to invalidate local copies
+func (c *multiLevelCache) SetWithTags(ctx context.Context, key string, val interface{}, expiration time.Duration, tags ...string) error {
+	cacheKey, err := BuildCacheKey(c.KeyPrefix, key)
+	if err != nil {
+		return fmt.Errorf("BuildCacheKey error: %v, key=%s", err, key)
+	}
+
+	err = c.remote.SetWithTags(ctx, key, val, expiration, tags...)
+	if err != nil {
+		return err
+	}
+	_ = c.local.Set(ctx, cacheKey, val, c.getLocalExpiration(expiration))
+	c.publish(ctx, cacheKey)
+
+	return nil
+}
+
+// InvalidateTags delete all data associated with the tags from redis and local,
+// the local copies of other instances are invalidated by the deleted keys
+func (c *multiLevelCache) InvalidateTags(ctx context.Context, tags ...string) error {
+	cacheKeys, err := redisInvalidateTags(ctx, c.client, c.KeyPrefix, tags)
+	for _, cacheKey := range cacheKeys {
+		_ = c.local.Del(ctx, cacheKey)
+	}
+	c.publish(ctx, cacheKeys...)
+	return err
+}
+
+// DelByPattern delete all data whose key matches the pattern from redis and local,
+// and notify other instances to invalidate local copies
+func (c *multiLevelCache) DelByPattern(ctx context.Context, pattern string) error {
+	match, err := BuildCacheKey(c.KeyPrefix, pattern)
+	if err != nil {
+		return fmt.Errorf("BuildCacheKey error: %v, pattern=%s", err, pattern)
+	}
+
+	_, err = redisDelByPattern(ctx, c.client, match)
+	if err != nil {
+		return err
+	}
+	_ = c.local.DelByPattern(ctx, match)
+	c.publishMessage(ctx, &invalidateMessage{InstanceID: c.instanceID, Pattern: match})
+
+	return nil
+}
+
+// Close stop receiving invalidation messages, the shared subscription is closed
+// when the last cache using it is closed
+func (c *multiLevelCache) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		err = unsubscribeInvalidate(c)
+	})
+	return err
+}
+
+func (c *multiLevelCache) getLocalExpiration(expiration time.Duration) time.Duration {
+	if expiration <= 0 || expiration > c.localExpiration {
+		return c.localExpiration
+	}
+	return expiration
+}
+
+// get the local expiry times of the keys from the remaining expiry times in redis,
+// 0 means the key should not be stored locally
+func (c *multiLevelCache) getRemoteExpirations(ctx context.Context, cacheKeys ...string) []time.Duration {
+	expirations := make([]time.Duration, len(cacheKeys))
+
+	pipeline := c.client.Pipeline()
+	cmds := make([]*redis.DurationCmd, len(cacheKeys))
+	for i, cacheKey := range cacheKeys {
+		cmds[i] = pipeline.PTTL(ctx, cacheKey)
+	}
+	_, err := pipeline.Exec(ctx)
+	if err != nil {
+		return expirations
+	}
+
+	for i, cmd := range cmds {
+		ttl := cmd.Val()
+		switch {
+		case ttl == -1: // key without expiry time
+			expirations[i] = c.localExpiration
+		case ttl > 0:
+			expirations[i] = c.getLocalExpiration(ttl)
+		}
+	}
+	return expirations
+}
+
+// the data has been changed in redis, a failed notification only leaves the local copies
+// of other instances stale until they expire, so the error is logged instead of returned
+func (c *multiLevelCache) publish(ctx context.Context, cacheKeys ...string) {
+	if len(cacheKeys) == 0 {
+		return
+	}
+	c.publishMessage(ctx, &invalidateMessage{InstanceID: c.instanceID, Keys: cacheKeys})
+}
+
+func (c *multiLevelCache) publishMessage(ctx context.Context, msg *invalidateMessage) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		fmt.Printf("json.Marshal invalidate message error: %v, msg=%+v\n", err, msg)
+		return
+	}
+	err = c.client.Publish(ctx, c.channel, data).Err()
+	if err != nil {
+		fmt.Printf("publish invalidate message error: %v, channel=%s, msg=%+v\n", err, c.channel, msg)
+	}
+}
+
+// delete the local copies changed by other instances
+func (c *multiLevelCache) invalidate(ctx context.Context, im *invalidateMessage) {
+	if im.InstanceID == c.instanceID {
+		return
+	}
+	for _, cacheKey := range im.Keys {
+		if strings.HasPrefix(cacheKey, c.KeyPrefix) {
+			_ = c.local.Del(ctx, cacheKey)
+		}
+	}
+	if im.Pattern != "" && strings.HasPrefix(im.Pattern, c.KeyPrefix) {
+		_ = c.local.DelByPattern(ctx, im.Pattern)
+	}
+}
+
+type subscriberKey struct {
+	client  *redis.Client
+	channel string
+}
+
+// invalidateSubscriber one redis subscription shared by the caches using the same client and channel
+type invalidateSubscriber struct {
+	pubSub *redis.PubSub
+	cancel context.CancelFunc
+
+	mu     sync.RWMutex
+	caches map[*multiLevelCache]struct{}
+}
+
+var (
+	subscribersMu sync.Mutex
+	subscribers   = map[subscriberKey]*invalidateSubscriber{}
+)
+
+func subscribeInvalidate(c *multiLevelCache) {
+	subscribersMu.Lock()
+	defer subscribersMu.Unlock()
+
+	key := subscriberKey{client: c.client, channel: c.channel}
+	s, ok := subscribers[key]
+	if !ok {
+		ctx, cancel := context.WithCancel(context.Background())
+		s = &invalidateSubscriber{
+			pubSub: c.client.Subscribe(ctx, c.channel),
+			cancel: cancel,
+			caches: map[*multiLevelCache]struct{}{},
+		}
+		subscribers[key] = s
+		go s.watch(ctx)
+	}
+
+	s.mu.Lock()
+	s.caches[c] = struct{}{}
+	s.mu.Unlock()
+}
+
+func unsubscribeInvalidate(c *multiLevelCache) error {
+	subscribersMu.Lock()
+	defer subscribersMu.Unlock()
+
+	key := subscriberKey{client: c.client, channel: c.channel}
+	s, ok := subscribers[key]
+	if !ok {
+		return nil
+	}
+
+	s.mu.Lock()
+	delete(s.caches, c)
+	n := len(s.caches)
+	s.mu.Unlock()
+	if n > 0 {
+		return nil
+	}
+
+	delete(subscribers, key)
+	s.cancel()
+	return s.pubSub.Close()
+}
+
+// watch invalidation messages from other instances and dispatch them to the caches
+func (s *invalidateSubscriber) watch(ctx context.Context) {
+	ch := s.pubSub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			im := &invalidateMessage{}
+			if err := json.Unmarshal([]byte(msg.Payload), im); err != nil {
+				fmt.Printf("json.Unmarshal invalidate message error: %v, payload=%s\n", err, msg.Payload)
+				continue
+			}
+			s.mu.RLock()
+			for c := range s.caches {
+				c.invalidate(ctx, im)
+			}
+			s.mu.RUnlock()
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-dev-frame/sponge/pkg/encoding"
+	"github.com/go-dev-frame/sponge/pkg/gotest"
+	"github.com/go-dev-frame/sponge/pkg/utils"
+)
+
+func newMultiLevelCache() *gotest.Cache {
+	testData := newTestData()
+	c := gotest.NewCache(testData)
+	cachePrefix := ""
+	c.ICache = NewMultiLevelCache(c.RedisClient, cachePrefix, encoding.JSONEncoding{}, func() interface{} {
+		return &redisUser{}
+	}, WithLocalExpiration(time.Minute), WithInvalidateChannel("test:invalidate"))
+
+	return c
+}
+
+func TestMultiLevelCache(t *testing.T) {
+	c := newMultiLevelCache()
+	defer c.Close()
+	testData := c.TestDataSlice[0].(*redisUser)
+	iCache := c.ICache.(Cache)
+	defer iCache.(*multiLevelCache).Close()
+
+	key := utils.Uint64ToStr(testData.ID)
+	err := iCache.Set(c.Ctx, key, c.TestDataMap[key], time.Minute)
+	assert.NoError(t, err)
+
+	val := &redisUser{}
+	err = iCache.Get(c.Ctx, key, val)
+	assert.NoError(t, err)
+	assert.Equal(t, testData.Name, val.Name)
+
+	err = iCache.Del(c.Ctx, key)
+	assert.NoError(t, err)
+	time.Sleep(time.Millisecond * 10)
+	err = iCache.Get(c.Ctx, key, val)
+	assert.Equal(t, CacheNotFound, err)
+
+	err = iCache.MultiSet(c.Ctx, c.TestDataMap, time.Minute)
+	assert.NoError(t, err)
+
+	var keys []string
+	for k := range c.TestDataMap {
+		keys = append(keys, k)
+	}
+	vals := make(map[string]*redisUser)
+	err = iCache.MultiGet(c.Ctx, keys, vals)
+	assert.NoError(t, err)
+	assert.Equal(t, len(c.TestDataSlice), len(vals))
+
+	err = iCache.SetCacheWithNotFound(c.Ctx, "not_found")
+	assert.NoError(t, err)
+	err = iCache.Get(c.Ctx, "not_found", val)
+	assert.Equal(t, ErrPlaceholder, err)
+}
+
+func TestMultiLevelCacheInvalidate(t *testing.T) {
+	c := newMultiLevelCache()
+	defer c.Close()
+	testData := c.TestDataSlice[0].(*redisUser)
+	cache1 := c.ICache.(*multiLevelCache)
+	defer cache1.Close()
+	cache2 := NewMultiLevelCache(c.RedisClient, "", encoding.JSONEncoding{}, func() interface{} {
+		return &redisUser{}
+	}, WithInvalidateChannel("test:invalidate")).(*multiLevelCache)
+	defer cache2.Close()
+	time.Sleep(time.Millisecond * 50)
+
+	key := utils.Uint64ToStr(testData.ID)
+	err := cache1.Set(c.Ctx, key, testData, time.Minute)
+	assert.NoError(t, err)
+
+	// read from redis and backfill local of cache2
+	val := &redisUser{}
+	err = cache2.Get(c.Ctx, key, val)
+	assert.NoError(t, err)
+	assert.Equal(t, testData.Name, val.Name)
+	time.Sleep(time.Millisecond * 10)
+	err = cache2.local.Get(c.Ctx, key, val)
+	assert.NoError(t, err)
+
+	// local copy of cache2 is invalidated after cache1 changed
+	err = cache1.Set(c.Ctx, key, &redisUser{ID: testData.ID, Name: "changed"}, time.Minute)
+	assert.NoError(t, err)
+	time.Sleep(time.Millisecond * 50)
+	err = cache2.local.Get(c.Ctx, key, val)
+	assert.Equal(t, CacheNotFound, err)
+	err = cache2.Get(c.Ctx, key, val)
+	assert.NoError(t, err)
+	assert.Equal(t, "changed", val.Name)
+
+	// local copy of cache2 is invalidated after cache1 deleted
+	time.Sleep(time.Millisecond * 10)
+	err = cache1.Del(c.Ctx, key)
+	assert.NoError(t, err)
+	time.Sleep(time.Millisecond * 50)
+	err = cache2.Get(c.Ctx, key, val)
+	assert.Equal(t, CacheNotFound, err)
+}
+
+func TestMultiLevelCacheError(t *testing.T) {
+	c := newMultiLevelCache()
+	defer c.Close()
+	testData := c.TestDataSlice[0].(*redisUser)
+	iCache := c.ICache.(Cache)
+	defer iCache.(*multiLevelCache).Close()
+
+	// Set empty key error test
+	key := utils.Uint64ToStr(testData.ID)
+	err := iCache.Set(c.Ctx, "", c.TestDataMap[key], time.Minute)
+	assert.Error(t, err)
+
+	// Get empty key error test
+	val := &redisUser{}
+	err = iCache.Get(c.Ctx, "", val)
+	assert.Error(t, err)
+
+	// Get empty result test
+	err = iCache.Get(c.Ctx, key, val)
+	assert.Error(t, err)
+
+	_ = iCache.MultiSet(c.Ctx, nil, time.Minute)
+	_ = iCache.MultiGet(c.Ctx, nil, nil)
+	err = iCache.MultiGet(c.Ctx, []string{""}, map[string]*redisUser{})
+	assert.Error(t, err)
+
+	// Del empty key test
+	err = iCache.Del(c.Ctx)
+	assert.NoError(t, err)
+	err = iCache.SetCacheWithNotFound(c.Ctx, "")
+	assert.Error(t, err)
+}
+
+func TestMultiLevelCacheTags(t *testing.T) {
+	c := newMultiLevelCache()
+	defer c.Close()
+	testData := c.TestDataSlice[0].(*redisUser)
+	cache1 := c.ICache.(*multiLevelCache)
+	defer cache1.Close()
+	cache2 := NewMultiLevelCache(c.RedisClient, "", encoding.JSONEncoding{}, func() interface{} {
+		return &redisUser{}
+	}, WithInvalidateChannel("test:invalidate")).(*multiLevelCache)
+	defer cache2.Close()
+	time.Sleep(time.Millisecond * 50)
+
+	err := cache1.SetWithTags(c.Ctx, "user:1", testData, time.Minute, "users")
+	assert.NoError(t, err)
+	err = cache1.Set(c.Ctx, "order:1", testData, time.Minute)
+	assert.NoError(t, err)
+
+	// backfill local of cache2
+	err = cache2.Get(c.Ctx, "user:1", &redisUser{})
+	assert.NoError(t, err)
+	err = cache2.Get(c.Ctx, "order:1", &redisUser{})
+	assert.NoError(t, err)
+	time.Sleep(time.Millisecond * 10)
+
+	// local copies of cache2 are invalidated by tags
+	err = cache1.InvalidateTags(c.Ctx, "users")
+	assert.NoError(t, err)
+	time.Sleep(time.Millisecond * 50)
+	err = cache2.local.Get(c.Ctx, "user:1", &redisUser{})
+	assert.Equal(t, CacheNotFound, err)
+	err = cache2.Get(c.Ctx, "user:1", &redisUser{})
+	assert.Equal(t, CacheNotFound, err)
+
+	// local copies of cache2 are invalidated by pattern
+	err = cache2.local.Get(c.Ctx, "order:1", &redisUser{})
+	assert.NoError(t, err)
+	err = cache1.DelByPattern(c.Ctx, "order:*")
+	assert.NoError(t, err)
+	time.Sleep(time.Millisecond * 50)
+	err = cache2.local.Get(c.Ctx, "order:1", &redisUser{})
+	assert.Equal(t, CacheNotFound, err)
+
+	err = cache1.SetWithTags(c.Ctx, "", testData, time.Minute, "users")
+	assert.Error(t, err)
+	err = cache1.DelByPattern(c.Ctx, "")
+	assert.Error(t, err)
+}
+
+func TestMultiLevelCacheRemoteExpiration(t *testing.T) {
+	c := newMultiLevelCache()
+	defer c.Close()
+	testData := c.TestDataSlice[0].(*redisUser)
+	iCache := c.ICache.(*multiLevelCache)
+	defer iCache.Close()
+
+	err := iCache.remote.Set(c.Ctx, "ttl:short", testData, time.Second*10)
+	assert.NoError(t, err)
+	err = c.RedisClient.Set(c.Ctx, "ttl:none", "{}", 0).Err()
+	assert.NoError(t, err)
+
+	// the local copy does not outlive the redis key
+	expirations := iCache.getRemoteExpirations(c.Ctx, "ttl:short", "ttl:none", "ttl:missing")
+	assert.True(t, expirations[0] > 0 && expirations[0] <= time.Second*10)
+	assert.Equal(t, iCache.localExpiration, expirations[1])
+	assert.Equal(t, time.Duration(0), expirations[2])
+
+	val := &redisUser{}
+	err = iCache.Get(c.Ctx, "ttl:short", val)
+	assert.NoError(t, err)
+	assert.Equal(t, testData.Name, val.Name)
+}
+
+func TestMultiLevelCacheSharedSubscriber(t *testing.T) {
+	c := newMultiLevelCache()
+	defer c.Close()
+	cache1 := c.ICache.(*multiLevelCache)
+	cache2 := NewMultiLevelCache(c.RedisClient, "", encoding.JSONEncoding{}, func() interface{} {
+		return &redisUser{}
+	}, WithInvalidateChannel("test:invalidate")).(*multiLevelCache)
+
+	key := subscriberKey{client: c.RedisClient, channel: "test:invalidate"}
+	subscribersMu.Lock()
+	s := subscribers[key]
+	subscribersMu.Unlock()
+	assert.NotNil(t, s)
+	assert.Equal(t, 2, len(s.caches))
+
+	assert.NoError(t, cache1.Close())
+	assert.NoError(t, cache1.Close())
+	assert.Equal(t, 1, len(s.caches))
+
+	assert.NoError(t, cache2.Close())
+	subscribersMu.Lock()
+	_, ok := subscribers[key]
+	subscribersMu.Unlock()
+	assert.False(t, ok)
+}
@@ -5,7 +5,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"reflect"
 	"strings"
 	"time"
 
@@ -40,7 +39,7 @@ func NewRedisCache(client *redis.Client, keyPrefix string, encode encoding.Encod
 func (c *redisCache) Set(ctx context.Context, key string, val interface{}, expiration time.Duration) error {
 	buf, err := encoding.Marshal(c.encoding, val)
 	if err != nil {
-		return fmt.Errorf("encoding.Marshal error: %v, key=%s, val=%+v ", err, key, val)
+		return newCodecError(ErrEncode, "encoding.Marshal error: %v, key=%s, val=%+v ", err, key, val)
 	}
 
 	cacheKey, err := BuildCacheKey(c.KeyPrefix, key)
@@ -80,7 +79,7 @@ func (c *redisCache) Get(ctx context.Context, key string, val interface{}) error
 	}
 	err = encoding.Unmarshal(c.encoding, dataBytes, val)
 	if err != nil {
-		return fmt.Errorf("encoding.Unmarshal error: %v, key=%s, cacheKey=%s, type=%T, json=%s ",
+		return newCodecError(ErrDecode, "encoding.Unmarshal error: %v, key=%s, cacheKey=%s, type=%T, json=%s ",
 			err, key, cacheKey, val, dataBytes)
 	}
 	return nil
@@ -149,8 +148,6 @@ func (c *redisCache) MultiGet(ctx context.Context, keys []string, value interfac
 		return fmt.Errorf("c.client.MGet error: %v, keys=%+v", err, cacheKeys)
 	}
 
-	// Injection into map via reflection
-	valueMap := reflect.ValueOf(value)
 	for i, v := range values {
 		if v == nil {
 			continue
@@ -165,7 +162,7 @@ func (c *redisCache) MultiGet(ctx context.Context, keys []string, value interfac
 			fmt.Printf("unmarshal data error: %+v, cacheKey=%s valueType=%T\n", err, cacheKeys[i], value)
 			continue
 		}
-		valueMap.SetMapIndex(reflect.ValueOf(cacheKeys[i]), reflect.ValueOf(object))
+		setMapValue(value, cacheKeys[i], object)
 	}
 	return nil
 }
@@ -201,6 +198,32 @@ func (c *redisCache) SetCacheWithNotFound(ctx context.Context, key string) error
 	return c.client.Set(ctx, cacheKey, NotFoundPlaceholder, DefaultNotFoundExpireTime).Err()
 }
 
+// SetWithTags set data and associate it with tags
+func (c *redisCache) SetWithTags(ctx context.Context, key string, val interface{}, expiration time.Duration, tags ...string) error {
+	err := c.Set(ctx, key, val, expiration)
+	if err != nil {
+		return err
+	}
+	cacheKey, _ := BuildCacheKey(c.KeyPrefix, key)
+	return redisAddTags(ctx, c.client, c.KeyPrefix, cacheKey, expiration, tags)
+}
+
+// InvalidateTags delete all data associated with the tags
+func (c *redisCache) InvalidateTags(ctx context.Context, tags ...string) error {
+	_, err := redisInvalidateTags(ctx, c.client, c.KeyPrefix, tags)
+	return err
+}
+
+// DelByPattern delete all data whose key matches the pattern, the pattern syntax is the same as redis SCAN MATCH
+func (c *redisCache) DelByPattern(ctx context.Context, pattern string) error {
+	match, err := BuildCacheKey(c.KeyPrefix, pattern)
+	if err != nil {
+		return fmt.Errorf("BuildCacheKey error: %v, pattern=%s", err, pattern)
+	}
+	_, err = redisDelByPattern(ctx, c.client, match)
+	return err
+}
+
 // BuildCacheKey construct a cache key with a prefix
 func BuildCacheKey(keyPrefix string, key string) (string, error) {
 	if key == "" {
@@ -240,7 +263,7 @@ func NewRedisClusterCache(client *redis.ClusterClient, keyPrefix string, encode
 func (c *redisClusterCache) Set(ctx context.Context, key string, val interface{}, expiration time.Duration) error {
 	buf, err := encoding.Marshal(c.encoding, val)
 	if err != nil {
-		return fmt.Errorf("encoding.Marshal error: %v, key=%s, val=%+v ", err, key, val)
+		return newCodecError(ErrEncode, "encoding.Marshal error: %v, key=%s, val=%+v ", err, key, val)
 	}
 
 	cacheKey, err := BuildCacheKey(c.KeyPrefix, key)
@@ -280,7 +303,7 @@ func (c *redisClusterCache) Get(ctx context.Context, key string, val interface{}
 	}
 	err = encoding.Unmarshal(c.encoding, dataBytes, val)
 	if err != nil {
-		return fmt.Errorf("encoding.Unmarshal error: %v, key=%s, cacheKey=%s, type=%T, json=%s ",
+		return newCodecError(ErrDecode, "encoding.Unmarshal error: %v, key=%s, cacheKey=%s, type=%T, json=%s ",
 			err, key, cacheKey, val, dataBytes)
 	}
 	return nil
@@ -346,8 +369,6 @@ func (c *redisClusterCache) MultiGet(ctx context.Context, keys []string, value i
 		return fmt.Errorf("c.client.MGet error: %v, keys=%+v", err, cacheKeys)
 	}
 
-	// Injection into map via reflection
-	valueMap := reflect.ValueOf(value)
 	for i, v := range values {
 		if v == nil {
 			continue
@@ -362,7 +383,7 @@ func (c *redisClusterCache) MultiGet(ctx context.Context, keys []string, value i
 			fmt.Printf("unmarshal data error: %+v, cacheKey=%s type=%T\n", err, cacheKeys[i], value)
 			continue
 		}
-		valueMap.SetMapIndex(reflect.ValueOf(cacheKeys[i]), reflect.ValueOf(object))
+		setMapValue(value, cacheKeys[i], object)
 	}
 	return nil
 }
@@ -397,3 +418,29 @@ func (c *redisClusterCache) SetCacheWithNotFound(ctx context.Context, key string
 
 	return c.client.Set(ctx, cacheKey, NotFoundPlaceholder, DefaultNotFoundExpireTime).Err()
 }
+
+// SetWithTags set data and associate it with tags
+func (c *redisClusterCache) SetWithTags(ctx context.Context, key string, val interface{}, expiration time.Duration, tags ...string) error {
+	err := c.Set(ctx, key, val, expiration)
+	if err != nil {
+		return err
+	}
+	cacheKey, _ := BuildCacheKey(c.KeyPrefix, key)
+	return redisAddTags(ctx, c.client, c.KeyPrefix, cacheKey, expiration, tags)
+}
+
+// InvalidateTags delete all data associated with the tags
+func (c *redisClusterCache) InvalidateTags(ctx context.Context, tags ...string) error {
+	_, err := redisInvalidateTags(ctx, c.client, c.KeyPrefix, tags)
+	return err
+}
+
+// DelByPattern delete all data whose key matches the pattern, the pattern syntax is the same as redis SCAN MATCH
+func (c *redisClusterCache) DelByPattern(ctx context.Context, pattern string) error {
+	match, err := BuildCacheKey(c.KeyPrefix, pattern)
+	if err != nil {
+		return fmt.Errorf("BuildCacheKey error: %v, pattern=%s", err, pattern)
+	}
+	_, err = redisDelByPattern(ctx, c.client, match)
+	return err
+}
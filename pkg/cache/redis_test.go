@@ -218,3 +218,101 @@ func TestBuildCacheKey(t *testing.T) {
 	_, err = BuildCacheKey("foo", "bar")
 	assert.NoError(t, err)
 }
+
+func testRedisCacheTags(t *testing.T, iCache Cache, c *gotest.Cache) {
+	for k, v := range c.TestDataMap {
+		err := iCache.SetWithTags(c.Ctx, "user:"+k, v, time.Minute, "users", "all")
+		assert.NoError(t, err)
+	}
+	err := iCache.SetWithTags(c.Ctx, "order:1", c.TestDataSlice[0], 0, "all")
+	assert.NoError(t, err)
+	err = iCache.Set(c.Ctx, "order:2", c.TestDataSlice[0], time.Minute)
+	assert.NoError(t, err)
+
+	err = iCache.InvalidateTags(c.Ctx, "users")
+	assert.NoError(t, err)
+	for k := range c.TestDataMap {
+		err = iCache.Get(c.Ctx, "user:"+k, &redisUser{})
+		assert.Equal(t, CacheNotFound, err)
+	}
+	err = iCache.Get(c.Ctx, "order:1", &redisUser{})
+	assert.NoError(t, err)
+
+	err = iCache.InvalidateTags(c.Ctx, "all", "not_exist")
+	assert.NoError(t, err)
+	err = iCache.Get(c.Ctx, "order:1", &redisUser{})
+	assert.Equal(t, CacheNotFound, err)
+
+	err = iCache.DelByPattern(c.Ctx, "order:*")
+	assert.NoError(t, err)
+	err = iCache.Get(c.Ctx, "order:2", &redisUser{})
+	assert.Equal(t, CacheNotFound, err)
+
+	err = iCache.InvalidateTags(c.Ctx, "")
+	assert.Error(t, err)
+	err = iCache.DelByPattern(c.Ctx, "")
+	assert.Error(t, err)
+	err = iCache.SetWithTags(c.Ctx, "", c.TestDataSlice[0], time.Minute, "users")
+	assert.Error(t, err)
+	err = iCache.SetWithTags(c.Ctx, "order:3", c.TestDataSlice[0], time.Minute, "")
+	assert.Error(t, err)
+}
+
+func TestRedisCacheTags(t *testing.T) {
+	c := newRedisCache()
+	defer c.Close()
+	testRedisCacheTags(t, c.ICache.(Cache), c)
+
+	// the tag set expires not earlier than its members
+	err := c.ICache.(Cache).SetWithTags(c.Ctx, "foo", c.TestDataSlice[0], time.Minute, "ttl")
+	assert.NoError(t, err)
+	err = c.ICache.(Cache).SetWithTags(c.Ctx, "bar", c.TestDataSlice[0], time.Hour, "ttl")
+	assert.NoError(t, err)
+	err = c.ICache.(Cache).SetWithTags(c.Ctx, "baz", c.TestDataSlice[0], time.Second, "ttl")
+	assert.NoError(t, err)
+	ttl := c.RedisClient.TTL(c.Ctx, "tag:ttl").Val()
+	assert.Equal(t, time.Hour, ttl)
+}
+
+func TestRedisClusterCacheTags(t *testing.T) {
+	c := newRedisClusterCache()
+	defer c.Close()
+	iCache := c.ICache.(Cache)
+
+	cc := &gotest.Cache{Ctx: c.Ctx, TestDataSlice: c.TestDataSlice, TestDataMap: c.TestDataMap}
+	testRedisCacheTags(t, iCache, cc)
+}
+
+func TestRedisCacheCompress(t *testing.T) {
+	c := newRedisCache()
+	defer c.Close()
+	testData := c.TestDataSlice[0].(*redisUser)
+	key := utils.Uint64ToStr(testData.ID)
+
+	// write by the uncompressed encoding
+	err := c.ICache.(Cache).Set(c.Ctx, key, testData, time.Minute)
+	assert.NoError(t, err)
+
+	compressEncoding := encoding.NewCompressEncoding(encoding.JSONEncoding{},
+		encoding.WithCompressType(encoding.CompressZstd), encoding.WithCompressThreshold(0))
+	iCache := NewRedisCache(c.RedisClient, "", compressEncoding, func() interface{} {
+		return &redisUser{}
+	})
+
+	// the old uncompressed value can still be decoded
+	val := &redisUser{}
+	err = iCache.Get(c.Ctx, key, val)
+	assert.NoError(t, err)
+	assert.Equal(t, testData.Name, val.Name)
+
+	// the new value is compressed
+	err = iCache.Set(c.Ctx, key, testData, time.Minute)
+	assert.NoError(t, err)
+	data, err := c.RedisClient.Get(c.Ctx, key).Bytes()
+	assert.NoError(t, err)
+	assert.True(t, encoding.IsCompressed(data))
+	val = &redisUser{}
+	err = iCache.Get(c.Ctx, key, val)
+	assert.NoError(t, err)
+	assert.Equal(t, testData.Name, val.Name)
+}
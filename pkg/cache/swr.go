@@ -0,0 +1,335 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/go-dev-frame/sponge/pkg/encoding"
+)
+
+var (
+	// DefaultStaleTime the time that stale data can still be served after the soft expiration,
+	// the cache expiration is the soft expiration plus the stale time.
+	DefaultStaleTime = time.Minute * 5
+	// DefaultRefreshDelta the minimum time taken to reload data, used for early refresh.
+	DefaultRefreshDelta = time.Millisecond * 50
+	// DefaultRefreshTimeout timeout of refreshing data in the background
+	DefaultRefreshTimeout = time.Second * 10
+)
+
+// SWREntry value stored with soft expiration metadata
+type SWREntry struct {
+	Value        interface{} `json:"value"`
+	SoftExpireAt int64       `json:"softExpireAt"` // unix millisecond, after this time the value is stale
+	Delta        int64       `json:"delta"`        // millisecond, time taken to reload the value
+}
+
+// NewSWREntry create an entry for decoding, obj is a pointer to the value type,
+// often used as the newObject of the cache wrapped by SWRCache.
+func NewSWREntry(obj interface{}) *SWREntry {
+	return &SWREntry{Value: obj}
+}
+
+// swrEntryMagic header of the entry whose value is encoded separately from the metadata,
+// followed by the varints of SoftExpireAt and Delta, and the encoded value.
+var swrEntryMagic = []byte{0x00, 'S', 'W'}
+
+// MarshalWith encode the entry with e, if e cannot encode the entry as a whole (e.g. the protobuf codec
+// only encodes proto message), the metadata is encoded outside the payload of the value encoded by e.
+func (e *SWREntry) MarshalWith(enc encoding.Encoding) ([]byte, error) {
+	data, err := enc.Marshal(e)
+	if err == nil {
+		return data, nil
+	}
+
+	payload, valueErr := enc.Marshal(e.Value)
+	if valueErr != nil {
+		return nil, err
+	}
+	buf := make([]byte, 0, len(swrEntryMagic)+2*binary.MaxVarintLen64+len(payload))
+	buf = append(buf, swrEntryMagic...)
+	buf = binary.AppendVarint(buf, e.SoftExpireAt)
+	buf = binary.AppendVarint(buf, e.Delta)
+	return append(buf, payload...), nil
+}
+
+// UnmarshalWith decode the entry with enc, the data encoded by MarshalWith in both ways are supported.
+func (e *SWREntry) UnmarshalWith(enc encoding.Encoding, data []byte) error {
+	if !bytes.HasPrefix(data, swrEntryMagic) {
+		return enc.Unmarshal(data, e)
+	}
+
+	data = data[len(swrEntryMagic):]
+	softExpireAt, n := binary.Varint(data)
+	if n <= 0 {
+		return errors.New("invalid swr entry data")
+	}
+	data = data[n:]
+	delta, n := binary.Varint(data)
+	if n <= 0 {
+		return errors.New("invalid swr entry data")
+	}
+	e.SoftExpireAt, e.Delta = softExpireAt, delta
+	return enc.Unmarshal(data[n:], e.Value)
+}
+
+// IsStale whether the value has passed the soft expiration
+func (e *SWREntry) IsStale(now time.Time) bool {
+	return now.UnixMilli() >= e.SoftExpireAt
+}
+
+// ShouldRefresh whether the value needs to be refreshed, the value is stale or is probabilistically
+// refreshed early before the soft expiration, the closer to expiration, the greater the probability.
+// see: https://cseweb.ucsd.edu/~avattani/papers/cache_stampede.pdf
+func (e *SWREntry) ShouldRefresh(now time.Time, beta float64, minDelta time.Duration) bool {
+	if e.IsStale(now) {
+		return true
+	}
+
+	delta := float64(e.Delta)
+	if minDelta.Milliseconds() > e.Delta {
+		delta = float64(minDelta.Milliseconds())
+	}
+	early := -delta * beta * math.Log(1-rand.Float64()) //nolint
+	return float64(now.UnixMilli())+early >= float64(e.SoftExpireAt)
+}
+
+// SWROption set the SWRCache options.
+type SWROption func(*swrOptions)
+
+type swrOptions struct {
+	staleTime      time.Duration
+	beta           float64
+	minDelta       time.Duration
+	refreshTimeout time.Duration
+}
+
+func defaultSWROptions() *swrOptions {
+	return &swrOptions{
+		staleTime:      DefaultStaleTime,
+		beta:           1.0,
+		minDelta:       DefaultRefreshDelta,
+		refreshTimeout: DefaultRefreshTimeout,
+	}
+}
+
+func (o *swrOptions) apply(opts ...SWROption) {
+	for _, opt := range opts {
+		opt(o)
+	}
+}
+
+// WithStaleTime set the time that stale data can still be served after the soft expiration
+func WithStaleTime(d time.Duration) SWROption {
+	return func(o *swrOptions) {
+		if d > 0 {
+			o.staleTime = d
+		}
+	}
+}
+
+// WithEarlyRefreshBeta set the beta of probabilistic early refresh, the larger the value,
+// the earlier the refresh, 0 means no early refresh, default is 1.0
+func WithEarlyRefreshBeta(beta float64) SWROption {
+	return func(o *swrOptions) {
+		if beta >= 0 {
+			o.beta = beta
+		}
+	}
+}
+
+// WithRefreshDelta set the minimum time taken to reload data, used for early refresh
+func WithRefreshDelta(d time.Duration) SWROption {
+	return func(o *swrOptions) {
+		if d > 0 {
+			o.minDelta = d
+		}
+	}
+}
+
+// WithRefreshTimeout set the timeout of refreshing data in the background
+func WithRefreshTimeout(d time.Duration) SWROption {
+	return func(o *swrOptions) {
+		if d > 0 {
+			o.refreshTimeout = d
+		}
+	}
+}
+
+// SWRCache stale-while-revalidate cache, the value is stored with soft expiration metadata,
+// stale value is still returned after the soft expiration, and refreshed by one goroutine
+// in the background, the newObject of the wrapped cache must return *SWREntry.
+type SWRCache struct {
+	cache Cache
+	opts  *swrOptions
+
+	refreshing sync.Map // key is the cache key which is refreshing
+}
+
+// NewSWRCache create a stale-while-revalidate cache
+func NewSWRCache(c Cache, opts ...SWROption) *SWRCache {
+	o := defaultSWROptions()
+	o.apply(opts...)
+	return &SWRCache{cache: c, opts: o}
+}
+
+// Set data, expiration is the soft expiration
+func (c *SWRCache) Set(ctx context.Context, key string, val interface{}, expiration time.Duration) error {
+	return c.SetWithDelta(ctx, key, val, expiration, 0)
+}
+
+// SetWithDelta set data and the time taken to reload it, expiration is the soft expiration
+func (c *SWRCache) SetWithDelta(ctx context.Context, key string, val interface{}, expiration time.Duration, delta time.Duration) error {
+	entry, hardExpiration := c.newEntry(val, expiration, delta)
+	return c.cache.Set(ctx, key, entry, hardExpiration)
+}
+
+// Get data, the stale data is also returned
+func (c *SWRCache) Get(ctx context.Context, key string, val interface{}) error {
+	_, err := c.GetWithRefresh(ctx, key, val)
+	return err
+}
+
+// GetWithRefresh get data, and report whether it is stale or should be refreshed early
+func (c *SWRCache) GetWithRefresh(ctx context.Context, key string, val interface{}) (bool, error) {
+	entry := NewSWREntry(val)
+	err := c.cache.Get(ctx, key, entry)
+	if err != nil {
+		return false, err
+	}
+	if entry.SoftExpireAt == 0 { // not written by SWRCache
+		return false, CacheNotFound
+	}
+
+	return entry.ShouldRefresh(time.Now(), c.opts.beta, c.opts.minDelta), nil
+}
+
+// MultiSet multiple set data, expiration is the soft expiration
+func (c *SWRCache) MultiSet(ctx context.Context, valueMap map[string]interface{}, expiration time.Duration) error {
+	if len(valueMap) == 0 {
+		return nil
+	}
+
+	var hardExpiration time.Duration
+	entryMap := make(map[string]interface{}, len(valueMap))
+	for key, value := range valueMap {
+		entryMap[key], hardExpiration = c.newEntry(value, expiration, 0)
+	}
+	return c.cache.MultiSet(ctx, entryMap, hardExpiration)
+}
+
+// MultiGet multiple get data, the stale data is also returned
+func (c *SWRCache) MultiGet(ctx context.Context, keys []string, value interface{}) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	entryMap := make(map[string]interface{})
+	err := c.cache.MultiGet(ctx, keys, entryMap)
+	if err != nil {
+		return err
+	}
+
+	if m, ok := value.(map[string]interface{}); ok {
+		for key, val := range entryMap {
+			if entry, ok := val.(*SWREntry); ok && entry.SoftExpireAt != 0 && entry.Value != nil {
+				m[key] = entry.Value
+			}
+		}
+		return nil
+	}
+
+	valueMap := reflect.ValueOf(value)
+	elemType := valueMap.Type().Elem()
+	for key, val := range entryMap {
+		entry, ok := val.(*SWREntry)
+		if !ok || entry == nil || entry.SoftExpireAt == 0 || entry.Value == nil {
+			continue
+		}
+		v := reflect.ValueOf(entry.Value)
+		if !v.Type().AssignableTo(elemType) {
+			continue
+		}
+		valueMap.SetMapIndex(reflect.ValueOf(key), v)
+	}
+	return nil
+}
+
+// Del delete data
+func (c *SWRCache) Del(ctx context.Context, keys ...string) error {
+	return c.cache.Del(ctx, keys...)
+}
+
+// SetCacheWithNotFound set not found
+func (c *SWRCache) SetCacheWithNotFound(ctx context.Context, key string) error {
+	return c.cache.SetCacheWithNotFound(ctx, key)
+}
+
+// SetWithTags set data and associate it with tags, expiration is the soft expiration
+func (c *SWRCache) SetWithTags(ctx context.Context, key string, val interface{}, expiration time.Duration, tags ...string) error {
+	entry, hardExpiration := c.newEntry(val, expiration, 0)
+	return c.cache.SetWithTags(ctx, key, entry, hardExpiration, tags...)
+}
+
+// InvalidateTags delete all data associated with the tags
+func (c *SWRCache) InvalidateTags(ctx context.Context, tags ...string) error {
+	return c.cache.InvalidateTags(ctx, tags...)
+}
+
+// DelByPattern delete all data whose key matches the pattern
+func (c *SWRCache) DelByPattern(ctx context.Context, pattern string) error {
+	return c.cache.DelByPattern(ctx, pattern)
+}
+
+// Refresh reload data by loader in the background and write to cache, for the same key,
+// only one goroutine is refreshing at the same time, if the loader returns nil value,
+// the cache is not written.
+func (c *SWRCache) Refresh(key string, expiration time.Duration, loader func(ctx context.Context) (interface{}, error)) {
+	if _, loaded := c.refreshing.LoadOrStore(key, struct{}{}); loaded {
+		return
+	}
+
+	go func() {
+		defer c.refreshing.Delete(key)
+		defer func() {
+			if e := recover(); e != nil {
+				fmt.Printf("refresh cache panic: %v, key=%s\n", e, key)
+			}
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), c.opts.refreshTimeout)
+		defer cancel()
+
+		start := time.Now()
+		val, err := loader(ctx)
+		if err != nil {
+			fmt.Printf("refresh cache error: %v, key=%s\n", err, key)
+			return
+		}
+		if val == nil || reflect.ValueOf(val).IsZero() {
+			return
+		}
+		err = c.SetWithDelta(ctx, key, val, expiration, time.Since(start))
+		if err != nil {
+			fmt.Printf("refresh cache error: %v, key=%s\n", err, key)
+		}
+	}()
+}
+
+func (c *SWRCache) newEntry(val interface{}, expiration time.Duration, delta time.Duration) (*SWREntry, time.Duration) {
+	entry := &SWREntry{Value: val, Delta: delta.Milliseconds()}
+	if expiration <= 0 { // never stale
+		entry.SoftExpireAt = math.MaxInt64
+		return entry, 0
+	}
+	entry.SoftExpireAt = time.Now().Add(expiration).UnixMilli()
+	return entry, expiration + c.opts.staleTime
+}
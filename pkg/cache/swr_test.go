@@ -0,0 +1,227 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/go-dev-frame/sponge/pkg/encoding"
+	"github.com/go-dev-frame/sponge/pkg/encoding/proto"
+	"github.com/go-dev-frame/sponge/pkg/gotest"
+	"github.com/go-dev-frame/sponge/pkg/utils"
+)
+
+func newSWRCache() *gotest.Cache {
+	testData := newTestData()
+	c := gotest.NewCache(testData)
+	cachePrefix := ""
+	rc := NewRedisCache(c.RedisClient, cachePrefix, encoding.JSONEncoding{}, func() interface{} {
+		return NewSWREntry(&redisUser{})
+	})
+	c.ICache = NewSWRCache(rc, WithStaleTime(time.Minute), WithEarlyRefreshBeta(1.0),
+		WithRefreshDelta(time.Millisecond*10), WithRefreshTimeout(time.Second))
+
+	return c
+}
+
+func TestSWREntry_ShouldRefresh(t *testing.T) {
+	now := time.Now()
+
+	entry := &SWREntry{SoftExpireAt: now.Add(-time.Second).UnixMilli()}
+	assert.True(t, entry.IsStale(now))
+	assert.True(t, entry.ShouldRefresh(now, 1.0, time.Millisecond))
+
+	entry = &SWREntry{SoftExpireAt: now.Add(time.Hour).UnixMilli(), Delta: 10}
+	assert.False(t, entry.IsStale(now))
+	assert.False(t, entry.ShouldRefresh(now, 1.0, time.Millisecond))
+	assert.False(t, entry.ShouldRefresh(now, 0, time.Millisecond))
+
+	// close to expiration, the delta is much larger than the remaining time
+	entry = &SWREntry{SoftExpireAt: now.Add(time.Millisecond).UnixMilli(), Delta: 1000000}
+	assert.True(t, entry.ShouldRefresh(now, 1.0, time.Millisecond))
+
+	entry = &SWREntry{SoftExpireAt: math.MaxInt64}
+	assert.False(t, entry.ShouldRefresh(now, 1.0, time.Second))
+}
+
+func TestSWRCache(t *testing.T) {
+	c := newSWRCache()
+	defer c.Close()
+	testData := c.TestDataSlice[0].(*redisUser)
+	iCache := c.ICache.(Cache)
+
+	key := utils.Uint64ToStr(testData.ID)
+	err := iCache.Set(c.Ctx, key, c.TestDataMap[key], time.Minute)
+	assert.NoError(t, err)
+
+	val := &redisUser{}
+	err = iCache.Get(c.Ctx, key, val)
+	assert.NoError(t, err)
+	assert.Equal(t, testData.Name, val.Name)
+
+	err = iCache.Del(c.Ctx, key)
+	assert.NoError(t, err)
+	err = iCache.Get(c.Ctx, key, val)
+	assert.Equal(t, CacheNotFound, err)
+
+	err = iCache.MultiSet(c.Ctx, c.TestDataMap, time.Minute)
+	assert.NoError(t, err)
+
+	var keys []string
+	for k := range c.TestDataMap {
+		keys = append(keys, k)
+	}
+	vals := make(map[string]*redisUser)
+	err = iCache.MultiGet(c.Ctx, keys, vals)
+	assert.NoError(t, err)
+	assert.Equal(t, len(c.TestDataSlice), len(vals))
+
+	err = iCache.SetCacheWithNotFound(c.Ctx, "not_found")
+	assert.NoError(t, err)
+	err = iCache.Get(c.Ctx, "not_found", val)
+	assert.Equal(t, ErrPlaceholder, err)
+
+	// value not written by SWRCache
+	err = c.RedisClient.Set(c.Ctx, "legacy", `{"ID":1,"Name":"foo"}`, time.Minute).Err()
+	assert.NoError(t, err)
+	err = iCache.Get(c.Ctx, "legacy", val)
+	assert.Equal(t, CacheNotFound, err)
+}
+
+func TestSWRCache_Refresh(t *testing.T) {
+	c := newSWRCache()
+	defer c.Close()
+	testData := c.TestDataSlice[0].(*redisUser)
+	swr := c.ICache.(*SWRCache)
+
+	key := utils.Uint64ToStr(testData.ID)
+	err := swr.Set(c.Ctx, key, testData, time.Millisecond)
+	assert.NoError(t, err)
+	time.Sleep(time.Millisecond * 5)
+
+	// stale value is returned
+	val := &redisUser{}
+	isRefresh, err := swr.GetWithRefresh(c.Ctx, key, val)
+	assert.NoError(t, err)
+	assert.True(t, isRefresh)
+	assert.Equal(t, testData.Name, val.Name)
+
+	// only one goroutine is refreshing for the same key
+	var count int32
+	for i := 0; i < 10; i++ {
+		swr.Refresh(key, time.Minute, func(ctx context.Context) (interface{}, error) {
+			atomic.AddInt32(&count, 1)
+			time.Sleep(time.Millisecond * 20)
+			return &redisUser{ID: testData.ID, Name: "refreshed"}, nil
+		})
+	}
+	time.Sleep(time.Millisecond * 100)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&count))
+
+	isRefresh, err = swr.GetWithRefresh(c.Ctx, key, val)
+	assert.NoError(t, err)
+	assert.False(t, isRefresh)
+	assert.Equal(t, "refreshed", val.Name)
+
+	// loader error, stale value is kept
+	swr.Refresh(key, time.Minute, func(ctx context.Context) (interface{}, error) {
+		return nil, errors.New("load error")
+	})
+	// nil value, cache is not written
+	var nilUser *redisUser
+	swr.Refresh(key+"_nil", time.Minute, func(ctx context.Context) (interface{}, error) {
+		return nilUser, nil
+	})
+	time.Sleep(time.Millisecond * 50)
+	err = swr.Get(c.Ctx, key, val)
+	assert.NoError(t, err)
+	assert.Equal(t, "refreshed", val.Name)
+	err = swr.Get(c.Ctx, key+"_nil", val)
+	assert.Equal(t, CacheNotFound, err)
+}
+
+func TestSWRCache_Tags(t *testing.T) {
+	c := newSWRCache()
+	defer c.Close()
+	testData := c.TestDataSlice[0].(*redisUser)
+	iCache := c.ICache.(Cache)
+
+	err := iCache.SetWithTags(c.Ctx, "user:1", testData, time.Minute, "users")
+	assert.NoError(t, err)
+	err = iCache.Set(c.Ctx, "order:1", testData, time.Minute)
+	assert.NoError(t, err)
+
+	err = iCache.InvalidateTags(c.Ctx, "users")
+	assert.NoError(t, err)
+	err = iCache.Get(c.Ctx, "user:1", &redisUser{})
+	assert.Equal(t, CacheNotFound, err)
+
+	err = iCache.DelByPattern(c.Ctx, "order:*")
+	assert.NoError(t, err)
+	err = iCache.Get(c.Ctx, "order:1", &redisUser{})
+	assert.Equal(t, CacheNotFound, err)
+}
+
+func TestSWRCache_ProtoCodec(t *testing.T) {
+	codec := encoding.GetCodec(proto.Name)
+	encs := []encoding.Encoding{
+		codec,
+		encoding.NewCompressEncoding(codec, encoding.WithCompressThreshold(1)),
+	}
+	for _, enc := range encs {
+		mc := NewMemoryCache("", enc, func() interface{} {
+			return NewSWREntry(&wrapperspb.StringValue{})
+		})
+		c := NewSWRCache(mc, WithStaleTime(time.Minute))
+		ctx := context.Background()
+
+		// the metadata of the entry is encoded outside the proto payload
+		err := c.SetWithDelta(ctx, "foo", wrapperspb.String("bar"), time.Minute, time.Millisecond*20)
+		assert.NoError(t, err)
+		time.Sleep(time.Millisecond * 10)
+		val := &wrapperspb.StringValue{}
+		refreshing, err := c.GetWithRefresh(ctx, "foo", val)
+		assert.NoError(t, err)
+		assert.False(t, refreshing)
+		assert.Equal(t, "bar", val.GetValue())
+
+		err = c.MultiSet(ctx, map[string]interface{}{"k1": wrapperspb.String("v1"), "k2": wrapperspb.String("v2")}, time.Minute)
+		assert.NoError(t, err)
+		time.Sleep(time.Millisecond * 10)
+		vals := make(map[string]*wrapperspb.StringValue)
+		err = c.MultiGet(ctx, []string{"k1", "k2"}, vals)
+		assert.NoError(t, err)
+		assert.Equal(t, "v2", vals["k2"].GetValue())
+	}
+}
+
+func TestSWREntry_MarshalWith(t *testing.T) {
+	codec := encoding.GetCodec(proto.Name)
+	entry := &SWREntry{Value: wrapperspb.String("bar"), SoftExpireAt: 1700000000000, Delta: 30}
+	data, err := encoding.Marshal(codec, entry)
+	assert.NoError(t, err)
+
+	decoded := NewSWREntry(&wrapperspb.StringValue{})
+	assert.NoError(t, encoding.Unmarshal(codec, data, decoded))
+	assert.Equal(t, entry.SoftExpireAt, decoded.SoftExpireAt)
+	assert.Equal(t, entry.Delta, decoded.Delta)
+	assert.Equal(t, "bar", decoded.Value.(*wrapperspb.StringValue).GetValue())
+
+	// the entry encoded as a whole is still decoded
+	data, err = encoding.Marshal(encoding.JSONEncoding{}, entry)
+	assert.NoError(t, err)
+	assert.Equal(t, byte('{'), data[0])
+	decoded = NewSWREntry(&wrapperspb.StringValue{})
+	assert.NoError(t, encoding.Unmarshal(encoding.JSONEncoding{}, data, decoded))
+	assert.Equal(t, entry.SoftExpireAt, decoded.SoftExpireAt)
+
+	assert.Error(t, decoded.UnmarshalWith(codec, swrEntryMagic))
+	_, err = encoding.Marshal(codec, &SWREntry{Value: "not a proto message"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,281 @@
+package cache
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// tagKeyPrefix prefix of the key which stores the members of a tag
+const tagKeyPrefix = "tag:"
+
+// BuildTagKey construct the key which stores the members of a tag
+func BuildTagKey(keyPrefix string, tag string) (string, error) {
+	if tag == "" {
+		return "", errors.New("[cache] tag should not be empty")
+	}
+	return BuildCacheKey(keyPrefix, tagKeyPrefix+tag)
+}
+
+// DigestKey md5 digest of the json encoding of v, often used as a cache key composed of complex parameters
+func DigestKey(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	sum := md5.Sum(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// add the cache key to the tag set, the tag set expires not earlier than any of its members,
+// a new tag set has no ttl, so it is distinguished by whether it exists before adding.
+var addTagScript = redis.NewScript(`
+local exists = redis.call('EXISTS', KEYS[1])
+redis.call('SADD', KEYS[1], ARGV[1])
+local ttl = tonumber(ARGV[2])
+if ttl <= 0 then
+	redis.call('PERSIST', KEYS[1])
+	return 1
+end
+local cur = redis.call('PTTL', KEYS[1])
+if exists == 0 or (cur >= 0 and cur < ttl) then
+	redis.call('PEXPIRE', KEYS[1], ttl)
+end
+return 1
+`)
+
+func redisAddTags(ctx context.Context, client redis.UniversalClient, keyPrefix string, cacheKey string,
+	expiration time.Duration, tags []string) error {
+	for _, tag := range tags {
+		tagKey, err := BuildTagKey(keyPrefix, tag)
+		if err != nil {
+			return fmt.Errorf("BuildTagKey error: %v, tag=%s", err, tag)
+		}
+		err = addTagScript.Run(ctx, client, []string{tagKey}, cacheKey, expiration.Milliseconds()).Err()
+		if err != nil {
+			return fmt.Errorf("add tag error: %v, tagKey=%s, cacheKey=%s", err, tagKey, cacheKey)
+		}
+	}
+	return nil
+}
+
+// delete the members of tags, return the deleted cache keys
+func redisInvalidateTags(ctx context.Context, client redis.UniversalClient, keyPrefix string, tags []string) ([]string, error) {
+	var deletedKeys []string
+	for _, tag := range tags {
+		tagKey, err := BuildTagKey(keyPrefix, tag)
+		if err != nil {
+			return deletedKeys, fmt.Errorf("BuildTagKey error: %v, tag=%s", err, tag)
+		}
+		members, err := client.SMembers(ctx, tagKey).Result()
+		if err != nil {
+			return deletedKeys, fmt.Errorf("client.SMembers error: %v, tagKey=%s", err, tagKey)
+		}
+		if len(members) == 0 {
+			continue
+		}
+		err = redisDelKeys(ctx, client, members)
+		if err != nil {
+			return deletedKeys, err
+		}
+		// members added after SMembers are kept
+		args := make([]interface{}, len(members))
+		for i, member := range members {
+			args[i] = member
+		}
+		err = client.SRem(ctx, tagKey, args...).Err()
+		if err != nil {
+			return deletedKeys, fmt.Errorf("client.SRem error: %v, tagKey=%s", err, tagKey)
+		}
+		deletedKeys = append(deletedKeys, members...)
+	}
+	return deletedKeys, nil
+}
+
+// delete the keys matching the pattern, return the deleted cache keys
+func redisDelByPattern(ctx context.Context, client redis.UniversalClient, match string) ([]string, error) {
+	var (
+		mu          sync.Mutex
+		deletedKeys []string
+	)
+	scanAndDel := func(ctx context.Context, client redis.UniversalClient) error {
+		var cursor uint64
+		for {
+			keys, nextCursor, err := client.Scan(ctx, cursor, match, 1000).Result()
+			if err != nil {
+				return fmt.Errorf("client.Scan error: %v, match=%s", err, match)
+			}
+			if len(keys) > 0 {
+				if err = redisDelKeys(ctx, client, keys); err != nil {
+					return err
+				}
+				mu.Lock()
+				deletedKeys = append(deletedKeys, keys...)
+				mu.Unlock()
+			}
+			if nextCursor == 0 {
+				return nil
+			}
+			cursor = nextCursor
+		}
+	}
+
+	var err error
+	if cc, ok := client.(*redis.ClusterClient); ok {
+		err = cc.ForEachMaster(ctx, func(ctx context.Context, master *redis.Client) error {
+			return scanAndDel(ctx, master)
+		})
+	} else {
+		err = scanAndDel(ctx, client)
+	}
+	return deletedKeys, err
+}
+
+// delete keys one by one in a pipeline, the keys may be in different slots of redis cluster
+func redisDelKeys(ctx context.Context, client redis.UniversalClient, keys []string) error {
+	pipeline := client.Pipeline()
+	for _, key := range keys {
+		pipeline.Del(ctx, key)
+	}
+	_, err := pipeline.Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("pipeline.Exec error: %v, keys=%+v", err, keys)
+	}
+	return nil
+}
+
+// -------------------------------------------------------------------------------------------
+
+// keyIndex index of the keys in memory cache, used for tag and pattern invalidation
+type keyIndex struct {
+	mu        sync.Mutex
+	keys      map[string]*indexedKey         // cache key --> metadata
+	tags      map[string]map[string]struct{} // tag --> cache keys
+	pruneSize int
+}
+
+type indexedKey struct {
+	expireAt int64 // unix nano, 0 means never expire
+	tags     []string
+}
+
+const minPruneSize = 1024
+
+func newKeyIndex() *keyIndex {
+	return &keyIndex{
+		keys:      make(map[string]*indexedKey),
+		tags:      make(map[string]map[string]struct{}),
+		pruneSize: minPruneSize,
+	}
+}
+
+func (x *keyIndex) add(key string, expiration time.Duration, tags ...string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	ik, ok := x.keys[key]
+	if !ok {
+		ik = &indexedKey{}
+		x.keys[key] = ik
+	}
+	ik.expireAt = 0
+	if expiration > 0 {
+		ik.expireAt = time.Now().Add(expiration).UnixNano()
+	}
+	for _, tag := range tags {
+		members, ok := x.tags[tag]
+		if !ok {
+			members = make(map[string]struct{})
+			x.tags[tag] = members
+		}
+		if _, ok = members[key]; !ok {
+			members[key] = struct{}{}
+			ik.tags = append(ik.tags, tag)
+		}
+	}
+
+	if len(x.keys) >= x.pruneSize {
+		x.prune()
+	}
+}
+
+func (x *keyIndex) remove(keys ...string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	for _, key := range keys {
+		x.removeKey(key)
+	}
+}
+
+// removeTags remove the tags and return their members
+func (x *keyIndex) removeTags(tags ...string) []string {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	var keys []string
+	for _, tag := range tags {
+		for key := range x.tags[tag] {
+			keys = append(keys, key)
+			x.removeKey(key)
+		}
+		delete(x.tags, tag)
+	}
+	return keys
+}
+
+// removeMatch remove the keys matching the pattern and return them, the pattern syntax is the same as path.Match
+func (x *keyIndex) removeMatch(pattern string) ([]string, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	var keys []string
+	for key := range x.keys {
+		if ok, _ := path.Match(pattern, key); ok {
+			keys = append(keys, key)
+			x.removeKey(key)
+		}
+	}
+	return keys, nil
+}
+
+func (x *keyIndex) removeKey(key string) {
+	ik, ok := x.keys[key]
+	if !ok {
+		return
+	}
+	for _, tag := range ik.tags {
+		if members, ok := x.tags[tag]; ok {
+			delete(members, key)
+			if len(members) == 0 {
+				delete(x.tags, tag)
+			}
+		}
+	}
+	delete(x.keys, key)
+}
+
+// prune the expired keys, the index size is limited to about twice the number of live keys
+func (x *keyIndex) prune() {
+	now := time.Now().UnixNano()
+	for key, ik := range x.keys {
+		if ik.expireAt > 0 && ik.expireAt <= now {
+			x.removeKey(key)
+		}
+	}
+	x.pruneSize = 2 * len(x.keys)
+	if x.pruneSize < minPruneSize {
+		x.pruneSize = minPruneSize
+	}
+}
@@ -0,0 +1,179 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Typed type-safe cache wrapper, the key of type K is converted to the cache key with the key prefix,
+// the value of type V is usually a pointer to struct, it is the same type as the newObject of the wrapped cache.
+//
+// The key in the map returned by MultiGet of the redis and multilevel cache is the full cache key,
+// so the prefix of the wrapped cache should be empty, like the generated caches.
+type Typed[K comparable, V any] struct {
+	cache     Cache
+	keyPrefix string
+}
+
+// NewTyped create a type-safe cache, keyPrefix is the prefix of cache key, e.g. "user:"
+func NewTyped[K comparable, V any](c Cache, keyPrefix string) *Typed[K, V] {
+	return &Typed[K, V]{cache: c, keyPrefix: keyPrefix}
+}
+
+// Cache return the wrapped cache
+func (t *Typed[K, V]) Cache() Cache {
+	return t.cache
+}
+
+// Key cache key of the key k
+func (t *Typed[K, V]) Key(k K) string {
+	return t.keyPrefix + keyToString(k)
+}
+
+// Set write to cache
+func (t *Typed[K, V]) Set(ctx context.Context, k K, v V, expiration time.Duration) error {
+	return t.cache.Set(ctx, t.Key(k), v, expiration)
+}
+
+// Get cache value, return ErrPlaceholder if the value is placeholder, return CacheNotFound if not found
+func (t *Typed[K, V]) Get(ctx context.Context, k K) (V, error) {
+	var v V
+	err := t.cache.Get(ctx, t.Key(k), &v)
+	if err != nil {
+		var zero V
+		return zero, err
+	}
+	return v, nil
+}
+
+// GetWithRefresh get cache value, the stale value is also returned, and report whether it should be refreshed,
+// the wrapped cache must be a SWRCache, otherwise it is the same as Get and never reports refresh.
+func (t *Typed[K, V]) GetWithRefresh(ctx context.Context, k K) (V, bool, error) {
+	swr, ok := t.cache.(*SWRCache)
+	if !ok {
+		v, err := t.Get(ctx, k)
+		return v, false, err
+	}
+
+	var v V
+	isRefresh, err := swr.GetWithRefresh(ctx, t.Key(k), &v)
+	if err != nil {
+		var zero V
+		return zero, false, err
+	}
+	return v, isRefresh, nil
+}
+
+// Refresh reload the value by loader in the background and write to cache,
+// the wrapped cache must be a SWRCache, otherwise it does nothing.
+func (t *Typed[K, V]) Refresh(k K, expiration time.Duration, loader func(ctx context.Context) (V, error)) {
+	swr, ok := t.cache.(*SWRCache)
+	if !ok {
+		return
+	}
+	swr.Refresh(t.Key(k), expiration, func(ctx context.Context) (interface{}, error) {
+		return loader(ctx)
+	})
+}
+
+// MultiSet multiple set cache
+func (t *Typed[K, V]) MultiSet(ctx context.Context, valMap map[K]V, expiration time.Duration) error {
+	if len(valMap) == 0 {
+		return nil
+	}
+	m := make(map[string]interface{}, len(valMap))
+	for k, v := range valMap {
+		m[t.Key(k)] = v
+	}
+	return t.cache.MultiSet(ctx, m, expiration)
+}
+
+// MultiGet multiple get cache, the keys not found or placeholder are not in the returned map
+func (t *Typed[K, V]) MultiGet(ctx context.Context, keys []K) (map[K]V, error) {
+	retMap := make(map[K]V, len(keys))
+	if len(keys) == 0 {
+		return retMap, nil
+	}
+
+	cacheKeys := make([]string, 0, len(keys))
+	keyMap := make(map[string]K, len(keys))
+	for _, k := range keys {
+		cacheKey := t.Key(k)
+		cacheKeys = append(cacheKeys, cacheKey)
+		keyMap[cacheKey] = k
+	}
+
+	itemMap := make(map[string]interface{}, len(keys))
+	err := t.cache.MultiGet(ctx, cacheKeys, itemMap)
+	if err != nil {
+		return nil, err
+	}
+
+	for cacheKey, item := range itemMap {
+		k, ok := keyMap[cacheKey]
+		if !ok {
+			continue
+		}
+		if v, ok := item.(V); ok {
+			retMap[k] = v
+		}
+	}
+	return retMap, nil
+}
+
+// Del delete cache
+func (t *Typed[K, V]) Del(ctx context.Context, keys ...K) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	cacheKeys := make([]string, 0, len(keys))
+	for _, k := range keys {
+		cacheKeys = append(cacheKeys, t.Key(k))
+	}
+	return t.cache.Del(ctx, cacheKeys...)
+}
+
+// SetWithTags write to cache and associate it with tags
+func (t *Typed[K, V]) SetWithTags(ctx context.Context, k K, v V, expiration time.Duration, tags ...string) error {
+	return t.cache.SetWithTags(ctx, t.Key(k), v, expiration, tags...)
+}
+
+// InvalidateTags delete all cache associated with the tags
+func (t *Typed[K, V]) InvalidateTags(ctx context.Context, tags ...string) error {
+	return t.cache.InvalidateTags(ctx, tags...)
+}
+
+// SetPlaceholder set placeholder value to cache, prevent cache penetration
+func (t *Typed[K, V]) SetPlaceholder(ctx context.Context, k K) error {
+	return t.cache.SetCacheWithNotFound(ctx, t.Key(k))
+}
+
+// IsPlaceholderErr check if cache is placeholder error
+func (t *Typed[K, V]) IsPlaceholderErr(err error) bool {
+	return errors.Is(err, ErrPlaceholder)
+}
+
+func keyToString(k interface{}) string {
+	switch v := k.(type) {
+	case string:
+		return v
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case uint:
+		return strconv.FormatUint(uint64(v), 10)
+	case int:
+		return strconv.Itoa(v)
+	case uint32:
+		return strconv.FormatUint(uint64(v), 10)
+	case int32:
+		return strconv.FormatInt(int64(v), 10)
+	case fmt.Stringer:
+		return v.String()
+	}
+	return fmt.Sprintf("%v", k)
+}
@@ -0,0 +1,127 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-dev-frame/sponge/pkg/encoding"
+	"github.com/go-dev-frame/sponge/pkg/gotest"
+)
+
+func testTypedCache(t *testing.T, tc *Typed[uint64, *redisUser]) {
+	ctx := context.Background()
+	u1 := &redisUser{ID: 1, Name: "foo"}
+	u2 := &redisUser{ID: 2, Name: "bar"}
+	assert.Equal(t, "user:1", tc.Key(1))
+
+	err := tc.Set(ctx, u1.ID, u1, time.Minute)
+	assert.NoError(t, err)
+	time.Sleep(time.Millisecond * 10)
+	got, err := tc.Get(ctx, u1.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, u1, got)
+
+	err = tc.MultiSet(ctx, map[uint64]*redisUser{u1.ID: u1, u2.ID: u2}, time.Minute)
+	assert.NoError(t, err)
+	time.Sleep(time.Millisecond * 10)
+	items, err := tc.MultiGet(ctx, []uint64{u1.ID, u2.ID, 3})
+	assert.NoError(t, err)
+	assert.Len(t, items, 2)
+	assert.Equal(t, u2, items[u2.ID])
+
+	err = tc.Del(ctx, u1.ID, u2.ID)
+	assert.NoError(t, err)
+	time.Sleep(time.Millisecond * 10)
+	_, err = tc.Get(ctx, u1.ID)
+	assert.Error(t, err)
+
+	err = tc.SetPlaceholder(ctx, u1.ID)
+	assert.NoError(t, err)
+	time.Sleep(time.Millisecond * 10)
+	_, err = tc.Get(ctx, u1.ID)
+	assert.True(t, tc.IsPlaceholderErr(err))
+	items, err = tc.MultiGet(ctx, []uint64{u1.ID})
+	assert.NoError(t, err)
+	assert.Len(t, items, 0)
+
+	err = tc.SetWithTags(ctx, u2.ID, u2, time.Minute, "users")
+	assert.NoError(t, err)
+	time.Sleep(time.Millisecond * 10)
+	err = tc.InvalidateTags(ctx, "users")
+	assert.NoError(t, err)
+	time.Sleep(time.Millisecond * 10)
+	_, err = tc.Get(ctx, u2.ID)
+	assert.Error(t, err)
+}
+
+func TestTyped(t *testing.T) {
+	newObject := func() interface{} {
+		return &redisUser{}
+	}
+
+	t.Run("memory", func(t *testing.T) {
+		tc := NewTyped[uint64, *redisUser](NewMemoryCache("", encoding.JSONEncoding{}, newObject), "user:")
+		testTypedCache(t, tc)
+	})
+
+	t.Run("redis", func(t *testing.T) {
+		c := gotest.NewCache(nil)
+		defer c.Close()
+		tc := NewTyped[uint64, *redisUser](NewRedisCache(c.RedisClient, "", encoding.JSONEncoding{}, newObject), "user:")
+		testTypedCache(t, tc)
+		assert.NotNil(t, tc.Cache())
+	})
+}
+
+func TestTyped_SWR(t *testing.T) {
+	c := newSWRCache()
+	defer c.Close()
+	tc := NewTyped[uint64, *redisUser](c.ICache.(*SWRCache), "user:")
+	ctx := context.Background()
+
+	u := &redisUser{ID: 1, Name: "foo"}
+	err := tc.Set(ctx, u.ID, u, time.Millisecond)
+	assert.NoError(t, err)
+	time.Sleep(time.Millisecond * 5)
+
+	got, isRefresh, err := tc.GetWithRefresh(ctx, u.ID)
+	assert.NoError(t, err)
+	assert.True(t, isRefresh)
+	assert.Equal(t, u.Name, got.Name)
+
+	items, err := tc.MultiGet(ctx, []uint64{u.ID})
+	assert.NoError(t, err)
+	assert.Equal(t, u.Name, items[u.ID].Name)
+
+	tc.Refresh(u.ID, time.Hour, func(ctx context.Context) (*redisUser, error) {
+		return &redisUser{ID: 1, Name: "bar"}, nil
+	})
+	time.Sleep(time.Millisecond * 50)
+	got, isRefresh, err = tc.GetWithRefresh(ctx, u.ID)
+	assert.NoError(t, err)
+	assert.False(t, isRefresh)
+	assert.Equal(t, "bar", got.Name)
+
+	// not a SWRCache
+	mc := NewTyped[uint64, *redisUser](NewMemoryCache("", encoding.JSONEncoding{}, func() interface{} {
+		return &redisUser{}
+	}), "user:")
+	mc.Refresh(u.ID, time.Hour, nil)
+	_, isRefresh, err = mc.GetWithRefresh(ctx, u.ID)
+	assert.Error(t, err)
+	assert.False(t, isRefresh)
+}
+
+func Test_keyToString(t *testing.T) {
+	assert.Equal(t, "1", keyToString(uint64(1)))
+	assert.Equal(t, "-1", keyToString(int64(-1)))
+	assert.Equal(t, "1", keyToString(uint(1)))
+	assert.Equal(t, "1", keyToString(1))
+	assert.Equal(t, "1", keyToString(uint32(1)))
+	assert.Equal(t, "1", keyToString(int32(1)))
+	assert.Equal(t, "foo", keyToString("foo"))
+	assert.Equal(t, "1.5", keyToString(1.5))
+}
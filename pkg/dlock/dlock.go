@@ -1,7 +1,21 @@
-// Package dlock provides distributed locking primitives, supports redis and etcd.
+// Package dlock provides distributed locking primitives, supports redis, etcd and local(in-process).
 package dlock
 
-import "context"
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrNotLocked the lock is not held by the owner
+	ErrNotLocked = errors.New("dlock: lock is not held by the owner")
+
+	defaultExpiry        = 8 * time.Second
+	defaultRetryInterval = 50 * time.Millisecond
+)
 
 // Locker is the interface that wraps the basic locking operations.
 type Locker interface {
@@ -10,3 +24,109 @@ type Locker interface {
 	TryLock(ctx context.Context) (bool, error)
 	Close() error
 }
+
+// LostNotifier is implemented by the lockers that report the loss of the held lock, e.g. RedisLock.
+type LostNotifier interface {
+	// Lost returns a channel that is closed when the held lock is lost.
+	Lost() <-chan struct{}
+}
+
+// FencingLocker is a Locker that returns a fencing token when the lock is acquired,
+// the token of the same key increases monotonically, pass it to the downstream services,
+// they reject the requests whose token is smaller than the largest token they have seen.
+type FencingLocker interface {
+	Locker
+	// LockWithToken blocks until the lock is acquired or the context is canceled, return the fencing token.
+	LockWithToken(ctx context.Context) (uint64, error)
+	// TryLockWithToken tries to acquire the lock without blocking, return the fencing token.
+	TryLockWithToken(ctx context.Context) (uint64, bool, error)
+}
+
+// Option set the options of the reentrant redis lock and the local lock.
+type Option func(*options)
+
+type options struct {
+	expiry        time.Duration
+	owner         string
+	retryInterval time.Duration
+	watchdog      bool
+}
+
+func defaultOptions() *options {
+	return &options{
+		expiry:        defaultExpiry,
+		retryInterval: defaultRetryInterval,
+		watchdog:      true,
+	}
+}
+
+func (o *options) apply(opts ...Option) {
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.owner == "" {
+		o.owner = newOwnerToken()
+	}
+}
+
+// WithExpiry set the expiration of the lock, default is 8s, the lock is renewed by the watchdog
+// while the holder is alive.
+func WithExpiry(d time.Duration) Option {
+	return func(o *options) {
+		if d > 0 {
+			o.expiry = d
+		}
+	}
+}
+
+// WithOwner set the owner token, the lockers with the same owner token can acquire the same lock
+// repeatedly(reentrant), default is a random token of each locker.
+func WithOwner(token string) Option {
+	return func(o *options) {
+		o.owner = token
+	}
+}
+
+// WithRetryInterval set the interval of retrying to acquire the lock in Lock, default is 50ms
+func WithRetryInterval(d time.Duration) Option {
+	return func(o *options) {
+		if d > 0 {
+			o.retryInterval = d
+		}
+	}
+}
+
+// WithDisableWatchdog disable renewing the lock automatically, the lock is released after the expiration.
+func WithDisableWatchdog() Option {
+	return func(o *options) {
+		o.watchdog = false
+	}
+}
+
+func newOwnerToken() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// lockWithRetry retry tryLock until the lock is acquired or the context is canceled
+func lockWithRetry(ctx context.Context, interval time.Duration, tryLock func(ctx context.Context) (uint64, bool, error)) (uint64, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		token, ok, err := tryLock(ctx)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			return token, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
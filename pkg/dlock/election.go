@@ -0,0 +1,212 @@
+package dlock
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/client/v3/concurrency"
+)
+
+// Elector elects one leader among the instances campaigning with the same key.
+type Elector interface {
+	// Campaign blocks to campaign for the leadership until the context is canceled, onChange is called
+	// when the leadership of this instance changes, the leadership is resigned when it returns.
+	Campaign(ctx context.Context, onChange func(isLeader bool))
+	// IsLeader returns whether this instance is the leader.
+	IsLeader() bool
+}
+
+// KEYS[1] leader key, ARGV[1] owner, ARGV[2] expiration in milliseconds
+var electorRenewScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// KEYS[1] leader key, ARGV[1] owner
+var electorResignScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// RedisElector implements Elector using Redis, the leader holds the key with the expiration and renews it
+// every 1/3 of the expiration, the followers try to set the key at the same interval. The leadership is lost
+// if the key is not renewed before the expiration.
+type RedisElector struct {
+	client   redis.UniversalClient
+	key      string
+	opts     *options
+	isLeader atomic.Bool
+}
+
+// NewRedisElector creates a new redis elector, the expiration set by WithExpiry is the lease of the leader,
+// the owner token set by WithOwner is the id of the instance.
+func NewRedisElector(client redis.UniversalClient, key string, opts ...Option) (Elector, error) {
+	if client == nil {
+		return nil, errors.New("redis client is nil")
+	}
+	if key == "" {
+		return nil, errors.New("key is empty")
+	}
+
+	o := defaultOptions()
+	o.apply(opts...)
+	return &RedisElector{
+		client: client,
+		key:    key,
+		opts:   o,
+	}, nil
+}
+
+// Campaign blocks to campaign for the leadership until the context is canceled.
+func (e *RedisElector) Campaign(ctx context.Context, onChange func(isLeader bool)) {
+	interval := e.opts.expiry / 3
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// the lease is measured from the time before the command is sent, the leader steps down
+	// if the renewal fails and the lease may expire before the next renewal.
+	var renewedAt time.Time
+	for {
+		sentAt := time.Now()
+		if e.IsLeader() {
+			ok, err := e.renew(ctx, interval)
+			if err == nil && ok {
+				renewedAt = sentAt
+			} else if err == nil || e.isLeaseExpiring(renewedAt, interval) {
+				e.setLeader(false, onChange)
+			}
+		} else {
+			ok, err := e.client.SetNX(ctx, e.key, e.opts.owner, e.opts.expiry).Result()
+			if err == nil && ok {
+				renewedAt = sentAt
+				e.setLeader(true, onChange)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			if e.IsLeader() {
+				rctx, cancel := context.WithTimeout(context.Background(), interval)
+				_, _ = electorResignScript.Run(rctx, e.client, []string{e.key}, e.opts.owner).Result()
+				cancel()
+				e.setLeader(false, onChange)
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// IsLeader returns whether this instance is the leader.
+func (e *RedisElector) IsLeader() bool {
+	return e.isLeader.Load()
+}
+
+func (e *RedisElector) renew(ctx context.Context, timeout time.Duration) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	n, err := electorRenewScript.Run(ctx, e.client, []string{e.key}, e.opts.owner, e.opts.expiry.Milliseconds()).Int64()
+	return n == 1, err
+}
+
+// the lease expires before the next renewal
+func (e *RedisElector) isLeaseExpiring(renewedAt time.Time, interval time.Duration) bool {
+	return time.Until(renewedAt.Add(e.opts.expiry)) < interval
+}
+
+func (e *RedisElector) setLeader(isLeader bool, onChange func(isLeader bool)) {
+	if e.isLeader.Swap(isLeader) != isLeader && onChange != nil {
+		onChange(isLeader)
+	}
+}
+
+// EtcdElector implements Elector using the etcd election, the leadership is kept alive by the session,
+// it is lost when the session is expired, and then the instance campaigns again with a new session.
+type EtcdElector struct {
+	client   *clientv3.Client
+	prefix   string
+	ttl      int
+	id       string
+	isLeader atomic.Bool
+}
+
+// NewEtcdElector creates a new etcd elector, ttl is the session ttl in seconds, id is the value of
+// the instance in the election, a random id is used if it is empty.
+func NewEtcdElector(client *clientv3.Client, prefix string, ttl int, id string) (Elector, error) {
+	if client == nil {
+		return nil, errors.New("etcd client is nil")
+	}
+	if prefix == "" {
+		return nil, errors.New("prefix is empty")
+	}
+	if ttl <= 0 {
+		ttl = defaultTTL
+	}
+	if id == "" {
+		id = newOwnerToken()
+	}
+
+	return &EtcdElector{
+		client: client,
+		prefix: prefix,
+		ttl:    ttl,
+		id:     id,
+	}, nil
+}
+
+// Campaign blocks to campaign for the leadership until the context is canceled.
+func (e *EtcdElector) Campaign(ctx context.Context, onChange func(isLeader bool)) {
+	for {
+		_ = e.campaign(ctx, onChange)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// IsLeader returns whether this instance is the leader.
+func (e *EtcdElector) IsLeader() bool {
+	return e.isLeader.Load()
+}
+
+// campaign wait for the leadership and hold it until the context is canceled or the session is expired
+func (e *EtcdElector) campaign(ctx context.Context, onChange func(isLeader bool)) error {
+	session, err := concurrency.NewSession(e.client, concurrency.WithTTL(e.ttl))
+	if err != nil {
+		return err
+	}
+	defer session.Close() //nolint
+
+	election := concurrency.NewElection(session, e.prefix)
+	if err = election.Campaign(ctx, e.id); err != nil {
+		return err
+	}
+	e.setLeader(true, onChange)
+	defer e.setLeader(false, onChange)
+
+	select {
+	case <-ctx.Done():
+		rctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+		defer cancel()
+		return election.Resign(rctx)
+	case <-session.Done():
+		return errors.New("etcd session is expired")
+	}
+}
+
+func (e *EtcdElector) setLeader(isLeader bool, onChange func(isLeader bool)) {
+	if e.isLeader.Swap(isLeader) != isLeader && onChange != nil {
+		onChange(isLeader)
+	}
+}
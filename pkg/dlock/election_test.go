@@ -0,0 +1,121 @@
+package dlock
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testElector campaign with two electors, the leader is changed after the first one resigns
+func testElector(t *testing.T, e1 Elector, e2 Elector) {
+	var mu sync.Mutex
+	var changes []bool
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	done1 := make(chan struct{})
+	go func() {
+		defer close(done1)
+		e1.Campaign(ctx1, func(isLeader bool) {
+			mu.Lock()
+			changes = append(changes, isLeader)
+			mu.Unlock()
+		})
+	}()
+	assert.Eventually(t, e1.IsLeader, time.Second*3, time.Millisecond*10)
+
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	done2 := make(chan struct{})
+	go func() {
+		defer close(done2)
+		e2.Campaign(ctx2, nil)
+	}()
+	time.Sleep(time.Millisecond * 300)
+	assert.False(t, e2.IsLeader())
+
+	cancel1()
+	<-done1
+	assert.False(t, e1.IsLeader())
+	mu.Lock()
+	assert.Equal(t, []bool{true, false}, changes)
+	mu.Unlock()
+	assert.Eventually(t, e2.IsLeader, time.Second*3, time.Millisecond*10)
+
+	cancel2()
+	<-done2
+	assert.False(t, e2.IsLeader())
+}
+
+func TestRedisElector(t *testing.T) {
+	s, client := newMiniRedis(t)
+	defer s.Close()
+
+	e1, err := NewRedisElector(client, "test_leader", WithExpiry(time.Millisecond*300))
+	assert.NoError(t, err)
+	e2, _ := NewRedisElector(client, "test_leader", WithExpiry(time.Millisecond*300))
+	testElector(t, e1, e2)
+
+	_, err = NewRedisElector(nil, "foo")
+	assert.Error(t, err)
+	_, err = NewRedisElector(client, "")
+	assert.Error(t, err)
+}
+
+func TestRedisElector_LoseLeadership(t *testing.T) {
+	s, client := newMiniRedis(t)
+	defer s.Close()
+
+	e, _ := NewRedisElector(client, "test_leader", WithExpiry(time.Millisecond*300), WithOwner("node-1"))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go e.Campaign(ctx, nil)
+	assert.Eventually(t, e.IsLeader, time.Second, time.Millisecond*10)
+
+	// the key is taken by another instance
+	s.Set("test_leader", "node-2")
+	assert.Eventually(t, func() bool { return !e.IsLeader() }, time.Second, time.Millisecond*10)
+	s.Del("test_leader")
+	assert.Eventually(t, e.IsLeader, time.Second, time.Millisecond*10)
+}
+
+func TestRedisElector_RenewError(t *testing.T) {
+	s, client := newMiniRedis(t)
+	defer s.Close()
+
+	e, _ := NewRedisElector(client, "test_leader", WithExpiry(time.Millisecond*300))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go e.Campaign(ctx, nil)
+	assert.Eventually(t, e.IsLeader, time.Second, time.Millisecond*10)
+
+	// the leader steps down when redis is unavailable and the lease is about to expire
+	s.SetError("connection refused")
+	assert.Eventually(t, func() bool { return !e.IsLeader() }, time.Second, time.Millisecond*10)
+	s.SetError("")
+	s.FastForward(time.Millisecond * 300)
+	assert.Eventually(t, e.IsLeader, time.Second, time.Millisecond*10)
+
+	re := e.(*RedisElector)
+	interval := time.Millisecond * 100
+	assert.False(t, re.isLeaseExpiring(time.Now(), interval))
+	assert.False(t, re.isLeaseExpiring(time.Now().Add(-time.Millisecond*150), interval))
+	assert.True(t, re.isLeaseExpiring(time.Now().Add(-time.Millisecond*250), interval))
+	assert.True(t, re.isLeaseExpiring(time.Now().Add(-time.Second), interval))
+}
+
+func TestEtcdElector(t *testing.T) {
+	e, client := newEmbedEtcd(t)
+	defer e.Close()
+	defer client.Close()
+
+	e1, err := NewEtcdElector(client, "sponge/leader", 5, "node-1")
+	assert.NoError(t, err)
+	e2, _ := NewEtcdElector(client, "sponge/leader", 5, "")
+	testElector(t, e1, e2)
+
+	_, err = NewEtcdElector(nil, "foo", 5, "")
+	assert.Error(t, err)
+	_, err = NewEtcdElector(client, "", 5, "")
+	assert.Error(t, err)
+}
@@ -11,13 +11,15 @@ import (
 
 var defaultTTL = 15 // seconds
 
+// EtcdLock implements FencingLocker using etcd, the lock is kept alive by the session,
+// the fencing token is the revision when the lock is acquired.
 type EtcdLock struct {
 	session *concurrency.Session
 	mutex   *concurrency.Mutex
 }
 
 // NewEtcd creates a new etcd locker with the given key and ttl.
-func NewEtcd(client *clientv3.Client, key string, ttl int) (Locker, error) {
+func NewEtcd(client *clientv3.Client, key string, ttl int) (FencingLocker, error) {
 	if client == nil {
 		return nil, errors.New("etcd client is nil")
 	}
@@ -72,6 +74,31 @@ func (l *EtcdLock) TryLock(ctx context.Context) (bool, error) {
 	return false, err
 }
 
+// LockWithToken blocks until the lock is acquired or the context is canceled, return the fencing token.
+func (l *EtcdLock) LockWithToken(ctx context.Context) (uint64, error) {
+	err := l.mutex.Lock(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return l.token(), nil
+}
+
+// TryLockWithToken tries to acquire the lock without blocking, return the fencing token.
+func (l *EtcdLock) TryLockWithToken(ctx context.Context) (uint64, bool, error) {
+	ok, err := l.TryLock(ctx)
+	if !ok {
+		return 0, false, err
+	}
+	return l.token(), true, nil
+}
+
+func (l *EtcdLock) token() uint64 {
+	if hdr := l.mutex.Header(); hdr != nil {
+		return uint64(hdr.Revision)
+	}
+	return 0
+}
+
 // Close releases the lock and the etcd session.
 func (l *EtcdLock) Close() error {
 	if l.session != nil {
@@ -0,0 +1,129 @@
+package dlock
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// local locks of the process, the fencing tokens are kept after the locks are released
+var localLocks = struct {
+	sync.Mutex
+	states map[string]*localLockState
+	tokens map[string]uint64
+}{
+	states: make(map[string]*localLockState),
+	tokens: make(map[string]uint64),
+}
+
+type localLockState struct {
+	owner    string
+	count    int
+	token    uint64
+	released chan struct{} // closed when the lock is released
+}
+
+// LocalLock implements FencingLocker in the process, it has the same semantics as the reentrant redis lock,
+// it is often used in tests or single instance services without redis or etcd. The lockers with the same key
+// in the process compete for the same lock, the expiration and watchdog options are ignored.
+type LocalLock struct {
+	key  string
+	opts *options
+}
+
+// NewLocalLock creates a new local lock
+func NewLocalLock(key string, opts ...Option) (FencingLocker, error) {
+	if key == "" {
+		return nil, errors.New("key is empty")
+	}
+
+	o := defaultOptions()
+	o.apply(opts...)
+	return &LocalLock{key: key, opts: o}, nil
+}
+
+// Owner return the owner token of the locker
+func (l *LocalLock) Owner() string {
+	return l.opts.owner
+}
+
+// TryLockWithToken tries to acquire the lock without blocking, return the fencing token.
+func (l *LocalLock) TryLockWithToken(_ context.Context) (uint64, bool, error) {
+	token, _, ok := l.tryLock()
+	return token, ok, nil
+}
+
+// TryLock tries to acquire the lock without blocking.
+func (l *LocalLock) TryLock(ctx context.Context) (bool, error) {
+	_, ok, err := l.TryLockWithToken(ctx)
+	return ok, err
+}
+
+// LockWithToken blocks until the lock is acquired or the context is canceled, return the fencing token.
+func (l *LocalLock) LockWithToken(ctx context.Context) (uint64, error) {
+	for {
+		token, released, ok := l.tryLock()
+		if ok {
+			return token, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-released:
+		}
+	}
+}
+
+// Lock blocks until the lock is acquired or the context is canceled.
+func (l *LocalLock) Lock(ctx context.Context) error {
+	_, err := l.LockWithToken(ctx)
+	return err
+}
+
+// Unlock releases the lock once, return ErrNotLocked if the lock is not held by the owner.
+func (l *LocalLock) Unlock(_ context.Context) error {
+	localLocks.Lock()
+	defer localLocks.Unlock()
+
+	state, ok := localLocks.states[l.key]
+	if !ok || state.owner != l.opts.owner {
+		return ErrNotLocked
+	}
+	state.count--
+	if state.count <= 0 {
+		delete(localLocks.states, l.key)
+		close(state.released)
+	}
+	return nil
+}
+
+// Close no-op for LocalLock.
+func (l *LocalLock) Close() error {
+	return nil
+}
+
+// tryLock return the fencing token if the lock is acquired, otherwise return the channel
+// which is closed when the lock is released.
+func (l *LocalLock) tryLock() (uint64, <-chan struct{}, bool) {
+	localLocks.Lock()
+	defer localLocks.Unlock()
+
+	state, ok := localLocks.states[l.key]
+	if !ok {
+		localLocks.tokens[l.key]++
+		state = &localLockState{
+			owner:    l.opts.owner,
+			count:    1,
+			token:    localLocks.tokens[l.key],
+			released: make(chan struct{}),
+		}
+		localLocks.states[l.key] = state
+		return state.token, nil, true
+	}
+	if state.owner == l.opts.owner {
+		state.count++
+		return state.token, nil, true
+	}
+	return 0, state.released, false
+}
@@ -0,0 +1,90 @@
+package dlock
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalLock_TryLock(t *testing.T) {
+	initLocker := func() Locker {
+		l, _ := NewLocalLock("test_local_lock")
+		return l
+	}
+	testLockAndUnlock(initLocker, false, t)
+}
+
+func TestLocalLock_Lock(t *testing.T) {
+	initLocker := func() Locker {
+		l, _ := NewLocalLock("test_local_lock")
+		return l
+	}
+	testLockAndUnlock(initLocker, true, t)
+}
+
+func TestLocalLock(t *testing.T) {
+	ctx := context.Background()
+	l1, err := NewLocalLock("local_lock")
+	assert.NoError(t, err)
+	l2, _ := NewLocalLock("local_lock", WithOwner(l1.(*LocalLock).Owner()))
+	other, _ := NewLocalLock("local_lock")
+
+	token, err := l1.LockWithToken(ctx)
+	assert.NoError(t, err)
+	token2, ok, err := l2.TryLockWithToken(ctx)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, token, token2)
+
+	ok, _ = other.TryLock(ctx)
+	assert.False(t, ok)
+	assert.ErrorIs(t, other.Unlock(ctx), ErrNotLocked)
+
+	ctx2, cancel := context.WithTimeout(ctx, time.Millisecond*50)
+	defer cancel()
+	assert.ErrorIs(t, other.Lock(ctx2), context.DeadlineExceeded)
+
+	assert.NoError(t, l1.Unlock(ctx))
+	assert.NoError(t, l2.Unlock(ctx))
+	token3, err := other.LockWithToken(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, token+1, token3)
+	assert.NoError(t, other.Unlock(ctx))
+	assert.NoError(t, other.Close())
+
+	_, err = NewLocalLock("")
+	assert.Error(t, err)
+}
+
+func TestLocalLock_Concurrent(t *testing.T) {
+	ctx := context.Background()
+	var counter, maxCounter int
+	var mu sync.Mutex
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			l, _ := NewLocalLock("local_lock_concurrent")
+			if err := l.Lock(ctx); err != nil {
+				return
+			}
+			mu.Lock()
+			counter++
+			if counter > maxCounter {
+				maxCounter = counter
+			}
+			mu.Unlock()
+			time.Sleep(time.Millisecond * 5)
+			mu.Lock()
+			counter--
+			mu.Unlock()
+			_ = l.Unlock(ctx)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 1, maxCounter)
+}
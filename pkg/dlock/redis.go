@@ -3,17 +3,36 @@ package dlock
 import (
 	"context"
 	"errors"
+	"sync"
+	"time"
 
 	"github.com/go-redsync/redsync/v4"
 	"github.com/go-redsync/redsync/v4/redis/goredis/v9"
 	"github.com/redis/go-redis/v9"
 )
 
-// RedisLock implements Locker using Redis.
+// RedisLock implements Locker using Redis, the lock is released after the expiration(set by
+// redsync.WithExpiry, default 8s) unless it is renewed by the watchdog, see WithRedisWatchdog.
 type RedisLock struct {
-	mutex *redsync.Mutex
+	mutex    *redsync.Mutex
+	watchdog bool
+
+	mu     sync.Mutex
+	wd     *watchdog
+	timer  *time.Timer
+	lostCh chan struct{}
+}
+
+// WithRedisWatchdog renew the redis lock every 1/3 of the expiration until it is unlocked or closed,
+// it is an option of NewRedisLock and NewRedisClusterLock, the lock is not renewed by default.
+func WithRedisWatchdog() redsync.Option {
+	return redisWatchdogOption{}
 }
 
+type redisWatchdogOption struct{}
+
+func (redisWatchdogOption) Apply(*redsync.Mutex) {}
+
 // NewRedisLock creates a new RedisLock.
 func NewRedisLock(client *redis.Client, key string, options ...redsync.Option) (Locker, error) {
 	if client == nil {
@@ -37,19 +56,27 @@ func NewRedisClusterLock(clusterClient *redis.ClusterClient, key string, options
 }
 
 func newLocker(delegate redis.UniversalClient, key string, options ...redsync.Option) Locker {
+	l := &RedisLock{lostCh: make(chan struct{})}
+	mutexOptions := make([]redsync.Option, 0, len(options))
+	for _, o := range options {
+		if _, ok := o.(redisWatchdogOption); ok {
+			l.watchdog = true
+			continue
+		}
+		mutexOptions = append(mutexOptions, o)
+	}
+
 	pool := goredis.NewPool(delegate)
 	rs := redsync.New(pool)
-	mutex := rs.NewMutex(key, options...)
-
-	return &RedisLock{
-		mutex: mutex,
-	}
+	l.mutex = rs.NewMutex(key, mutexOptions...)
+	return l
 }
 
 // TryLock tries to acquire the lock without blocking.
 func (l *RedisLock) TryLock(ctx context.Context) (bool, error) {
 	err := l.mutex.TryLockContext(ctx)
 	if err == nil {
+		l.held()
 		return true, nil
 	}
 	return false, err
@@ -57,16 +84,80 @@ func (l *RedisLock) TryLock(ctx context.Context) (bool, error) {
 
 // Lock blocks until the lock is acquired or the context is canceled.
 func (l *RedisLock) Lock(ctx context.Context) error {
-	return l.mutex.LockContext(ctx)
+	err := l.mutex.LockContext(ctx)
+	if err != nil {
+		return err
+	}
+	l.held()
+	return nil
 }
 
 // Unlock releases the lock, if unlocking the key is successful, the key will be automatically deleted
 func (l *RedisLock) Unlock(ctx context.Context) error {
+	l.release()
 	_, err := l.mutex.UnlockContext(ctx)
 	return err
 }
 
-// Close no-op for RedisLock.
+// Close stop renewing the lock, the lock is released after the expiration if it is not unlocked.
 func (l *RedisLock) Close() error {
+	l.release()
 	return nil
 }
+
+// Lost returns a channel that is closed when the lock is lost while it is held, i.e. it is expired
+// without the watchdog, or the watchdog fails to renew it because it has been taken by others or expired.
+// The channel of the latest acquisition is returned, it is not closed by Unlock or Close.
+func (l *RedisLock) Lost() <-chan struct{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.lostCh
+}
+
+// held start watching the lock which has just been acquired
+func (l *RedisLock) held() {
+	ttl := time.Until(l.mutex.Until())
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.stopLocked()
+
+	if l.watchdog && ttl/3 > 0 {
+		l.wd = startWatchdog(ttl/3, l.extend)
+		l.lostCh = l.wd.lostCh
+		return
+	}
+
+	lostCh := make(chan struct{})
+	l.lostCh = lostCh
+	l.timer = time.AfterFunc(ttl, func() { close(lostCh) })
+}
+
+// extend the expiration of the lock, the lock is lost if it has been taken by others or expired,
+// the other errors are retried by the watchdog.
+func (l *RedisLock) extend(ctx context.Context) (bool, error) {
+	ok, err := l.mutex.ExtendContext(ctx)
+	if err != nil {
+		var errTaken *redsync.ErrTaken
+		if errors.Is(err, redsync.ErrExtendFailed) || errors.As(err, &errTaken) {
+			return false, nil
+		}
+	}
+	return ok, err
+}
+
+func (l *RedisLock) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.stopLocked()
+}
+
+// stopLocked stop the watchdog or the expiration timer, must be called with l.mu held.
+func (l *RedisLock) stopLocked() {
+	l.wd.Stop()
+	l.wd = nil
+	if l.timer != nil {
+		l.timer.Stop()
+		l.timer = nil
+	}
+}
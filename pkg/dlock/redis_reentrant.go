@@ -0,0 +1,178 @@
+package dlock
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// KEYS[1] lock key, KEYS[2] fencing token key, ARGV[1] owner, ARGV[2] expiration in milliseconds,
+// return the fencing token if the lock is acquired, otherwise return 0.
+var reentrantLockScript = redis.NewScript(`
+local owner = redis.call('HGET', KEYS[1], 'owner')
+if owner == false then
+	local token = redis.call('INCR', KEYS[2])
+	redis.call('HSET', KEYS[1], 'owner', ARGV[1], 'count', 1, 'token', token)
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+	return token
+end
+if owner == ARGV[1] then
+	redis.call('HINCRBY', KEYS[1], 'count', 1)
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+	return tonumber(redis.call('HGET', KEYS[1], 'token'))
+end
+return 0
+`)
+
+// KEYS[1] lock key, ARGV[1] owner, return the remaining count, return -1 if the lock is not held by the owner.
+var reentrantUnlockScript = redis.NewScript(`
+if redis.call('HGET', KEYS[1], 'owner') ~= ARGV[1] then
+	return -1
+end
+local count = redis.call('HINCRBY', KEYS[1], 'count', -1)
+if count <= 0 then
+	redis.call('DEL', KEYS[1])
+	return 0
+end
+return count
+`)
+
+// KEYS[1] lock key, ARGV[1] owner, ARGV[2] expiration in milliseconds
+var reentrantRenewScript = redis.NewScript(`
+if redis.call('HGET', KEYS[1], 'owner') == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// RedisReentrantLock implements FencingLocker using Redis, the lock can be acquired repeatedly by the
+// same owner, and released after it is unlocked the same number of times. The lock is renewed by the
+// watchdog while it is held, and the fencing token is increased by one each time the lock is acquired
+// from free.
+//
+// The lock key is "{key}" and the fencing token key is "{key}:fence", they are in the same slot of redis cluster,
+// the fencing token key is not deleted to keep the token increasing.
+type RedisReentrantLock struct {
+	client   redis.UniversalClient
+	key      string
+	fenceKey string
+	opts     *options
+
+	mu    sync.Mutex
+	holds int // number of times the lock is held by this locker
+	wd    *watchdog
+}
+
+// NewRedisReentrantLock creates a new reentrant redis lock, the client can be a single, sentinel or cluster client.
+func NewRedisReentrantLock(client redis.UniversalClient, key string, opts ...Option) (FencingLocker, error) {
+	if client == nil {
+		return nil, errors.New("redis client is nil")
+	}
+	if key == "" {
+		return nil, errors.New("key is empty")
+	}
+
+	o := defaultOptions()
+	o.apply(opts...)
+	lockKey := "{" + key + "}"
+
+	return &RedisReentrantLock{
+		client:   client,
+		key:      lockKey,
+		fenceKey: lockKey + ":fence",
+		opts:     o,
+	}, nil
+}
+
+// Owner return the owner token of the locker
+func (l *RedisReentrantLock) Owner() string {
+	return l.opts.owner
+}
+
+// TryLockWithToken tries to acquire the lock without blocking, return the fencing token.
+func (l *RedisReentrantLock) TryLockWithToken(ctx context.Context) (uint64, bool, error) {
+	token, err := reentrantLockScript.Run(ctx, l.client, []string{l.key, l.fenceKey},
+		l.opts.owner, l.opts.expiry.Milliseconds()).Uint64()
+	if err != nil {
+		return 0, false, err
+	}
+	if token == 0 {
+		return 0, false, nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.resetLost()
+	l.holds++
+	if l.holds == 1 && l.opts.watchdog {
+		l.wd = startWatchdog(l.opts.expiry/3, l.renew)
+	}
+	return token, true, nil
+}
+
+// TryLock tries to acquire the lock without blocking.
+func (l *RedisReentrantLock) TryLock(ctx context.Context) (bool, error) {
+	_, ok, err := l.TryLockWithToken(ctx)
+	return ok, err
+}
+
+// LockWithToken blocks until the lock is acquired or the context is canceled, return the fencing token.
+func (l *RedisReentrantLock) LockWithToken(ctx context.Context) (uint64, error) {
+	return lockWithRetry(ctx, l.opts.retryInterval, l.TryLockWithToken)
+}
+
+// Lock blocks until the lock is acquired or the context is canceled.
+func (l *RedisReentrantLock) Lock(ctx context.Context) error {
+	_, err := l.LockWithToken(ctx)
+	return err
+}
+
+// Unlock releases the lock once, the key is deleted when the count of holding is zero,
+// return ErrNotLocked if the lock is not held by the owner.
+func (l *RedisReentrantLock) Unlock(ctx context.Context) error {
+	count, err := reentrantUnlockScript.Run(ctx, l.client, []string{l.key}, l.opts.owner).Int64()
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if count < 0 {
+		l.holds = 0
+		l.wd.Stop()
+		return ErrNotLocked
+	}
+	if l.holds > 0 {
+		l.holds--
+	}
+	if l.holds == 0 || count == 0 {
+		l.wd.Stop()
+	}
+	return nil
+}
+
+// Close stop renewing the lock, the lock is released after the expiration if it is not unlocked.
+func (l *RedisReentrantLock) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.holds = 0
+	l.wd.Stop()
+	return nil
+}
+
+func (l *RedisReentrantLock) renew(ctx context.Context) (bool, error) {
+	n, err := reentrantRenewScript.Run(ctx, l.client, []string{l.key}, l.opts.owner, l.opts.expiry.Milliseconds()).Int64()
+	return n == 1, err
+}
+
+// the lock was lost before it was acquired again, e.g. expired during a long pause, reset the
+// count of holding so that the watchdog is started again, must be called with l.mu held.
+func (l *RedisReentrantLock) resetLost() {
+	if l.holds > 0 && l.wd.Lost() {
+		l.wd.Stop()
+		l.wd = nil
+		l.holds = 0
+	}
+}
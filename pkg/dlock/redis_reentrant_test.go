@@ -0,0 +1,119 @@
+package dlock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func newMiniRedis(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	s, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, redis.NewClient(&redis.Options{Addr: s.Addr()})
+}
+
+func TestRedisReentrantLock(t *testing.T) {
+	s, client := newMiniRedis(t)
+	defer s.Close()
+	ctx := context.Background()
+
+	l1, err := NewRedisReentrantLock(client, "reentrant_lock", WithDisableWatchdog())
+	assert.NoError(t, err)
+	owner := l1.(*RedisReentrantLock).Owner()
+	l2, _ := NewRedisReentrantLock(client, "reentrant_lock", WithOwner(owner))
+	other, _ := NewRedisReentrantLock(client, "reentrant_lock", WithRetryInterval(time.Millisecond*10))
+
+	token, err := l1.LockWithToken(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), token)
+
+	// reentrant by the same owner, the fencing token is not changed
+	token2, ok, err := l2.TryLockWithToken(ctx)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, token, token2)
+
+	ok, err = other.TryLock(ctx)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.ErrorIs(t, other.Unlock(ctx), ErrNotLocked)
+
+	assert.NoError(t, l2.Unlock(ctx))
+	ok, _ = other.TryLock(ctx)
+	assert.False(t, ok)
+
+	go func() {
+		time.Sleep(time.Millisecond * 50)
+		_ = l1.Unlock(ctx)
+	}()
+	token3, err := other.LockWithToken(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, token+1, token3)
+	assert.NoError(t, other.Unlock(ctx))
+	assert.False(t, s.Exists("{reentrant_lock}"))
+
+	// timeout
+	assert.NoError(t, l1.Lock(ctx))
+	ctx2, cancel := context.WithTimeout(ctx, time.Millisecond*100)
+	defer cancel()
+	err = other.Lock(ctx2)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.NoError(t, l1.Close())
+	assert.NoError(t, other.Close())
+
+	_, err = NewRedisReentrantLock(nil, "foo")
+	assert.Error(t, err)
+	_, err = NewRedisReentrantLock(client, "")
+	assert.Error(t, err)
+}
+
+func TestRedisReentrantLock_Watchdog(t *testing.T) {
+	s, client := newMiniRedis(t)
+	defer s.Close()
+	ctx := context.Background()
+
+	l, _ := NewRedisReentrantLock(client, "watchdog_lock", WithExpiry(time.Millisecond*600))
+	assert.NoError(t, l.Lock(ctx))
+
+	// the lock is renewed every 200ms, it is not expired even if the time passes over the expiration
+	for i := 0; i < 4; i++ {
+		time.Sleep(time.Millisecond * 250)
+		s.FastForward(time.Millisecond * 300)
+	}
+	assert.True(t, s.Exists("{watchdog_lock}"))
+
+	assert.NoError(t, l.Unlock(ctx))
+	assert.False(t, s.Exists("{watchdog_lock}"))
+
+	// the lock is lost while it is held, the count of holding is reset when it is acquired again
+	assert.NoError(t, l.Lock(ctx))
+	s.Del("{watchdog_lock}")
+	time.Sleep(time.Millisecond * 250)
+	rl := l.(*RedisReentrantLock)
+	rl.mu.Lock()
+	assert.True(t, rl.wd.Lost())
+	rl.mu.Unlock()
+	assert.NoError(t, l.Lock(ctx))
+	rl.mu.Lock()
+	assert.Equal(t, 1, rl.holds)
+	assert.False(t, rl.wd.Lost())
+	rl.mu.Unlock()
+	time.Sleep(time.Millisecond * 250)
+	s.FastForward(time.Millisecond * 500)
+	assert.True(t, s.Exists("{watchdog_lock}"))
+	assert.NoError(t, l.Unlock(ctx))
+	assert.False(t, s.Exists("{watchdog_lock}"))
+
+	// without watchdog
+	l, _ = NewRedisReentrantLock(client, "watchdog_lock", WithExpiry(time.Millisecond*600), WithDisableWatchdog())
+	assert.NoError(t, l.Lock(ctx))
+	s.FastForward(time.Millisecond * 700)
+	assert.False(t, s.Exists("{watchdog_lock}"))
+	assert.ErrorIs(t, l.Unlock(ctx), ErrNotLocked)
+}
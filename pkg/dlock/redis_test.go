@@ -7,6 +7,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/go-redsync/redsync/v4"
+	"github.com/stretchr/testify/assert"
+
 	"github.com/go-dev-frame/sponge/pkg/goredis"
 )
 
@@ -38,6 +41,75 @@ func TestClusterRedis_Lock(t *testing.T) {
 	testLockAndUnlock(initLocker, true, t)
 }
 
+func TestRedisLock_Watchdog(t *testing.T) {
+	s, client := newMiniRedis(t)
+	defer s.Close()
+	ctx := context.Background()
+
+	locker, err := NewRedisLock(client, "watchdog_redis_lock", redsync.WithExpiry(time.Millisecond*600), WithRedisWatchdog())
+	assert.NoError(t, err)
+	assert.NoError(t, locker.Lock(ctx))
+
+	// the lock is renewed about every 200ms
+	for i := 0; i < 4; i++ {
+		time.Sleep(time.Millisecond * 250)
+		s.FastForward(time.Millisecond * 300)
+	}
+	assert.True(t, s.Exists("watchdog_redis_lock"))
+
+	assert.NoError(t, locker.Unlock(ctx))
+	assert.False(t, s.Exists("watchdog_redis_lock"))
+
+	ok, err := locker.TryLock(ctx)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.NoError(t, locker.Close())
+	s.FastForward(time.Millisecond * 700)
+	assert.False(t, s.Exists("watchdog_redis_lock"))
+
+	// the watchdog stops when the lock is lost
+	assert.NoError(t, locker.Lock(ctx))
+	s.Del("watchdog_redis_lock")
+	select {
+	case <-locker.(LostNotifier).Lost():
+	case <-time.After(time.Second):
+		t.Fatal("the lost lock is not reported")
+	}
+	assert.NoError(t, locker.Close())
+}
+
+func TestRedisLock_WithoutWatchdog(t *testing.T) {
+	s, client := newMiniRedis(t)
+	defer s.Close()
+	ctx := context.Background()
+
+	locker, err := NewRedisLock(client, "no_watchdog_redis_lock", redsync.WithExpiry(time.Millisecond*300))
+	assert.NoError(t, err)
+	lost := locker.(LostNotifier)
+
+	// the lock is not renewed, it is lost after the expiration
+	assert.NoError(t, locker.Lock(ctx))
+	time.Sleep(time.Millisecond * 250)
+	s.FastForward(time.Millisecond * 350)
+	assert.False(t, s.Exists("no_watchdog_redis_lock"))
+	select {
+	case <-lost.Lost():
+	case <-time.After(time.Second):
+		t.Fatal("the expired lock is not reported")
+	}
+
+	// the channel is not closed when the lock is unlocked in time
+	ok, err := locker.TryLock(ctx)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.NoError(t, locker.Unlock(ctx))
+	select {
+	case <-lost.Lost():
+		t.Fatal("the unlocked lock is reported as lost")
+	case <-time.After(time.Millisecond * 400):
+	}
+}
+
 func getRedisLock() Locker {
 	redisCli, err := goredis.Init("default:123456@127.0.0.1:6379")
 	if err != nil {
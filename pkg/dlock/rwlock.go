@@ -0,0 +1,251 @@
+package dlock
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RWLocker is a read-write lock, the write lock is held by one holder, the read lock can be held
+// by multiple holders at the same time, the write lock and the read lock are exclusive.
+// Lock, TryLock and Unlock operate the write lock.
+type RWLocker interface {
+	Locker
+	// RLock blocks until the read lock is acquired or the context is canceled.
+	RLock(ctx context.Context) error
+	// TryRLock tries to acquire the read lock without blocking.
+	TryRLock(ctx context.Context) (bool, error)
+	// RUnlock releases the read lock, return ErrNotLocked if the read lock is not held.
+	RUnlock(ctx context.Context) error
+}
+
+// KEYS[1] write key, KEYS[2] readers key, KEYS[3] waiting writer key, ARGV[1] now in milliseconds,
+// ARGV[2] holder, ARGV[3] expiration in milliseconds, ARGV[4] 1 means waiting for the readers,
+// return 1 if the write lock is acquired.
+var rwLockScript = redis.NewScript(`
+local writer = redis.call('GET', KEYS[1])
+if writer then
+	if writer == ARGV[2] then
+		redis.call('PEXPIRE', KEYS[1], ARGV[3])
+		return 1
+	end
+	return 0
+end
+local waiting = redis.call('GET', KEYS[3])
+if waiting and waiting ~= ARGV[2] then
+	return 0
+end
+redis.call('ZREMRANGEBYSCORE', KEYS[2], '-inf', ARGV[1])
+if redis.call('ZCARD', KEYS[2]) > 0 then
+	if ARGV[4] == '1' then
+		redis.call('SET', KEYS[3], ARGV[2], 'PX', ARGV[3])
+	end
+	return 0
+end
+redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+if waiting then
+	redis.call('DEL', KEYS[3])
+end
+return 1
+`)
+
+// KEYS[1] write key, KEYS[2] readers key, KEYS[3] waiting writer key, ARGV[1] lease deadline in milliseconds,
+// ARGV[2] holder, ARGV[3] expiration in milliseconds, return 1 if the read lock is acquired.
+var rwRLockScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 or redis.call('EXISTS', KEYS[3]) == 1 then
+	if not redis.call('ZSCORE', KEYS[2], ARGV[2]) then
+		return 0
+	end
+end
+redis.call('ZADD', KEYS[2], ARGV[1], ARGV[2])
+redis.call('PEXPIRE', KEYS[2], ARGV[3])
+return 1
+`)
+
+// KEYS[1] write key, ARGV[1] holder
+var rwUnlockScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// KEYS[1] write key, KEYS[2] readers key, ARGV[1] lease deadline in milliseconds, ARGV[2] holder,
+// ARGV[3] expiration in milliseconds, ARGV[4] 1 means renewing the write lock, otherwise the read lock.
+var rwRenewScript = redis.NewScript(`
+if ARGV[4] == '1' then
+	if redis.call('GET', KEYS[1]) == ARGV[2] then
+		return redis.call('PEXPIRE', KEYS[1], ARGV[3])
+	end
+	return 0
+end
+if redis.call('ZSCORE', KEYS[2], ARGV[2]) then
+	redis.call('ZADD', KEYS[2], ARGV[1], ARGV[2])
+	redis.call('PEXPIRE', KEYS[2], ARGV[3])
+	return 1
+end
+return 0
+`)
+
+// RedisRWLock implements RWLocker using Redis, the write lock is a string key, the readers are stored in
+// a sorted set scored by the lease deadline. A writer waiting in Lock blocks the new readers to avoid
+// writer starvation. The locks are renewed by the watchdog while they are held.
+//
+// The keys are "{key}:write", "{key}:read" and "{key}:wait", they are in the same slot of redis cluster.
+type RedisRWLock struct {
+	client  redis.UniversalClient
+	keys    []string // write key, readers key, waiting writer key
+	opts    *options
+	mu      sync.Mutex
+	writeWd *watchdog
+	readWd  *watchdog
+}
+
+// NewRedisRWLock creates a new redis read-write lock, the owner token set by WithOwner is the holder id.
+func NewRedisRWLock(client redis.UniversalClient, key string, opts ...Option) (RWLocker, error) {
+	if client == nil {
+		return nil, errors.New("redis client is nil")
+	}
+	if key == "" {
+		return nil, errors.New("key is empty")
+	}
+
+	o := defaultOptions()
+	o.apply(opts...)
+	prefix := "{" + key + "}"
+
+	return &RedisRWLock{
+		client: client,
+		keys:   []string{prefix + ":write", prefix + ":read", prefix + ":wait"},
+		opts:   o,
+	}, nil
+}
+
+// TryLock tries to acquire the write lock without blocking.
+func (l *RedisRWLock) TryLock(ctx context.Context) (bool, error) {
+	return l.tryLock(ctx, false)
+}
+
+// Lock blocks until the write lock is acquired or the context is canceled,
+// the new readers are blocked while it is waiting.
+func (l *RedisRWLock) Lock(ctx context.Context) error {
+	_, err := lockWithRetry(ctx, l.opts.retryInterval, func(ctx context.Context) (uint64, bool, error) {
+		ok, err := l.tryLock(ctx, true)
+		return 0, ok, err
+	})
+	if err != nil {
+		// stop blocking the readers
+		_, _ = rwUnlockScript.Run(context.Background(), l.client, l.keys[2:], l.opts.owner).Result()
+	}
+	return err
+}
+
+// Unlock releases the write lock, return ErrNotLocked if the write lock is not held.
+func (l *RedisRWLock) Unlock(ctx context.Context) error {
+	l.mu.Lock()
+	l.writeWd.Stop()
+	l.mu.Unlock()
+
+	n, err := rwUnlockScript.Run(ctx, l.client, l.keys[:1], l.opts.owner).Int64()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotLocked
+	}
+	return nil
+}
+
+// TryRLock tries to acquire the read lock without blocking.
+func (l *RedisRWLock) TryRLock(ctx context.Context) (bool, error) {
+	n, err := rwRLockScript.Run(ctx, l.client, l.keys,
+		time.Now().Add(l.opts.expiry).UnixMilli(), l.opts.owner, l.opts.expiry.Milliseconds()).Int64()
+	if err != nil {
+		return false, err
+	}
+	if n != 1 {
+		return false, nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.opts.watchdog {
+		l.readWd.Stop()
+		l.readWd = startWatchdog(l.opts.expiry/3, func(ctx context.Context) (bool, error) {
+			return l.renew(ctx, false)
+		})
+	}
+	return true, nil
+}
+
+// RLock blocks until the read lock is acquired or the context is canceled.
+func (l *RedisRWLock) RLock(ctx context.Context) error {
+	_, err := lockWithRetry(ctx, l.opts.retryInterval, func(ctx context.Context) (uint64, bool, error) {
+		ok, err := l.TryRLock(ctx)
+		return 0, ok, err
+	})
+	return err
+}
+
+// RUnlock releases the read lock, return ErrNotLocked if the read lock is not held.
+func (l *RedisRWLock) RUnlock(ctx context.Context) error {
+	l.mu.Lock()
+	l.readWd.Stop()
+	l.mu.Unlock()
+
+	n, err := l.client.ZRem(ctx, l.keys[1], l.opts.owner).Result()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotLocked
+	}
+	return nil
+}
+
+// Close stop renewing the locks, the locks are released after the expiration if they are not unlocked.
+func (l *RedisRWLock) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.writeWd.Stop()
+	l.readWd.Stop()
+	return nil
+}
+
+func (l *RedisRWLock) tryLock(ctx context.Context, isWaiting bool) (bool, error) {
+	waiting := "0"
+	if isWaiting {
+		waiting = "1"
+	}
+	n, err := rwLockScript.Run(ctx, l.client, l.keys,
+		time.Now().UnixMilli(), l.opts.owner, l.opts.expiry.Milliseconds(), waiting).Int64()
+	if err != nil {
+		return false, err
+	}
+	if n != 1 {
+		return false, nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.opts.watchdog {
+		l.writeWd.Stop()
+		l.writeWd = startWatchdog(l.opts.expiry/3, func(ctx context.Context) (bool, error) {
+			return l.renew(ctx, true)
+		})
+	}
+	return true, nil
+}
+
+func (l *RedisRWLock) renew(ctx context.Context, isWrite bool) (bool, error) {
+	write := "0"
+	if isWrite {
+		write = "1"
+	}
+	n, err := rwRenewScript.Run(ctx, l.client, l.keys[:2],
+		time.Now().Add(l.opts.expiry).UnixMilli(), l.opts.owner, l.opts.expiry.Milliseconds(), write).Int64()
+	return n == 1, err
+}
@@ -0,0 +1,96 @@
+package dlock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedisRWLock(t *testing.T) {
+	s, client := newMiniRedis(t)
+	defer s.Close()
+	ctx := context.Background()
+
+	r1, err := NewRedisRWLock(client, "test_rwlock", WithRetryInterval(time.Millisecond*10))
+	assert.NoError(t, err)
+	r2, _ := NewRedisRWLock(client, "test_rwlock", WithRetryInterval(time.Millisecond*10))
+	w1, _ := NewRedisRWLock(client, "test_rwlock", WithRetryInterval(time.Millisecond*10))
+	w2, _ := NewRedisRWLock(client, "test_rwlock", WithRetryInterval(time.Millisecond*10))
+
+	// multiple readers
+	assert.NoError(t, r1.RLock(ctx))
+	ok, err := r2.TryRLock(ctx)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	ok, _ = w1.TryLock(ctx)
+	assert.False(t, ok)
+
+	// the waiting writer blocks the new readers
+	ctx2, cancel := context.WithTimeout(ctx, time.Millisecond*50)
+	defer cancel()
+	assert.ErrorIs(t, w1.Lock(ctx2), context.DeadlineExceeded)
+	assert.False(t, s.Exists("{test_rwlock}:wait"))
+	done := make(chan error)
+	go func() {
+		done <- w1.Lock(ctx)
+	}()
+	time.Sleep(time.Millisecond * 50)
+	assert.True(t, s.Exists("{test_rwlock}:wait"))
+	r3, _ := NewRedisRWLock(client, "test_rwlock")
+	ok, _ = r3.TryRLock(ctx)
+	assert.False(t, ok)
+	ok, _ = r1.TryRLock(ctx) // the reader holding the lock can renew it
+	assert.True(t, ok)
+
+	assert.NoError(t, r1.RUnlock(ctx))
+	assert.NoError(t, r2.RUnlock(ctx))
+	assert.ErrorIs(t, r2.RUnlock(ctx), ErrNotLocked)
+	assert.NoError(t, <-done)
+	assert.False(t, s.Exists("{test_rwlock}:wait"))
+
+	// the write lock is exclusive
+	ok, _ = w2.TryLock(ctx)
+	assert.False(t, ok)
+	ok, _ = r3.TryRLock(ctx)
+	assert.False(t, ok)
+	assert.ErrorIs(t, w2.Unlock(ctx), ErrNotLocked)
+	assert.NoError(t, w1.Unlock(ctx))
+	ok, _ = w2.TryLock(ctx)
+	assert.True(t, ok)
+	assert.NoError(t, w2.Unlock(ctx))
+
+	assert.NoError(t, r3.RLock(ctx))
+	assert.NoError(t, r3.RUnlock(ctx))
+	for _, l := range []RWLocker{r1, r2, r3, w1, w2} {
+		assert.NoError(t, l.Close())
+	}
+
+	_, err = NewRedisRWLock(nil, "foo")
+	assert.Error(t, err)
+	_, err = NewRedisRWLock(client, "")
+	assert.Error(t, err)
+}
+
+func TestRedisRWLock_Watchdog(t *testing.T) {
+	s, client := newMiniRedis(t)
+	defer s.Close()
+	ctx := context.Background()
+
+	w, _ := NewRedisRWLock(client, "test_rwlock", WithExpiry(time.Millisecond*600))
+	r, _ := NewRedisRWLock(client, "test_rwlock2", WithExpiry(time.Millisecond*600))
+	assert.NoError(t, w.Lock(ctx))
+	assert.NoError(t, r.RLock(ctx))
+
+	// the locks are renewed every 200ms
+	for i := 0; i < 4; i++ {
+		time.Sleep(time.Millisecond * 250)
+		s.FastForward(time.Millisecond * 300)
+	}
+	assert.True(t, s.Exists("{test_rwlock}:write"))
+	assert.True(t, s.Exists("{test_rwlock2}:read"))
+
+	assert.NoError(t, w.Unlock(ctx))
+	assert.NoError(t, r.RUnlock(ctx))
+}
@@ -0,0 +1,150 @@
+package dlock
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Semaphore is a counting semaphore, at most size holders can acquire it at the same time,
+// each Semaphore is a holder, acquiring it repeatedly before release is a no-op.
+type Semaphore interface {
+	// Acquire blocks until a permit is acquired or the context is canceled.
+	Acquire(ctx context.Context) error
+	// TryAcquire tries to acquire a permit without blocking.
+	TryAcquire(ctx context.Context) (bool, error)
+	// Release releases the permit, return ErrNotLocked if the permit is not held.
+	Release(ctx context.Context) error
+	Close() error
+}
+
+// KEYS[1] holders key, ARGV[1] now in milliseconds, ARGV[2] lease deadline in milliseconds, ARGV[3] holder,
+// ARGV[4] size, ARGV[5] expiration in milliseconds, return 1 if the permit is acquired.
+var semaphoreAcquireScript = redis.NewScript(`
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', ARGV[1])
+if redis.call('ZSCORE', KEYS[1], ARGV[3]) then
+	redis.call('ZADD', KEYS[1], ARGV[2], ARGV[3])
+	return 1
+end
+if redis.call('ZCARD', KEYS[1]) < tonumber(ARGV[4]) then
+	redis.call('ZADD', KEYS[1], ARGV[2], ARGV[3])
+	redis.call('PEXPIRE', KEYS[1], ARGV[5])
+	return 1
+end
+return 0
+`)
+
+// KEYS[1] holders key, ARGV[1] lease deadline in milliseconds, ARGV[2] holder, ARGV[3] expiration in milliseconds
+var semaphoreRenewScript = redis.NewScript(`
+if redis.call('ZSCORE', KEYS[1], ARGV[2]) then
+	redis.call('ZADD', KEYS[1], ARGV[1], ARGV[2])
+	redis.call('PEXPIRE', KEYS[1], ARGV[3])
+	return 1
+end
+return 0
+`)
+
+// RedisSemaphore implements Semaphore using Redis, the holders are stored in a sorted set scored by the lease
+// deadline, the lease is renewed by the watchdog while it is held, the holder whose lease is expired
+// (e.g. the process crashed) is removed when acquiring. The lease deadline is based on the local clock,
+// so the clock skew between the instances should be much smaller than the expiration.
+type RedisSemaphore struct {
+	client redis.UniversalClient
+	key    string
+	size   int
+	opts   *options
+
+	mu   sync.Mutex
+	held bool
+	wd   *watchdog
+}
+
+// NewRedisSemaphore creates a new redis semaphore with at most size holders,
+// the owner token set by WithOwner is the holder id.
+func NewRedisSemaphore(client redis.UniversalClient, key string, size int, opts ...Option) (Semaphore, error) {
+	if client == nil {
+		return nil, errors.New("redis client is nil")
+	}
+	if key == "" {
+		return nil, errors.New("key is empty")
+	}
+	if size <= 0 {
+		return nil, errors.New("size must be greater than 0")
+	}
+
+	o := defaultOptions()
+	o.apply(opts...)
+	return &RedisSemaphore{
+		client: client,
+		key:    key,
+		size:   size,
+		opts:   o,
+	}, nil
+}
+
+// TryAcquire tries to acquire a permit without blocking.
+func (s *RedisSemaphore) TryAcquire(ctx context.Context) (bool, error) {
+	now := time.Now()
+	n, err := semaphoreAcquireScript.Run(ctx, s.client, []string{s.key},
+		now.UnixMilli(), now.Add(s.opts.expiry).UnixMilli(), s.opts.owner, s.size, s.opts.expiry.Milliseconds()).Int64()
+	if err != nil {
+		return false, err
+	}
+	if n != 1 {
+		return false, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.held {
+		s.held = true
+		if s.opts.watchdog {
+			s.wd = startWatchdog(s.opts.expiry/3, s.renew)
+		}
+	}
+	return true, nil
+}
+
+// Acquire blocks until a permit is acquired or the context is canceled.
+func (s *RedisSemaphore) Acquire(ctx context.Context) error {
+	_, err := lockWithRetry(ctx, s.opts.retryInterval, func(ctx context.Context) (uint64, bool, error) {
+		ok, err := s.TryAcquire(ctx)
+		return 0, ok, err
+	})
+	return err
+}
+
+// Release releases the permit, return ErrNotLocked if the permit is not held.
+func (s *RedisSemaphore) Release(ctx context.Context) error {
+	s.mu.Lock()
+	s.held = false
+	s.wd.Stop()
+	s.mu.Unlock()
+
+	n, err := s.client.ZRem(ctx, s.key, s.opts.owner).Result()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotLocked
+	}
+	return nil
+}
+
+// Close stop renewing the lease, the permit is released after the expiration if it is not released.
+func (s *RedisSemaphore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.held = false
+	s.wd.Stop()
+	return nil
+}
+
+func (s *RedisSemaphore) renew(ctx context.Context) (bool, error) {
+	n, err := semaphoreRenewScript.Run(ctx, s.client, []string{s.key},
+		time.Now().Add(s.opts.expiry).UnixMilli(), s.opts.owner, s.opts.expiry.Milliseconds()).Int64()
+	return n == 1, err
+}
@@ -0,0 +1,200 @@
+package dlock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/client/v3/concurrency"
+)
+
+// EtcdSemaphore implements Semaphore using etcd, each holder puts a key with the session lease under the prefix,
+// the first size keys ordered by the create revision hold the permits, the others wait in order.
+// The key is deleted when the session is closed or the lease is expired, the permit is lost at the same time.
+type EtcdSemaphore struct {
+	session *concurrency.Session
+	prefix  string
+	size    int
+	myKey   string
+
+	mu   sync.Mutex
+	held bool
+}
+
+// NewEtcdSemaphore creates a new etcd semaphore with at most size holders, ttl is the session ttl in seconds.
+func NewEtcdSemaphore(client *clientv3.Client, key string, size int, ttl int) (Semaphore, error) {
+	if client == nil {
+		return nil, errors.New("etcd client is nil")
+	}
+	if key == "" {
+		return nil, errors.New("key is empty")
+	}
+	if size <= 0 {
+		return nil, errors.New("size must be greater than 0")
+	}
+	if ttl <= 0 {
+		ttl = defaultTTL
+	}
+
+	session, err := concurrency.NewSession(client, concurrency.WithTTL(ttl))
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := strings.TrimSuffix(key, "/") + "/"
+	s := &EtcdSemaphore{
+		session: session,
+		prefix:  prefix,
+		size:    size,
+		myKey:   fmt.Sprintf("%s%x", prefix, session.Lease()),
+	}
+	go s.watchSession()
+	return s, nil
+}
+
+// the key is deleted by etcd when the lease is expired or revoked, the permit is no longer held
+func (s *EtcdSemaphore) watchSession() {
+	<-s.session.Done()
+	s.mu.Lock()
+	s.held = false
+	s.mu.Unlock()
+}
+
+// TryAcquire tries to acquire a permit without blocking.
+func (s *EtcdSemaphore) TryAcquire(ctx context.Context) (bool, error) {
+	ok, _, err := s.tryAcquire(ctx)
+	if err != nil {
+		return false, err
+	}
+	if !ok {
+		_, err = s.session.Client().Delete(ctx, s.myKey)
+		return false, err
+	}
+	return true, nil
+}
+
+// Acquire blocks until a permit is acquired or the context is canceled.
+func (s *EtcdSemaphore) Acquire(ctx context.Context) error {
+	for {
+		ok, rev, err := s.tryAcquire(ctx)
+		if err == nil && ok {
+			return nil
+		}
+		if err == nil {
+			err = s.waitRelease(ctx, rev)
+		}
+		if err != nil {
+			// give up the place in the queue
+			_, _ = s.session.Client().Delete(context.Background(), s.myKey)
+			return err
+		}
+	}
+}
+
+// Release releases the permit, return ErrNotLocked if the permit is not held.
+func (s *EtcdSemaphore) Release(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.held {
+		return ErrNotLocked
+	}
+	_, err := s.session.Client().Delete(ctx, s.myKey)
+	if err != nil {
+		return err
+	}
+	s.held = false
+	return nil
+}
+
+// Close releases the permit and the etcd session.
+func (s *EtcdSemaphore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.held = false
+	return s.session.Close()
+}
+
+// tryAcquire put the key of the holder if it does not exist, and check whether it is in the first size keys,
+// return the revision of the check.
+func (s *EtcdSemaphore) tryAcquire(ctx context.Context) (bool, int64, error) {
+	client := s.session.Client()
+	if s.isHeld() {
+		// the session may not have noticed that the lease is gone, make sure the key still exists
+		resp, err := client.Get(ctx, s.myKey, clientv3.WithKeysOnly())
+		if err != nil {
+			return false, 0, err
+		}
+		if len(resp.Kvs) > 0 {
+			return true, 0, nil
+		}
+		s.mu.Lock()
+		s.held = false
+		s.mu.Unlock()
+	}
+
+	cmp := clientv3.Compare(clientv3.CreateRevision(s.myKey), "=", 0)
+	put := clientv3.OpPut(s.myKey, "", clientv3.WithLease(s.session.Lease()))
+	get := clientv3.OpGet(s.myKey)
+	txnResp, err := client.Txn(ctx).If(cmp).Then(put, get).Else(get).Commit()
+	if err != nil {
+		return false, 0, err
+	}
+	myRev := txnResp.Header.Revision
+	if !txnResp.Succeeded {
+		kvs := txnResp.Responses[0].GetResponseRange().Kvs
+		if len(kvs) > 0 {
+			myRev = kvs[0].CreateRevision
+		}
+	}
+
+	resp, err := client.Get(ctx, s.prefix, clientv3.WithPrefix(), clientv3.WithKeysOnly(),
+		clientv3.WithSort(clientv3.SortByCreateRevision, clientv3.SortAscend), clientv3.WithLimit(int64(s.size)))
+	if err != nil {
+		return false, 0, err
+	}
+	for _, kv := range resp.Kvs {
+		if kv.CreateRevision == myRev {
+			s.mu.Lock()
+			s.held = true
+			s.mu.Unlock()
+			return true, resp.Header.Revision, nil
+		}
+	}
+	return false, resp.Header.Revision, nil
+}
+
+func (s *EtcdSemaphore) isHeld() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.held
+}
+
+// waitRelease wait for any key under the prefix to be deleted after the revision
+func (s *EtcdSemaphore) waitRelease(ctx context.Context, rev int64) error {
+	wctx, cancel := context.WithCancel(clientv3.WithRequireLeader(ctx))
+	defer cancel()
+
+	wch := s.session.Client().Watch(wctx, s.prefix, clientv3.WithPrefix(), clientv3.WithRev(rev+1), clientv3.WithFilterPut())
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.session.Done():
+			return errors.New("etcd session is closed")
+		case wr, ok := <-wch:
+			if !ok {
+				return ctx.Err()
+			}
+			if err := wr.Err(); err != nil {
+				return err
+			}
+			if len(wr.Events) > 0 {
+				return nil
+			}
+		}
+	}
+}
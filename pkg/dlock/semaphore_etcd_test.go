@@ -0,0 +1,142 @@
+package dlock
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/server/v3/embed"
+)
+
+func newEmbedEtcd(t *testing.T) (*embed.Etcd, *clientv3.Client) {
+	cfg := embed.NewConfig()
+	cfg.Dir = t.TempDir()
+	cfg.LogLevel = "error"
+	clientURL, _ := url.Parse("http://127.0.0.1:0")
+	peerURL, _ := url.Parse("http://127.0.0.1:0")
+	cfg.ListenClientUrls = []url.URL{*clientURL}
+	cfg.AdvertiseClientUrls = []url.URL{*clientURL}
+	cfg.ListenPeerUrls = []url.URL{*peerURL}
+	cfg.AdvertisePeerUrls = []url.URL{*peerURL}
+	cfg.InitialCluster = cfg.InitialClusterFromName(cfg.Name)
+
+	e, err := embed.StartEtcd(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-e.Server.ReadyNotify():
+	case <-time.After(time.Second * 10):
+		e.Close()
+		t.Fatal("embedded etcd start timeout")
+	}
+
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   []string{e.Clients[0].Addr().String()},
+		DialTimeout: time.Second * 3,
+	})
+	if err != nil {
+		e.Close()
+		t.Fatal(err)
+	}
+	return e, client
+}
+
+func TestEtcdSemaphore(t *testing.T) {
+	e, client := newEmbedEtcd(t)
+	defer e.Close()
+	defer client.Close()
+	ctx := context.Background()
+
+	s1, err := NewEtcdSemaphore(client, "sponge/semaphore", 2, 5)
+	assert.NoError(t, err)
+	s2, _ := NewEtcdSemaphore(client, "sponge/semaphore", 2, 5)
+	s3, _ := NewEtcdSemaphore(client, "sponge/semaphore", 2, 5)
+	defer s3.Close()
+
+	ok, err := s1.TryAcquire(ctx)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	ok, _ = s1.TryAcquire(ctx) // acquire repeatedly
+	assert.True(t, ok)
+	assert.NoError(t, s2.Acquire(ctx))
+	ok, _ = s3.TryAcquire(ctx)
+	assert.False(t, ok)
+
+	ctx2, cancel := context.WithTimeout(ctx, time.Millisecond*100)
+	defer cancel()
+	assert.ErrorIs(t, s3.Acquire(ctx2), context.DeadlineExceeded)
+
+	go func() {
+		time.Sleep(time.Millisecond * 100)
+		_ = s1.Release(ctx)
+	}()
+	assert.NoError(t, s3.Acquire(ctx))
+	assert.ErrorIs(t, s1.Release(ctx), ErrNotLocked)
+
+	// the permit is released when the session is closed
+	assert.NoError(t, s2.Close())
+	ok, _ = s1.TryAcquire(ctx)
+	assert.True(t, ok)
+	assert.NoError(t, s1.Close())
+	assert.NoError(t, s3.Release(ctx))
+
+	_, err = NewEtcdSemaphore(nil, "foo", 1, 5)
+	assert.Error(t, err)
+	_, err = NewEtcdSemaphore(client, "", 1, 5)
+	assert.Error(t, err)
+	_, err = NewEtcdSemaphore(client, "foo", 0, 5)
+	assert.Error(t, err)
+}
+
+func TestEtcdSemaphore_Concurrent(t *testing.T) {
+	e, client := newEmbedEtcd(t)
+	defer e.Close()
+	defer client.Close()
+
+	testSemaphoreConcurrent(t, 3, func() Semaphore {
+		sem, _ := NewEtcdSemaphore(client, "sponge/semaphore_concurrent", 3, 5)
+		return sem
+	})
+}
+
+func TestEtcdSemaphore_LeaseRevoked(t *testing.T) {
+	e, client := newEmbedEtcd(t)
+	defer e.Close()
+	defer client.Close()
+	ctx := context.Background()
+
+	s1, err := NewEtcdSemaphore(client, "sponge/semaphore_revoked", 1, 2)
+	assert.NoError(t, err)
+	defer s1.Close()
+	s2, _ := NewEtcdSemaphore(client, "sponge/semaphore_revoked", 1, 2)
+	defer s2.Close()
+
+	ok, err := s1.TryAcquire(ctx)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// the key of s1 is deleted with the lease, s1 no longer holds the permit
+	_, err = client.Revoke(ctx, s1.(*EtcdSemaphore).session.Lease())
+	assert.NoError(t, err)
+	ok, _ = s1.TryAcquire(ctx)
+	assert.False(t, ok)
+	ok, err = s2.TryAcquire(ctx)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// the session of s1 is done after the lease is revoked
+	assert.Eventually(t, func() bool {
+		return !s1.(*EtcdSemaphore).isHeld()
+	}, time.Second*5, time.Millisecond*50)
+	select {
+	case <-s1.(*EtcdSemaphore).session.Done():
+	case <-time.After(time.Second * 5):
+		t.Fatal("etcd session is not done after the lease is revoked")
+	}
+	assert.ErrorIs(t, s1.Release(ctx), ErrNotLocked)
+	assert.NoError(t, s2.Release(ctx))
+}
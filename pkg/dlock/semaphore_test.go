@@ -0,0 +1,119 @@
+package dlock
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testSemaphoreConcurrent at most size holders run at the same time
+func testSemaphoreConcurrent(t *testing.T, size int, newSemaphore func() Semaphore) {
+	var running, maxRunning int32
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+			defer cancel()
+
+			s := newSemaphore()
+			defer s.Close()
+			if err := s.Acquire(ctx); err != nil {
+				t.Error(err)
+				return
+			}
+			n := atomic.AddInt32(&running, 1)
+			for {
+				m := atomic.LoadInt32(&maxRunning)
+				if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond * 50)
+			atomic.AddInt32(&running, -1)
+			assert.NoError(t, s.Release(ctx))
+		}()
+	}
+	wg.Wait()
+	assert.LessOrEqual(t, maxRunning, int32(size))
+	assert.Greater(t, maxRunning, int32(0))
+}
+
+func TestRedisSemaphore(t *testing.T) {
+	s, client := newMiniRedis(t)
+	defer s.Close()
+	ctx := context.Background()
+
+	s1, err := NewRedisSemaphore(client, "test_semaphore", 2)
+	assert.NoError(t, err)
+	s2, _ := NewRedisSemaphore(client, "test_semaphore", 2)
+	s3, _ := NewRedisSemaphore(client, "test_semaphore", 2, WithRetryInterval(time.Millisecond*10))
+
+	ok, err := s1.TryAcquire(ctx)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	ok, _ = s1.TryAcquire(ctx) // acquire repeatedly
+	assert.True(t, ok)
+	assert.NoError(t, s2.Acquire(ctx))
+	ok, _ = s3.TryAcquire(ctx)
+	assert.False(t, ok)
+
+	ctx2, cancel := context.WithTimeout(ctx, time.Millisecond*50)
+	defer cancel()
+	assert.ErrorIs(t, s3.Acquire(ctx2), context.DeadlineExceeded)
+
+	go func() {
+		time.Sleep(time.Millisecond * 50)
+		_ = s1.Release(ctx)
+	}()
+	assert.NoError(t, s3.Acquire(ctx))
+	assert.ErrorIs(t, s1.Release(ctx), ErrNotLocked)
+	assert.NoError(t, s2.Release(ctx))
+	assert.NoError(t, s3.Release(ctx))
+	assert.NoError(t, s3.Close())
+
+	_, err = NewRedisSemaphore(nil, "foo", 1)
+	assert.Error(t, err)
+	_, err = NewRedisSemaphore(client, "", 1)
+	assert.Error(t, err)
+	_, err = NewRedisSemaphore(client, "foo", 0)
+	assert.Error(t, err)
+}
+
+func TestRedisSemaphore_Expire(t *testing.T) {
+	s, client := newMiniRedis(t)
+	defer s.Close()
+	ctx := context.Background()
+
+	// the holder crashed without release, its lease is expired
+	s1, _ := NewRedisSemaphore(client, "test_semaphore", 1, WithExpiry(time.Millisecond*100), WithDisableWatchdog())
+	s2, _ := NewRedisSemaphore(client, "test_semaphore", 1, WithExpiry(time.Millisecond*300))
+	ok, _ := s1.TryAcquire(ctx)
+	assert.True(t, ok)
+	ok, _ = s2.TryAcquire(ctx)
+	assert.False(t, ok)
+	time.Sleep(time.Millisecond * 150)
+	ok, _ = s2.TryAcquire(ctx)
+	assert.True(t, ok)
+
+	// renewed by the watchdog
+	time.Sleep(time.Millisecond * 500)
+	ok, _ = s1.TryAcquire(ctx)
+	assert.False(t, ok)
+	assert.NoError(t, s2.Release(ctx))
+}
+
+func TestRedisSemaphore_Concurrent(t *testing.T) {
+	s, client := newMiniRedis(t)
+	defer s.Close()
+
+	testSemaphoreConcurrent(t, 3, func() Semaphore {
+		sem, _ := NewRedisSemaphore(client, "test_semaphore_concurrent", 3, WithRetryInterval(time.Millisecond*5))
+		return sem
+	})
+}
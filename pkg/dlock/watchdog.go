@@ -0,0 +1,72 @@
+package dlock
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// watchdog renews the lock periodically while the holder is alive, it exits when it is stopped,
+// or the lock is no longer held by the owner.
+type watchdog struct {
+	stopCh chan struct{}
+	doneCh chan struct{}
+	lostCh chan struct{} // closed when the lock is no longer held
+	once   sync.Once
+}
+
+// renew returns false if the lock is no longer held, the error is retried at the next interval.
+func startWatchdog(interval time.Duration, renew func(ctx context.Context) (bool, error)) *watchdog {
+	w := &watchdog{
+		stopCh: make(chan struct{}),
+		doneCh: make(chan struct{}),
+		lostCh: make(chan struct{}),
+	}
+
+	go func() {
+		defer close(w.doneCh)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-w.stopCh:
+				return
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), interval)
+				ok, err := renew(ctx)
+				cancel()
+				if err == nil && !ok {
+					close(w.lostCh)
+					return
+				}
+			}
+		}
+	}()
+
+	return w
+}
+
+// Stop stop renewing and wait for the goroutine to exit
+func (w *watchdog) Stop() {
+	if w == nil {
+		return
+	}
+	w.once.Do(func() {
+		close(w.stopCh)
+	})
+	<-w.doneCh
+}
+
+// Lost report whether the watchdog has exited because the lock is no longer held
+func (w *watchdog) Lost() bool {
+	if w == nil {
+		return false
+	}
+	select {
+	case <-w.lostCh:
+		return true
+	default:
+		return false
+	}
+}
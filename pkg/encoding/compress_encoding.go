@@ -0,0 +1,164 @@
+package encoding
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// CompressType compression algorithm
+type CompressType byte
+
+const (
+	// CompressGzip gzip compression
+	CompressGzip CompressType = 1
+	// CompressSnappy snappy compression, fast with a moderate ratio
+	CompressSnappy CompressType = 2
+	// CompressZstd zstd compression, high ratio with a good speed
+	CompressZstd CompressType = 3
+)
+
+// DefaultCompressThreshold the data is compressed only if its size is not less than the threshold
+const DefaultCompressThreshold = 1024
+
+// compressMagic header of the compressed data, followed by one byte of CompressType,
+// the first byte 0x00 is not the beginning of json, protobuf or gob data.
+var compressMagic = []byte{0x00, 'S', 'C'}
+
+var (
+	zstdEncoder     *zstd.Encoder
+	zstdDecoder     *zstd.Decoder
+	zstdEncoderOnce sync.Once
+	zstdDecoderOnce sync.Once
+)
+
+// CompressOption set the compress encoding options.
+type CompressOption func(*compressOptions)
+
+type compressOptions struct {
+	compressType CompressType
+	threshold    int
+}
+
+func defaultCompressOptions() *compressOptions {
+	return &compressOptions{
+		compressType: CompressSnappy,
+		threshold:    DefaultCompressThreshold,
+	}
+}
+
+func (o *compressOptions) apply(opts ...CompressOption) {
+	for _, opt := range opts {
+		opt(o)
+	}
+}
+
+// WithCompressType set compression algorithm, default is snappy
+func WithCompressType(t CompressType) CompressOption {
+	return func(o *compressOptions) {
+		switch t {
+		case CompressGzip, CompressSnappy, CompressZstd:
+			o.compressType = t
+		}
+	}
+}
+
+// WithCompressThreshold set the minimum size of data to be compressed, default is 1024 bytes
+func WithCompressThreshold(size int) CompressOption {
+	return func(o *compressOptions) {
+		if size >= 0 {
+			o.threshold = size
+		}
+	}
+}
+
+// CompressEncoding wrap an Encoding, the encoded data which is not less than the threshold
+// is compressed and prefixed with a magic header, the data without the magic header is
+// decoded directly, so the values written before compression is enabled can still be decoded.
+type CompressEncoding struct {
+	encoding     Encoding
+	compressType CompressType
+	threshold    int
+}
+
+// NewCompressEncoding create a compress encoding, e.g. NewCompressEncoding(JSONEncoding{}, WithCompressType(CompressZstd))
+func NewCompressEncoding(e Encoding, opts ...CompressOption) *CompressEncoding {
+	o := defaultCompressOptions()
+	o.apply(opts...)
+	return &CompressEncoding{
+		encoding:     e,
+		compressType: o.compressType,
+		threshold:    o.threshold,
+	}
+}
+
+// Marshal encode and compress
+func (c *CompressEncoding) Marshal(v interface{}) ([]byte, error) {
+	data, err := Marshal(c.encoding, v)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < c.threshold {
+		return data, nil
+	}
+
+	compressed, err := compress(c.compressType, data)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 0, len(compressMagic)+1+len(compressed))
+	buf = append(buf, compressMagic...)
+	buf = append(buf, byte(c.compressType))
+	return append(buf, compressed...), nil
+}
+
+// Unmarshal decompress and decode, the data without magic header is decoded directly
+func (c *CompressEncoding) Unmarshal(data []byte, v interface{}) error {
+	if IsCompressed(data) {
+		t := CompressType(data[len(compressMagic)])
+		decompressed, err := decompress(t, data[len(compressMagic)+1:])
+		if err != nil {
+			return err
+		}
+		data = decompressed
+	}
+	return Unmarshal(c.encoding, data, v)
+}
+
+// IsCompressed whether the data is compressed by CompressEncoding
+func IsCompressed(data []byte) bool {
+	return len(data) > len(compressMagic) && bytes.HasPrefix(data, compressMagic)
+}
+
+func compress(t CompressType, data []byte) ([]byte, error) {
+	switch t {
+	case CompressGzip:
+		return GzipEncode(data)
+	case CompressSnappy:
+		return snappy.Encode(nil, data), nil
+	case CompressZstd:
+		zstdEncoderOnce.Do(func() {
+			zstdEncoder, _ = zstd.NewWriter(nil)
+		})
+		return zstdEncoder.EncodeAll(data, nil), nil
+	}
+	return nil, fmt.Errorf("unsupported compress type %d", t)
+}
+
+func decompress(t CompressType, data []byte) ([]byte, error) {
+	switch t {
+	case CompressGzip:
+		return GzipDecode(data)
+	case CompressSnappy:
+		return snappy.Decode(nil, data)
+	case CompressZstd:
+		zstdDecoderOnce.Do(func() {
+			zstdDecoder, _ = zstd.NewReader(nil)
+		})
+		return zstdDecoder.DecodeAll(data, nil)
+	}
+	return nil, fmt.Errorf("unsupported compress type %d", t)
+}
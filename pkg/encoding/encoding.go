@@ -68,11 +68,22 @@ type Encoding interface {
 	Unmarshal(data []byte, v interface{}) error
 }
 
+// Wrapper is implemented by the value that wraps another value with metadata, e.g. the entry of cache
+// with expiration time, it encodes the metadata by itself and the wrapped value by the encoding, so that
+// the encodings of the specific types can be used, e.g. the protobuf codec encodes only the proto message.
+type Wrapper interface {
+	MarshalWith(e Encoding) ([]byte, error)
+	UnmarshalWith(e Encoding, data []byte) error
+}
+
 // Marshal encode data
 func Marshal(e Encoding, v interface{}) (data []byte, err error) {
 	if !isPointer(v) {
 		return data, ErrNotAPointer
 	}
+	if w, ok := v.(Wrapper); ok && e != nil {
+		return w.MarshalWith(e)
+	}
 	bm, ok := v.(encoding.BinaryMarshaler)
 	if ok && e == nil {
 		return bm.MarshalBinary()
@@ -94,6 +105,9 @@ func Unmarshal(e Encoding, data []byte, v interface{}) (err error) {
 	if !isPointer(v) {
 		return ErrNotAPointer
 	}
+	if w, ok := v.(Wrapper); ok && e != nil {
+		return w.UnmarshalWith(e, data)
+	}
 	bm, ok := v.(encoding.BinaryUnmarshaler)
 	if ok && e == nil {
 		err = bm.UnmarshalBinary(data)
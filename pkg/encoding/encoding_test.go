@@ -2,6 +2,7 @@ package encoding
 
 import (
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -49,6 +50,44 @@ func TestEncoding(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestCompressEncoding(t *testing.T) {
+	for _, ct := range []CompressType{CompressGzip, CompressSnappy, CompressZstd} {
+		e := NewCompressEncoding(JSONEncoding{}, WithCompressType(ct), WithCompressThreshold(0))
+		err := xEncoding(e)
+		assert.NoError(t, err)
+
+		data, err := e.Marshal(&obj{ID: 1, Name: "foo"})
+		assert.NoError(t, err)
+		assert.True(t, IsCompressed(data))
+		assert.Equal(t, byte(ct), data[len(compressMagic)])
+	}
+
+	// the data smaller than the threshold is not compressed
+	e := NewCompressEncoding(JSONEncoding{})
+	data, err := e.Marshal(&obj{ID: 1, Name: "foo"})
+	assert.NoError(t, err)
+	assert.False(t, IsCompressed(data))
+	data, err = e.Marshal(&obj{ID: 1, Name: strings.Repeat("foo", 1000)})
+	assert.NoError(t, err)
+	assert.True(t, IsCompressed(data))
+	o := &obj{}
+	err = e.Unmarshal(data, o)
+	assert.NoError(t, err)
+	assert.Equal(t, strings.Repeat("foo", 1000), o.Name)
+
+	// the old uncompressed data can still be decoded
+	o = &obj{}
+	err = e.Unmarshal([]byte(`{"id":2,"name":"bar"}`), o)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), o.ID)
+
+	// unsupported compress type
+	err = e.Unmarshal(append(append([]byte{}, compressMagic...), 9, 1), o)
+	assert.Error(t, err)
+	_, err = compress(9, nil)
+	assert.Error(t, err)
+}
+
 func TestEncodingError(t *testing.T) {
 	gobE := GobEncoding{}
 	// gob error test
@@ -159,6 +198,39 @@ func TestUnmarshall(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+type wrapper struct {
+	Value *obj
+}
+
+func (w *wrapper) MarshalWith(e Encoding) ([]byte, error) {
+	data, err := e.Marshal(w.Value)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte("w:"), data...), nil
+}
+
+func (w *wrapper) UnmarshalWith(e Encoding, data []byte) error {
+	if !strings.HasPrefix(string(data), "w:") {
+		return errors.New("invalid data")
+	}
+	return e.Unmarshal(data[2:], w.Value)
+}
+
+func TestWrapper(t *testing.T) {
+	data, err := Marshal(JSONEncoding{}, &wrapper{Value: &obj{ID: 1, Name: "foo"}})
+	assert.NoError(t, err)
+	assert.Equal(t, `w:{"id":1,"name":"foo"}`, string(data))
+
+	w := &wrapper{Value: &obj{}}
+	err = Unmarshal(JSONEncoding{}, data, w)
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", w.Value.Name)
+
+	err = Unmarshal(JSONEncoding{}, []byte(`{"id":1}`), w)
+	assert.Error(t, err)
+}
+
 func BenchmarkJsonMarshal(b *testing.B) {
 	a := make([]int, 0, 400)
 	for i := 0; i < 400; i++ {
@@ -3,6 +3,7 @@ package proto
 
 import (
 	"fmt"
+	"reflect"
 
 	"google.golang.org/protobuf/proto"
 
@@ -20,7 +21,7 @@ func init() {
 type codec struct{}
 
 func (codec) Marshal(v interface{}) ([]byte, error) {
-	vv, ok := v.(proto.Message)
+	vv, ok := toMessage(v, false)
 	if !ok {
 		return nil, fmt.Errorf("failed to marshal, message is %T, want proto.Message", v)
 	}
@@ -28,13 +29,35 @@ func (codec) Marshal(v interface{}) ([]byte, error) {
 }
 
 func (codec) Unmarshal(data []byte, v interface{}) error {
-	vv, ok := v.(proto.Message)
+	vv, ok := toMessage(v, true)
 	if !ok {
 		return fmt.Errorf("failed to unmarshal, message is %T, want proto.Message", v)
 	}
 	return proto.Unmarshal(data, vv)
 }
 
+// toMessage v is a message or a pointer to message, e.g. **pb.User passed to the cache,
+// if alloc is true and the message is nil, a new message is created and assigned to v.
+func toMessage(v interface{}, alloc bool) (proto.Message, bool) {
+	if vv, ok := v.(proto.Message); ok {
+		return vv, true
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Ptr {
+		return nil, false
+	}
+	elem := rv.Elem()
+	if elem.IsNil() {
+		if !alloc {
+			return nil, false
+		}
+		elem.Set(reflect.New(elem.Type().Elem()))
+	}
+	vv, ok := elem.Interface().(proto.Message)
+	return vv, ok
+}
+
 func (codec) Name() string {
 	return Name
 }
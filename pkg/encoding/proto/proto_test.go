@@ -28,6 +28,17 @@ func TestProto(t *testing.T) {
 	o2 := new(pluginpb.CodeGeneratorRequest)
 	err = c.Unmarshal(b, o2)
 	assert.NoError(t, err)
+
+	// pointer to message
+	b, err = c.Marshal(&o1)
+	assert.NoError(t, err)
+	var o3 *pluginpb.CodeGeneratorResponse
+	err = c.Unmarshal(b, &o3)
+	assert.NoError(t, err)
+	assert.NotNil(t, o3)
+	var o4 *pluginpb.CodeGeneratorResponse
+	_, err = c.Marshal(&o4)
+	assert.Error(t, err)
 }
 
 func TestProtoError(t *testing.T) {
@@ -85,10 +85,20 @@ func (s *RPCStatus) ErrToHTTP(desc ...string) error {
 	return status.Errorf(s.status.Code(), "%s%s", message, ToHTTPCodeLabel)
 }
 
+// RewriteMsg rewrite status message, the status code is unchanged
+func (s *RPCStatus) RewriteMsg(msg string) *RPCStatus {
+	return &RPCStatus{status: status.New(s.status.Code(), msg)}
+}
+
 // ToRPCErr converted to standard RPC error,
 // use it if you need to convert to standard RPC errors,
-// if there is a parameter 'desc', it will replace the original message.
+// if there is a parameter 'desc', it will replace the original message,
+// the message rewritten by RewriteMsg is kept if there is no parameter 'desc'.
 func (s *RPCStatus) ToRPCErr(desc ...string) error {
+	if len(desc) == 0 && s.status.Message() != statusCodes[s.status.Code()] {
+		desc = []string{s.status.Message()}
+	}
+
 	switch s.status.Code() {
 	case StatusInvalidParams.status.Code():
 		return toRPCErr(codes.InvalidArgument, desc...)
@@ -8,6 +8,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
 	"github.com/go-dev-frame/sponge/pkg/utils"
@@ -64,6 +65,21 @@ func TestRPCStatus(t *testing.T) {
 	NewRPCStatus(401101, "something is wrong 2")
 }
 
+func TestRPCStatus_RewriteMsg(t *testing.T) {
+	st := StatusAborted.RewriteMsg("record has been modified")
+	assert.Equal(t, StatusAborted.Code(), st.Code())
+	assert.Equal(t, "record has been modified", st.Msg())
+	assert.Equal(t, "Aborted", StatusAborted.Msg())
+
+	s, _ := status.FromError(st.ToRPCErr())
+	assert.Equal(t, codes.Aborted, s.Code())
+	assert.Equal(t, "record has been modified", s.Message())
+
+	s, _ = status.FromError(StatusAborted.ToRPCErr())
+	assert.Equal(t, codes.Aborted, s.Code())
+	assert.Equal(t, codes.Aborted.String(), s.Message())
+}
+
 func TestToRPCCode(t *testing.T) {
 	var codes []string
 	for _, s := range rpcStatus {
@@ -0,0 +1,148 @@
+// Package cronadmin provides gin handlers to manage the scheduled tasks of gocron,
+// list tasks, view the latest runs, trigger, pause and resume tasks.
+package cronadmin
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/go-dev-frame/sponge/pkg/errcode"
+	"github.com/go-dev-frame/sponge/pkg/gin/response"
+	"github.com/go-dev-frame/sponge/pkg/gocron"
+)
+
+var (
+	defaultPrefix = "/cron"
+
+	defaultRunsLimit = 20
+	maxRunsLimit     = 100
+)
+
+// Option set options
+type Option func(o *options)
+
+type options struct {
+	prefix      string
+	middlewares []gin.HandlerFunc
+}
+
+func (o *options) apply(opts ...Option) {
+	for _, opt := range opts {
+		opt(o)
+	}
+}
+
+// WithPrefix set route prefix, default is /cron
+func WithPrefix(prefix string) Option {
+	return func(o *options) {
+		if prefix == "" {
+			return
+		}
+		o.prefix = prefix
+	}
+}
+
+// WithMiddlewares set the middlewares of the routes, e.g. authentication
+func WithMiddlewares(middlewares ...gin.HandlerFunc) Option {
+	return func(o *options) {
+		o.middlewares = append(o.middlewares, middlewares...)
+	}
+}
+
+// Register the scheduled tasks management routes for gin router, the tasks are managed on the
+// instance which receives the request.
+//
+//	GET  /cron/tasks                list tasks
+//	GET  /cron/tasks/:name          get task
+//	GET  /cron/tasks/:name/runs     list the latest runs of the task, query parameter limit, default 20, max 100
+//	POST /cron/tasks/:name/trigger  trigger the task now
+//	POST /cron/tasks/:name/pause    pause the task
+//	POST /cron/tasks/:name/resume   resume the task
+func Register(r gin.IRouter, opts ...Option) {
+	o := &options{prefix: defaultPrefix}
+	o.apply(opts...)
+
+	group := r.Group(o.prefix, o.middlewares...)
+
+	group.GET("/tasks", ListTasks)
+	group.GET("/tasks/:name", GetTask)
+	group.GET("/tasks/:name/runs", ListTaskRuns)
+	group.POST("/tasks/:name/trigger", TriggerTask)
+	group.POST("/tasks/:name/pause", PauseTask)
+	group.POST("/tasks/:name/resume", ResumeTask)
+}
+
+// ListTasks list tasks
+func ListTasks(c *gin.Context) {
+	response.Success(c, gin.H{"tasks": gocron.GetTasks(), "isLeader": gocron.IsLeader()})
+}
+
+// GetTask get task
+func GetTask(c *gin.Context) {
+	info, err := gocron.GetTask(c.Param("name"))
+	if err != nil {
+		responseError(c, err)
+		return
+	}
+	response.Success(c, info)
+}
+
+// ListTaskRuns list the latest runs of the task, the limit is capped at 100
+func ListTaskRuns(c *gin.Context) {
+	limit := defaultRunsLimit
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			response.Error(c, errcode.InvalidParams)
+			return
+		}
+		limit = n
+	}
+	if limit > maxRunsLimit {
+		limit = maxRunsLimit
+	}
+
+	records, err := gocron.GetTaskRuns(c.Request.Context(), c.Param("name"), limit)
+	if err != nil {
+		responseError(c, err)
+		return
+	}
+	response.Success(c, gin.H{"runs": records})
+}
+
+// TriggerTask trigger the task now
+func TriggerTask(c *gin.Context) {
+	if err := gocron.TriggerTask(c.Param("name")); err != nil {
+		responseError(c, err)
+		return
+	}
+	response.Success(c)
+}
+
+// PauseTask pause the task
+func PauseTask(c *gin.Context) {
+	if err := gocron.PauseTask(c.Param("name")); err != nil {
+		responseError(c, err)
+		return
+	}
+	response.Success(c)
+}
+
+// ResumeTask resume the task
+func ResumeTask(c *gin.Context) {
+	if err := gocron.ResumeTask(c.Param("name")); err != nil {
+		responseError(c, err)
+		return
+	}
+	response.Success(c)
+}
+
+func responseError(c *gin.Context, err error) {
+	if errors.Is(err, gocron.ErrTaskNotFound) {
+		response.Error(c, errcode.NotFound)
+		return
+	}
+	response.Error(c, errcode.InternalServerError.RewriteMsg(err.Error()))
+}
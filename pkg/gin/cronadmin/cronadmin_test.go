@@ -0,0 +1,87 @@
+package cronadmin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/go-dev-frame/sponge/pkg/errcode"
+	"github.com/go-dev-frame/sponge/pkg/gocron"
+)
+
+type result struct {
+	Code int             `json:"code"`
+	Msg  string          `json:"msg"`
+	Data json.RawMessage `json:"data"`
+}
+
+func doRequest(t *testing.T, r http.Handler, method string, path string) *result {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	res := &result{}
+	if err := json.Unmarshal(w.Body.Bytes(), res); err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func TestRegister(t *testing.T) {
+	err := gocron.Init(gocron.WithLog(zap.NewNop()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer gocron.Stop()
+	err = gocron.Run(&gocron.Task{
+		Name:     "task1",
+		TimeSpec: "@every 1h",
+		Fn:       func() {},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	var isAuth bool
+	Register(r, WithPrefix(""), WithPrefix("/admin/cron"), WithMiddlewares(func(c *gin.Context) {
+		isAuth = true
+	}))
+
+	res := doRequest(t, r, http.MethodGet, "/admin/cron/tasks")
+	assert.Equal(t, 0, res.Code)
+	assert.Contains(t, string(res.Data), "task1")
+	assert.True(t, isAuth)
+
+	res = doRequest(t, r, http.MethodPost, "/admin/cron/tasks/task1/pause")
+	assert.Equal(t, 0, res.Code)
+	res = doRequest(t, r, http.MethodGet, "/admin/cron/tasks/task1")
+	assert.Contains(t, string(res.Data), `"isPaused":true`)
+	res = doRequest(t, r, http.MethodPost, "/admin/cron/tasks/task1/resume")
+	assert.Equal(t, 0, res.Code)
+
+	res = doRequest(t, r, http.MethodPost, "/admin/cron/tasks/task1/trigger")
+	assert.Equal(t, 0, res.Code)
+	assert.Eventually(t, func() bool {
+		res = doRequest(t, r, http.MethodGet, "/admin/cron/tasks/task1/runs?limit=5")
+		return res.Code == 0 && len(res.Data) > len(`{"runs":[]}`)
+	}, time.Second, time.Millisecond*10)
+	assert.Contains(t, string(res.Data), `"trigger":"manual"`)
+
+	res = doRequest(t, r, http.MethodGet, "/admin/cron/tasks/task1/runs?limit=100000")
+	assert.Equal(t, 0, res.Code)
+	res = doRequest(t, r, http.MethodGet, "/admin/cron/tasks/task1/runs?limit=abc")
+	assert.Equal(t, errcode.InvalidParams.Code(), res.Code)
+	res = doRequest(t, r, http.MethodPost, "/admin/cron/tasks/notFound/trigger")
+	assert.Equal(t, errcode.NotFound.Code(), res.Code)
+	res = doRequest(t, r, http.MethodGet, "/admin/cron/tasks/notFound")
+	assert.Equal(t, errcode.NotFound.Code(), res.Code)
+}
@@ -8,6 +8,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/go-dev-frame/sponge/pkg/krand"
+	"github.com/go-dev-frame/sponge/pkg/msgctx"
 )
 
 var (
@@ -46,6 +47,7 @@ func (o *requestIDOptions) setRequestIDKey() {
 	if o.headerXRequestIDKey != HeaderXRequestIDKey {
 		HeaderXRequestIDKey = o.headerXRequestIDKey
 	}
+	msgctx.SetRequestIDKey(ContextRequestIDKey, HeaderXRequestIDKey)
 }
 
 // WithContextRequestIDKey set context request id key, minimum length of 4
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/go-dev-frame/sponge/pkg/msgctx"
 	"github.com/go-dev-frame/sponge/pkg/utils"
 
 	"github.com/gin-gonic/gin"
@@ -108,4 +109,7 @@ func TestRequestIDKeyOptions(t *testing.T) {
 
 	assert.Equal(t, "my_req_id", ContextRequestIDKey)
 	assert.Equal(t, "My-X-Req-Id", HeaderXRequestIDKey)
+	// the keys of the message headers are the same
+	assert.Equal(t, "my_req_id", msgctx.ContextRequestIDKey)
+	assert.Equal(t, "My-X-Req-Id", msgctx.HeaderRequestIDKey)
 }
@@ -2,12 +2,16 @@
 package gocron
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/robfig/cron/v3"
+
+	"github.com/go-dev-frame/sponge/pkg/dlock"
 )
 
 var (
@@ -16,6 +20,18 @@ var (
 	nameID = sync.Map{}
 	// id and task name mapping, used in log printing
 	idName = sync.Map{}
+	// task name and state mapping
+	nameTask = sync.Map{}
+
+	cronLog        *zapLog
+	historyStore   HistoryStore
+	elector        dlock.Elector
+	stopCampaign   context.CancelFunc
+	campaignDoneCh chan struct{}
+
+	lockTimeout = 3 * time.Second
+	// max time that the lock of the scheduled execution is held after the tick
+	maxLockHoldTime = time.Minute
 )
 
 // Task scheduled task
@@ -28,6 +44,27 @@ type Task struct {
 	Name      string // task name
 	Fn        func() // task function
 	IsRunOnce bool   // if the task is only run once
+
+	// task function with context, it takes precedence over Fn, the returned error triggers the retries,
+	// the context is canceled after the timeout.
+	FnWithContext func(ctx context.Context) error
+	// timeout of each attempt, the attempt fails when it is exceeded, default 0 means no timeout,
+	// it requires FnWithContext, which should exit when the context is canceled, otherwise the
+	// timed out attempt may overlap with the retries.
+	Timeout time.Duration
+	// max number of retries after the attempt fails (error, panic or timeout), default 0 means no retry
+	MaxRetries int
+	// wait time before the first retry, it is doubled for each retry up to 1 minute, default 1s
+	RetryBackoff time.Duration
+
+	// if the task is only run on the elected leader, requires the WithElector option of Init
+	IsLeaderOnly bool
+	// run the task under the lock, the execution is skipped if the lock is held by others (e.g. the task
+	// is running on another instance). The lock of the scheduled execution is held until half of the interval
+	// (at most 1 minute) has passed since the tick, even if the execution is finished earlier, so that the
+	// replicas with slightly skewed clocks skip the same tick, the lock of the manual trigger is released
+	// after the execution.
+	Locker dlock.Locker
 }
 
 // Init initialize and start timed tasks
@@ -35,7 +72,11 @@ func Init(opts ...Option) error {
 	o := defaultOptions()
 	o.apply(opts...)
 
+	stopElection()
+
 	log := &zapLog{zapLog: o.zapLog, isOnlyPrintError: o.isOnlyPrintError}
+	cronLog = log
+	historyStore = o.historyStore
 	cronOpts := []cron.Option{
 		cron.WithLogger(log),
 		cron.WithChain(
@@ -49,9 +90,41 @@ func Init(opts ...Option) error {
 	c = cron.New(cronOpts...)
 	c.Start()
 
+	elector = o.elector
+	if elector != nil {
+		startElection(elector, log)
+	}
+
 	return nil
 }
 
+// startElection campaign for the leadership in the background, and log the leadership changes
+func startElection(e dlock.Elector, log *zapLog) {
+	ctx, cancel := context.WithCancel(context.Background())
+	stopCampaign = cancel
+	campaignDoneCh = make(chan struct{})
+	go func(done chan struct{}) {
+		defer close(done)
+		e.Campaign(ctx, func(isLeader bool) {
+			log.Info("leader changed", "isLeader", isLeader, "leaderOnlyTasks", getLeaderOnlyTasks())
+		})
+	}(campaignDoneCh)
+}
+
+// stopElection stop campaigning and resign the leadership
+func stopElection() {
+	if stopCampaign != nil {
+		stopCampaign()
+		<-campaignDoneCh
+		stopCampaign = nil
+	}
+}
+
+// IsLeader returns whether the instance is the elected leader, it is false if the elector is not set.
+func IsLeader() bool {
+	return elector != nil && elector.IsLeader()
+}
+
 // Run the tasks
 func Run(tasks ...*Task) error {
 	if c == nil {
@@ -65,18 +138,25 @@ func Run(tasks ...*Task) error {
 			continue
 		}
 
-		if err := checkRunOnce(task); err != nil {
+		if err := checkTask(task); err != nil {
 			errs = append(errs, err.Error())
 			continue
 		}
+		if task.IsLeaderOnly && elector == nil {
+			errs = append(errs, fmt.Sprintf("task '%s' is leader only, but the elector is not set", task.Name))
+			continue
+		}
 
-		id, err := c.AddFunc(task.TimeSpec, task.Fn)
+		state := &taskState{task: task}
+		id, err := c.AddFunc(task.TimeSpec, state.runScheduled)
 		if err != nil {
 			errs = append(errs, fmt.Sprintf("run task '%s' error: %v", task.Name, err))
 			continue
 		}
+		state.id = id
 		idName.Store(id, task.Name)
 		nameID.Store(task.Name, id)
+		nameTask.Store(task.Name, state)
 	}
 
 	if len(errs) > 0 {
@@ -86,16 +166,15 @@ func Run(tasks ...*Task) error {
 	return nil
 }
 
-func checkRunOnce(task *Task) error {
-	if task.Fn == nil {
+func checkTask(task *Task) error {
+	if task.Fn == nil && task.FnWithContext == nil {
 		return fmt.Errorf("task '%s' is nil", task.Name)
 	}
-	if task.IsRunOnce {
-		job := task.Fn
-		task.Fn = func() {
-			job()
-			DeleteTask(task.Name)
-		}
+	if task.Timeout > 0 && task.FnWithContext == nil {
+		return fmt.Errorf("task '%s' timeout requires FnWithContext, Fn can not be canceled", task.Name)
+	}
+	if task.MaxRetries < 0 {
+		return fmt.Errorf("task '%s' max retries must be greater than or equal to 0", task.Name)
 	}
 	return nil
 }
@@ -106,16 +185,32 @@ func IsRunningTask(name string) bool {
 	return ok
 }
 
-// GetRunningTasks gets a list of running task names
+// GetRunningTasks gets a list of running task names, the leader only tasks are
+// included only when the instance is the leader.
 func GetRunningTasks() []string {
+	isLeader := IsLeader()
 	var names []string
 	nameID.Range(func(key, value interface{}) bool {
+		if v, ok := nameTask.Load(key); ok && v.(*taskState).task.IsLeaderOnly && !isLeader {
+			return true
+		}
 		names = append(names, key.(string))
 		return true
 	})
 	return names
 }
 
+func getLeaderOnlyTasks() []string {
+	var names []string
+	nameTask.Range(func(key, value interface{}) bool {
+		if value.(*taskState).task.IsLeaderOnly {
+			names = append(names, key.(string))
+		}
+		return true
+	})
+	return names
+}
+
 // DeleteTask stop and delete the specified task
 func DeleteTask(name string) {
 	if id, ok := nameID.Load(name); ok {
@@ -126,6 +221,7 @@ func DeleteTask(name string) {
 		c.Remove(entryID)
 		nameID.Delete(name)
 		idName.Delete(entryID)
+		nameTask.Delete(name)
 	}
 }
 
@@ -134,6 +230,7 @@ func Stop() {
 	if c != nil {
 		c.Stop()
 	}
+	stopElection()
 }
 
 // EverySecond every second size (1~59)
@@ -1,11 +1,18 @@
 package gocron
 
 import (
+	"context"
 	"fmt"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
+
+	"github.com/go-dev-frame/sponge/pkg/dlock"
 )
 
 func TestInitAndRun(t *testing.T) {
@@ -149,3 +156,144 @@ func TestEvery(t *testing.T) {
 
 	time.Sleep(time.Second * 7)
 }
+
+func TestLeaderOnly(t *testing.T) {
+	s, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	client := redis.NewClient(&redis.Options{Addr: s.Addr()})
+	s.Set("test_cron_leader", "other-instance") // another instance is the leader
+
+	elector, err := dlock.NewRedisElector(client, "test_cron_leader", dlock.WithExpiry(time.Millisecond*300))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = Init(WithLog(defaultLog), WithElector(elector))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer Stop()
+
+	var count int32
+	err = Run(&Task{
+		Name:         "leaderTask",
+		TimeSpec:     "@every 1s",
+		Fn:           func() { atomic.AddInt32(&count, 1) },
+		IsLeaderOnly: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer DeleteTask("leaderTask")
+
+	time.Sleep(time.Millisecond * 1200)
+	assert.False(t, IsLeader())
+	assert.Equal(t, int32(0), atomic.LoadInt32(&count))
+	assert.NotContains(t, GetRunningTasks(), "leaderTask")
+	assert.True(t, IsRunningTask("leaderTask"))
+
+	// the leader is gone, this instance becomes the leader
+	s.Del("test_cron_leader")
+	time.Sleep(time.Millisecond * 1200)
+	assert.True(t, IsLeader())
+	assert.Greater(t, atomic.LoadInt32(&count), int32(0))
+	assert.Contains(t, GetRunningTasks(), "leaderTask")
+}
+
+func TestLeaderOnlyWithoutElector(t *testing.T) {
+	err := Init(WithLog(zap.NewNop()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer Stop()
+
+	err = Run(&Task{
+		Name:         "leaderTask2",
+		TimeSpec:     "@every 1s",
+		Fn:           func() {},
+		IsLeaderOnly: true,
+	})
+	assert.Error(t, err)
+	assert.False(t, IsLeader())
+}
+
+func TestRunWithLocker(t *testing.T) {
+	locker, _ := dlock.NewLocalLock("test_cron_lock")
+	other, _ := dlock.NewLocalLock("test_cron_lock")
+	ctx := context.Background()
+
+	err := Init(WithLog(zap.NewNop()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer Stop()
+
+	var count int32
+	err = Run(&Task{
+		Name:     "lockTask",
+		TimeSpec: "@every 1s",
+		Fn:       func() { atomic.AddInt32(&count, 1) },
+		Locker:   locker,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer DeleteTask("lockTask")
+
+	// the lock is held by another instance, the execution is skipped
+	assert.NoError(t, other.Lock(ctx))
+	time.Sleep(time.Millisecond * 1200)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&count))
+
+	assert.NoError(t, other.Unlock(ctx))
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&count) > 0
+	}, time.Second*2, time.Millisecond*10)
+
+	// the lock is held until half of the interval has passed since the tick,
+	// the replica with a slightly skewed clock skips the same tick
+	ok, _ := other.TryLock(ctx)
+	assert.False(t, ok)
+	assert.Eventually(t, func() bool {
+		ok, _ := other.TryLock(ctx)
+		return ok
+	}, time.Second, time.Millisecond*10)
+	assert.NoError(t, other.Unlock(ctx))
+}
+
+func TestRunWithLocker_ManualTrigger(t *testing.T) {
+	locker, _ := dlock.NewLocalLock("test_cron_lock_manual")
+	other, _ := dlock.NewLocalLock("test_cron_lock_manual")
+	ctx := context.Background()
+
+	err := Init(WithLog(zap.NewNop()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer Stop()
+
+	var count int32
+	err = Run(&Task{
+		Name:     "lockManualTask",
+		TimeSpec: "@every 1h",
+		Fn:       func() { atomic.AddInt32(&count, 1) },
+		Locker:   locker,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer DeleteTask("lockManualTask")
+
+	// the lock of the manual trigger is released after the execution
+	assert.NoError(t, TriggerTask("lockManualTask"))
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&count) == 1
+	}, time.Second, time.Millisecond*10)
+	assert.Eventually(t, func() bool {
+		ok, _ := other.TryLock(ctx)
+		return ok
+	}, time.Second, time.Millisecond*10)
+	assert.NoError(t, other.Unlock(ctx))
+}
@@ -0,0 +1,165 @@
+package gocron
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// run status
+const (
+	StatusSuccess = "success"
+	StatusFailed  = "failed"
+	StatusTimeout = "timeout"
+)
+
+// trigger types
+const (
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+)
+
+var defaultMaxRecords = 20
+
+// RunRecord is the record of one execution of the task, the retries are included in the same record.
+type RunRecord struct {
+	TaskName  string    `json:"taskName"`
+	Trigger   string    `json:"trigger"`  // schedule or manual
+	Status    string    `json:"status"`   // success, failed or timeout
+	Error     string    `json:"error"`    // error of the last attempt, the panic is also included
+	Attempts  int       `json:"attempts"` // number of attempts, 1 means no retry
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+	Duration  int64     `json:"duration"` // milliseconds
+}
+
+// HistoryStore saves the run records of the tasks.
+type HistoryStore interface {
+	// Save saves a run record.
+	Save(ctx context.Context, record *RunRecord) error
+	// List returns the latest run records of the task, ordered by the start time in descending order.
+	List(ctx context.Context, taskName string, limit int) ([]*RunRecord, error)
+}
+
+// ------------------------------------------------------------------------------------------
+
+type memoryHistoryStore struct {
+	mu         sync.RWMutex
+	maxRecords int
+	records    map[string][]*RunRecord // task name and records mapping, the latest record is at the end
+}
+
+// NewMemoryHistoryStore creates a history store in memory, it keeps the latest maxRecords records of each task,
+// default is 20.
+func NewMemoryHistoryStore(maxRecords int) HistoryStore {
+	if maxRecords <= 0 {
+		maxRecords = defaultMaxRecords
+	}
+	return &memoryHistoryStore{
+		maxRecords: maxRecords,
+		records:    make(map[string][]*RunRecord),
+	}
+}
+
+func (s *memoryHistoryStore) Save(_ context.Context, record *RunRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	records := append(s.records[record.TaskName], record)
+	if len(records) > s.maxRecords {
+		records = append([]*RunRecord{}, records[len(records)-s.maxRecords:]...)
+	}
+	s.records[record.TaskName] = records
+	return nil
+}
+
+func (s *memoryHistoryStore) List(_ context.Context, taskName string, limit int) ([]*RunRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	records := s.records[taskName]
+	if limit <= 0 || limit > len(records) {
+		limit = len(records)
+	}
+	list := make([]*RunRecord, 0, limit)
+	for i := len(records) - 1; i >= len(records)-limit; i-- {
+		list = append(list, records[i])
+	}
+	return list, nil
+}
+
+// ------------------------------------------------------------------------------------------
+
+// CronRunRecord is the table of the run records saved by the gorm history store.
+type CronRunRecord struct {
+	ID        uint64    `gorm:"column:id;AUTO_INCREMENT;primary_key" json:"id"`
+	TaskName  string    `gorm:"column:task_name;type:varchar(128);index:idx_task_start;NOT NULL" json:"taskName"`
+	Trigger   string    `gorm:"column:trigger_type;type:varchar(16);NOT NULL" json:"trigger"`
+	Status    string    `gorm:"column:status;type:varchar(16);NOT NULL" json:"status"`
+	Error     string    `gorm:"column:error;type:text" json:"error"`
+	Attempts  int       `gorm:"column:attempts;NOT NULL" json:"attempts"`
+	StartTime time.Time `gorm:"column:start_time;index:idx_task_start;NOT NULL" json:"startTime"`
+	EndTime   time.Time `gorm:"column:end_time;NOT NULL" json:"endTime"`
+	Duration  int64     `gorm:"column:duration;NOT NULL" json:"duration"`
+}
+
+// TableName get table name
+func (table *CronRunRecord) TableName() string {
+	return "cron_run_record"
+}
+
+type gormHistoryStore struct {
+	db *gorm.DB
+}
+
+// NewGormHistoryStore creates a history store in the database, the table cron_run_record is created
+// if it does not exist, the records are not deleted automatically, clean up the old records regularly
+// if necessary, e.g. with a task.
+func NewGormHistoryStore(db *gorm.DB) (HistoryStore, error) {
+	if err := db.AutoMigrate(&CronRunRecord{}); err != nil {
+		return nil, err
+	}
+	return &gormHistoryStore{db: db}, nil
+}
+
+func (s *gormHistoryStore) Save(ctx context.Context, record *RunRecord) error {
+	return s.db.WithContext(ctx).Create(&CronRunRecord{
+		TaskName:  record.TaskName,
+		Trigger:   record.Trigger,
+		Status:    record.Status,
+		Error:     record.Error,
+		Attempts:  record.Attempts,
+		StartTime: record.StartTime,
+		EndTime:   record.EndTime,
+		Duration:  record.Duration,
+	}).Error
+}
+
+func (s *gormHistoryStore) List(ctx context.Context, taskName string, limit int) ([]*RunRecord, error) {
+	if limit <= 0 {
+		limit = defaultMaxRecords
+	}
+	var rows []*CronRunRecord
+	err := s.db.WithContext(ctx).Where("task_name = ?", taskName).
+		Order("start_time DESC").Order("id DESC").Limit(limit).Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]*RunRecord, 0, len(rows))
+	for _, row := range rows {
+		records = append(records, &RunRecord{
+			TaskName:  row.TaskName,
+			Trigger:   row.Trigger,
+			Status:    row.Status,
+			Error:     row.Error,
+			Attempts:  row.Attempts,
+			StartTime: row.StartTime,
+			EndTime:   row.EndTime,
+			Duration:  row.Duration,
+		})
+	}
+	return records, nil
+}
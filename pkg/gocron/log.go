@@ -3,6 +3,8 @@ package gocron
 import (
 	"github.com/robfig/cron/v3"
 	"go.uber.org/zap"
+
+	"github.com/go-dev-frame/sponge/pkg/dlock"
 )
 
 var (
@@ -17,6 +19,9 @@ type options struct {
 	isOnlyPrintError bool // default false
 
 	granularity int // 0: second, 1: minute
+
+	elector      dlock.Elector
+	historyStore HistoryStore
 }
 
 func defaultOptions() *options {
@@ -24,7 +29,8 @@ func defaultOptions() *options {
 		zapLog:           defaultLog,
 		isOnlyPrintError: false,
 
-		granularity: SecondType,
+		granularity:  SecondType,
+		historyStore: NewMemoryHistoryStore(defaultMaxRecords),
 	}
 }
 
@@ -59,6 +65,24 @@ func WithLog(log *zap.Logger, isOnlyPrintError ...bool) Option {
 	}
 }
 
+// WithElector set the leader elector, the tasks with IsLeaderOnly run only on the elected leader,
+// e.g. dlock.NewRedisElector or dlock.NewEtcdElector.
+func WithElector(elector dlock.Elector) Option {
+	return func(o *options) {
+		o.elector = elector
+	}
+}
+
+// WithHistoryStore set the store of the run records, default is the memory store that keeps
+// the latest 20 records of each task, use NewGormHistoryStore to persist the records in the database.
+func WithHistoryStore(store HistoryStore) Option {
+	return func(o *options) {
+		if store != nil {
+			o.historyStore = store
+		}
+	}
+}
+
 type zapLog struct {
 	zapLog           *zap.Logger
 	isOnlyPrintError bool
@@ -0,0 +1,301 @@
+package gocron
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+var (
+	// ErrTaskNotFound the task does not exist
+	ErrTaskNotFound = errors.New("task not found")
+
+	errTimeout = errors.New("task execution timeout")
+
+	defaultRetryBackoff = time.Second
+	maxRetryBackoff     = time.Minute
+	saveTimeout         = 3 * time.Second
+)
+
+// TaskInfo the task information and status on this instance
+type TaskInfo struct {
+	Name         string     `json:"name"`
+	TimeSpec     string     `json:"timeSpec"`
+	IsRunOnce    bool       `json:"isRunOnce"`
+	IsLeaderOnly bool       `json:"isLeaderOnly"`
+	IsPaused     bool       `json:"isPaused"`
+	IsExecuting  bool       `json:"isExecuting"`
+	PrevTime     time.Time  `json:"prevTime"` // last scheduled time
+	NextTime     time.Time  `json:"nextTime"` // next scheduled time
+	LastRun      *RunRecord `json:"lastRun"`  // last run on this instance, nil if it has not been run
+}
+
+// taskState the scheduled task and its status
+type taskState struct {
+	task      *Task
+	id        cron.EntryID
+	paused    atomic.Bool
+	executing atomic.Int32
+	lastRun   atomic.Pointer[RunRecord]
+}
+
+// runScheduled the job added to cron
+func (s *taskState) runScheduled() {
+	if s.paused.Load() {
+		return
+	}
+	if s.task.IsLeaderOnly && !IsLeader() {
+		return
+	}
+	if s.run(TriggerSchedule, s.lockHoldUntil()) && s.task.IsRunOnce {
+		DeleteTask(s.task.Name)
+	}
+}
+
+// lockHoldUntil get the time until which the lock of the current tick is held, the replicas fire the
+// same tick a few milliseconds apart, releasing the lock right after a short execution would let them
+// run the tick again.
+func (s *taskState) lockHoldUntil() time.Time {
+	if s.task.Locker == nil || c == nil {
+		return time.Time{}
+	}
+	id, ok := nameID.Load(s.task.Name)
+	if !ok {
+		return time.Time{}
+	}
+	entry := c.Entry(id.(cron.EntryID)) // Prev is the time of the current tick when the job is running
+	if entry.Prev.IsZero() || !entry.Next.After(entry.Prev) {
+		return time.Time{}
+	}
+	hold := entry.Next.Sub(entry.Prev) / 2
+	if hold > maxLockHoldTime {
+		hold = maxLockHoldTime
+	}
+	return entry.Prev.Add(hold)
+}
+
+// run execute the task under the lock if it is set, the lock is released after the execution, or at
+// lockUntil if it is later, return false if the execution is skipped.
+func (s *taskState) run(trigger string, lockUntil time.Time) bool {
+	task := s.task
+	if task.Locker != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), lockTimeout)
+		ok, err := task.Locker.TryLock(ctx)
+		cancel()
+		if err != nil {
+			cronLog.Error(err, "lock task failed", "task", task.Name)
+			return false
+		}
+		if !ok {
+			return false
+		}
+		defer func() {
+			unlock := func() {
+				ctx, cancel := context.WithTimeout(context.Background(), lockTimeout)
+				defer cancel()
+				if err := task.Locker.Unlock(ctx); err != nil {
+					cronLog.Error(err, "unlock task failed", "task", task.Name)
+				}
+			}
+			if d := time.Until(lockUntil); d > 0 {
+				time.AfterFunc(d, unlock)
+				return
+			}
+			unlock()
+		}()
+	}
+
+	s.executing.Add(1)
+	record := s.execute(trigger)
+	s.executing.Add(-1)
+
+	s.lastRun.Store(record)
+	if historyStore != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), saveTimeout)
+		defer cancel()
+		if err := historyStore.Save(ctx, record); err != nil {
+			cronLog.Error(err, "save run record failed", "task", task.Name)
+		}
+	}
+	return true
+}
+
+// execute run the task and retry with exponential backoff if it fails
+func (s *taskState) execute(trigger string) *RunRecord {
+	task := s.task
+	record := &RunRecord{
+		TaskName:  task.Name,
+		Trigger:   trigger,
+		Status:    StatusSuccess,
+		StartTime: time.Now(),
+	}
+
+	backoff := task.RetryBackoff
+	if backoff <= 0 {
+		backoff = defaultRetryBackoff
+	}
+	for {
+		record.Attempts++
+		err := s.runAttempt()
+		if err == nil {
+			record.Status = StatusSuccess
+			record.Error = ""
+			break
+		}
+
+		record.Status = StatusFailed
+		if errors.Is(err, errTimeout) {
+			record.Status = StatusTimeout
+		}
+		record.Error = err.Error()
+		cronLog.Error(err, "run task failed", "task", task.Name, "attempt", record.Attempts)
+		if record.Attempts > task.MaxRetries {
+			break
+		}
+
+		time.Sleep(backoff)
+		if backoff *= 2; backoff > maxRetryBackoff {
+			backoff = maxRetryBackoff
+		}
+	}
+
+	record.EndTime = time.Now()
+	record.Duration = record.EndTime.Sub(record.StartTime).Milliseconds()
+	return record
+}
+
+// runAttempt run the task once, the task function is not waited for after the timeout,
+// the context of FnWithContext is canceled to notify it to exit.
+func (s *taskState) runAttempt() error {
+	if s.task.Timeout <= 0 {
+		return s.call(context.Background())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.task.Timeout)
+	defer cancel()
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- s.call(ctx)
+	}()
+
+	select {
+	case err := <-errCh:
+		if err != nil && ctx.Err() != nil {
+			return errTimeout
+		}
+		return err
+	case <-ctx.Done():
+		return errTimeout
+	}
+}
+
+// call the task function, the panic is recovered and returned as an error
+func (s *taskState) call(ctx context.Context) (err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			err = fmt.Errorf("panic: %v", e)
+			cronLog.Error(err, "task panic", "task", s.task.Name, "stack", string(debug.Stack()))
+		}
+	}()
+
+	if s.task.FnWithContext != nil {
+		return s.task.FnWithContext(ctx)
+	}
+	s.task.Fn()
+	return nil
+}
+
+func (s *taskState) info() *TaskInfo {
+	info := &TaskInfo{
+		Name:         s.task.Name,
+		TimeSpec:     s.task.TimeSpec,
+		IsRunOnce:    s.task.IsRunOnce,
+		IsLeaderOnly: s.task.IsLeaderOnly,
+		IsPaused:     s.paused.Load(),
+		IsExecuting:  s.executing.Load() > 0,
+		LastRun:      s.lastRun.Load(),
+	}
+	if c != nil {
+		entry := c.Entry(s.id)
+		info.PrevTime = entry.Prev
+		info.NextTime = entry.Next
+	}
+	return info
+}
+
+func getTaskState(name string) (*taskState, error) {
+	v, ok := nameTask.Load(name)
+	if !ok {
+		return nil, ErrTaskNotFound
+	}
+	return v.(*taskState), nil
+}
+
+// GetTasks gets the information of all tasks on this instance, ordered by name
+func GetTasks() []*TaskInfo {
+	var infos []*TaskInfo
+	nameTask.Range(func(key, value interface{}) bool {
+		infos = append(infos, value.(*taskState).info())
+		return true
+	})
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+	return infos
+}
+
+// GetTask gets the information of the specified task on this instance
+func GetTask(name string) (*TaskInfo, error) {
+	s, err := getTaskState(name)
+	if err != nil {
+		return nil, err
+	}
+	return s.info(), nil
+}
+
+// GetTaskRuns gets the latest run records of the task from the history store
+func GetTaskRuns(ctx context.Context, name string, limit int) ([]*RunRecord, error) {
+	if historyStore == nil {
+		return nil, errors.New("cron is not initialized")
+	}
+	return historyStore.List(ctx, name, limit)
+}
+
+// TriggerTask run the task now in the background on this instance, regardless of the schedule,
+// the pause and the leadership, the Locker of the task is still applied.
+func TriggerTask(name string) error {
+	s, err := getTaskState(name)
+	if err != nil {
+		return err
+	}
+	go s.run(TriggerManual, time.Time{})
+	return nil
+}
+
+// PauseTask pause the scheduled execution of the task on this instance
+func PauseTask(name string) error {
+	s, err := getTaskState(name)
+	if err != nil {
+		return err
+	}
+	s.paused.Store(true)
+	cronLog.Info("pause task", "task", name)
+	return nil
+}
+
+// ResumeTask resume the scheduled execution of the paused task on this instance
+func ResumeTask(name string) error {
+	s, err := getTaskState(name)
+	if err != nil {
+		return err
+	}
+	s.paused.Store(false)
+	cronLog.Info("resume task", "task", name)
+	return nil
+}
@@ -0,0 +1,189 @@
+package gocron
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/go-dev-frame/sponge/pkg/sgorm/sqlite"
+)
+
+func waitLastRun(t *testing.T, name string) *RunRecord {
+	var info *TaskInfo
+	assert.Eventually(t, func() bool {
+		info, _ = GetTask(name)
+		return info != nil && info.LastRun != nil
+	}, time.Second*3, time.Millisecond*10)
+	return info.LastRun
+}
+
+func TestTaskRetryTimeoutAndPanic(t *testing.T) {
+	err := Init(WithLog(zap.NewNop()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer Stop()
+
+	var count int32
+	tasks := []*Task{
+		{
+			Name:     "retryTask",
+			TimeSpec: "@every 1h",
+			FnWithContext: func(ctx context.Context) error {
+				if atomic.AddInt32(&count, 1) < 3 {
+					return errors.New("mock error")
+				}
+				return nil
+			},
+			MaxRetries:   3,
+			RetryBackoff: time.Millisecond * 10,
+		},
+		{
+			Name:     "timeoutTask",
+			TimeSpec: "@every 1h",
+			FnWithContext: func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+			Timeout: time.Millisecond * 50,
+		},
+		{
+			Name:       "panicTask",
+			TimeSpec:   "@every 1h",
+			Fn:         func() { panic("mock panic") },
+			MaxRetries: 1,
+		},
+	}
+	err = Run(tasks...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		for _, task := range tasks {
+			DeleteTask(task.Name)
+		}
+	}()
+
+	for _, task := range tasks {
+		assert.NoError(t, TriggerTask(task.Name))
+	}
+
+	record := waitLastRun(t, "retryTask")
+	assert.Equal(t, StatusSuccess, record.Status)
+	assert.Equal(t, 3, record.Attempts)
+	assert.Equal(t, TriggerManual, record.Trigger)
+
+	record = waitLastRun(t, "timeoutTask")
+	assert.Equal(t, StatusTimeout, record.Status)
+	assert.Equal(t, 1, record.Attempts)
+
+	record = waitLastRun(t, "panicTask")
+	assert.Equal(t, StatusFailed, record.Status)
+	assert.Equal(t, 2, record.Attempts)
+	assert.Contains(t, record.Error, "mock panic")
+
+	records, err := GetTaskRuns(context.Background(), "retryTask", 10)
+	assert.NoError(t, err)
+	assert.Len(t, records, 1)
+
+	err = Run(&Task{Name: "badTask", TimeSpec: "@every 1h", Fn: func() {}, MaxRetries: -1})
+	assert.Error(t, err)
+	err = Run(&Task{Name: "badTask", TimeSpec: "@every 1h", Fn: func() {}, Timeout: time.Second})
+	assert.Error(t, err)
+}
+
+func TestPauseAndResume(t *testing.T) {
+	err := Init(WithLog(zap.NewNop()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer Stop()
+
+	var count int32
+	err = Run(&Task{
+		Name:     "pauseTask",
+		TimeSpec: "@every 1s",
+		Fn:       func() { atomic.AddInt32(&count, 1) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer DeleteTask("pauseTask")
+
+	assert.NoError(t, PauseTask("pauseTask"))
+	info, err := GetTask("pauseTask")
+	assert.NoError(t, err)
+	assert.True(t, info.IsPaused)
+	assert.False(t, info.NextTime.IsZero())
+	time.Sleep(time.Millisecond * 1200)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&count))
+
+	assert.NoError(t, ResumeTask("pauseTask"))
+	time.Sleep(time.Millisecond * 1200)
+	assert.Greater(t, atomic.LoadInt32(&count), int32(0))
+	record := waitLastRun(t, "pauseTask")
+	assert.Equal(t, TriggerSchedule, record.Trigger)
+	var names []string
+	for _, v := range GetTasks() {
+		names = append(names, v.Name)
+	}
+	assert.Contains(t, names, "pauseTask")
+
+	assert.ErrorIs(t, PauseTask("notFound"), ErrTaskNotFound)
+	assert.ErrorIs(t, ResumeTask("notFound"), ErrTaskNotFound)
+	assert.ErrorIs(t, TriggerTask("notFound"), ErrTaskNotFound)
+	_, err = GetTask("notFound")
+	assert.ErrorIs(t, err, ErrTaskNotFound)
+}
+
+func testHistoryStore(t *testing.T, store HistoryStore) {
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+	for i := 0; i < 5; i++ {
+		err := store.Save(ctx, &RunRecord{
+			TaskName:  "task1",
+			Trigger:   TriggerSchedule,
+			Status:    StatusSuccess,
+			Attempts:  i + 1,
+			StartTime: now.Add(time.Duration(i) * time.Second),
+			EndTime:   now.Add(time.Duration(i) * time.Second),
+		})
+		assert.NoError(t, err)
+	}
+
+	records, err := store.List(ctx, "task1", 2)
+	assert.NoError(t, err)
+	assert.Len(t, records, 2)
+	assert.Equal(t, 5, records[0].Attempts)
+	assert.Equal(t, 4, records[1].Attempts)
+
+	records, err = store.List(ctx, "task2", 2)
+	assert.NoError(t, err)
+	assert.Len(t, records, 0)
+}
+
+func TestMemoryHistoryStore(t *testing.T) {
+	store := NewMemoryHistoryStore(3)
+	testHistoryStore(t, store)
+
+	records, _ := store.List(context.Background(), "task1", 0)
+	assert.Len(t, records, 3)
+}
+
+func TestGormHistoryStore(t *testing.T) {
+	db, err := sqlite.Init(filepath.Join(t.TempDir(), "cron.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := NewGormHistoryStore(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testHistoryStore(t, store)
+}
@@ -73,6 +73,8 @@ func (d *Dao) Close() {
 	}
 }
 
+var valuerType = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+
 // GetAnyArgs Dynamic generation of parameter types based on structures
 func (d *Dao) GetAnyArgs(obj interface{}) []driver.Value {
 	to := reflect.TypeOf(obj)
@@ -105,7 +107,9 @@ func (d *Dao) GetAnyArgs(obj interface{}) []driver.Value {
 			continue
 		}
 		if fieldValue.CanInterface() {
-			if fieldValue.Kind() == reflect.Struct && field.Type.String() != "time.Time" {
+			// the struct is an embedded model, e.g. sgorm.Model, its fields are columns, except the struct
+			// is a column value itself, e.g. time.Time, gorm.DeletedAt, sql.NullString
+			if fieldValue.Kind() == reflect.Struct && field.Type.String() != "time.Time" && !field.Type.Implements(valuerType) {
 				count += fieldValue.NumField()
 				continue
 			}
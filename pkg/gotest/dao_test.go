@@ -7,8 +7,10 @@ import (
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
 	"gorm.io/gorm"
 
+	"github.com/go-dev-frame/sponge/pkg/sgorm"
 	"github.com/go-dev-frame/sponge/pkg/sgorm/query"
 )
 
@@ -41,6 +43,10 @@ func TestDao_GetAnyArgs(t *testing.T) {
 
 	t.Log(d.GetAnyArgs(testData))
 
+	// the fields of embedded model are columns, the soft delete field is one column
+	assert.Len(t, d.GetAnyArgs(&softDeleteUser{}), 5)
+	assert.Len(t, d.GetAnyArgs(&embedModelUser{}), 5)
+
 	// test error
 	defer func() {
 		recover()
@@ -63,6 +69,19 @@ type User struct {
 	UpdatedAt time.Time `gorm:"column:updated_at;NOT NULL"`
 }
 
+type softDeleteUser struct {
+	ID        uint64         `gorm:"column:id;AUTO_INCREMENT;primary_key" json:"id"`
+	Name      string         `gorm:"column:name;NOT NULL" json:"name"`
+	CreatedAt time.Time      `gorm:"column:created_at;NOT NULL"`
+	UpdatedAt time.Time      `gorm:"column:updated_at;NOT NULL"`
+	DeletedAt gorm.DeletedAt `gorm:"column:deleted_at"`
+}
+
+type embedModelUser struct {
+	sgorm.Model `gorm:"embedded"`
+	Name        string `gorm:"column:name;NOT NULL" json:"name"`
+}
+
 type userDao struct {
 	db *gorm.DB
 }
@@ -10,6 +10,7 @@ import (
 	"google.golang.org/grpc/metadata"
 
 	"github.com/go-dev-frame/sponge/pkg/krand"
+	"github.com/go-dev-frame/sponge/pkg/msgctx"
 )
 
 var (
@@ -25,6 +26,7 @@ func SetContextRequestIDKey(key string) {
 	}
 	once.Do(func() {
 		ContextRequestIDKey = key
+		msgctx.SetRequestIDKey(key, "")
 	})
 }
 
@@ -18,6 +18,7 @@ import (
 	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/runtime/protoimpl"
 
+	"github.com/go-dev-frame/sponge/pkg/msgctx"
 	"github.com/go-dev-frame/sponge/pkg/utils"
 )
 
@@ -574,4 +575,5 @@ func TestSetContextRequestIDKey(t *testing.T) {
 	SetContextRequestIDKey("foo_bar") // invalid key, sync.Once
 	t.Log(ContextRequestIDKey)
 	SetContextRequestIDKey("xx") // invalid key
+	assert.Equal(t, ContextRequestIDKey, msgctx.ContextRequestIDKey)
 }
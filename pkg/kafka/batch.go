@@ -0,0 +1,268 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/IBM/sarama"
+	"go.uber.org/zap"
+)
+
+// HandleBatchFn is a function that handles a batch of messages from one partition, the messages are in order of offset.
+type HandleBatchFn func(msgs []*sarama.ConsumerMessage) error
+
+// BatchOption set batch consumption options.
+type BatchOption func(*batchOptions)
+
+type batchOptions struct {
+	size          int           // default 100
+	flushInterval time.Duration // default 1s
+	concurrency   int           // default 1
+}
+
+func (o *batchOptions) apply(opts ...BatchOption) {
+	for _, opt := range opts {
+		opt(o)
+	}
+}
+
+func defaultBatchOptions() *batchOptions {
+	return &batchOptions{
+		size:          100,
+		flushInterval: time.Second,
+		concurrency:   1,
+	}
+}
+
+// BatchWithSize set the max number of messages in a batch, default is 100
+func BatchWithSize(size int) BatchOption {
+	return func(o *batchOptions) {
+		if size > 0 {
+			o.size = size
+		}
+	}
+}
+
+// BatchWithFlushInterval set the max wait time from receiving the first message of a batch to handling it, default is 1s
+func BatchWithFlushInterval(d time.Duration) BatchOption {
+	return func(o *batchOptions) {
+		if d > 0 {
+			o.flushInterval = d
+		}
+	}
+}
+
+// BatchWithConcurrency set the number of goroutines handling a batch, the batch is split into sub-batches by the
+// message key, the messages with the same key are in the same sub-batch and in order of offset, default is 1.
+func BatchWithConcurrency(n int) BatchOption {
+	return func(o *batchOptions) {
+		if n > 0 {
+			o.concurrency = n
+		}
+	}
+}
+
+// ConsumeBatch consume messages in batches, a batch is handled when it reaches the batch size or the flush interval,
+// the offset is committed after all messages of the batch are handled successfully. The failed batch is retried if
+// ConsumerWithRetry is set, then its messages are sent to the retry topics and the dead letter topic if
+// ConsumerWithDeadLetter is set, otherwise it is logged and retried with backoff(up to 1 minute) until it succeeds
+// or ctx is done, the partition is blocked meanwhile and the offset does not move.
+func (c *ConsumerGroup) ConsumeBatch(ctx context.Context, topics []string, handleBatchFn HandleBatchFn, opts ...BatchOption) error {
+	o := defaultBatchOptions()
+	o.apply(opts...)
+	handler := &batchConsumerHandler{
+		defaultConsumerHandler: &defaultConsumerHandler{
+			ctx:              ctx,
+			zapLogger:        c.zapLogger,
+			autoCommitEnable: c.autoCommitEnable,
+			maxRetries:       c.maxRetries,
+			retryBackoff:     c.retryBackoff,
+			deadLetter:       c.deadLetter,
+		},
+		handleBatchFn: handleBatchFn,
+		opts:          o,
+	}
+	topics = c.deadLetter.withRetryTopics(topics)
+
+	err := c.Group.Consume(ctx, topics, handler)
+	if err != nil {
+		c.zapLogger.Error("failed to consume messages", zap.String("group_id", c.groupID), zap.Strings("topics", topics), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+type batchConsumerHandler struct {
+	*defaultConsumerHandler
+	handleBatchFn HandleBatchFn
+	opts          *batchOptions
+}
+
+// ConsumeClaim consumes messages in batches
+func (h *batchConsumerHandler) ConsumeClaim(sess sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	// stop handling when the consumer is stopped or the session is rebalanced
+	ctx, cancel := context.WithCancel(h.ctx)
+	defer cancel()
+	stop := context.AfterFunc(sess.Context(), cancel)
+	defer stop()
+
+	var (
+		batch   []*sarama.ConsumerMessage
+		flushCh <-chan time.Time
+	)
+	flush := func() bool {
+		flushCh = nil
+		if len(batch) == 0 {
+			return true
+		}
+		if !h.handleBatch(ctx, batch) {
+			return false // the batch is not marked, it will be consumed again
+		}
+		sess.MarkMessage(batch[len(batch)-1], "")
+		sess.Commit()
+		batch = nil
+		return true
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-claim.Messages():
+			if !ok {
+				flush()
+				return nil
+			}
+			if len(batch) == 0 {
+				batch = make([]*sarama.ConsumerMessage, 0, h.opts.size)
+				flushCh = time.After(h.opts.flushInterval)
+			}
+			batch = append(batch, msg)
+			if len(batch) >= h.opts.size && !flush() {
+				return nil
+			}
+		case <-flushCh:
+			if !flush() {
+				return nil
+			}
+		}
+	}
+}
+
+// handleBatch handle the sub-batches concurrently, return false if ctx is done before the batch is handled,
+// or the failed batch cannot be sent to the dead letter topic.
+func (h *batchConsumerHandler) handleBatch(ctx context.Context, batch []*sarama.ConsumerMessage) bool {
+	// the messages of the retry topic are handled after the retry time, the last message has the latest retry time
+	if err := waitRetryAt(ctx, batch[len(batch)-1]); err != nil {
+		return false
+	}
+
+	subBatches := splitBatch(batch, h.opts.concurrency)
+	attempts := make([]int, len(subBatches))
+	errs := make([]error, len(subBatches))
+	if len(subBatches) == 1 {
+		attempts[0], errs[0] = h.handleSubBatch(ctx, subBatches[0])
+	} else {
+		var wg sync.WaitGroup
+		for i := range subBatches {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				attempts[i], errs[i] = h.handleSubBatch(ctx, subBatches[i])
+			}(i)
+		}
+		wg.Wait()
+	}
+	if ctx.Err() != nil {
+		return false
+	}
+
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		msgs := subBatches[i]
+		if h.deadLetter == nil {
+			if err = h.retryUntilDone(ctx, msgs, err); err != nil {
+				return false
+			}
+			continue
+		}
+		for _, msg := range msgs {
+			if err = h.sendFailed(ctx, msg, attempts[i], errs[i]); err != nil {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (h *batchConsumerHandler) handleSubBatch(ctx context.Context, msgs []*sarama.ConsumerMessage) (int, error) {
+	return h.retry(ctx, func() error { return h.call(msgs) }, batchFields(msgs)...)
+}
+
+// retryUntilDone retry the failed sub-batch with backoff until it succeeds or ctx is done, it is used when there
+// is no dead letter topic to park the failed messages, the partition is blocked and the failure is logged meanwhile.
+func (h *batchConsumerHandler) retryUntilDone(ctx context.Context, msgs []*sarama.ConsumerMessage, err error) error {
+	backoff := h.retryBackoff
+	for i := 1; err != nil; i++ {
+		h.zapLogger.Error("failed to handle batch messages, retry until it succeeds",
+			append(batchFields(msgs), zap.Error(err), zap.Int("rounds", i))...)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > time.Minute {
+			backoff = time.Minute
+		}
+		err = h.call(msgs)
+	}
+	return nil
+}
+
+// call the batch handler, the panic is converted to error
+func (h *batchConsumerHandler) call(msgs []*sarama.ConsumerMessage) (err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			err = fmt.Errorf("panic: %v", e)
+		}
+	}()
+	return h.handleBatchFn(msgs)
+}
+
+func batchFields(msgs []*sarama.ConsumerMessage) []zap.Field {
+	return []zap.Field{zap.String("topic", msgs[0].Topic), zap.Int32("partition", msgs[0].Partition),
+		zap.Int64("first_offset", msgs[0].Offset), zap.Int("count", len(msgs))}
+}
+
+// splitBatch split the batch into n sub-batches by the message key, the messages without key are split by offset
+func splitBatch(batch []*sarama.ConsumerMessage, n int) [][]*sarama.ConsumerMessage {
+	if n <= 1 || len(batch) <= 1 {
+		return [][]*sarama.ConsumerMessage{batch}
+	}
+
+	buckets := make([][]*sarama.ConsumerMessage, n)
+	for _, msg := range batch {
+		var i int
+		if len(msg.Key) > 0 {
+			hash := fnv.New32a()
+			_, _ = hash.Write(msg.Key)
+			i = int(hash.Sum32() % uint32(n))
+		} else {
+			i = int(msg.Offset % int64(n))
+		}
+		buckets[i] = append(buckets[i], msg)
+	}
+
+	subBatches := make([][]*sarama.ConsumerMessage, 0, n)
+	for _, bucket := range buckets {
+		if len(bucket) > 0 {
+			subBatches = append(subBatches, bucket)
+		}
+	}
+	return subBatches
+}
@@ -0,0 +1,203 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func newTestBatchHandler(fn HandleBatchFn, opts ...BatchOption) *batchConsumerHandler {
+	o := defaultBatchOptions()
+	o.apply(opts...)
+	return &batchConsumerHandler{
+		defaultConsumerHandler: &defaultConsumerHandler{
+			ctx:       context.Background(),
+			zapLogger: zap.NewNop(),
+		},
+		handleBatchFn: fn,
+		opts:          o,
+	}
+}
+
+func TestBatchConsumerHandler(t *testing.T) {
+	var mu sync.Mutex
+	var batches [][]int64
+	h := newTestBatchHandler(func(msgs []*sarama.ConsumerMessage) error {
+		mu.Lock()
+		defer mu.Unlock()
+		var offsets []int64
+		for _, msg := range msgs {
+			offsets = append(offsets, msg.Offset)
+		}
+		batches = append(batches, offsets)
+		return nil
+	}, BatchWithSize(3), BatchWithFlushInterval(time.Millisecond*50))
+
+	sess := &testSession{ctx: context.Background()}
+	claim := &testClaim{ch: make(chan *sarama.ConsumerMessage, 10)}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.NoError(t, h.ConsumeClaim(sess, claim))
+	}()
+
+	// flush by batch size, then flush by interval
+	for i := int64(0); i < 5; i++ {
+		claim.ch <- &sarama.ConsumerMessage{Topic: "my-topic", Offset: i}
+	}
+	time.Sleep(time.Millisecond * 150)
+	mu.Lock()
+	assert.Equal(t, [][]int64{{0, 1, 2}, {3, 4}}, batches)
+	mu.Unlock()
+	assert.Equal(t, []int64{2, 4}, sess.getMarked())
+
+	// flush the remaining messages when the claim is closed
+	claim.ch <- &sarama.ConsumerMessage{Topic: "my-topic", Offset: 5}
+	close(claim.ch)
+	<-done
+	assert.Equal(t, []int64{2, 4, 5}, sess.getMarked())
+	assert.Equal(t, 3, sess.commits)
+}
+
+func TestBatchConsumerHandler_Concurrency(t *testing.T) {
+	var mu sync.Mutex
+	keyOffsets := make(map[string][]int64)
+	h := newTestBatchHandler(func(msgs []*sarama.ConsumerMessage) error {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, msg := range msgs {
+			keyOffsets[string(msg.Key)] = append(keyOffsets[string(msg.Key)], msg.Offset)
+		}
+		return nil
+	}, BatchWithSize(100), BatchWithFlushInterval(time.Millisecond*10), BatchWithConcurrency(4))
+
+	var msgs []*sarama.ConsumerMessage
+	for i := int64(0); i < 30; i++ {
+		msgs = append(msgs, &sarama.ConsumerMessage{Topic: "my-topic", Offset: i, Key: []byte("key" + strconv.Itoa(int(i%5)))})
+	}
+	sess := &testSession{ctx: context.Background()}
+	claim := &testClaim{ch: make(chan *sarama.ConsumerMessage, len(msgs))}
+	for _, msg := range msgs {
+		claim.ch <- msg
+	}
+	close(claim.ch)
+	assert.NoError(t, h.ConsumeClaim(sess, claim))
+
+	assert.Equal(t, []int64{29}, sess.getMarked())
+	assert.Len(t, keyOffsets, 5)
+	for key, offsets := range keyOffsets {
+		assert.Len(t, offsets, 6, key)
+		for i := 1; i < len(offsets); i++ {
+			assert.Less(t, offsets[i-1], offsets[i], key) // in order of offset
+		}
+	}
+
+	subBatches := splitBatch(msgs[:10], 3)
+	total := 0
+	for _, sub := range subBatches {
+		total += len(sub)
+	}
+	assert.Equal(t, 10, total)
+	assert.Len(t, splitBatch(msgs[:10], 1), 1)
+	assert.Len(t, splitBatch([]*sarama.ConsumerMessage{{Offset: 1}, {Offset: 2}}, 2), 2)
+}
+
+func TestBatchConsumerHandler_Failed(t *testing.T) {
+	count := 0
+	h := newTestBatchHandler(func(msgs []*sarama.ConsumerMessage) error {
+		count++
+		switch count {
+		case 1:
+			panic("bad batch")
+		case 2, 3:
+			return errors.New("db error")
+		}
+		return nil
+	}, BatchWithSize(2))
+	h.maxRetries = 1
+	h.retryBackoff = time.Millisecond
+
+	// without dead letter, the failed batch is retried until it succeeds, the offset moves in order
+	msgs := []*sarama.ConsumerMessage{{Topic: "my-topic", Offset: 0}, {Topic: "my-topic", Offset: 1}}
+	sess := &testSession{ctx: context.Background()}
+	claim := &testClaim{ch: make(chan *sarama.ConsumerMessage, 4)}
+	claim.ch <- msgs[0]
+	claim.ch <- msgs[1]
+	claim.ch <- &sarama.ConsumerMessage{Topic: "my-topic", Offset: 2}
+	claim.ch <- &sarama.ConsumerMessage{Topic: "my-topic", Offset: 3}
+	close(claim.ch)
+	assert.NoError(t, h.ConsumeClaim(sess, claim))
+	assert.Equal(t, 5, count)
+	assert.Equal(t, []int64{1, 3}, sess.getMarked())
+
+	// without dead letter, the failed batch is not marked when the consumer is stopped
+	h.handleBatchFn = func(msgs []*sarama.ConsumerMessage) error {
+		return errors.New("db error")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	h.ctx = ctx
+	time.AfterFunc(time.Millisecond*100, cancel)
+	sess = &testSession{ctx: context.Background()}
+	claim = &testClaim{ch: make(chan *sarama.ConsumerMessage, 2)}
+	claim.ch <- msgs[0]
+	claim.ch <- msgs[1]
+	assert.NoError(t, h.ConsumeClaim(sess, claim))
+	assert.Empty(t, sess.getMarked())
+	assert.Equal(t, 0, sess.commits)
+	h.ctx = context.Background()
+
+	// the messages of the failed batch are sent to the dead letter topic
+	mp := mocks.NewSyncProducer(t, nil)
+	var sent []*sarama.ProducerMessage
+	for i := 0; i < 2; i++ {
+		mp.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(pm *sarama.ProducerMessage) error {
+			sent = append(sent, pm)
+			return nil
+		})
+	}
+	defer mp.Close()
+	h.deadLetter = &deadLetterOptions{producer: &SyncProducer{Producer: mp}}
+	sess = &testSession{ctx: context.Background()}
+	claim = &testClaim{ch: make(chan *sarama.ConsumerMessage, 2)}
+	claim.ch <- msgs[0]
+	claim.ch <- msgs[1]
+	close(claim.ch)
+	assert.NoError(t, h.ConsumeClaim(sess, claim))
+	assert.Equal(t, []int64{1}, sess.getMarked())
+	assert.Len(t, sent, 2)
+	for i, pm := range sent {
+		assert.Equal(t, "my-topic.dlq", pm.Topic)
+		assert.Equal(t, strconv.Itoa(i), getProducerHeader(pm, HeaderOriginalOffset))
+		assert.Equal(t, "2", getProducerHeader(pm, HeaderAttempts))
+		assert.Equal(t, "db error", getProducerHeader(pm, HeaderError))
+	}
+
+	// the batch is not marked when the consumer is stopped
+	ctx, cancel = context.WithCancel(context.Background())
+	h.ctx = ctx
+	h.maxRetries = 100
+	h.retryBackoff = time.Millisecond * 50
+	time.AfterFunc(time.Millisecond*100, cancel)
+	sess = &testSession{ctx: context.Background()}
+	claim = &testClaim{ch: make(chan *sarama.ConsumerMessage, 2)}
+	claim.ch <- msgs[0]
+	claim.ch <- msgs[1]
+	assert.NoError(t, h.ConsumeClaim(sess, claim))
+	assert.Empty(t, sess.getMarked())
+}
+
+func TestBatchOptions(t *testing.T) {
+	o := defaultBatchOptions()
+	o.apply(BatchWithSize(0), BatchWithFlushInterval(0), BatchWithConcurrency(0))
+	assert.Equal(t, 100, o.size)
+	assert.Equal(t, time.Second, o.flushInterval)
+	assert.Equal(t, 1, o.concurrency)
+}
@@ -2,6 +2,7 @@ package kafka
 
 import (
 	"context"
+	"time"
 
 	"github.com/IBM/sarama"
 	"go.uber.org/zap"
@@ -15,6 +16,10 @@ type ConsumerGroup struct {
 	groupID          string
 	zapLogger        *zap.Logger
 	autoCommitEnable bool
+
+	maxRetries   int
+	retryBackoff time.Duration
+	deadLetter   *deadLetterOptions
 }
 
 // InitConsumerGroup init consumer group
@@ -48,17 +53,25 @@ func InitConsumerGroup(addrs []string, groupID string, opts ...ConsumerOption) (
 		groupID:          groupID,
 		zapLogger:        o.zapLogger,
 		autoCommitEnable: config.Consumer.Offsets.AutoCommit.Enable,
+		maxRetries:       o.maxRetries,
+		retryBackoff:     o.retryBackoff,
+		deadLetter:       o.deadLetter,
 	}, nil
 }
 
-// Consume consume messages
+// Consume consume messages, the failed message is retried and sent to the retry topics and the dead letter
+// topic if ConsumerWithRetry and ConsumerWithDeadLetter are set, otherwise it is logged and skipped.
 func (c *ConsumerGroup) Consume(ctx context.Context, topics []string, handleMessageFn HandleMessageFn) error {
 	handler := &defaultConsumerHandler{
 		ctx:              ctx,
 		handleMessageFn:  handleMessageFn,
 		zapLogger:        c.zapLogger,
 		autoCommitEnable: c.autoCommitEnable,
+		maxRetries:       c.maxRetries,
+		retryBackoff:     c.retryBackoff,
+		deadLetter:       c.deadLetter,
 	}
+	topics = c.deadLetter.withRetryTopics(topics)
 
 	err := c.Group.Consume(ctx, topics, handler)
 	if err != nil {
@@ -78,11 +91,12 @@ func (c *ConsumerGroup) ConsumeCustom(ctx context.Context, topics []string, hand
 	return nil
 }
 
+// Close the consumer group
 func (c *ConsumerGroup) Close() error {
 	if c == nil || c.Group == nil {
-		return c.Group.Close()
+		return nil
 	}
-	return nil
+	return c.Group.Close()
 }
 
 type defaultConsumerHandler struct {
@@ -90,6 +104,10 @@ type defaultConsumerHandler struct {
 	handleMessageFn  HandleMessageFn
 	zapLogger        *zap.Logger
 	autoCommitEnable bool
+
+	maxRetries   int
+	retryBackoff time.Duration
+	deadLetter   *deadLetterOptions // nil means the failed message is logged and skipped
 }
 
 // Setup is run at the beginning of a new session, before ConsumeClaim
@@ -113,18 +131,32 @@ func (h *defaultConsumerHandler) ConsumeClaim(sess sarama.ConsumerGroupSession,
 		}
 	}()
 
+	// stop handling when the consumer is stopped or the session is rebalanced
+	ctx, cancel := context.WithCancel(h.ctx)
+	defer cancel()
+	stop := context.AfterFunc(sess.Context(), cancel)
+	defer stop()
+
 	for {
 		select {
-		case <-h.ctx.Done():
+		case <-ctx.Done():
 			return nil
 		case msg, ok := <-claim.Messages():
 			if !ok {
 				return nil
 			}
-			err := h.handleMessageFn(msg)
+			attempts, err := h.handle(ctx, msg)
 			if err != nil {
-				h.zapLogger.Error("failed to handle message", zap.Error(err))
-				continue
+				if ctx.Err() != nil {
+					return nil // the message is not marked, it will be consumed again
+				}
+				if h.deadLetter == nil {
+					h.zapLogger.Error("failed to handle message", zap.Error(err))
+					continue
+				}
+				if err = h.sendFailed(ctx, msg, attempts, err); err != nil {
+					return nil
+				}
 			}
 			sess.MarkMessage(msg, "")
 			if !h.autoCommitEnable {
@@ -222,7 +254,7 @@ func (c *Consumer) ConsumeAllPartition(ctx context.Context, topic string, offset
 // Close the consumer
 func (c *Consumer) Close() error {
 	if c == nil || c.C == nil {
-		return c.C.Close()
+		return nil
 	}
-	return nil
+	return c.C.Close()
 }
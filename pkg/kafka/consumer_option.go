@@ -26,6 +26,11 @@ type consumerOptions struct {
 	offsetsAutoCommitEnable   bool                     // default true
 	offsetsAutoCommitInterval time.Duration            // default 1s, when offsetsAutoCommitEnable is true
 
+	// failure handling options of the consumer group
+	maxRetries   int                // default 0
+	retryBackoff time.Duration      // default 100ms
+	deadLetter   *deadLetterOptions // default nil, the failed message is logged and skipped
+
 	// custom config, if not nil, it will override the default config, the above parameters are invalid
 	config *sarama.Config // default nil
 
@@ -46,6 +51,7 @@ func defaultConsumerOptions() *consumerOptions {
 		offsetsInitial:            sarama.OffsetOldest,
 		offsetsAutoCommitEnable:   true,
 		offsetsAutoCommitInterval: time.Second,
+		retryBackoff:              time.Millisecond * 100,
 		clientID:                  "sarama",
 		zapLogger:                 zapLogger,
 	}
@@ -88,6 +94,32 @@ func ConsumerWithOffsetsAutoCommitInterval(offsetsAutoCommitInterval time.Durati
 	}
 }
 
+// ConsumerWithRetry set the number of retries of the failed message in the consumer group,
+// the backoff is doubled for each retry up to 1 minute.
+func ConsumerWithRetry(maxRetries int, backoff time.Duration) ConsumerOption {
+	return func(o *consumerOptions) {
+		if maxRetries >= 0 {
+			o.maxRetries = maxRetries
+		}
+		if backoff > 0 {
+			o.retryBackoff = backoff
+		}
+	}
+}
+
+// ConsumerWithDeadLetter set the producer to send the messages that still fail after the retries to the
+// retry topics and the dead letter topic of the consumer group, the offset of the failed message is committed
+// after it is sent, so a poison message does not block the partition.
+func ConsumerWithDeadLetter(producer *SyncProducer, opts ...DeadLetterOption) ConsumerOption {
+	return func(o *consumerOptions) {
+		if producer == nil {
+			return
+		}
+		o.deadLetter = &deadLetterOptions{producer: producer}
+		o.deadLetter.apply(opts...)
+	}
+}
+
 // ConsumerWithClientID set clientID.
 func ConsumerWithClientID(clientID string) ConsumerOption {
 	return func(o *consumerOptions) {
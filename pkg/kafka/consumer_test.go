@@ -266,6 +266,21 @@ func TestConsumerGroup(t *testing.T) {
 		autoCommitEnable: g.autoCommitEnable,
 	})
 
+	<-time.After(time.Second)
+
+	broker0.SetHandlerByMap(mockData)
+	group, err = sarama.NewConsumerGroup([]string{broker0.Addr()}, myGroup, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.Group = group
+	go g.ConsumeBatch(ctx, topics, func(msgs []*sarama.ConsumerMessage) error {
+		for _, msg := range msgs {
+			_ = handleMsgFn(msg)
+		}
+		return nil
+	}, BatchWithSize(10), BatchWithFlushInterval(time.Millisecond*100))
+
 	<-time.After(time.Second)
 	cancel()
 }
@@ -0,0 +1,72 @@
+package kafka
+
+import (
+	"context"
+
+	"github.com/IBM/sarama"
+
+	"github.com/go-dev-frame/sponge/pkg/msgctx"
+)
+
+// HandleMessageCtxFn is a function that handles a message with the context, the context contains
+// the trace context and request id of the producer.
+type HandleMessageCtxFn func(ctx context.Context, msg *sarama.ConsumerMessage) error
+
+// CtxHandler convert HandleMessageCtxFn to HandleMessageFn, the trace context and request id in the
+// message headers are extracted into the ctx of the handler.
+func CtxHandler(ctx context.Context, fn HandleMessageCtxFn) HandleMessageFn {
+	return func(msg *sarama.ConsumerMessage) error {
+		return fn(MessageContext(ctx, msg), msg)
+	}
+}
+
+// MessageContext extract the trace context and request id from the message headers into ctx
+func MessageContext(ctx context.Context, msg *sarama.ConsumerMessage) context.Context {
+	return msgctx.Extract(ctx, consumerHeaderCarrier(msg.Headers))
+}
+
+// injectContext inject the trace context and request id of ctx into the message headers
+func injectContext(ctx context.Context, msg *sarama.ProducerMessage) {
+	msgctx.Inject(ctx, (*producerHeaderCarrier)(msg))
+}
+
+type producerHeaderCarrier sarama.ProducerMessage
+
+func (c *producerHeaderCarrier) Get(key string) string {
+	for _, h := range c.Headers {
+		if string(h.Key) == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+func (c *producerHeaderCarrier) Set(key string, value string) {
+	c.Headers = setHeader(c.Headers, key, value)
+}
+
+func (c *producerHeaderCarrier) Keys() []string {
+	keys := make([]string, 0, len(c.Headers))
+	for _, h := range c.Headers {
+		keys = append(keys, string(h.Key))
+	}
+	return keys
+}
+
+type consumerHeaderCarrier []*sarama.RecordHeader
+
+func (c consumerHeaderCarrier) Get(key string) string {
+	return getHeader(c, key)
+}
+
+func (c consumerHeaderCarrier) Set(string, string) {}
+
+func (c consumerHeaderCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for _, h := range c {
+		if h != nil {
+			keys = append(keys, string(h.Key))
+		}
+	}
+	return keys
+}
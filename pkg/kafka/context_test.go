@@ -0,0 +1,100 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"github.com/go-dev-frame/sponge/pkg/msgctx"
+)
+
+func newTraceContext() (context.Context, trace.SpanContext) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+		SpanID:     trace.SpanID{1, 2, 3, 4, 5, 6, 7, 8},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+	return msgctx.WithRequestID(ctx, "req-1"), sc
+}
+
+func toTestConsumerMessage(pm *sarama.ProducerMessage) *sarama.ConsumerMessage {
+	cm := &sarama.ConsumerMessage{Topic: pm.Topic}
+	for i := range pm.Headers {
+		cm.Headers = append(cm.Headers, &pm.Headers[i])
+	}
+	return cm
+}
+
+func TestSyncProducer_SendDataWithContext(t *testing.T) {
+	ctx, sc := newTraceContext()
+	mp := mocks.NewSyncProducer(t, nil)
+	var sent []*sarama.ProducerMessage
+	for i := 0; i < 2; i++ {
+		mp.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(pm *sarama.ProducerMessage) error {
+			sent = append(sent, pm)
+			return nil
+		})
+	}
+	p := &SyncProducer{Producer: mp}
+	defer p.Close()
+
+	_, _, err := p.SendDataWithContext(ctx, testTopic, "foo")
+	assert.NoError(t, err)
+	msg := &sarama.ProducerMessage{
+		Topic:   testTopic,
+		Value:   sarama.StringEncoder("bar"),
+		Headers: []sarama.RecordHeader{{Key: []byte("traceparent"), Value: []byte("old")}},
+	}
+	_, _, err = p.SendMessageWithContext(ctx, msg)
+	assert.NoError(t, err)
+	_, _, err = p.SendDataWithContext(ctx, testTopic, make(chan int))
+	assert.Error(t, err)
+
+	assert.Len(t, sent, 2)
+	assert.Len(t, sent[1].Headers, 2) // traceparent is replaced
+	for _, pm := range sent {
+		var handled bool
+		fn := CtxHandler(context.Background(), func(ctx context.Context, msg *sarama.ConsumerMessage) error {
+			handled = true
+			assert.Equal(t, sc.TraceID(), trace.SpanContextFromContext(ctx).TraceID())
+			assert.Equal(t, "req-1", msgctx.RequestID(ctx))
+			return nil
+		})
+		assert.NoError(t, fn(toTestConsumerMessage(pm)))
+		assert.True(t, handled)
+	}
+}
+
+func TestAsyncProducer_SendDataWithContext(t *testing.T) {
+	ctx, sc := newTraceContext()
+	mp := mocks.NewAsyncProducer(t, nil)
+	var sent []*sarama.ProducerMessage
+	for i := 0; i < 3; i++ {
+		mp.ExpectInputWithMessageCheckerFunctionAndSucceed(func(pm *sarama.ProducerMessage) error {
+			sent = append(sent, pm)
+			return nil
+		})
+	}
+	p := &AsyncProducer{Producer: mp, zapLogger: zap.NewNop(), exit: make(chan struct{})}
+
+	assert.NoError(t, p.SendDataWithContext(ctx, testTopic, "foo", []byte("bar")))
+	assert.NoError(t, p.SendMessageWithContext(ctx, &sarama.ProducerMessage{Topic: testTopic, Value: sarama.StringEncoder("baz")}))
+	assert.Error(t, p.SendDataWithContext(ctx, testTopic, make(chan int)))
+	assert.NoError(t, mp.Close())
+
+	assert.Len(t, sent, 3)
+	for _, pm := range sent {
+		ctx := MessageContext(context.Background(), toTestConsumerMessage(pm))
+		assert.Equal(t, sc.TraceID(), trace.SpanContextFromContext(ctx).TraceID())
+		assert.Equal(t, "req-1", msgctx.RequestID(ctx))
+	}
+}
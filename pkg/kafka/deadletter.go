@@ -0,0 +1,476 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/IBM/sarama"
+	"go.uber.org/zap"
+)
+
+// headers of the messages sent to the retry topics and the dead letter topic
+const (
+	HeaderOriginalTopic     = "x-original-topic"     // topic of the message when it failed for the first time
+	HeaderOriginalPartition = "x-original-partition" // partition of the message when it failed for the first time
+	HeaderOriginalOffset    = "x-original-offset"    // offset of the message when it failed for the first time
+	HeaderRetryTier         = "x-retry-tier"         // tier of the retry topic, starts from 1
+	HeaderRetryAt           = "x-retry-at"           // the message of the retry topic is handled after this time, unix milliseconds
+	HeaderAttempts          = "x-attempts"           // total number of attempts to handle the message
+	HeaderError             = "x-error"              // error of the last attempt
+	HeaderFailedAt          = "x-failed-at"          // time of the last attempt, RFC3339
+)
+
+var failureHeaders = []string{
+	HeaderOriginalTopic, HeaderOriginalPartition, HeaderOriginalOffset,
+	HeaderRetryTier, HeaderRetryAt, HeaderAttempts, HeaderError, HeaderFailedAt,
+}
+
+// RetryTopic returns the name of the retry topic of the tier, e.g. my-topic.retry.1
+func RetryTopic(topic string, tier int) string {
+	return fmt.Sprintf("%s.retry.%d", topic, tier)
+}
+
+// DeadLetterTopic returns the default name of the dead letter topic, e.g. my-topic.dlq
+func DeadLetterTopic(topic string) string {
+	return topic + ".dlq"
+}
+
+type nonRetryableError struct {
+	err error
+}
+
+func (e *nonRetryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *nonRetryableError) Unwrap() error {
+	return e.err
+}
+
+// NonRetryable wrap the error returned by HandleMessageFn to mark the message as a poison message,
+// it is sent to the dead letter topic directly without retries.
+func NonRetryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &nonRetryableError{err: err}
+}
+
+// IsNonRetryable check if the error is marked by NonRetryable
+func IsNonRetryable(err error) bool {
+	var e *nonRetryableError
+	return errors.As(err, &e)
+}
+
+// -------------------------------------------------------------------------------------------
+
+// DeadLetterOption set dead letter options.
+type DeadLetterOption func(*deadLetterOptions)
+
+type deadLetterOptions struct {
+	producer    *SyncProducer
+	topic       string          // default "<original topic>.dlq"
+	retryDelays []time.Duration // delay of each retry topic, default nil
+}
+
+func (o *deadLetterOptions) apply(opts ...DeadLetterOption) {
+	for _, opt := range opts {
+		opt(o)
+	}
+}
+
+// DeadLetterWithTopic set the dead letter topic of all consumed topics, default is "<original topic>.dlq".
+func DeadLetterWithTopic(topic string) DeadLetterOption {
+	return func(o *deadLetterOptions) {
+		o.topic = topic
+	}
+}
+
+// DeadLetterWithRetryTopics set the tiered retry topics, the failed message is sent to the retry topic
+// "<original topic>.retry.<tier>" and handled again after the delay of the tier, it is sent to the dead
+// letter topic after all tiers failed. The consumer group consumes the retry topics automatically,
+// the retry topics must exist or auto creation of topics is enabled.
+func DeadLetterWithRetryTopics(delays ...time.Duration) DeadLetterOption {
+	return func(o *deadLetterOptions) {
+		o.retryDelays = delays
+	}
+}
+
+// withRetryTopics returns the topics with their retry topics
+func (o *deadLetterOptions) withRetryTopics(topics []string) []string {
+	if o == nil || len(o.retryDelays) == 0 {
+		return topics
+	}
+
+	exists := make(map[string]bool, len(topics))
+	for _, topic := range topics {
+		exists[topic] = true
+	}
+	result := append([]string{}, topics...)
+	for _, topic := range topics {
+		for tier := 1; tier <= len(o.retryDelays); tier++ {
+			retryTopic := RetryTopic(topic, tier)
+			if !exists[retryTopic] {
+				exists[retryTopic] = true
+				result = append(result, retryTopic)
+			}
+		}
+	}
+	return result
+}
+
+// failedMessage create the message sent to the next retry topic or the dead letter topic
+func (o *deadLetterOptions) failedMessage(msg *sarama.ConsumerMessage, attempts int, handleErr error) *sarama.ProducerMessage {
+	headers := make([]sarama.RecordHeader, 0, len(msg.Headers)+len(failureHeaders))
+	for _, h := range msg.Headers {
+		if h != nil {
+			headers = append(headers, *h)
+		}
+	}
+
+	originalTopic := getHeader(msg.Headers, HeaderOriginalTopic)
+	if originalTopic == "" {
+		originalTopic = msg.Topic
+		headers = setHeader(headers, HeaderOriginalTopic, msg.Topic)
+		headers = setHeader(headers, HeaderOriginalPartition, strconv.Itoa(int(msg.Partition)))
+		headers = setHeader(headers, HeaderOriginalOffset, strconv.FormatInt(msg.Offset, 10))
+	}
+	prevAttempts, _ := strconv.Atoi(getHeader(msg.Headers, HeaderAttempts))
+	tier, _ := strconv.Atoi(getHeader(msg.Headers, HeaderRetryTier))
+	now := time.Now()
+	headers = setHeader(headers, HeaderAttempts, strconv.Itoa(prevAttempts+attempts))
+	headers = setHeader(headers, HeaderError, handleErr.Error())
+	headers = setHeader(headers, HeaderFailedAt, now.Format(time.RFC3339))
+
+	var topic string
+	if tier < len(o.retryDelays) && !IsNonRetryable(handleErr) {
+		topic = RetryTopic(originalTopic, tier+1)
+		headers = setHeader(headers, HeaderRetryTier, strconv.Itoa(tier+1))
+		headers = setHeader(headers, HeaderRetryAt, strconv.FormatInt(now.Add(o.retryDelays[tier]).UnixMilli(), 10))
+	} else {
+		topic = o.topic
+		if topic == "" {
+			topic = DeadLetterTopic(originalTopic)
+		}
+		headers = deleteHeader(headers, HeaderRetryAt)
+	}
+
+	pm := &sarama.ProducerMessage{
+		Topic:   topic,
+		Value:   sarama.ByteEncoder(msg.Value),
+		Headers: headers,
+	}
+	if msg.Key != nil {
+		pm.Key = sarama.ByteEncoder(msg.Key)
+	}
+	return pm
+}
+
+// handle the message with retries, wait until the retry time if the message comes from a retry topic,
+// returns the number of attempts and the error of the last attempt.
+func (h *defaultConsumerHandler) handle(ctx context.Context, msg *sarama.ConsumerMessage) (int, error) {
+	if err := waitRetryAt(ctx, msg); err != nil {
+		return 0, err
+	}
+	return h.retry(ctx, func() error { return h.call(msg) },
+		zap.String("topic", msg.Topic), zap.Int32("partition", msg.Partition), zap.Int64("offset", msg.Offset))
+}
+
+// retry fn with backoff until it succeeds or the retries are exhausted, returns the number of attempts
+// and the error of the last attempt.
+func (h *defaultConsumerHandler) retry(ctx context.Context, fn func() error, fields ...zap.Field) (int, error) {
+	backoff := h.retryBackoff
+	for i := 1; ; i++ {
+		err := fn()
+		if err == nil || i > h.maxRetries || IsNonRetryable(err) {
+			return i, err
+		}
+		h.zapLogger.Warn("failed to handle message, retry", append(fields, zap.Error(err), zap.Int("attempts", i))...)
+		select {
+		case <-ctx.Done():
+			return i, ctx.Err()
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > time.Minute {
+			backoff = time.Minute
+		}
+	}
+}
+
+// waitRetryAt wait until the retry time if the message comes from a retry topic
+func waitRetryAt(ctx context.Context, msg *sarama.ConsumerMessage) error {
+	v := getHeader(msg.Headers, HeaderRetryAt)
+	if v == "" {
+		return nil
+	}
+	ms, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return nil
+	}
+	if d := time.Until(time.UnixMilli(ms)); d > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(d):
+		}
+	}
+	return nil
+}
+
+// call the handler, the panic is converted to error
+func (h *defaultConsumerHandler) call(msg *sarama.ConsumerMessage) (err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			err = fmt.Errorf("panic: %v", e)
+		}
+	}()
+	return h.handleMessageFn(msg)
+}
+
+// sendFailed send the failed message to the retry topic or the dead letter topic, retry until it is sent or ctx is done.
+func (h *defaultConsumerHandler) sendFailed(ctx context.Context, msg *sarama.ConsumerMessage, attempts int, handleErr error) error {
+	pm := h.deadLetter.failedMessage(msg, attempts, handleErr)
+	backoff := time.Second
+	for {
+		_, _, err := h.deadLetter.producer.SendMessage(pm)
+		if err == nil {
+			h.zapLogger.Warn("failed to handle message, sent to "+pm.Topic, zap.Error(handleErr), zap.String("topic", msg.Topic),
+				zap.Int32("partition", msg.Partition), zap.Int64("offset", msg.Offset), zap.Int("attempts", attempts))
+			return nil
+		}
+		h.zapLogger.Error("failed to send message to "+pm.Topic, zap.Error(err), zap.String("topic", msg.Topic),
+			zap.Int32("partition", msg.Partition), zap.Int64("offset", msg.Offset))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > time.Minute {
+			backoff = time.Minute
+		}
+	}
+}
+
+// -------------------------------------------------------------------------------------------
+
+// ReplayOption set replay options.
+type ReplayOption func(*replayOptions)
+
+type replayOptions struct {
+	topic  string                                 // default is the original topic of the message
+	filter func(msg *sarama.ConsumerMessage) bool // default nil, replay all messages
+	config *sarama.Config                         // default sarama.NewConfig()
+}
+
+func (o *replayOptions) apply(opts ...ReplayOption) {
+	for _, opt := range opts {
+		opt(o)
+	}
+}
+
+// ReplayWithTopic set the topic the messages are sent to, default is the original topic of each message.
+func ReplayWithTopic(topic string) ReplayOption {
+	return func(o *replayOptions) {
+		o.topic = topic
+	}
+}
+
+// ReplayWithFilter set the filter, only the messages for which it returns true are replayed,
+// e.g. filter by HeaderFailedAt to avoid replaying the messages repeatedly.
+func ReplayWithFilter(fn func(msg *sarama.ConsumerMessage) bool) ReplayOption {
+	return func(o *replayOptions) {
+		o.filter = fn
+	}
+}
+
+// ReplayWithConfig set custom config of the client.
+func ReplayWithConfig(config *sarama.Config) ReplayOption {
+	return func(o *replayOptions) {
+		o.config = config
+	}
+}
+
+// ReplayDeadLetter read the messages of the dead letter topic from the oldest offset to the newest offset
+// at the time of calling, and send them back to their original topics, the failure headers are removed,
+// so the messages are handled like new messages. Returns the number of replayed messages.
+// The dead letter topic is not modified, calling it again replays the same messages again.
+func ReplayDeadLetter(ctx context.Context, addrs []string, dlqTopic string, producer *SyncProducer, opts ...ReplayOption) (int, error) {
+	o := &replayOptions{}
+	o.apply(opts...)
+	config := o.config
+	if config == nil {
+		config = sarama.NewConfig()
+	}
+
+	client, err := sarama.NewClient(addrs, config)
+	if err != nil {
+		return 0, err
+	}
+	defer client.Close()
+
+	partitions, err := client.Partitions(dlqTopic)
+	if err != nil {
+		return 0, err
+	}
+	ranges := make(map[int32][2]int64, len(partitions))
+	for _, partition := range partitions {
+		oldest, err := client.GetOffset(dlqTopic, partition, sarama.OffsetOldest)
+		if err != nil {
+			return 0, err
+		}
+		newest, err := client.GetOffset(dlqTopic, partition, sarama.OffsetNewest)
+		if err != nil {
+			return 0, err
+		}
+		if newest > oldest {
+			ranges[partition] = [2]int64{oldest, newest}
+		}
+	}
+
+	consumer, err := sarama.NewConsumerFromClient(client)
+	if err != nil {
+		return 0, err
+	}
+	defer consumer.Close()
+
+	return replay(ctx, consumer, producer, dlqTopic, ranges, o)
+}
+
+// replay the messages of the partitions in the offset range [start, end)
+func replay(ctx context.Context, consumer sarama.Consumer, producer *SyncProducer, dlqTopic string,
+	ranges map[int32][2]int64, o *replayOptions) (int, error) {
+	partitions := make([]int32, 0, len(ranges))
+	for partition := range ranges {
+		partitions = append(partitions, partition)
+	}
+	sort.Slice(partitions, func(i, j int) bool { return partitions[i] < partitions[j] })
+
+	n := 0
+	for _, partition := range partitions {
+		start, end := ranges[partition][0], ranges[partition][1]
+		pc, err := consumer.ConsumePartition(dlqTopic, partition, start)
+		if err != nil {
+			return n, err
+		}
+		count, err := replayPartition(ctx, pc, producer, end, o)
+		n += count
+		_ = pc.Close()
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// the interval of checking whether there are no more messages to replay, the offsets before the end
+// may never be delivered, e.g. they are transaction markers or the messages are removed by compaction.
+var replayIdleInterval = time.Second
+
+func replayPartition(ctx context.Context, pc sarama.PartitionConsumer, producer *SyncProducer, end int64, o *replayOptions) (int, error) {
+	idleTicker := time.NewTicker(replayIdleInterval)
+	defer idleTicker.Stop()
+
+	n := 0
+	received := false
+	for offset := int64(-1); offset < end-1; {
+		select {
+		case <-ctx.Done():
+			return n, ctx.Err()
+		case err := <-pc.Errors():
+			if err != nil {
+				return n, err
+			}
+		case <-idleTicker.C:
+			// the high water mark has been reached and no message is received during the interval
+			if !received && len(pc.Messages()) == 0 && pc.HighWaterMarkOffset() >= end {
+				return n, nil
+			}
+			received = false
+		case msg, ok := <-pc.Messages():
+			if !ok {
+				return n, errors.New("partition consumer is closed")
+			}
+			received = true
+			if msg.Offset >= end { // the message is sent after calling replay
+				return n, nil
+			}
+			offset = msg.Offset
+			if o.filter != nil && !o.filter(msg) {
+				continue
+			}
+			pm, err := replayMessage(msg, o.topic)
+			if err != nil {
+				return n, err
+			}
+			if _, _, err = producer.SendMessage(pm); err != nil {
+				return n, err
+			}
+			n++
+		}
+	}
+	return n, nil
+}
+
+func replayMessage(msg *sarama.ConsumerMessage, topic string) (*sarama.ProducerMessage, error) {
+	if topic == "" {
+		topic = getHeader(msg.Headers, HeaderOriginalTopic)
+		if topic == "" {
+			return nil, fmt.Errorf("original topic not found in message, partition=%d, offset=%d", msg.Partition, msg.Offset)
+		}
+	}
+
+	headers := make([]sarama.RecordHeader, 0, len(msg.Headers))
+	for _, h := range msg.Headers {
+		if h != nil {
+			headers = append(headers, *h)
+		}
+	}
+	for _, key := range failureHeaders {
+		headers = deleteHeader(headers, key)
+	}
+
+	pm := &sarama.ProducerMessage{
+		Topic:   topic,
+		Value:   sarama.ByteEncoder(msg.Value),
+		Headers: headers,
+	}
+	if msg.Key != nil {
+		pm.Key = sarama.ByteEncoder(msg.Key)
+	}
+	return pm, nil
+}
+
+// -------------------------------------------------------------------------------------------
+
+func getHeader(headers []*sarama.RecordHeader, key string) string {
+	for _, h := range headers {
+		if h != nil && string(h.Key) == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+func setHeader(headers []sarama.RecordHeader, key string, value string) []sarama.RecordHeader {
+	for i := range headers {
+		if string(headers[i].Key) == key {
+			headers[i].Value = []byte(value)
+			return headers
+		}
+	}
+	return append(headers, sarama.RecordHeader{Key: []byte(key), Value: []byte(value)})
+}
+
+func deleteHeader(headers []sarama.RecordHeader, key string) []sarama.RecordHeader {
+	result := headers[:0]
+	for _, h := range headers {
+		if string(h.Key) != key {
+			result = append(result, h)
+		}
+	}
+	return result
+}
@@ -0,0 +1,340 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+type testSession struct {
+	ctx     context.Context
+	mu      sync.Mutex
+	marked  []int64
+	commits int
+}
+
+func (s *testSession) Claims() map[string][]int32                                               { return nil }
+func (s *testSession) MemberID() string                                                         { return "" }
+func (s *testSession) GenerationID() int32                                                      { return 0 }
+func (s *testSession) MarkOffset(topic string, partition int32, offset int64, metadata string)  {}
+func (s *testSession) ResetOffset(topic string, partition int32, offset int64, metadata string) {}
+func (s *testSession) Context() context.Context                                                 { return s.ctx }
+func (s *testSession) MarkMessage(msg *sarama.ConsumerMessage, metadata string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.marked = append(s.marked, msg.Offset)
+}
+func (s *testSession) Commit() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.commits++
+}
+func (s *testSession) getMarked() []int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]int64{}, s.marked...)
+}
+
+type testClaim struct {
+	ch chan *sarama.ConsumerMessage
+}
+
+func (c *testClaim) Topic() string                            { return "" }
+func (c *testClaim) Partition() int32                         { return 0 }
+func (c *testClaim) InitialOffset() int64                     { return 0 }
+func (c *testClaim) HighWaterMarkOffset() int64               { return 0 }
+func (c *testClaim) Messages() <-chan *sarama.ConsumerMessage { return c.ch }
+
+func consumeTestMessages(t *testing.T, h *defaultConsumerHandler, msgs ...*sarama.ConsumerMessage) *testSession {
+	sess := &testSession{ctx: context.Background()}
+	claim := &testClaim{ch: make(chan *sarama.ConsumerMessage, len(msgs))}
+	for _, msg := range msgs {
+		claim.ch <- msg
+	}
+	close(claim.ch)
+	assert.NoError(t, h.ConsumeClaim(sess, claim))
+	return sess
+}
+
+func getProducerHeader(pm *sarama.ProducerMessage, key string) string {
+	for _, h := range pm.Headers {
+		if string(h.Key) == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+func toConsumerMessage(pm *sarama.ProducerMessage, offset int64) *sarama.ConsumerMessage {
+	cm := &sarama.ConsumerMessage{Topic: pm.Topic, Offset: offset}
+	cm.Value, _ = pm.Value.Encode()
+	if pm.Key != nil {
+		cm.Key, _ = pm.Key.Encode()
+	}
+	for i := range pm.Headers {
+		cm.Headers = append(cm.Headers, &pm.Headers[i])
+	}
+	return cm
+}
+
+func TestConsumerHandler_Retry(t *testing.T) {
+	count := 0
+	h := &defaultConsumerHandler{
+		ctx: context.Background(),
+		handleMessageFn: func(msg *sarama.ConsumerMessage) error {
+			count++
+			if count < 3 {
+				return errors.New("temporary error")
+			}
+			return nil
+		},
+		zapLogger:        zap.NewNop(),
+		autoCommitEnable: true,
+		maxRetries:       3,
+		retryBackoff:     time.Millisecond,
+	}
+
+	sess := consumeTestMessages(t, h, &sarama.ConsumerMessage{Topic: "my-topic", Offset: 1})
+	assert.Equal(t, 3, count)
+	assert.Equal(t, []int64{1}, sess.getMarked())
+
+	// without dead letter, the failed message is skipped
+	h.maxRetries = 1
+	h.handleMessageFn = func(msg *sarama.ConsumerMessage) error {
+		panic("bad message")
+	}
+	sess = consumeTestMessages(t, h, &sarama.ConsumerMessage{Topic: "my-topic", Offset: 2})
+	assert.Empty(t, sess.getMarked())
+}
+
+func TestConsumerHandler_DeadLetter(t *testing.T) {
+	mp := mocks.NewSyncProducer(t, nil)
+	var sent []*sarama.ProducerMessage
+	for i := 0; i < 4; i++ {
+		mp.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(pm *sarama.ProducerMessage) error {
+			sent = append(sent, pm)
+			return nil
+		})
+	}
+	defer mp.Close()
+
+	var handled []string
+	h := &defaultConsumerHandler{
+		ctx: context.Background(),
+		handleMessageFn: func(msg *sarama.ConsumerMessage) error {
+			handled = append(handled, msg.Topic)
+			if string(msg.Value) == "poison" {
+				return NonRetryable(errors.New("invalid message"))
+			}
+			return errors.New("service unavailable")
+		},
+		zapLogger:        zap.NewNop(),
+		autoCommitEnable: true,
+		maxRetries:       1,
+		retryBackoff:     time.Millisecond,
+		deadLetter: &deadLetterOptions{
+			producer:    &SyncProducer{Producer: mp},
+			retryDelays: []time.Duration{time.Millisecond * 10, time.Millisecond * 20},
+		},
+	}
+
+	msg := &sarama.ConsumerMessage{
+		Topic:     "my-topic",
+		Partition: 2,
+		Offset:    5,
+		Key:       []byte("key"),
+		Value:     []byte("foo"),
+		Headers:   []*sarama.RecordHeader{{Key: []byte("traceparent"), Value: []byte("00-trace")}},
+	}
+	sess := consumeTestMessages(t, h, msg)
+	assert.Equal(t, []int64{5}, sess.getMarked())
+	assert.Len(t, handled, 2)
+	assert.Len(t, sent, 1)
+	pm := sent[0]
+	assert.Equal(t, "my-topic.retry.1", pm.Topic)
+	assert.Equal(t, "00-trace", getProducerHeader(pm, "traceparent"))
+	assert.Equal(t, "my-topic", getProducerHeader(pm, HeaderOriginalTopic))
+	assert.Equal(t, "2", getProducerHeader(pm, HeaderOriginalPartition))
+	assert.Equal(t, "5", getProducerHeader(pm, HeaderOriginalOffset))
+	assert.Equal(t, "1", getProducerHeader(pm, HeaderRetryTier))
+	assert.Equal(t, "2", getProducerHeader(pm, HeaderAttempts))
+	assert.Equal(t, "service unavailable", getProducerHeader(pm, HeaderError))
+
+	// retry topic 1 --> retry topic 2, the message is handled after the delay
+	retryAt, _ := strconv.ParseInt(getProducerHeader(pm, HeaderRetryAt), 10, 64)
+	consumeTestMessages(t, h, toConsumerMessage(pm, 0))
+	assert.GreaterOrEqual(t, time.Now().UnixMilli(), retryAt)
+	assert.Len(t, sent, 2)
+	pm = sent[1]
+	assert.Equal(t, "my-topic.retry.2", pm.Topic)
+	assert.Equal(t, "my-topic", getProducerHeader(pm, HeaderOriginalTopic))
+	assert.Equal(t, "2", getProducerHeader(pm, HeaderRetryTier))
+	assert.Equal(t, "4", getProducerHeader(pm, HeaderAttempts))
+
+	// retry topic 2 --> dead letter topic
+	consumeTestMessages(t, h, toConsumerMessage(pm, 0))
+	assert.Len(t, sent, 3)
+	pm = sent[2]
+	assert.Equal(t, "my-topic.dlq", pm.Topic)
+	assert.Equal(t, "6", getProducerHeader(pm, HeaderAttempts))
+	assert.Equal(t, "", getProducerHeader(pm, HeaderRetryAt))
+	assert.Equal(t, []string{"my-topic", "my-topic", "my-topic.retry.1", "my-topic.retry.1", "my-topic.retry.2", "my-topic.retry.2"}, handled)
+
+	// poison message is sent to the dead letter topic directly
+	handled = nil
+	h.deadLetter.topic = "my-dlq"
+	sess = consumeTestMessages(t, h, &sarama.ConsumerMessage{Topic: "my-topic", Offset: 6, Value: []byte("poison")})
+	assert.Equal(t, []int64{6}, sess.getMarked())
+	assert.Len(t, handled, 1)
+	assert.Len(t, sent, 4)
+	assert.Equal(t, "my-dlq", sent[3].Topic)
+	assert.Equal(t, "invalid message", getProducerHeader(sent[3], HeaderError))
+}
+
+func TestConsumerHandler_DeadLetterSendFailed(t *testing.T) {
+	mp := mocks.NewSyncProducer(t, nil)
+	mp.ExpectSendMessageAndFail(sarama.ErrOutOfBrokers)
+	defer mp.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	h := &defaultConsumerHandler{
+		ctx: ctx,
+		handleMessageFn: func(msg *sarama.ConsumerMessage) error {
+			return errors.New("failed")
+		},
+		zapLogger:  zap.NewNop(),
+		deadLetter: &deadLetterOptions{producer: &SyncProducer{Producer: mp}},
+	}
+	time.AfterFunc(time.Millisecond*100, cancel)
+
+	// the message is not marked if it is not sent to the dead letter topic
+	sess := consumeTestMessages(t, h, &sarama.ConsumerMessage{Topic: "my-topic", Offset: 1})
+	assert.Empty(t, sess.getMarked())
+}
+
+func TestDeadLetterOptions(t *testing.T) {
+	o := defaultConsumerOptions()
+	o.apply(ConsumerWithRetry(3, time.Second), ConsumerWithDeadLetter(nil))
+	assert.Equal(t, 3, o.maxRetries)
+	assert.Equal(t, time.Second, o.retryBackoff)
+	assert.Nil(t, o.deadLetter)
+
+	o.apply(ConsumerWithDeadLetter(&SyncProducer{},
+		DeadLetterWithTopic("my-dlq"),
+		DeadLetterWithRetryTopics(time.Second, time.Minute),
+	))
+	assert.Equal(t, "my-dlq", o.deadLetter.topic)
+	assert.Equal(t, []string{"a", "b", "a.retry.1", "a.retry.2", "b.retry.1", "b.retry.2"},
+		o.deadLetter.withRetryTopics([]string{"a", "b"}))
+	assert.Equal(t, []string{"a"}, (*deadLetterOptions)(nil).withRetryTopics([]string{"a"}))
+
+	assert.Nil(t, NonRetryable(nil))
+	assert.True(t, IsNonRetryable(NonRetryable(errors.New("foo"))))
+	assert.False(t, IsNonRetryable(errors.New("foo")))
+}
+
+func TestReplay(t *testing.T) {
+	dlqTopic := "my-topic.dlq"
+	consumer := mocks.NewConsumer(t, nil)
+	pc := consumer.ExpectConsumePartition(dlqTopic, 0, 0)
+	for i, v := range []string{"foo", "bar", "skip"} {
+		pc.YieldMessage(&sarama.ConsumerMessage{
+			Key:   []byte(strconv.Itoa(i)),
+			Value: []byte(v),
+			Headers: []*sarama.RecordHeader{
+				{Key: []byte(HeaderOriginalTopic), Value: []byte("my-topic")},
+				{Key: []byte(HeaderAttempts), Value: []byte("3")},
+				{Key: []byte(HeaderError), Value: []byte("failed")},
+				{Key: []byte("traceparent"), Value: []byte("00-trace")},
+			},
+		})
+	}
+
+	mp := mocks.NewSyncProducer(t, nil)
+	var sent []*sarama.ProducerMessage
+	for i := 0; i < 2; i++ {
+		mp.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(pm *sarama.ProducerMessage) error {
+			sent = append(sent, pm)
+			return nil
+		})
+	}
+	defer mp.Close()
+
+	o := &replayOptions{}
+	o.apply(ReplayWithFilter(func(msg *sarama.ConsumerMessage) bool {
+		return string(msg.Value) != "skip"
+	}))
+	n, err := replay(context.Background(), consumer, &SyncProducer{Producer: mp}, dlqTopic, map[int32][2]int64{0: {0, 3}}, o)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Len(t, sent, 2)
+	for _, pm := range sent {
+		assert.Equal(t, "my-topic", pm.Topic)
+		assert.Len(t, pm.Headers, 1)
+		assert.Equal(t, "00-trace", getProducerHeader(pm, "traceparent"))
+	}
+
+	// original topic is not found
+	_, err = replayMessage(&sarama.ConsumerMessage{}, "")
+	assert.Error(t, err)
+	pm, err := replayMessage(&sarama.ConsumerMessage{}, "other-topic")
+	assert.NoError(t, err)
+	assert.Equal(t, "other-topic", pm.Topic)
+}
+
+// the high water mark of the mock partition consumer only counts the yielded messages
+type hwmPartitionConsumer struct {
+	sarama.PartitionConsumer
+	hwm int64
+}
+
+func (pc *hwmPartitionConsumer) HighWaterMarkOffset() int64 {
+	return pc.hwm
+}
+
+func TestReplayPartitionWithMissingOffsets(t *testing.T) {
+	defer func(d time.Duration) { replayIdleInterval = d }(replayIdleInterval)
+	replayIdleInterval = time.Millisecond * 50
+
+	dlqTopic := "my-topic.dlq"
+	consumer := mocks.NewConsumer(t, nil)
+	for _, v := range []string{"foo", "bar"} {
+		consumer.ExpectConsumePartition(dlqTopic, 0, 0).YieldMessage(&sarama.ConsumerMessage{
+			Value:   []byte(v),
+			Headers: []*sarama.RecordHeader{{Key: []byte(HeaderOriginalTopic), Value: []byte("my-topic")}},
+		})
+	}
+	pc, err := consumer.ConsumePartition(dlqTopic, 0, 0)
+	assert.NoError(t, err)
+	defer pc.Close()
+
+	mp := mocks.NewSyncProducer(t, nil)
+	mp.ExpectSendMessageAndSucceed()
+	mp.ExpectSendMessageAndSucceed()
+	defer mp.Close()
+
+	// the last offset is a transaction marker, it is never delivered
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	n, err := replayPartition(ctx, &hwmPartitionConsumer{PartitionConsumer: pc, hwm: 3}, &SyncProducer{Producer: mp}, 3, &replayOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+}
+
+func TestReplayDeadLetter(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	config := sarama.NewConfig()
+	config.Net.DialTimeout = time.Millisecond * 100
+	config.Metadata.Retry.Max = 0
+	_, err := ReplayDeadLetter(ctx, []string{"localhost:19092"}, "my-topic.dlq", nil, ReplayWithConfig(config), ReplayWithTopic("my-topic"))
+	t.Log(err)
+}
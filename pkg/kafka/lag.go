@@ -0,0 +1,324 @@
+package kafka
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// Lag of the consumer group on the topic
+type Lag struct {
+	GroupID    string     `json:"groupID"`
+	Topic      string     `json:"topic"`
+	Total      int64      `json:"total"`      // total lag of all partitions
+	Partitions []*Backlog `json:"partitions"` // lag of each partition
+}
+
+// LagAlertFn is called when the total lag of the consumer group on the topic exceeds the threshold
+// (isExceeded is true), and when it falls back to or below the threshold (isExceeded is false).
+type LagAlertFn func(lag *Lag, isExceeded bool)
+
+// LagOption set lag exporter options.
+type LagOption func(*lagOptions)
+
+type lagOptions struct {
+	groupTopics map[string][]string // consumer group and topics mapping
+	interval    time.Duration       // default 30s
+	threshold   int64               // default 0, no alert
+	alertFn     LagAlertFn          // default nil
+	gaugeName   string              // default kafka_consumer_group_lag
+	config      *sarama.Config      // default sarama.NewConfig()
+	zapLogger   *zap.Logger         // default NewProduction
+}
+
+func (o *lagOptions) apply(opts ...LagOption) {
+	for _, opt := range opts {
+		opt(o)
+	}
+}
+
+func defaultLagOptions() *lagOptions {
+	zapLogger, _ := zap.NewProduction()
+	return &lagOptions{
+		groupTopics: make(map[string][]string),
+		interval:    time.Second * 30,
+		gaugeName:   "kafka_consumer_group_lag",
+		zapLogger:   zapLogger,
+	}
+}
+
+// LagWithGroupTopics add the consumer group and its topics whose lag is collected, it can be set multiple times.
+func LagWithGroupTopics(groupID string, topics ...string) LagOption {
+	return func(o *lagOptions) {
+		if groupID != "" && len(topics) > 0 {
+			o.groupTopics[groupID] = append(o.groupTopics[groupID], topics...)
+		}
+	}
+}
+
+// LagWithInterval set the interval of collecting the lag, default is 30s
+func LagWithInterval(d time.Duration) LagOption {
+	return func(o *lagOptions) {
+		if d > 0 {
+			o.interval = d
+		}
+	}
+}
+
+// LagWithAlert set the threshold of the total lag of the consumer group on the topic and the alert function.
+func LagWithAlert(threshold int64, fn LagAlertFn) LagOption {
+	return func(o *lagOptions) {
+		if threshold > 0 && fn != nil {
+			o.threshold = threshold
+			o.alertFn = fn
+		}
+	}
+}
+
+// LagWithGaugeName set the name of the prometheus gauge, default is kafka_consumer_group_lag
+func LagWithGaugeName(name string) LagOption {
+	return func(o *lagOptions) {
+		if name != "" {
+			o.gaugeName = name
+		}
+	}
+}
+
+// LagWithConfig set custom config of the client.
+func LagWithConfig(config *sarama.Config) LagOption {
+	return func(o *lagOptions) {
+		o.config = config
+	}
+}
+
+// LagWithZapLogger set zapLogger.
+func LagWithZapLogger(zapLogger *zap.Logger) LagOption {
+	return func(o *lagOptions) {
+		if zapLogger != nil {
+			o.zapLogger = zapLogger
+		}
+	}
+}
+
+// -------------------------------------------------------------------------------------------
+
+type offsetGetter interface {
+	Partitions(topic string) ([]int32, error)
+	GetOffset(topic string, partition int32, time int64) (int64, error)
+}
+
+type groupOffsetLister interface {
+	ListConsumerGroupOffsets(group string, topicPartitions map[string][]int32) (*sarama.OffsetFetchResponse, error)
+}
+
+// LagExporter collects the lag of the consumer groups periodically, and exports the lag of each partition
+// as the prometheus gauge with the labels group, topic and partition. It implements app.IServer, it can be
+// added to the services of the app.
+type LagExporter struct {
+	client  offsetGetter
+	lister  groupOffsetLister
+	closeFn func() error
+	opts    *lagOptions
+	gauge   *prometheus.GaugeVec
+
+	refreshMu sync.Mutex
+	exceeded  map[string]bool // group/topic and whether the lag exceeds the threshold
+
+	mu   sync.RWMutex
+	lags []*Lag
+
+	stopCh chan struct{}
+	once   sync.Once
+}
+
+// InitLagExporter init lag exporter, call Start to collect the lag in the background, register the gauge
+// to the metrics endpoint by prometheus.MustRegister(e.GaugeVec()) for gin, or metrics.WithGaugeMetrics(e.GaugeVec())
+// for grpc server.
+func InitLagExporter(addrs []string, opts ...LagOption) (*LagExporter, error) {
+	o := defaultLagOptions()
+	o.apply(opts...)
+	if len(o.groupTopics) == 0 {
+		return nil, errors.New("consumer group and topics are not set, please set them by LagWithGroupTopics")
+	}
+	config := o.config
+	if config == nil {
+		config = sarama.NewConfig()
+	}
+
+	client, err := sarama.NewClient(addrs, config)
+	if err != nil {
+		return nil, err
+	}
+	admin, err := sarama.NewClusterAdminFromClient(client)
+	if err != nil {
+		_ = client.Close()
+		return nil, err
+	}
+
+	return newLagExporter(client, admin, admin.Close, o), nil
+}
+
+func newLagExporter(client offsetGetter, lister groupOffsetLister, closeFn func() error, o *lagOptions) *LagExporter {
+	return &LagExporter{
+		client:  client,
+		lister:  lister,
+		closeFn: closeFn,
+		opts:    o,
+		gauge: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: o.gaugeName,
+			Help: "Lag of the kafka consumer group on each partition.",
+		}, []string{"group", "topic", "partition"}),
+		exceeded: make(map[string]bool),
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// GaugeVec returns the prometheus gauge of the lag
+func (e *LagExporter) GaugeVec() *prometheus.GaugeVec {
+	return e.gauge
+}
+
+// Start collect the lag periodically, blocking until Stop is called.
+func (e *LagExporter) Start() error {
+	ticker := time.NewTicker(e.opts.interval)
+	defer ticker.Stop()
+
+	for {
+		_, _ = e.Refresh() // the error has been logged
+		select {
+		case <-e.stopCh:
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Stop collecting the lag and close the client
+func (e *LagExporter) Stop() error {
+	var err error
+	e.once.Do(func() {
+		close(e.stopCh)
+		if e.closeFn != nil {
+			err = e.closeFn()
+		}
+	})
+	return err
+}
+
+// String comment
+func (e *LagExporter) String() string {
+	return "kafka consumer group lag exporter"
+}
+
+// GetLags returns the lag of the last collection
+func (e *LagExporter) GetLags() []*Lag {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return append([]*Lag{}, e.lags...)
+}
+
+// Refresh collect the lag of all consumer groups and topics immediately, update the gauge and call the alert function,
+// the lag of the other groups and topics is still collected if one of them fails, the last error is returned.
+func (e *LagExporter) Refresh() ([]*Lag, error) {
+	e.refreshMu.Lock()
+	defer e.refreshMu.Unlock()
+
+	groups := make([]string, 0, len(e.opts.groupTopics))
+	for groupID := range e.opts.groupTopics {
+		groups = append(groups, groupID)
+	}
+	sort.Strings(groups)
+
+	var (
+		lags    []*Lag
+		lastErr error
+	)
+	for _, groupID := range groups {
+		for _, topic := range e.opts.groupTopics[groupID] {
+			lag, err := e.getLag(groupID, topic)
+			if err != nil {
+				lastErr = err
+				e.opts.zapLogger.Warn("failed to get kafka consumer group lag", zap.String("group", groupID), zap.String("topic", topic), zap.Error(err))
+				continue
+			}
+			for _, p := range lag.Partitions {
+				e.gauge.WithLabelValues(groupID, topic, strconv.Itoa(int(p.Partition))).Set(float64(p.Backlog))
+			}
+			e.checkThreshold(lag)
+			lags = append(lags, lag)
+		}
+	}
+
+	e.mu.Lock()
+	e.lags = lags
+	e.mu.Unlock()
+	return lags, lastErr
+}
+
+func (e *LagExporter) getLag(groupID string, topic string) (*Lag, error) {
+	partitions, err := e.client.Partitions(topic)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := e.lister.ListConsumerGroupOffsets(groupID, map[string][]int32{topic: partitions})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Err != sarama.ErrNoError {
+		return nil, resp.Err
+	}
+
+	lag := &Lag{GroupID: groupID, Topic: topic}
+	for _, partition := range partitions {
+		newest, err := e.client.GetOffset(topic, partition, sarama.OffsetNewest)
+		if err != nil {
+			return nil, err
+		}
+
+		next := int64(-1)
+		if block := resp.GetBlock(topic, partition); block != nil {
+			if block.Err != sarama.ErrNoError {
+				return nil, block.Err
+			}
+			next = block.Offset
+		}
+		// the consumer group has not committed the offset of the partition, all retained messages are lag
+		from := next
+		if from < 0 {
+			if from, err = e.client.GetOffset(topic, partition, sarama.OffsetOldest); err != nil {
+				return nil, err
+			}
+		}
+
+		backlog := newest - from
+		if backlog < 0 {
+			backlog = 0
+		}
+		lag.Total += backlog
+		lag.Partitions = append(lag.Partitions, &Backlog{
+			Partition:         partition,
+			Backlog:           backlog,
+			NextConsumeOffset: next,
+		})
+	}
+	return lag, nil
+}
+
+func (e *LagExporter) checkThreshold(lag *Lag) {
+	if e.opts.alertFn == nil {
+		return
+	}
+	key := lag.GroupID + "/" + lag.Topic
+	isExceeded := lag.Total > e.opts.threshold
+	if isExceeded == e.exceeded[key] {
+		return
+	}
+	e.exceeded[key] = isExceeded
+	e.opts.alertFn(lag, isExceeded) // the alert function decides how to report it, e.g. log or notify
+}
@@ -0,0 +1,138 @@
+package kafka
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type fakeOffsetClient struct {
+	mu        sync.Mutex
+	newest    map[int32]int64
+	committed map[int32]int64
+}
+
+func (c *fakeOffsetClient) Partitions(topic string) ([]int32, error) {
+	if topic == "unknown" {
+		return nil, sarama.ErrUnknownTopicOrPartition
+	}
+	return []int32{0, 1}, nil
+}
+
+func (c *fakeOffsetClient) GetOffset(topic string, partition int32, time int64) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if time == sarama.OffsetOldest {
+		return 10, nil
+	}
+	return c.newest[partition], nil
+}
+
+func (c *fakeOffsetClient) ListConsumerGroupOffsets(group string, topicPartitions map[string][]int32) (*sarama.OffsetFetchResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	resp := &sarama.OffsetFetchResponse{}
+	for topic, partitions := range topicPartitions {
+		for _, partition := range partitions {
+			offset, ok := c.committed[partition]
+			if !ok {
+				offset = -1
+			}
+			resp.AddBlock(topic, partition, &sarama.OffsetFetchResponseBlock{Offset: offset, Err: sarama.ErrNoError})
+		}
+	}
+	return resp, nil
+}
+
+func (c *fakeOffsetClient) set(partition int32, newest int64, committed int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.newest[partition] = newest
+	c.committed[partition] = committed
+}
+
+func TestLagExporter(t *testing.T) {
+	client := &fakeOffsetClient{
+		newest:    map[int32]int64{0: 100, 1: 50},
+		committed: map[int32]int64{0: 90}, // partition 1 has no committed offset
+	}
+
+	var mu sync.Mutex
+	var alerts []bool
+	core, logs := observer.New(zap.InfoLevel)
+	o := defaultLagOptions()
+	o.apply(
+		LagWithGroupTopics("my-group", "my-topic"),
+		LagWithGroupTopics("other-group", "unknown"),
+		LagWithInterval(time.Millisecond*50),
+		LagWithAlert(100, func(lag *Lag, isExceeded bool) {
+			mu.Lock()
+			defer mu.Unlock()
+			alerts = append(alerts, isExceeded)
+		}),
+		LagWithZapLogger(zap.New(core)),
+	)
+	e := newLagExporter(client, client, nil, o)
+
+	lags, err := e.Refresh()
+	assert.ErrorIs(t, err, sarama.ErrUnknownTopicOrPartition)
+	assert.Len(t, lags, 1)
+	assert.Equal(t, int64(50), lags[0].Total)
+	assert.Equal(t, int64(10), lags[0].Partitions[0].Backlog)
+	assert.Equal(t, int64(90), lags[0].Partitions[0].NextConsumeOffset)
+	assert.Equal(t, int64(40), lags[0].Partitions[1].Backlog)
+	assert.Equal(t, int64(-1), lags[0].Partitions[1].NextConsumeOffset)
+	assert.Equal(t, float64(40), testutil.ToFloat64(e.GaugeVec().WithLabelValues("my-group", "my-topic", "1")))
+	assert.Empty(t, alerts)
+
+	// the lag exceeds the threshold, then falls back
+	go func() {
+		_ = e.Start()
+	}()
+	client.set(1, 200, 110) // equal to the threshold, not exceeded
+	time.Sleep(time.Millisecond * 120)
+	assert.Equal(t, int64(100), e.GetLags()[0].Total)
+	mu.Lock()
+	assert.Empty(t, alerts)
+	mu.Unlock()
+	client.set(1, 200, 60)
+	time.Sleep(time.Millisecond * 120)
+	assert.Equal(t, int64(150), e.GetLags()[0].Total)
+	assert.Equal(t, float64(140), testutil.ToFloat64(e.GaugeVec().WithLabelValues("my-group", "my-topic", "1")))
+	client.set(1, 200, 200)
+	time.Sleep(time.Millisecond * 120)
+	assert.NoError(t, e.Stop())
+	assert.NoError(t, e.Stop())
+	assert.Equal(t, int64(10), e.GetLags()[0].Total)
+
+	mu.Lock()
+	assert.Equal(t, []bool{true, false}, alerts)
+	mu.Unlock()
+	assert.Zero(t, logs.FilterMessageSnippet("threshold").Len()) // reported by the alert function only
+	assert.Equal(t, "kafka consumer group lag exporter", e.String())
+}
+
+func TestInitLagExporter(t *testing.T) {
+	_, err := InitLagExporter(addrs)
+	assert.Error(t, err)
+
+	config := sarama.NewConfig()
+	config.Net.DialTimeout = time.Millisecond * 100
+	config.Metadata.Retry.Max = 0
+	e, err := InitLagExporter([]string{"localhost:19092"},
+		LagWithGroupTopics(groupID, testTopic),
+		LagWithConfig(config),
+		LagWithGaugeName("my_lag"),
+	)
+	if err != nil {
+		t.Log(err)
+		return
+	}
+	defer e.Stop()
+}
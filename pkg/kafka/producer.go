@@ -2,6 +2,7 @@
 package kafka
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 
@@ -53,29 +54,32 @@ func (p *SyncProducer) SendMessage(msg *sarama.ProducerMessage) (int32, int64, e
 	return p.Producer.SendMessage(msg)
 }
 
+// SendMessageWithContext sends a message to a topic, the trace context and request id of ctx are
+// injected into the message headers.
+func (p *SyncProducer) SendMessageWithContext(ctx context.Context, msg *sarama.ProducerMessage) (int32, int64, error) {
+	injectContext(ctx, msg)
+	return p.Producer.SendMessage(msg)
+}
+
 // SendData sends a message to a topic with multiple types of data.
 func (p *SyncProducer) SendData(topic string, data interface{}) (int32, int64, error) {
-	var msg *sarama.ProducerMessage
-	switch val := data.(type) {
-	case *sarama.ProducerMessage:
-		msg = val
-	case []byte:
-		msg = &sarama.ProducerMessage{Topic: topic, Value: sarama.ByteEncoder(val)}
-	case string:
-		msg = &sarama.ProducerMessage{Topic: topic, Value: sarama.StringEncoder(val)}
-	case *Message:
-		msg = &sarama.ProducerMessage{Topic: val.Topic, Value: sarama.ByteEncoder(val.Data), Key: sarama.ByteEncoder(val.Key)}
-	default:
-		buf, err := json.Marshal(data)
-		if err != nil {
-			return 0, 0, err
-		}
-		msg = &sarama.ProducerMessage{Topic: topic, Value: sarama.ByteEncoder(buf)}
+	msg, err := toProducerMessage(topic, data)
+	if err != nil {
+		return 0, 0, err
 	}
-
 	return p.Producer.SendMessage(msg)
 }
 
+// SendDataWithContext sends a message to a topic with multiple types of data, the trace context
+// and request id of ctx are injected into the message headers.
+func (p *SyncProducer) SendDataWithContext(ctx context.Context, topic string, data interface{}) (int32, int64, error) {
+	msg, err := toProducerMessage(topic, data)
+	if err != nil {
+		return 0, 0, err
+	}
+	return p.SendMessageWithContext(ctx, msg)
+}
+
 // Close closes the producer.
 func (p *SyncProducer) Close() error {
 	if p.Producer != nil {
@@ -153,32 +157,63 @@ func (p *AsyncProducer) SendMessage(messages ...*sarama.ProducerMessage) error {
 	return nil
 }
 
+// SendMessageWithContext sends messages to a topic, the trace context and request id of ctx are
+// injected into the message headers.
+func (p *AsyncProducer) SendMessageWithContext(ctx context.Context, messages ...*sarama.ProducerMessage) error {
+	for _, msg := range messages {
+		injectContext(ctx, msg)
+	}
+	return p.SendMessage(messages...)
+}
+
 // SendData sends messages to a topic with multiple types of data.
 func (p *AsyncProducer) SendData(topic string, multiData ...interface{}) error {
-	var messages []*sarama.ProducerMessage
+	messages, err := toProducerMessages(topic, multiData...)
+	if err != nil {
+		return err
+	}
+	return p.SendMessage(messages...)
+}
 
+// SendDataWithContext sends messages to a topic with multiple types of data, the trace context
+// and request id of ctx are injected into the message headers.
+func (p *AsyncProducer) SendDataWithContext(ctx context.Context, topic string, multiData ...interface{}) error {
+	messages, err := toProducerMessages(topic, multiData...)
+	if err != nil {
+		return err
+	}
+	return p.SendMessageWithContext(ctx, messages...)
+}
+
+func toProducerMessages(topic string, multiData ...interface{}) ([]*sarama.ProducerMessage, error) {
+	messages := make([]*sarama.ProducerMessage, 0, len(multiData))
 	for _, data := range multiData {
-		var msg *sarama.ProducerMessage
-		switch val := data.(type) {
-		case *sarama.ProducerMessage:
-			msg = val
-		case []byte:
-			msg = &sarama.ProducerMessage{Topic: topic, Value: sarama.ByteEncoder(val)}
-		case string:
-			msg = &sarama.ProducerMessage{Topic: topic, Value: sarama.StringEncoder(val)}
-		case *Message:
-			msg = &sarama.ProducerMessage{Topic: val.Topic, Value: sarama.ByteEncoder(val.Data), Key: sarama.ByteEncoder(val.Key)}
-		default:
-			buf, err := json.Marshal(data)
-			if err != nil {
-				return err
-			}
-			msg = &sarama.ProducerMessage{Topic: topic, Value: sarama.ByteEncoder(buf)}
+		msg, err := toProducerMessage(topic, data)
+		if err != nil {
+			return nil, err
 		}
 		messages = append(messages, msg)
 	}
+	return messages, nil
+}
 
-	return p.SendMessage(messages...)
+func toProducerMessage(topic string, data interface{}) (*sarama.ProducerMessage, error) {
+	switch val := data.(type) {
+	case *sarama.ProducerMessage:
+		return val, nil
+	case []byte:
+		return &sarama.ProducerMessage{Topic: topic, Value: sarama.ByteEncoder(val)}, nil
+	case string:
+		return &sarama.ProducerMessage{Topic: topic, Value: sarama.StringEncoder(val)}, nil
+	case *Message:
+		return &sarama.ProducerMessage{Topic: val.Topic, Value: sarama.ByteEncoder(val.Data), Key: sarama.ByteEncoder(val.Key)}, nil
+	default:
+		buf, err := json.Marshal(data)
+		if err != nil {
+			return nil, err
+		}
+		return &sarama.ProducerMessage{Topic: topic, Value: sarama.ByteEncoder(buf)}, nil
+	}
 }
 
 // handleResponse handles the response of async producer, if producer message failed, you can handle it, e.g. add to other queue to handle later.
@@ -37,6 +37,15 @@ func ExcludeDeleted(filter bson.M) bson.M {
 	return filter
 }
 
+// OnlyDeleted only soft deleted records
+func OnlyDeleted(filter bson.M) bson.M {
+	if filter == nil {
+		filter = bson.M{}
+	}
+	filter["deleted_at"] = bson.M{"$exists": true}
+	return filter
+}
+
 // EmbedUpdatedAt embed updated_at datetime column
 func EmbedUpdatedAt(update bson.M) bson.M {
 	updateM := bson.M{}
@@ -66,6 +75,15 @@ func EmbedDeletedAt(update bson.M) bson.M {
 	return updateM
 }
 
+// UnsetDeletedAt remove deleted_at column to restore soft deleted records
+func UnsetDeletedAt(update bson.M) bson.M {
+	if update == nil {
+		update = bson.M{}
+	}
+	update["$unset"] = bson.M{"deleted_at": ""}
+	return update
+}
+
 // ConvertToObjectIDs convert ids to objectIDs
 func ConvertToObjectIDs(ids []string) []primitive.ObjectID {
 	oids := []primitive.ObjectID{}
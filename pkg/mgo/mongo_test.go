@@ -69,6 +69,15 @@ func TestExcludeDeleted(t *testing.T) {
 	assert.NotNil(t, filter["deleted_at"])
 }
 
+func TestOnlyDeleted(t *testing.T) {
+	filter := bson.M{"foo": "bar"}
+	filter = OnlyDeleted(filter)
+	assert.Equal(t, bson.M{"$exists": true}, filter["deleted_at"])
+
+	filter = OnlyDeleted(nil)
+	assert.NotNil(t, filter["deleted_at"])
+}
+
 func TestEmbedUpdatedAt(t *testing.T) {
 	update := bson.M{"$set": bson.M{"foo": "bar"}}
 	update = EmbedUpdatedAt(update)
@@ -93,6 +102,15 @@ func TestEmbedDeletedAt(t *testing.T) {
 	assert.NotNil(t, m["deleted_at"])
 }
 
+func TestUnsetDeletedAt(t *testing.T) {
+	update := UnsetDeletedAt(bson.M{"$set": bson.M{"foo": "bar"}})
+	assert.Equal(t, bson.M{"deleted_at": ""}, update["$unset"])
+	assert.NotNil(t, update["$set"])
+
+	update = UnsetDeletedAt(nil)
+	assert.NotNil(t, update["$unset"])
+}
+
 func TestConvertToObjectIDs(t *testing.T) {
 	ids := []string{"65c9ae1b1378ae7f0787a039", "invalid_id"}
 	oids := ConvertToObjectIDs(ids)
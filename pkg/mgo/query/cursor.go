@@ -0,0 +1,481 @@
+package query
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	cursorTypeNull     = "n"
+	cursorTypeInt      = "i"
+	cursorTypeUint     = "u"
+	cursorTypeFloat    = "f"
+	cursorTypeBool     = "b"
+	cursorTypeTime     = "t"
+	cursorTypeObjectID = "o"
+	cursorTypeString   = "s"
+)
+
+var fieldNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*(\.[a-zA-Z_][a-zA-Z0-9_]*)*$`)
+
+// CursorInfo the cursor tokens of the adjacent pages, empty value means there is no adjacent page
+type CursorInfo struct {
+	Next string `json:"next"` // cursor of the next page
+	Prev string `json:"prev"` // cursor of the previous page
+}
+
+// cursor content, it is encoded as an opaque token
+type cursor struct {
+	Sort   string        `json:"s"`           // sort of the query that generated the cursor
+	Values []cursorValue `json:"v"`           // sort field values of the boundary document
+	Prev   bool          `json:"p,omitempty"` // true means turning to the previous page
+}
+
+type cursorValue struct {
+	Type  string `json:"t"`
+	Value string `json:"v,omitempty"`
+}
+
+type sortField struct {
+	name string
+	desc bool
+}
+
+// KeysetPage keyset (cursor) paging info, the documents are located by the sort field values
+// of the cursor instead of skip, so the query performance does not degrade on later pages.
+// Note: sort fields should not be null, and it is recommended to add an index to them.
+type KeysetPage struct {
+	limit  int
+	sort   string
+	fields []sortField
+	cursor *cursor // nil means the first page
+}
+
+// ConvertToKeysetPage converted to keyset page, the parameter keyFields are unique fields
+// appended to the sort fields to make the order stable, default is _id.
+func (p *Params) ConvertToKeysetPage(keyFields ...string) (*KeysetPage, error) {
+	if len(keyFields) == 0 {
+		keyFields = []string{oidName}
+	}
+
+	fields, err := parseSortFields(p.Sort, keyFields)
+	if err != nil {
+		return nil, err
+	}
+	strs := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if field.desc {
+			strs = append(strs, "-"+field.name)
+		} else {
+			strs = append(strs, field.name)
+		}
+	}
+
+	page := &KeysetPage{
+		limit:  NewPage(0, p.Limit, "").limit,
+		sort:   strings.Join(strs, ","),
+		fields: fields,
+	}
+
+	if p.Cursor != "" {
+		c, err := decodeCursor(p.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		if c.Sort != page.sort || len(c.Values) != len(fields) {
+			return nil, fmt.Errorf("cursor does not match the sort '%s'", page.sort)
+		}
+		page.cursor = c
+	}
+
+	return page, nil
+}
+
+// Limit number per page, query Limit()+1 documents to determine whether there is a next page
+func (k *KeysetPage) Limit() int {
+	return k.limit
+}
+
+// Sort get sort of the query, the direction is reversed when turning to the previous page
+func (k *KeysetPage) Sort() bson.D {
+	d := bson.D{}
+	for _, field := range k.fields {
+		if k.isDesc(field) {
+			d = append(d, bson.E{Key: field.name, Value: -1})
+		} else {
+			d = append(d, bson.E{Key: field.name, Value: 1})
+		}
+	}
+	return d
+}
+
+// Filter get the mongo filter of the cursor, the filter is empty on the first page,
+// e.g. sort=-age, the filter is {"$or": [{"age": {"$lt": v1}}, {"age": v1, "_id": {"$lt": v2}}]}
+func (k *KeysetPage) Filter() bson.M {
+	if k.cursor == nil {
+		return bson.M{}
+	}
+
+	ors := make([]bson.M, 0, len(k.fields))
+	for i, field := range k.fields {
+		m := bson.M{}
+		for j := 0; j < i; j++ {
+			m[k.fields[j].name] = k.cursor.Values[j].decode()
+		}
+		exp := "$gt"
+		if k.isDesc(field) {
+			exp = "$lt"
+		}
+		m[field.name] = bson.M{exp: k.cursor.Values[i].decode()}
+		ors = append(ors, m)
+	}
+
+	return bson.M{"$or": ors}
+}
+
+// Cursors trim the documents to the page size, restore their order when turning to the previous page,
+// and return the cursors of adjacent pages. the parameter records must be a pointer to the slice
+// of documents queried by Sort(), Filter() and Limit()+1.
+func (k *KeysetPage) Cursors(records interface{}) (*CursorInfo, error) {
+	rv := reflect.ValueOf(records)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Slice {
+		return nil, fmt.Errorf("records must be a pointer to slice, but got %T", records)
+	}
+	list := rv.Elem()
+
+	hasMore := list.Len() > k.limit
+	if hasMore {
+		list = list.Slice(0, k.limit)
+	}
+	isPrev := k.cursor != nil && k.cursor.Prev
+	if isPrev {
+		swap := reflect.Swapper(list.Interface())
+		for i, j := 0, list.Len()-1; i < j; i, j = i+1, j-1 {
+			swap(i, j)
+		}
+	}
+	rv.Elem().Set(list)
+
+	info := &CursorInfo{}
+	if list.Len() == 0 {
+		if k.cursor != nil { // allow turning back to the page where the cursor came from
+			token, err := encodeCursor(&cursor{Sort: k.sort, Values: k.cursor.Values, Prev: !isPrev})
+			if err != nil {
+				return nil, err
+			}
+			if isPrev {
+				info.Next = token
+			} else {
+				info.Prev = token
+			}
+		}
+		return info, nil
+	}
+
+	var err error
+	if hasMore || isPrev {
+		info.Next, err = k.encode(list.Index(list.Len()-1), false)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if (hasMore && isPrev) || (k.cursor != nil && !isPrev) {
+		info.Prev, err = k.encode(list.Index(0), true)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return info, nil
+}
+
+// Projection add the sort fields to the projection if they are not included, so that Cursors
+// can get the values of the boundary document, empty projection means all fields are returned.
+func (k *KeysetPage) Projection(projection bson.M) bson.M {
+	if len(projection) == 0 {
+		return projection
+	}
+
+	result := make(bson.M, len(projection)+len(k.fields))
+	for key, value := range projection {
+		result[key] = value
+	}
+	for _, field := range k.fields {
+		included := false
+		for key := range result {
+			if key == field.name || strings.HasPrefix(field.name, key+".") {
+				included = true
+				break
+			}
+		}
+		if included {
+			continue
+		}
+		// the sub fields conflict with the parent field in projection
+		for key := range result {
+			if strings.HasPrefix(key, field.name+".") {
+				delete(result, key)
+			}
+		}
+		result[field.name] = 1
+	}
+	return result
+}
+
+func (k *KeysetPage) isDesc(field sortField) bool {
+	if k.cursor != nil && k.cursor.Prev {
+		return !field.desc
+	}
+	return field.desc
+}
+
+func (k *KeysetPage) encode(record reflect.Value, isPrev bool) (string, error) {
+	c := &cursor{Sort: k.sort, Prev: isPrev}
+	for _, field := range k.fields {
+		value, ok := getFieldValue(record, strings.Split(field.name, "."))
+		if !ok {
+			return "", fmt.Errorf("field '%s' not found in document %s", field.name, record.Type())
+		}
+		c.Values = append(c.Values, newCursorValue(value))
+	}
+	return encodeCursor(c)
+}
+
+func parseSortFields(sort string, keyFields []string) ([]sortField, error) {
+	sort = strings.Replace(sort, " ", "", -1)
+	if sort == "" {
+		sort = "-" + strings.Join(keyFields, ",-")
+	}
+
+	var (
+		fields []sortField
+		exists = map[string]bool{}
+	)
+	for _, name := range strings.Split(sort, ",") {
+		field := sortField{name: name}
+		if strings.HasPrefix(name, "-") {
+			field = sortField{name: name[1:], desc: true}
+		}
+		if field.name == "id" {
+			field.name = oidName
+		}
+		if !fieldNameRegexp.MatchString(field.name) {
+			return nil, fmt.Errorf("invalid sort field '%s'", field.name)
+		}
+		if exists[field.name] {
+			continue
+		}
+		exists[field.name] = true
+		fields = append(fields, field)
+	}
+
+	// append the key fields to make the order stable
+	desc := fields[len(fields)-1].desc
+	for _, name := range keyFields {
+		if name == "id" {
+			name = oidName
+		}
+		if !fieldNameRegexp.MatchString(name) {
+			return nil, fmt.Errorf("invalid key field '%s'", name)
+		}
+		if exists[name] {
+			continue
+		}
+		exists[name] = true
+		fields = append(fields, sortField{name: name, desc: desc})
+	}
+
+	return fields, nil
+}
+
+func encodeCursor(c *cursor) (string, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeCursor(token string) (*cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor '%s'", token)
+	}
+	c := &cursor{}
+	if err = json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("invalid cursor '%s'", token)
+	}
+	for _, v := range c.Values {
+		if err = v.check(); err != nil {
+			return nil, fmt.Errorf("invalid cursor '%s', %v", token, err)
+		}
+	}
+	return c, nil
+}
+
+func newCursorValue(value interface{}) cursorValue {
+	rv := reflect.ValueOf(value)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return cursorValue{Type: cursorTypeNull}
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return cursorValue{Type: cursorTypeNull}
+	}
+
+	switch v := rv.Interface().(type) {
+	case time.Time:
+		return cursorValue{Type: cursorTypeTime, Value: v.Format(time.RFC3339Nano)}
+	case primitive.DateTime:
+		return cursorValue{Type: cursorTypeTime, Value: v.Time().Format(time.RFC3339Nano)}
+	case primitive.ObjectID:
+		return cursorValue{Type: cursorTypeObjectID, Value: v.Hex()}
+	}
+	switch rv.Kind() { //nolint
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cursorValue{Type: cursorTypeInt, Value: strconv.FormatInt(rv.Int(), 10)}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return cursorValue{Type: cursorTypeUint, Value: strconv.FormatUint(rv.Uint(), 10)}
+	case reflect.Float32, reflect.Float64:
+		return cursorValue{Type: cursorTypeFloat, Value: strconv.FormatFloat(rv.Float(), 'g', -1, 64)}
+	case reflect.Bool:
+		return cursorValue{Type: cursorTypeBool, Value: strconv.FormatBool(rv.Bool())}
+	case reflect.String:
+		return cursorValue{Type: cursorTypeString, Value: rv.String()}
+	}
+
+	return cursorValue{Type: cursorTypeString, Value: fmt.Sprintf("%v", rv.Interface())}
+}
+
+func (v cursorValue) check() error {
+	var err error
+	switch v.Type {
+	case cursorTypeNull, cursorTypeString:
+	case cursorTypeInt:
+		_, err = strconv.ParseInt(v.Value, 10, 64)
+	case cursorTypeUint:
+		_, err = strconv.ParseUint(v.Value, 10, 64)
+	case cursorTypeFloat:
+		_, err = strconv.ParseFloat(v.Value, 64)
+	case cursorTypeBool:
+		_, err = strconv.ParseBool(v.Value)
+	case cursorTypeTime:
+		_, err = time.Parse(time.RFC3339Nano, v.Value)
+	case cursorTypeObjectID:
+		_, err = primitive.ObjectIDFromHex(v.Value)
+	default:
+		err = fmt.Errorf("unknown value type '%s'", v.Type)
+	}
+	return err
+}
+
+// decode the value, it has been checked when decoding cursor
+func (v cursorValue) decode() interface{} {
+	switch v.Type {
+	case cursorTypeNull:
+		return nil
+	case cursorTypeInt:
+		i, _ := strconv.ParseInt(v.Value, 10, 64)
+		return i
+	case cursorTypeUint:
+		u, _ := strconv.ParseUint(v.Value, 10, 64)
+		return u
+	case cursorTypeFloat:
+		f, _ := strconv.ParseFloat(v.Value, 64)
+		return f
+	case cursorTypeBool:
+		b, _ := strconv.ParseBool(v.Value)
+		return b
+	case cursorTypeTime:
+		t, _ := time.Parse(time.RFC3339Nano, v.Value)
+		return t
+	case cursorTypeObjectID:
+		oid, _ := primitive.ObjectIDFromHex(v.Value)
+		return oid
+	}
+	return v.Value
+}
+
+// get the field value from the document, the field name of struct field is
+// taken from the bson tag, otherwise it is the lowercase of struct field name.
+func getFieldValue(record reflect.Value, path []string) (interface{}, bool) {
+	if !record.IsValid() { // nil value of map
+		return nil, len(path) == 0
+	}
+	for record.Kind() == reflect.Ptr || record.Kind() == reflect.Interface {
+		if record.IsNil() {
+			return nil, len(path) == 0
+		}
+		record = record.Elem()
+	}
+	if len(path) == 0 {
+		return record.Interface(), true
+	}
+
+	switch v := record.Interface().(type) {
+	case bson.D:
+		for _, e := range v {
+			if e.Key == path[0] {
+				return getFieldValue(reflect.ValueOf(e.Value), path[1:])
+			}
+		}
+		return nil, false
+	case bson.M:
+		value, ok := v[path[0]]
+		if !ok {
+			return nil, false
+		}
+		return getFieldValue(reflect.ValueOf(value), path[1:])
+	}
+
+	switch record.Kind() { //nolint
+	case reflect.Map:
+		if record.Type().Key().Kind() != reflect.String {
+			return nil, false
+		}
+		value := record.MapIndex(reflect.ValueOf(path[0]).Convert(record.Type().Key()))
+		if !value.IsValid() {
+			return nil, false
+		}
+		return getFieldValue(value, path[1:])
+
+	case reflect.Struct:
+		rt := record.Type()
+		for i := 0; i < rt.NumField(); i++ {
+			field := rt.Field(i)
+			tag := field.Tag.Get("bson")
+			if tag == "-" {
+				continue
+			}
+			name, opts, _ := strings.Cut(tag, ",")
+			if strings.Contains(opts, "inline") {
+				if value, ok := getFieldValue(record.Field(i), path); ok {
+					return value, true
+				}
+				continue
+			}
+			if field.PkgPath != "" { // unexported field
+				continue
+			}
+			if name == "" {
+				name = strings.ToLower(field.Name)
+			}
+			if name == path[0] {
+				return getFieldValue(record.Field(i), path[1:])
+			}
+		}
+	}
+
+	return nil, false
+}
@@ -3,8 +3,10 @@ package query
 
 import (
 	"fmt"
+	"reflect"
 	"regexp"
 	"strings"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -33,6 +35,20 @@ const (
 	Like = "like"
 	// In include
 	In = "in"
+	// NotIn not include
+	NotIn = "notin"
+	// Between in the range of two values, including the boundary values
+	Between = "between"
+	// IsNull is null or not exists, the value is ignored
+	IsNull = "isnull"
+	// NotNull is not null and exists, the value is ignored
+	NotNull = "notnull"
+	// NotLike fuzzy lookup of not include
+	NotLike = "notlike"
+	// Prefix fuzzy lookup of prefix
+	Prefix = "prefix"
+	// Suffix fuzzy lookup of suffix
+	Suffix = "suffix"
 
 	// AND logic and
 	AND        string = "and" //nolint
@@ -62,8 +78,20 @@ var expMap = map[string]string{
 	lteSymbol: lteSymbol,
 	Like:      Like,
 	In:        In,
+	NotIn:     NotIn,
+	Between:   Between,
+	IsNull:    IsNull,
+	NotNull:   NotNull,
+	NotLike:   NotLike,
+	Prefix:    Prefix,
+	Suffix:    Suffix,
 }
 
+var fieldRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*(\.[a-zA-Z0-9_]+)*(:oid)?$`)
+
+// the max nesting depth of group
+const maxGroupDepth = 10
+
 var logicMap = map[string]string{
 	AND:        andSymbol1,
 	andSymbol1: andSymbol1,
@@ -79,26 +107,51 @@ type Params struct {
 	Limit int    `json:"limit" form:"limit" binding:"gte=1"`
 	Sort  string `json:"sort,omitempty" form:"sort" binding:""`
 
+	// cursor of keyset paging, it is returned by the previous query, empty means the first page, not required
+	Cursor string `json:"cursor,omitempty" form:"cursor"`
+
 	Columns []Column `json:"columns,omitempty" form:"columns"` // not required
 
+	// conditions of tree structure, it is combined with Columns by and, not required
+	Where *Group `json:"where,omitempty" form:"-"`
+
+	// fields to be returned, multiple fields separated by comma, empty means all fields, not required
+	Fields string `json:"fields,omitempty" form:"fields"`
+
 	// Deprecated: use Limit instead in sponge version v1.8.6, will remove in the future
 	Size int `json:"size" form:"size"`
 }
 
 // Column query info
 type Column struct {
-	Name  string      `json:"name" form:"name"`   // column name
-	Exp   string      `json:"exp" form:"exp"`     // expressions, default value is "=", support =, !=, >, >=, <, <=, like, in
-	Value interface{} `json:"value" form:"value"` // column value
+	// column name, the path of embedded document is separated by . or ->>, e.g. profile.address.city
+	Name  string      `json:"name" form:"name"`
+	Exp   string      `json:"exp" form:"exp"`     // expressions, default value is "=", support =, !=, >, >=, <, <=, like, in, notin, between, isnull, notnull, notlike, prefix, suffix
+	Value interface{} `json:"value" form:"value"` // column value, if exp=in, notin or between, multiple values are separated by commas
 	Logic string      `json:"logic" form:"logic"` // logical type, defaults to and when the value is null, with &(and), ||(or)
 }
 
+// Group conditions of tree structure, the columns and sub groups are combined by the logical type of group,
+// e.g. (a=1 OR b=2) AND (c=3 OR d=4) is expressed as
+// {"logic":"and","groups":[{"logic":"or","columns":[a=1,b=2]},{"logic":"or","columns":[c=3,d=4]}]}
+type Group struct {
+	Logic   string   `json:"logic,omitempty" form:"logic"`     // logical type, defaults to and when the value is null, with &(and), ||(or)
+	Columns []Column `json:"columns,omitempty" form:"columns"` // column conditions, the logical type of column is ignored
+	Groups  []Group  `json:"groups,omitempty" form:"groups"`   // sub groups
+}
+
 func (c *Column) checkValid() error {
 	if c.Name == "" {
 		return fmt.Errorf("field 'name' cannot be empty")
 	}
+	if !fieldRegexp.MatchString(strings.ReplaceAll(c.Name, "->>", ".")) {
+		return fmt.Errorf("invalid column name '%s'", c.Name)
+	}
 	if c.Value == nil {
-		return fmt.Errorf("field 'value' cannot be nil")
+		exp := strings.ToLower(c.Exp)
+		if exp != IsNull && exp != NotNull {
+			return fmt.Errorf("field 'value' cannot be nil")
+		}
 	}
 	return nil
 }
@@ -120,6 +173,7 @@ func (c *Column) convert() error {
 		return err
 	}
 
+	c.Name = strings.ReplaceAll(c.Name, "->>", ".")
 	if c.Name == "id" || c.Name == "_id" {
 		if str, ok := c.Value.(string); ok {
 			c.Name = "_id"
@@ -137,6 +191,9 @@ func (c *Column) convert() error {
 	}
 	if v, ok := expMap[strings.ToLower(c.Exp)]; ok { //nolint
 		c.Exp = v
+		if err := checkValue(c.Exp, c.Value); err != nil {
+			return err
+		}
 		switch c.Exp {
 		//case eqSymbol:
 		case neqSymbol:
@@ -152,17 +209,40 @@ func (c *Column) convert() error {
 		case Like:
 			escapedValue := regexp.QuoteMeta(fmt.Sprintf("%v", c.Value))
 			c.Value = bson.M{"$regex": escapedValue, "$options": "i"}
+		case NotLike:
+			escapedValue := regexp.QuoteMeta(fmt.Sprintf("%v", c.Value))
+			c.Value = bson.M{"$not": primitive.Regex{Pattern: escapedValue, Options: "i"}}
+		case Prefix:
+			escapedValue := regexp.QuoteMeta(fmt.Sprintf("%v", c.Value))
+			c.Value = bson.M{"$regex": "^" + escapedValue, "$options": "i"}
+		case Suffix:
+			escapedValue := regexp.QuoteMeta(fmt.Sprintf("%v", c.Value))
+			c.Value = bson.M{"$regex": escapedValue + "$", "$options": "i"}
 		case In:
-			val, ok := c.Value.(string)
-			if !ok {
-				return fmt.Errorf("invalid value type '%s'", c.Value)
-			}
-			values := []interface{}{}
-			ss := strings.Split(val, ",")
-			for _, s := range ss {
-				values = append(values, s)
+			values, err := splitValues(c.Value)
+			if err != nil {
+				return err
 			}
 			c.Value = bson.M{"$in": values}
+		case NotIn:
+			values, err := splitValues(c.Value)
+			if err != nil {
+				return err
+			}
+			c.Value = bson.M{"$nin": values}
+		case Between:
+			values, err := splitValues(c.Value)
+			if err != nil {
+				return err
+			}
+			if len(values) != 2 {
+				return fmt.Errorf("exp type 'between' requires 2 values, but got %d", len(values))
+			}
+			c.Value = bson.M{"$gte": values[0], "$lte": values[1]}
+		case IsNull:
+			c.Value = nil
+		case NotNull:
+			c.Value = bson.M{"$ne": nil}
 		}
 	} else {
 		return fmt.Errorf("unknown exp type '%s'", c.Exp)
@@ -171,6 +251,84 @@ func (c *Column) convert() error {
 	return c.convertLogic()
 }
 
+var (
+	objectIDType   = reflect.TypeOf(primitive.ObjectID{})
+	timeType       = reflect.TypeOf(time.Time{})
+	decimal128Type = reflect.TypeOf(primitive.Decimal128{})
+)
+
+// the value from user input is not allowed to be a document, otherwise it is parsed as the operators
+// of mongodb, e.g. {"name":"email","value":{"$ne":""}} matches all records, the slice is only allowed
+// by in, notin and between, and its elements must be scalar values.
+func checkValue(exp string, value interface{}) error {
+	switch exp {
+	case IsNull, NotNull: // the value is ignored
+		return nil
+	case In, NotIn, Between:
+		if _, ok := value.(string); ok {
+			return nil
+		}
+		rv := reflect.ValueOf(value)
+		if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+			if _, ok := value.(bson.D); ok {
+				return fmt.Errorf("invalid value type '%T' of exp type '%s'", value, exp)
+			}
+			for i := 0; i < rv.Len(); i++ {
+				if !isScalarValue(rv.Index(i).Interface()) {
+					return fmt.Errorf("invalid value type '%T' in the values of exp type '%s'", rv.Index(i).Interface(), exp)
+				}
+			}
+			return nil
+		}
+	}
+
+	if !isScalarValue(value) {
+		return fmt.Errorf("invalid value type '%T' of exp type '%s'", value, exp)
+	}
+	return nil
+}
+
+// scalar values are nil, bool, number, string and the scalar types of bson such as ObjectID and time
+func isScalarValue(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	rt := reflect.TypeOf(value)
+	for rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	switch rt {
+	case objectIDType, timeType, decimal128Type:
+		return true
+	}
+	switch rt.Kind() {
+	case reflect.Map, reflect.Struct, reflect.Slice, reflect.Array, reflect.Interface,
+		reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		return false
+	}
+	return true
+}
+
+func splitValues(value interface{}) ([]interface{}, error) {
+	if val, ok := value.(string); ok {
+		values := []interface{}{}
+		for _, s := range strings.Split(val, ",") {
+			values = append(values, s)
+		}
+		return values, nil
+	}
+
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, fmt.Errorf("invalid value type '%v'", value)
+	}
+	values := make([]interface{}, 0, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		values = append(values, rv.Index(i).Interface())
+	}
+	return values, nil
+}
+
 // ConvertToPage converted to page
 func (p *Params) ConvertToPage() (sort bson.D, limit int, skip int) { //nolint
 	page := NewPage(p.Page, p.Limit, p.Sort)
@@ -180,9 +338,45 @@ func (p *Params) ConvertToPage() (sort bson.D, limit int, skip int) { //nolint
 	return //nolint
 }
 
-// ConvertToMongoFilter conversion to mongo-compliant parameters based on the Columns parameter
-// ignore the logical type of the last column, whether it is a one-column or multi-column query
-func (p *Params) ConvertToMongoFilter() (bson.M, error) {
+// ConvertToMongoFilter conversion to mongo-compliant parameters based on the Columns and Where parameters
+// ignore the logical type of the last column, whether it is a one-column or multi-column query,
+// if the whitelist is set, the columns of conditions and sort are checked by the whitelist.
+func (p *Params) ConvertToMongoFilter(opts ...Option) (bson.M, error) {
+	o := defaultOptions()
+	o.apply(opts...)
+	if w := o.whitelist; w != nil {
+		if err := w.checkSort(p.Sort); err != nil {
+			return nil, err
+		}
+		if err := w.checkColumns(p.Columns); err != nil {
+			return nil, err
+		}
+		if p.Where != nil {
+			if err := w.checkGroup(p.Where); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	filter, err := p.convertColumns()
+	if err != nil || p.Where == nil {
+		return filter, err
+	}
+
+	whereFilter, err := p.Where.ConvertToMongo()
+	if err != nil {
+		return nil, err
+	}
+	if len(whereFilter) == 0 {
+		return filter, nil
+	}
+	if len(filter) == 0 {
+		return whereFilter, nil
+	}
+	return bson.M{"$and": []bson.M{filter, whereFilter}}, nil
+}
+
+func (p *Params) convertColumns() (bson.M, error) {
 	filter := bson.M{}
 	l := len(p.Columns)
 	switch l {
@@ -338,6 +532,55 @@ func groupingIndex(l int, orIndexes []int) [][]int {
 	return groupIndexes
 }
 
+// ConvertToMongo conversion to mongo-compliant parameters based on the conditions of tree structure
+func (g *Group) ConvertToMongo() (bson.M, error) {
+	return g.convertToMongo(1)
+}
+
+func (g *Group) convertToMongo(depth int) (bson.M, error) {
+	if depth > maxGroupDepth {
+		return nil, fmt.Errorf("the nesting depth of group cannot exceed %d", maxGroupDepth)
+	}
+
+	logic := g.Logic
+	if logic == "" {
+		logic = AND
+	}
+	logic, ok := logicMap[strings.ToLower(logic)]
+	if !ok {
+		return nil, fmt.Errorf("unknown logic type '%s'", g.Logic)
+	}
+
+	conditions := []bson.M{}
+	for _, column := range g.Columns {
+		if err := column.convert(); err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, bson.M{column.Name: column.Value})
+	}
+	for i := range g.Groups {
+		condition, err := g.Groups[i].convertToMongo(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if len(condition) == 0 { // ignore empty group
+			continue
+		}
+		conditions = append(conditions, condition)
+	}
+
+	switch len(conditions) {
+	case 0:
+		return bson.M{}, nil
+	case 1:
+		return conditions[0], nil
+	}
+	if logic == orSymbol1 {
+		return bson.M{"$or": conditions}, nil
+	}
+	return bson.M{"$and": conditions}, nil
+}
+
 // Conditions query conditions
 type Conditions struct {
 	Columns []Column `json:"columns" form:"columns" binding:"min=1"` // columns info
@@ -371,7 +614,7 @@ func (c *Conditions) CheckValid() error {
 
 // ConvertToMongo conversion to mongo-compliant parameters based on the Columns parameter
 // ignore the logical type of the last column, whether it is a one-column or multi-column query
-func (c *Conditions) ConvertToMongo() (bson.M, error) {
+func (c *Conditions) ConvertToMongo(opts ...Option) (bson.M, error) {
 	p := &Params{Columns: c.Columns}
-	return p.ConvertToMongoFilter()
+	return p.ConvertToMongoFilter(opts...)
 }
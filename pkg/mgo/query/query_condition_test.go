@@ -1,8 +1,10 @@
 package query
 
 import (
+	"encoding/json"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"go.mongodb.org/mongo-driver/bson"
@@ -667,3 +669,317 @@ func Test_getSort(t *testing.T) {
 		t.Log(d)
 	}
 }
+
+type cursorUser struct {
+	ID        primitive.ObjectID `bson:"_id"`
+	Age       int                `bson:"age"`
+	DeletedAt *time.Time         `bson:"deleted_at,omitempty"`
+	Profile   struct {
+		Score float64 // field name is score
+	} `bson:"profile"`
+}
+
+func TestParams_ConvertToKeysetPage(t *testing.T) {
+	ids := []primitive.ObjectID{primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()}
+
+	p := &Params{Limit: 2, Sort: "-age"}
+	page, err := p.ConvertToKeysetPage()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, page.Limit())
+	assert.Equal(t, bson.D{{Key: "age", Value: -1}, {Key: "_id", Value: -1}}, page.Sort())
+	assert.Equal(t, bson.M{}, page.Filter())
+
+	// first page, query Limit()+1 documents
+	records := []*cursorUser{{ID: ids[2], Age: 30}, {ID: ids[1], Age: 30}, {ID: ids[0], Age: 20}}
+	info, err := page.Cursors(&records)
+	assert.NoError(t, err)
+	assert.Len(t, records, 2)
+	assert.NotEmpty(t, info.Next)
+	assert.Empty(t, info.Prev)
+
+	// next page
+	p.Cursor = info.Next
+	page, err = p.ConvertToKeysetPage()
+	assert.NoError(t, err)
+	assert.Equal(t, bson.M{"$or": []bson.M{
+		{"age": bson.M{"$lt": int64(30)}},
+		{"age": int64(30), "_id": bson.M{"$lt": ids[1]}},
+	}}, page.Filter())
+	records = []*cursorUser{{ID: ids[0], Age: 20}}
+	info, err = page.Cursors(&records)
+	assert.NoError(t, err)
+	assert.Empty(t, info.Next)
+	assert.NotEmpty(t, info.Prev)
+
+	// previous page, the order is reversed when querying
+	p.Cursor = info.Prev
+	page, err = p.ConvertToKeysetPage()
+	assert.NoError(t, err)
+	assert.Equal(t, bson.D{{Key: "age", Value: 1}, {Key: "_id", Value: 1}}, page.Sort())
+	records = []*cursorUser{{ID: ids[1], Age: 30}, {ID: ids[2], Age: 30}}
+	info, err = page.Cursors(&records)
+	assert.NoError(t, err)
+	assert.Equal(t, ids[2], records[0].ID)
+	assert.NotEmpty(t, info.Next)
+	assert.Empty(t, info.Prev)
+
+	// nested field and bson.M documents
+	p = &Params{Limit: 1, Sort: "profile.score,deleted_at"}
+	page, err = p.ConvertToKeysetPage("id")
+	assert.NoError(t, err)
+	users := []cursorUser{{ID: ids[0]}, {ID: ids[1]}}
+	info, err = page.Cursors(&users)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, info.Next)
+	docs := []bson.M{{"_id": ids[0], "profile": bson.M{"score": 1.5}, "deleted_at": nil}, {"_id": ids[1]}}
+	info, err = page.Cursors(&docs)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, info.Next)
+}
+
+func TestKeysetPage_Projection(t *testing.T) {
+	page, _ := (&Params{Sort: "-age"}).ConvertToKeysetPage()
+	assert.Equal(t, bson.M{"name": 1, "age": 1, "_id": 1}, page.Projection(bson.M{"name": 1}))
+	assert.Empty(t, page.Projection(nil))
+
+	// the parent field includes the sub field
+	page, _ = (&Params{Sort: "profile.score"}).ConvertToKeysetPage()
+	assert.Equal(t, bson.M{"profile": 1, "_id": 1}, page.Projection(bson.M{"profile": 1}))
+	page, _ = (&Params{Sort: "profile"}).ConvertToKeysetPage()
+	assert.Equal(t, bson.M{"profile": 1, "_id": 1}, page.Projection(bson.M{"profile.score": 1}))
+}
+
+func TestParams_ConvertToKeysetPageError(t *testing.T) {
+	_, err := (&Params{Sort: "$where"}).ConvertToKeysetPage()
+	assert.Error(t, err)
+	_, err = (&Params{Cursor: "not a cursor"}).ConvertToKeysetPage()
+	assert.Error(t, err)
+
+	page, _ := (&Params{Limit: 1, Sort: "age"}).ConvertToKeysetPage()
+	records := []*cursorUser{{Age: 1}, {Age: 2}}
+	info, _ := page.Cursors(&records)
+	_, err = (&Params{Limit: 1, Sort: "-age", Cursor: info.Next}).ConvertToKeysetPage()
+	assert.Error(t, err)
+
+	_, err = page.Cursors(records)
+	assert.Error(t, err)
+	docs := []bson.M{{"_id": 1}, {"_id": 2}}
+	_, err = page.Cursors(&docs)
+	assert.Error(t, err)
+}
+
+func TestParams_ConvertToMongoFilterWithOperators(t *testing.T) {
+	tests := []struct {
+		name    string
+		columns []Column
+		want    bson.M
+		wantErr bool
+	}{
+		{
+			name:    "between",
+			columns: []Column{{Name: "age", Exp: Between, Value: []interface{}{10, 20}}},
+			want:    bson.M{"age": bson.M{"$gte": 10, "$lte": 20}},
+		},
+		{
+			name:    "not in",
+			columns: []Column{{Name: "name", Exp: NotIn, Value: "foo,bar"}},
+			want:    bson.M{"name": bson.M{"$nin": []interface{}{"foo", "bar"}}},
+		},
+		{
+			name:    "is null and not null",
+			columns: []Column{{Name: "deleted_at", Exp: IsNull}, {Name: "email", Exp: NotNull}},
+			want:    bson.M{"$and": []bson.M{{"deleted_at": nil}, {"email": bson.M{"$ne": nil}}}},
+		},
+		{
+			name:    "not like",
+			columns: []Column{{Name: "name", Exp: NotLike, Value: "a.b"}},
+			want:    bson.M{"name": bson.M{"$not": primitive.Regex{Pattern: `a\.b`, Options: "i"}}},
+		},
+		{
+			name:    "prefix and suffix",
+			columns: []Column{{Name: "name", Exp: Prefix, Value: "foo"}, {Name: "email", Exp: Suffix, Value: "@bar.com"}},
+			want:    bson.M{"$and": []bson.M{{"name": bson.M{"$regex": "^foo", "$options": "i"}}, {"email": bson.M{"$regex": `@bar\.com$`, "$options": "i"}}}},
+		},
+		{
+			name:    "embedded document path",
+			columns: []Column{{Name: "profile->>address.city", Value: "foo"}},
+			want:    bson.M{"profile.address.city": "foo"},
+		},
+		{
+			name:    "between error",
+			columns: []Column{{Name: "age", Exp: Between, Value: "10"}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid column name",
+			columns: []Column{{Name: "$where", Value: "1"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := &Params{Columns: tt.columns}
+			got, err := params.ConvertToMongoFilter()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ConvertToMongoFilter() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParams_ConvertToMongoFilterWithGroup(t *testing.T) {
+	// (a=1 OR b=2) AND (c=3 OR (d>4 AND e IS NULL))
+	data := `{"where":{"logic":"and","groups":[
+		{"logic":"or","columns":[{"name":"a","value":1},{"name":"b","value":2}]},
+		{"logic":"||","columns":[{"name":"c","value":3}],"groups":[{"columns":[{"name":"d","exp":">","value":4},{"name":"e","exp":"isnull"}]}]}
+	]}}`
+	params := &Params{}
+	err := json.Unmarshal([]byte(data), params)
+	assert.NoError(t, err)
+	filter, err := params.ConvertToMongoFilter()
+	assert.NoError(t, err)
+	where := bson.M{"$and": []bson.M{
+		{"$or": []bson.M{{"a": 1.0}, {"b": 2.0}}},
+		{"$or": []bson.M{{"c": 3.0}, {"$and": []bson.M{{"d": bson.M{"$gt": 4.0}}, {"e": nil}}}}},
+	}}
+	assert.Equal(t, where, filter)
+
+	// combine with flat columns
+	params.Columns = []Column{{Name: "name", Value: "foo"}}
+	filter, err = params.ConvertToMongoFilter()
+	assert.NoError(t, err)
+	assert.Equal(t, bson.M{"$and": []bson.M{{"name": "foo"}, where}}, filter)
+
+	// single column group and empty group
+	params = &Params{Where: &Group{Logic: OR, Columns: []Column{{Name: "name", Value: "foo"}}, Groups: []Group{{}}}}
+	filter, err = params.ConvertToMongoFilter()
+	assert.NoError(t, err)
+	assert.Equal(t, bson.M{"name": "foo"}, filter)
+
+	// error
+	params = &Params{Where: &Group{Logic: "xor", Columns: []Column{{Name: "a", Value: 1}}}}
+	_, err = params.ConvertToMongoFilter()
+	assert.Error(t, err)
+	params = &Params{Where: &Group{Groups: []Group{{Columns: []Column{{Name: "$where", Value: 1}}}}}}
+	_, err = params.ConvertToMongoFilter()
+	assert.Error(t, err)
+	group := &Group{Columns: []Column{{Name: "a", Value: 1}}}
+	for i := 0; i < maxGroupDepth; i++ {
+		group = &Group{Groups: []Group{*group}}
+	}
+	_, err = group.ConvertToMongo()
+	assert.Error(t, err)
+}
+
+func TestParams_Whitelist(t *testing.T) {
+	whitelist := &Whitelist{
+		Filter: []string{"id", "name", "profile"},
+		Sort:   []string{"id", "created_at"},
+		Return: []string{"id", "name", "email"},
+	}
+	opt := WithWhitelist(whitelist)
+
+	params := &Params{
+		Sort:    "-created_at,_id",
+		Columns: []Column{{Name: "name", Value: "foo"}, {Name: "profile.address.city", Value: "bar"}},
+		Where:   &Group{Groups: []Group{{Columns: []Column{{Name: "_id", Value: "65ce48483f11aff697e30d6d"}}}}},
+	}
+	_, err := params.ConvertToMongoFilter(opt)
+	assert.NoError(t, err)
+
+	params.Sort = "-age"
+	_, err = params.ConvertToMongoFilter(opt)
+	assert.Error(t, err)
+
+	params.Sort = ""
+	params.Columns = append(params.Columns, Column{Name: "password", Value: "foo"})
+	_, err = params.ConvertToMongoFilter(opt)
+	assert.Error(t, err)
+
+	params.Columns = nil
+	params.Where.Groups[0].Columns[0].Name = "password"
+	_, err = params.ConvertToMongoFilter(opt)
+	assert.Error(t, err)
+
+	conditions := &Conditions{Columns: []Column{{Name: "password", Value: "foo"}}}
+	_, err = conditions.ConvertToMongo(opt)
+	assert.Error(t, err)
+	_, err = conditions.ConvertToMongo()
+	assert.NoError(t, err)
+}
+
+func TestParams_ConvertToMongoFilterRejectOperatorValue(t *testing.T) {
+	// json input is decoded to map[string]interface{} and []interface{}
+	var params Params
+	err := json.Unmarshal([]byte(`{"columns":[{"name":"email","value":{"$ne":""}}]}`), &params)
+	assert.NoError(t, err)
+	_, err = params.ConvertToMongoFilter(WithWhitelist(&Whitelist{Filter: []string{"email"}}))
+	assert.Error(t, err)
+
+	invalidColumns := []Column{
+		{Name: "email", Value: bson.M{"$ne": ""}},
+		{Name: "email", Exp: "!=", Value: bson.D{{Key: "$exists", Value: true}}},
+		{Name: "age", Exp: ">", Value: map[string]interface{}{"$gt": 0}},
+		{Name: "age", Exp: "<", Value: struct{ Gt int }{Gt: 1}},
+		{Name: "name", Exp: "like", Value: bson.M{"$regex": ".*"}},
+		{Name: "age", Exp: "=", Value: []int{1, 2}},
+		{Name: "age", Exp: "in", Value: []interface{}{1, bson.M{"$gt": 0}}},
+		{Name: "age", Exp: "notin", Value: bson.D{{Key: "$gt", Value: 0}}},
+		{Name: "age", Exp: "between", Value: []interface{}{[]int{1}, 2}},
+		{Name: "age", Exp: "between", Value: bson.M{"$gte": 1}},
+	}
+	for _, column := range invalidColumns {
+		params := &Params{Columns: []Column{column}}
+		_, err = params.ConvertToMongoFilter()
+		assert.Error(t, err, column)
+
+		params = &Params{Where: &Group{Columns: []Column{column}}}
+		_, err = params.ConvertToMongoFilter()
+		assert.Error(t, err, column)
+	}
+
+	validColumns := []Column{
+		{Name: "email", Value: "foo@bar.com"},
+		{Name: "age", Exp: ">", Value: 18.0},
+		{Name: "is_vip", Value: true},
+		{Name: "created_at", Exp: "<", Value: time.Now()},
+		{Name: "user_id:oid", Value: "65ce48483f11aff697e30d6d"},
+		{Name: "owner_id", Value: primitive.NewObjectID()},
+		{Name: "age", Exp: "in", Value: []interface{}{1, 2}},
+		{Name: "age", Exp: "notin", Value: "1,2"},
+		{Name: "age", Exp: "between", Value: []int{1, 2}},
+		{Name: "deleted_at", Exp: "isnull"},
+	}
+	for _, column := range validColumns {
+		params := &Params{Columns: []Column{column}}
+		_, err = params.ConvertToMongoFilter()
+		assert.NoError(t, err, column)
+	}
+}
+
+func TestParams_ConvertToProjection(t *testing.T) {
+	opt := WithWhitelist(&Whitelist{Return: []string{"id", "name", "profile"}})
+
+	projection, err := (&Params{}).ConvertToProjection()
+	assert.NoError(t, err)
+	assert.Nil(t, projection)
+
+	projection, err = (&Params{}).ConvertToProjection(opt)
+	assert.NoError(t, err)
+	assert.Equal(t, bson.M{"_id": 1, "name": 1, "profile": 1}, projection)
+
+	projection, err = (&Params{Fields: "id, profile.city"}).ConvertToProjection(opt)
+	assert.NoError(t, err)
+	assert.Equal(t, bson.M{"_id": 1, "profile.city": 1}, projection)
+
+	_, err = (&Params{Fields: "name,password"}).ConvertToProjection(opt)
+	assert.Error(t, err)
+	_, err = (&Params{Fields: "$where"}).ConvertToProjection()
+	assert.Error(t, err)
+}
@@ -0,0 +1,143 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Whitelist columns allowed to be used by the query parameters, an empty list means no restriction
+type Whitelist struct {
+	Filter []string // columns allowed in query conditions
+	Sort   []string // columns allowed in sort
+	Return []string // columns allowed to be returned, it is also the default fields when the parameter fields is empty
+}
+
+// Option set the options of converting query parameters
+type Option func(*options)
+
+type options struct {
+	whitelist *Whitelist
+}
+
+func (o *options) apply(opts ...Option) {
+	for _, opt := range opts {
+		opt(o)
+	}
+}
+
+func defaultOptions() *options {
+	return &options{}
+}
+
+// WithWhitelist set the whitelist of columns
+func WithWhitelist(w *Whitelist) Option {
+	return func(o *options) {
+		o.whitelist = w
+	}
+}
+
+// get the top level column name of the field, e.g. profile->>address.city:oid --> profile, _id --> id
+func topColumnName(name string) string {
+	name = strings.Replace(name, ":oid", "", 1)
+	name = strings.ReplaceAll(name, "->>", ".")
+	if i := strings.IndexByte(name, '.'); i > 0 {
+		name = name[:i]
+	}
+	if name == oidName {
+		name = "id"
+	}
+	return name
+}
+
+func isAllowed(list []string, name string) bool {
+	if len(list) == 0 {
+		return true
+	}
+	name = topColumnName(name)
+	for _, v := range list {
+		if topColumnName(v) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// the sort value used by the generated dao to skip counting, it is not checked by whitelist
+const ignoreCountSort = "ignore count"
+
+func (w *Whitelist) checkSort(sort string) error {
+	if w == nil || sort == "" || sort == ignoreCountSort {
+		return nil
+	}
+	for _, name := range strings.Split(strings.ReplaceAll(sort, " ", ""), ",") {
+		name = strings.TrimPrefix(name, "-")
+		if !isAllowed(w.Sort, name) {
+			return fmt.Errorf("column '%s' is not allowed to sort", name)
+		}
+	}
+	return nil
+}
+
+func (w *Whitelist) checkColumns(columns []Column) error {
+	for _, column := range columns {
+		if !isAllowed(w.Filter, column.Name) {
+			return fmt.Errorf("column '%s' is not allowed to filter", column.Name)
+		}
+	}
+	return nil
+}
+
+func (w *Whitelist) checkGroup(g *Group) error {
+	if err := w.checkColumns(g.Columns); err != nil {
+		return err
+	}
+	for i := range g.Groups {
+		if err := w.checkGroup(&g.Groups[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ConvertToProjection converted to mongo projection based on the Fields parameter,
+// if the Fields parameter is empty, the returnable columns of whitelist are returned,
+// a nil result means all fields are returned.
+func (p *Params) ConvertToProjection(opts ...Option) (bson.M, error) {
+	o := defaultOptions()
+	o.apply(opts...)
+	var returnColumns []string
+	if o.whitelist != nil {
+		returnColumns = o.whitelist.Return
+	}
+
+	fields := returnColumns
+	if fieldsStr := strings.ReplaceAll(p.Fields, " ", ""); fieldsStr != "" {
+		fields = []string{}
+		for _, field := range strings.Split(fieldsStr, ",") {
+			if field == "" {
+				continue
+			}
+			if !fieldRegexp.MatchString(field) || strings.Contains(field, ":oid") {
+				return nil, fmt.Errorf("invalid field name '%s'", field)
+			}
+			if !isAllowed(returnColumns, field) {
+				return nil, fmt.Errorf("field '%s' is not allowed to return", field)
+			}
+			fields = append(fields, field)
+		}
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	projection := bson.M{}
+	for _, field := range fields {
+		if field == "id" {
+			field = oidName
+		}
+		projection[field] = 1
+	}
+	return projection, nil
+}
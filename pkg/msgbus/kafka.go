@@ -0,0 +1,131 @@
+package msgbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/IBM/sarama"
+
+	"github.com/go-dev-frame/sponge/pkg/kafka"
+)
+
+type kafkaPublisher struct {
+	producer *kafka.SyncProducer
+}
+
+// NewKafkaPublisher create a publisher of kafka, the topic of the message is the kafka topic,
+// the key of the message is the partition key.
+func NewKafkaPublisher(producer *kafka.SyncProducer) Publisher {
+	return &kafkaPublisher{producer: producer}
+}
+
+func (p *kafkaPublisher) Publish(ctx context.Context, msg *Message) error {
+	_, _, err := p.producer.SendMessage(toProducerMessage(ctx, msg))
+	return err
+}
+
+func (p *kafkaPublisher) Close() error {
+	return p.producer.Close()
+}
+
+func toProducerMessage(ctx context.Context, msg *Message) *sarama.ProducerMessage {
+	pm := &sarama.ProducerMessage{
+		Topic: msg.Topic,
+		Value: sarama.ByteEncoder(msg.Value),
+	}
+	if msg.Key != "" {
+		pm.Key = sarama.StringEncoder(msg.Key)
+	}
+	for k, v := range injectTrace(ctx, msg.Headers) {
+		pm.Headers = append(pm.Headers, sarama.RecordHeader{Key: []byte(k), Value: []byte(v)})
+	}
+	return pm
+}
+
+func fromConsumerMessage(cm *sarama.ConsumerMessage) *Message {
+	msg := &Message{
+		Topic:     cm.Topic,
+		Key:       string(cm.Key),
+		Value:     cm.Value,
+		ID:        fmt.Sprintf("%s/%d/%d", cm.Topic, cm.Partition, cm.Offset),
+		Timestamp: cm.Timestamp,
+	}
+	if len(cm.Headers) > 0 {
+		msg.Headers = make(map[string]string, len(cm.Headers))
+		for _, h := range cm.Headers {
+			if h != nil {
+				msg.Headers[string(h.Key)] = string(h.Value)
+			}
+		}
+	}
+	return msg
+}
+
+// -------------------------------------------------------------------------------------------
+
+type kafkaSubscriber struct {
+	group  *kafka.ConsumerGroup
+	topics []string
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewKafkaSubscriber create a subscriber of kafka consumer group, the offset of the message is committed
+// when the handler returns nil, the failed message is logged and skipped.
+func NewKafkaSubscriber(group *kafka.ConsumerGroup, topics ...string) Subscriber {
+	return &kafkaSubscriber{group: group, topics: topics}
+}
+
+func (s *kafkaSubscriber) Subscribe(ctx context.Context, handler Handler) error {
+	if len(s.topics) == 0 {
+		return errors.New("topics is empty")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		return errors.New("already subscribed")
+	}
+
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.done = make(chan struct{})
+	go func() {
+		defer close(s.done)
+		fn := func(cm *sarama.ConsumerMessage) error {
+			msg := fromConsumerMessage(cm)
+			return handler(extractTrace(ctx, msg.Headers), msg)
+		}
+		// Consume returns when the consumer group is rebalanced, consume again
+		for {
+			err := s.group.Consume(ctx, s.topics, fn)
+			if ctx.Err() != nil || errors.Is(err, sarama.ErrClosedConsumerGroup) {
+				return
+			}
+			if err != nil {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(time.Second):
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+func (s *kafkaSubscriber) Close() error {
+	s.mu.Lock()
+	cancel, done := s.cancel, s.done
+	s.mu.Unlock()
+
+	err := s.group.Close()
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+	return err
+}
@@ -0,0 +1,64 @@
+package msgbus
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/go-dev-frame/sponge/pkg/kafka"
+)
+
+func TestKafkaPublisher(t *testing.T) {
+	ctx, _ := newTraceContext()
+	mp := mocks.NewSyncProducer(t, nil)
+	mp.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(pm *sarama.ProducerMessage) error {
+		if pm.Topic != "topic1" || string(pm.Key.(sarama.StringEncoder)) != "k1" {
+			return errors.New("unexpected message")
+		}
+		// foo and traceparent
+		if len(pm.Headers) != 2 {
+			return errors.New("unexpected headers")
+		}
+		return nil
+	})
+
+	p := NewKafkaPublisher(&kafka.SyncProducer{Producer: mp})
+	err := p.Publish(ctx, &Message{Topic: "topic1", Key: "k1", Value: []byte("hello"), Headers: map[string]string{"foo": "bar"}})
+	assert.NoError(t, err)
+	assert.NoError(t, p.Close())
+}
+
+func TestKafkaMessageConvert(t *testing.T) {
+	ctx, sc := newTraceContext()
+	pm := toProducerMessage(ctx, &Message{Topic: "topic1", Key: "k1", Value: []byte("hello"), Headers: map[string]string{"foo": "bar"}})
+
+	cm := &sarama.ConsumerMessage{
+		Topic:     pm.Topic,
+		Partition: 2,
+		Offset:    10,
+		Value:     []byte("hello"),
+		Key:       []byte("k1"),
+		Timestamp: time.Now(),
+	}
+	for i := range pm.Headers {
+		cm.Headers = append(cm.Headers, &pm.Headers[i])
+	}
+
+	msg := fromConsumerMessage(cm)
+	assert.Equal(t, "topic1/2/10", msg.ID)
+	assert.Equal(t, "k1", msg.Key)
+	assert.Equal(t, "bar", msg.Headers["foo"])
+	assert.Equal(t, sc.TraceID(), trace.SpanContextFromContext(extractTrace(context.Background(), msg.Headers)).TraceID())
+}
+
+func TestKafkaSubscriber(t *testing.T) {
+	s := NewKafkaSubscriber(&kafka.ConsumerGroup{})
+	assert.Error(t, s.Subscribe(context.Background(), nil))
+	assert.NoError(t, s.Close())
+}
@@ -0,0 +1,154 @@
+package msgbus
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrClosed the message bus is closed
+var ErrClosed = errors.New("msgbus: closed")
+
+// MemoryBus is an in-process message bus for unit tests, every subscriber receives the messages of
+// its topics in the order of publishing, the message is dropped if the handler returns an error.
+type MemoryBus struct {
+	mu          sync.RWMutex
+	subscribers []*memorySubscriber
+	messages    map[string][]*Message // topic and published messages mapping
+	seq         int64
+	closed      bool
+}
+
+// NewMemoryBus create an in-memory message bus, it implements Publisher.
+func NewMemoryBus() *MemoryBus {
+	return &MemoryBus{messages: make(map[string][]*Message)}
+}
+
+// Publish send the message to the subscribers of the topic
+func (b *MemoryBus) Publish(ctx context.Context, msg *Message) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return ErrClosed
+	}
+	b.seq++
+	m := &Message{
+		Topic:     msg.Topic,
+		Key:       msg.Key,
+		Value:     msg.Value,
+		Headers:   injectTrace(ctx, msg.Headers),
+		ID:        msg.Topic + "/" + strconv.FormatInt(b.seq, 10),
+		Timestamp: time.Now(),
+	}
+	b.messages[m.Topic] = append(b.messages[m.Topic], m)
+	var subs []*memorySubscriber
+	for _, s := range b.subscribers {
+		if s.hasTopic(m.Topic) {
+			subs = append(subs, s)
+		}
+	}
+	b.mu.Unlock()
+
+	for _, s := range subs {
+		if err := s.push(ctx, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Messages returns the published messages of the topic
+func (b *MemoryBus) Messages(topic string) []*Message {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return append([]*Message{}, b.messages[topic]...)
+}
+
+// NewSubscriber create a subscriber of the topics, it receives all messages of the topics published after it is created.
+func (b *MemoryBus) NewSubscriber(topics ...string) Subscriber {
+	s := &memorySubscriber{
+		topics: topics,
+		ch:     make(chan *Message, 1024),
+		stopCh: make(chan struct{}),
+	}
+	b.mu.Lock()
+	b.subscribers = append(b.subscribers, s)
+	b.mu.Unlock()
+	return s
+}
+
+// Close the message bus and all subscribers
+func (b *MemoryBus) Close() error {
+	b.mu.Lock()
+	b.closed = true
+	subs := b.subscribers
+	b.subscribers = nil
+	b.mu.Unlock()
+
+	for _, s := range subs {
+		_ = s.Close()
+	}
+	return nil
+}
+
+type memorySubscriber struct {
+	topics []string
+	ch     chan *Message
+
+	mu         sync.Mutex
+	subscribed bool
+	stopCh     chan struct{}
+	stopOnce   sync.Once
+}
+
+func (s *memorySubscriber) hasTopic(topic string) bool {
+	for _, t := range s.topics {
+		if t == topic {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *memorySubscriber) push(ctx context.Context, msg *Message) error {
+	select {
+	case s.ch <- msg:
+		return nil
+	case <-s.stopCh:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *memorySubscriber) Subscribe(ctx context.Context, handler Handler) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.subscribed {
+		return errors.New("already subscribed")
+	}
+	s.subscribed = true
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-s.stopCh:
+				return
+			case msg := <-s.ch:
+				_ = handler(extractTrace(ctx, msg.Headers), msg)
+			}
+		}
+	}()
+	return nil
+}
+
+func (s *memorySubscriber) Close() error {
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+	})
+	return nil
+}
@@ -0,0 +1,107 @@
+// Package msgbus is a broker-agnostic message bus, it provides the same Publisher and Subscriber
+// interfaces over kafka, rabbitmq and memory, switching brokers does not require rewriting the consumers.
+package msgbus
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/propagation"
+
+	"github.com/go-dev-frame/sponge/pkg/app"
+	"github.com/go-dev-frame/sponge/pkg/msgctx"
+)
+
+// Message the message with metadata
+type Message struct {
+	Topic   string            // kafka topic, rabbitmq routing key
+	Key     string            // messages with the same key are ordered, e.g. kafka partition key
+	Value   []byte            // message body
+	Headers map[string]string // message headers, the trace context is injected into the headers when publishing
+
+	// the following fields are set by the subscriber
+	ID        string    // id of the message in the broker, e.g. topic/partition/offset of kafka
+	Timestamp time.Time // time of the message
+}
+
+// Handler handle the received message, ctx contains the trace context and request id of the publisher,
+// the message is acknowledged when it returns nil.
+type Handler func(ctx context.Context, msg *Message) error
+
+// Publisher publishes messages to the broker.
+type Publisher interface {
+	// Publish send the message, it returns after the broker acknowledges the message.
+	Publish(ctx context.Context, msg *Message) error
+	Close() error
+}
+
+// Subscriber subscribes messages from the broker.
+type Subscriber interface {
+	// Subscribe consume messages in the background until the context is canceled or Close is called.
+	Subscribe(ctx context.Context, handler Handler) error
+	Close() error
+}
+
+// injectTrace copy the headers of the message and inject the trace context and request id of ctx into it
+func injectTrace(ctx context.Context, headers map[string]string) map[string]string {
+	carrier := make(propagation.MapCarrier, len(headers)+2)
+	for k, v := range headers {
+		carrier[k] = v
+	}
+	msgctx.Inject(ctx, carrier)
+	return carrier
+}
+
+// extractTrace extract the trace context and request id from the headers of the message into ctx
+func extractTrace(ctx context.Context, headers map[string]string) context.Context {
+	if len(headers) == 0 {
+		return ctx
+	}
+	return msgctx.Extract(ctx, propagation.MapCarrier(headers))
+}
+
+// -------------------------------------------------------------------------------------------
+
+var _ app.IServer = (*server)(nil)
+
+type server struct {
+	name       string
+	subscriber Subscriber
+	handler    Handler
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewServer create a server to run the subscriber in the app, Start subscribes the messages
+// and blocks until Stop is called.
+func NewServer(name string, subscriber Subscriber, handler Handler) app.IServer {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &server{
+		name:       name,
+		subscriber: subscriber,
+		handler:    handler,
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+}
+
+// Start subscribe messages
+func (s *server) Start() error {
+	if err := s.subscriber.Subscribe(s.ctx, s.handler); err != nil {
+		return err
+	}
+	<-s.ctx.Done()
+	return nil
+}
+
+// Stop subscribe messages
+func (s *server) Stop() error {
+	s.cancel()
+	return s.subscriber.Close()
+}
+
+// String comment
+func (s *server) String() string {
+	return "message bus subscriber " + s.name
+}
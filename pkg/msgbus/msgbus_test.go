@@ -0,0 +1,95 @@
+package msgbus
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newTraceContext() (context.Context, trace.SpanContext) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+		SpanID:     trace.SpanID{1, 2, 3, 4, 5, 6, 7, 8},
+		TraceFlags: trace.FlagsSampled,
+	})
+	return trace.ContextWithSpanContext(context.Background(), sc), sc
+}
+
+func TestMemoryBus(t *testing.T) {
+	bus := NewMemoryBus()
+	defer bus.Close()
+	ctx, sc := newTraceContext()
+
+	var mu sync.Mutex
+	var received []*Message
+	var traceIDs []trace.TraceID
+	sub := bus.NewSubscriber("topic1", "topic2")
+	err := sub.Subscribe(context.Background(), func(ctx context.Context, msg *Message) error {
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, msg)
+		traceIDs = append(traceIDs, trace.SpanContextFromContext(ctx).TraceID())
+		return errors.New("the message is dropped")
+	})
+	assert.NoError(t, err)
+	assert.Error(t, sub.Subscribe(context.Background(), nil))
+
+	headers := map[string]string{"foo": "bar"}
+	assert.NoError(t, bus.Publish(ctx, &Message{Topic: "topic1", Key: "k1", Value: []byte("hello"), Headers: headers}))
+	assert.NoError(t, bus.Publish(ctx, &Message{Topic: "topic2", Value: []byte("world")}))
+	assert.NoError(t, bus.Publish(ctx, &Message{Topic: "topic3", Value: []byte("ignored")}))
+	assert.Len(t, headers, 1) // the headers of the caller are not modified
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(received) == 2
+	}, time.Second, time.Millisecond*10)
+	mu.Lock()
+	assert.Equal(t, "k1", received[0].Key)
+	assert.Equal(t, "bar", received[0].Headers["foo"])
+	assert.Equal(t, "topic1/1", received[0].ID)
+	assert.Equal(t, []byte("world"), received[1].Value)
+	assert.Equal(t, sc.TraceID(), traceIDs[0])
+	mu.Unlock()
+	assert.Len(t, bus.Messages("topic3"), 1)
+
+	assert.NoError(t, bus.Close())
+	assert.ErrorIs(t, bus.Publish(ctx, &Message{Topic: "topic1"}), ErrClosed)
+}
+
+func TestNewServer(t *testing.T) {
+	bus := NewMemoryBus()
+	defer bus.Close()
+
+	received := make(chan *Message, 1)
+	s := NewServer("test", bus.NewSubscriber("topic1"), func(ctx context.Context, msg *Message) error {
+		received <- msg
+		return nil
+	})
+	assert.Equal(t, "message bus subscriber test", s.String())
+
+	done := make(chan error)
+	go func() {
+		done <- s.Start()
+	}()
+	time.Sleep(time.Millisecond * 50)
+	assert.NoError(t, bus.Publish(context.Background(), &Message{Topic: "topic1", Value: []byte("hello")}))
+	select {
+	case msg := <-received:
+		assert.Equal(t, []byte("hello"), msg.Value)
+	case <-time.After(time.Second):
+		t.Fatal("receive message timeout")
+	}
+
+	assert.NoError(t, s.Stop())
+	assert.NoError(t, <-done)
+}
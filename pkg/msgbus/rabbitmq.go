@@ -0,0 +1,116 @@
+package msgbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+
+	"github.com/go-dev-frame/sponge/pkg/rabbitmq"
+)
+
+// rabbitmq has no message key, the key is carried by the header
+const rabbitmqKeyHeader = "x-msgbus-key"
+
+type rabbitmqPublisher struct {
+	producer *rabbitmq.Producer
+}
+
+// NewRabbitmqPublisher create a publisher of rabbitmq, the topic of the message is the routing key
+// of the topic exchange, it is ignored by the direct, fanout and headers exchanges. Enable the
+// publisher confirm of the producer to make sure the message is received by the broker.
+func NewRabbitmqPublisher(producer *rabbitmq.Producer) Publisher {
+	return &rabbitmqPublisher{producer: producer}
+}
+
+func (p *rabbitmqPublisher) Publish(ctx context.Context, msg *Message) error {
+	return p.producer.PublishWithHeaders(ctx, msg.Topic, toAMQPHeaders(ctx, msg), msg.Value)
+}
+
+func (p *rabbitmqPublisher) Close() error {
+	p.producer.Close()
+	return nil
+}
+
+func toAMQPHeaders(ctx context.Context, msg *Message) map[string]interface{} {
+	headers := injectTrace(ctx, msg.Headers)
+	table := make(map[string]interface{}, len(headers)+1)
+	for k, v := range headers {
+		table[k] = v
+	}
+	if msg.Key != "" {
+		table[rabbitmqKeyHeader] = msg.Key
+	}
+	return table
+}
+
+func fromDelivery(d *amqp.Delivery, tagID string) *Message {
+	msg := &Message{
+		Topic:     d.RoutingKey,
+		Value:     d.Body,
+		ID:        tagID,
+		Timestamp: d.Timestamp,
+	}
+	if len(d.Headers) > 0 {
+		msg.Headers = make(map[string]string, len(d.Headers))
+		for k, v := range d.Headers {
+			var value string
+			switch val := v.(type) {
+			case string:
+				value = val
+			case []byte:
+				value = string(val)
+			default:
+				value = fmt.Sprint(val)
+			}
+			if k == rabbitmqKeyHeader {
+				msg.Key = value
+				continue
+			}
+			msg.Headers[k] = value
+		}
+	}
+	return msg
+}
+
+// -------------------------------------------------------------------------------------------
+
+type rabbitmqSubscriber struct {
+	consumer *rabbitmq.Consumer
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+}
+
+// NewRabbitmqSubscriber create a subscriber of rabbitmq consumer, the message is acknowledged
+// when the handler returns nil if the auto ack of the consumer is disabled.
+func NewRabbitmqSubscriber(consumer *rabbitmq.Consumer) Subscriber {
+	return &rabbitmqSubscriber{consumer: consumer}
+}
+
+func (s *rabbitmqSubscriber) Subscribe(ctx context.Context, handler Handler) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		return errors.New("already subscribed")
+	}
+
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.consumer.ConsumeDelivery(ctx, func(ctx context.Context, d *amqp.Delivery, tagID string) error {
+		msg := fromDelivery(d, tagID)
+		return handler(extractTrace(ctx, msg.Headers), msg)
+	})
+	return nil
+}
+
+func (s *rabbitmqSubscriber) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.consumer.Close()
+	return nil
+}
@@ -0,0 +1,27 @@
+package msgbus
+
+import (
+	"context"
+	"testing"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestRabbitmqMessageConvert(t *testing.T) {
+	ctx, sc := newTraceContext()
+	headers := toAMQPHeaders(ctx, &Message{Topic: "info", Key: "k1", Value: []byte("hello"), Headers: map[string]string{"foo": "bar"}})
+	assert.Equal(t, "k1", headers[rabbitmqKeyHeader])
+	headers["count"] = int32(1)
+
+	d := &amqp.Delivery{RoutingKey: "info", Body: []byte("hello"), Headers: headers}
+	msg := fromDelivery(d, "exchange/queue/1")
+	assert.Equal(t, "exchange/queue/1", msg.ID)
+	assert.Equal(t, "info", msg.Topic)
+	assert.Equal(t, "k1", msg.Key)
+	assert.Equal(t, "bar", msg.Headers["foo"])
+	assert.Equal(t, "1", msg.Headers["count"])
+	assert.NotContains(t, msg.Headers, rabbitmqKeyHeader)
+	assert.Equal(t, sc.TraceID(), trace.SpanContextFromContext(extractTrace(context.Background(), msg.Headers)).TraceID())
+}
@@ -0,0 +1,79 @@
+// Package msgctx propagates the trace context and request id through the headers of kafka and rabbitmq messages,
+// the producer injects them into the message headers, and the consumer extracts them into the context of the handler,
+// so the traces and logs of the producer and consumer are connected.
+package msgctx
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"google.golang.org/grpc/metadata"
+)
+
+var (
+	// HeaderRequestIDKey request id key of the message headers, same as the http header
+	HeaderRequestIDKey = "X-Request-Id"
+
+	// ContextRequestIDKey request id key of the context, same as the gin middleware and grpc interceptor
+	ContextRequestIDKey = "request_id"
+)
+
+// SetRequestIDKey set the request id keys of the context and message headers, empty key is ignored,
+// it is called by the gin middleware.RequestID and grpc interceptor.SetContextRequestIDKey when the
+// keys are customized, so the keys are always the same as theirs.
+func SetRequestIDKey(contextKey string, headerKey string) {
+	if contextKey != "" {
+		ContextRequestIDKey = contextKey
+	}
+	if headerKey != "" {
+		HeaderRequestIDKey = headerKey
+	}
+}
+
+// Inject the trace context and request id of ctx into the message headers, the trace context is
+// injected by the global propagator which is set by tracer.Init, e.g. W3C traceparent.
+func Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	if ctx == nil {
+		return
+	}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	if requestID := RequestID(ctx); requestID != "" {
+		carrier.Set(HeaderRequestIDKey, requestID)
+	}
+}
+
+// Extract the trace context and request id from the message headers into ctx, the request id can be
+// obtained by middleware.CtxRequestID or RequestID, and it is passed to the grpc server called with ctx.
+func Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	ctx = otel.GetTextMapPropagator().Extract(ctx, carrier)
+	if requestID := carrier.Get(HeaderRequestIDKey); requestID != "" {
+		ctx = WithRequestID(ctx, requestID)
+	}
+	return ctx
+}
+
+// WithRequestID set the request id into ctx
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	ctx = context.WithValue(ctx, ContextRequestIDKey, requestID) //nolint
+	if md, ok := metadata.FromOutgoingContext(ctx); !ok || len(md.Get(ContextRequestIDKey)) == 0 {
+		ctx = metadata.AppendToOutgoingContext(ctx, ContextRequestIDKey, requestID)
+	}
+	return ctx
+}
+
+// RequestID get request id from ctx, it supports the context of gin, grpc server and grpc client.
+func RequestID(ctx context.Context) string {
+	if requestID, ok := ctx.Value(ContextRequestIDKey).(string); ok && requestID != "" {
+		return requestID
+	}
+	if values := metadata.ValueFromIncomingContext(ctx, ContextRequestIDKey); len(values) > 0 {
+		return values[0]
+	}
+	if md, ok := metadata.FromOutgoingContext(ctx); ok {
+		if values := md.Get(ContextRequestIDKey); len(values) > 0 {
+			return values[0]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,72 @@
+package msgctx
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestInjectExtract(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+		SpanID:     trace.SpanID{1, 2, 3, 4, 5, 6, 7, 8},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+	ctx = context.WithValue(ctx, ContextRequestIDKey, "req-1") //nolint
+
+	carrier := propagation.MapCarrier{}
+	Inject(ctx, carrier)
+	assert.Equal(t, "00-0102030405060708090a0b0c0d0e0f10-0102030405060708-01", carrier.Get("traceparent"))
+	assert.Equal(t, "req-1", carrier.Get(HeaderRequestIDKey))
+
+	ctx = Extract(context.Background(), carrier)
+	assert.Equal(t, sc.TraceID(), trace.SpanContextFromContext(ctx).TraceID())
+	assert.True(t, trace.SpanContextFromContext(ctx).IsRemote())
+	assert.Equal(t, "req-1", RequestID(ctx))
+	md, _ := metadata.FromOutgoingContext(ctx)
+	assert.Equal(t, []string{"req-1"}, md.Get(ContextRequestIDKey))
+
+	// no trace context and request id
+	carrier = propagation.MapCarrier{}
+	Inject(context.Background(), carrier)
+	assert.Empty(t, carrier)
+	ctx = Extract(context.Background(), carrier)
+	assert.Equal(t, "", RequestID(ctx))
+}
+
+func TestSetRequestIDKey(t *testing.T) {
+	defer SetRequestIDKey(ContextRequestIDKey, HeaderRequestIDKey)
+
+	SetRequestIDKey("my_request_id", "X-My-Request-Id")
+	SetRequestIDKey("", "") // ignored
+
+	ctx := context.WithValue(context.Background(), "my_request_id", "req-5") //nolint
+	carrier := propagation.MapCarrier{}
+	Inject(ctx, carrier)
+	assert.Equal(t, "req-5", carrier.Get("X-My-Request-Id"))
+
+	ctx = Extract(context.Background(), carrier)
+	assert.Equal(t, "req-5", RequestID(ctx))
+	assert.Equal(t, "req-5", ctx.Value("my_request_id"))
+}
+
+func TestRequestID(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(ContextRequestIDKey, "req-2"))
+	assert.Equal(t, "req-2", RequestID(ctx))
+
+	ctx = metadata.AppendToOutgoingContext(context.Background(), ContextRequestIDKey, "req-3")
+	assert.Equal(t, "req-3", RequestID(ctx))
+
+	// the request id of outgoing metadata is not overwritten
+	ctx = WithRequestID(ctx, "req-4")
+	assert.Equal(t, "req-4", RequestID(ctx))
+	md, _ := metadata.FromOutgoingContext(ctx)
+	assert.Equal(t, []string{"req-3"}, md.Get(ContextRequestIDKey))
+}
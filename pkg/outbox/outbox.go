@@ -0,0 +1,107 @@
+// Package outbox implements the transactional outbox, the events are written to the outbox table in the
+// same gorm transaction as the business data, and published to the message broker by the relay.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// event status
+const (
+	StatusPending   = "pending"
+	StatusPublished = "published"
+	StatusFailed    = "failed" // the max attempts is reached, the event is not published again
+)
+
+// Event is the table of the outbox, the events are saved in the transaction and published by the relay.
+type Event struct {
+	ID          uint64     `gorm:"column:id;AUTO_INCREMENT;primary_key" json:"id"`
+	Topic       string     `gorm:"column:topic;type:varchar(255);NOT NULL" json:"topic"` // kafka topic or rabbitmq routing key
+	Key         string     `gorm:"column:event_key;type:varchar(255)" json:"key"`        // partition key of kafka
+	Payload     []byte     `gorm:"column:payload" json:"payload"`
+	Headers     string     `gorm:"column:headers;type:text" json:"headers"` // json of the map[string]string
+	Status      string     `gorm:"column:status;type:varchar(16);index:idx_status_next;NOT NULL" json:"status"`
+	Attempts    int        `gorm:"column:attempts;NOT NULL" json:"attempts"`
+	LastError   string     `gorm:"column:last_error;type:text" json:"lastError"`
+	NextRetryAt time.Time  `gorm:"column:next_retry_at;index:idx_status_next;NOT NULL" json:"nextRetryAt"` // the event is not published before it
+	CreatedAt   time.Time  `gorm:"column:created_at;NOT NULL" json:"createdAt"`
+	PublishedAt *time.Time `gorm:"column:published_at" json:"publishedAt"`
+}
+
+// TableName get table name
+func (table *Event) TableName() string {
+	return "outbox_event"
+}
+
+// GetHeaders get the headers of the event.
+func (table *Event) GetHeaders() map[string]string {
+	if table.Headers == "" {
+		return nil
+	}
+	headers := map[string]string{}
+	_ = json.Unmarshal([]byte(table.Headers), &headers)
+	return headers
+}
+
+// NewEvent create an event, the data is marshaled to json if it is not []byte or string.
+func NewEvent(topic string, key string, data interface{}, headers ...map[string]string) (*Event, error) {
+	if topic == "" {
+		return nil, errors.New("topic is empty")
+	}
+
+	var payload []byte
+	switch v := data.(type) {
+	case []byte:
+		payload = v
+	case string:
+		payload = []byte(v)
+	default:
+		var err error
+		payload, err = json.Marshal(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	event := &Event{Topic: topic, Key: key, Payload: payload}
+	if len(headers) > 0 && len(headers[0]) > 0 {
+		data, err := json.Marshal(headers[0])
+		if err != nil {
+			return nil, err
+		}
+		event.Headers = string(data)
+	}
+	return event, nil
+}
+
+// Migrate create the table outbox_event if it does not exist.
+func Migrate(db *gorm.DB) error {
+	return db.AutoMigrate(&Event{})
+}
+
+// Save write the events to the outbox table in the transaction, call it with the same tx as
+// CreateByTx, UpdateByTx or DeleteByTx of the dao, the events are published only if the transaction is committed.
+func Save(ctx context.Context, tx *gorm.DB, events ...*Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	for _, event := range events {
+		if event.Topic == "" {
+			return errors.New("topic is empty")
+		}
+		event.ID = 0
+		event.Status = StatusPending
+		event.Attempts = 0
+		event.CreatedAt = now
+		event.NextRetryAt = now
+		event.PublishedAt = nil
+	}
+	return tx.WithContext(ctx).Create(&events).Error
+}
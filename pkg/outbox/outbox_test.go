@@ -0,0 +1,255 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"github.com/go-dev-frame/sponge/pkg/kafka"
+	"github.com/go-dev-frame/sponge/pkg/sgorm/sqlite"
+)
+
+type memPublisher struct {
+	mu     sync.Mutex
+	events []*Event
+	err    error
+}
+
+func (p *memPublisher) Publish(_ context.Context, event *Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return p.err
+	}
+	p.events = append(p.events, event)
+	return nil
+}
+
+func (p *memPublisher) Events() []*Event {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]*Event{}, p.events...)
+}
+
+func initDB(t *testing.T) *gorm.DB {
+	db, err := sqlite.Init(filepath.Join(t.TempDir(), "outbox.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func getEvent(t *testing.T, db *gorm.DB, id uint64) *Event {
+	event := &Event{}
+	if err := db.Where("id = ?", id).First(event).Error; err != nil {
+		t.Fatal(err)
+	}
+	return event
+}
+
+func TestNewEvent(t *testing.T) {
+	event, err := NewEvent("user", "1", map[string]string{"name": "foo"}, map[string]string{"type": "created"})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name":"foo"}`, string(event.Payload))
+	assert.Equal(t, map[string]string{"type": "created"}, event.GetHeaders())
+
+	event, err = NewEvent("user", "", []byte("foo"))
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", string(event.Payload))
+	assert.Nil(t, event.GetHeaders())
+
+	_, err = NewEvent("", "", "foo")
+	assert.Error(t, err)
+	_, err = NewEvent("user", "", make(chan int))
+	assert.Error(t, err)
+}
+
+func TestSave(t *testing.T) {
+	db := initDB(t)
+	ctx := context.Background()
+
+	// the event is discarded when the transaction is rolled back
+	_ = db.Transaction(func(tx *gorm.DB) error {
+		event, _ := NewEvent("user", "1", "created")
+		assert.NoError(t, Save(ctx, tx, event))
+		return errors.New("rollback")
+	})
+	var count int64
+	db.Model(&Event{}).Count(&count)
+	assert.Equal(t, int64(0), count)
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		event1, _ := NewEvent("user", "1", "created")
+		event2, _ := NewEvent("user", "1", "updated")
+		return Save(ctx, tx, event1, event2)
+	})
+	assert.NoError(t, err)
+	db.Model(&Event{}).Where("status = ?", StatusPending).Count(&count)
+	assert.Equal(t, int64(2), count)
+
+	assert.NoError(t, Save(ctx, db))
+	assert.Error(t, Save(ctx, db, &Event{}))
+}
+
+func TestRelay_PublishPending(t *testing.T) {
+	db := initDB(t)
+	ctx := context.Background()
+	publisher := &memPublisher{}
+	relay, err := NewRelay(db, publisher, WithBatchSize(2))
+	assert.NoError(t, err)
+
+	for _, data := range []string{"a", "b", "c"} {
+		event, _ := NewEvent("user", "", data)
+		assert.NoError(t, Save(ctx, db, event))
+	}
+
+	n, err := relay.PublishPending(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	n, err = relay.PublishPending(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	n, err = relay.PublishPending(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	events := publisher.Events()
+	assert.Len(t, events, 3)
+	for i, data := range []string{"a", "b", "c"} {
+		assert.Equal(t, data, string(events[i].Payload))
+		event := getEvent(t, db, events[i].ID)
+		assert.Equal(t, StatusPublished, event.Status)
+		assert.Equal(t, 1, event.Attempts)
+		assert.NotNil(t, event.PublishedAt)
+	}
+}
+
+func TestRelay_Retry(t *testing.T) {
+	db := initDB(t)
+	ctx := context.Background()
+	publisher := &memPublisher{err: errors.New("broker is down")}
+	relay, err := NewRelay(db, publisher, WithMaxAttempts(2), WithRetryBackoff(time.Millisecond*50, time.Second))
+	assert.NoError(t, err)
+
+	event, _ := NewEvent("user", "", "a")
+	assert.NoError(t, Save(ctx, db, event))
+
+	n, _ := relay.PublishPending(ctx)
+	assert.Equal(t, 1, n)
+	saved := getEvent(t, db, event.ID)
+	assert.Equal(t, StatusPending, saved.Status)
+	assert.Equal(t, 1, saved.Attempts)
+	assert.Equal(t, "broker is down", saved.LastError)
+
+	// not published again before the backoff
+	n, _ = relay.PublishPending(ctx)
+	assert.Equal(t, 0, n)
+
+	time.Sleep(time.Millisecond * 100)
+	n, _ = relay.PublishPending(ctx)
+	assert.Equal(t, 1, n)
+	saved = getEvent(t, db, event.ID)
+	assert.Equal(t, StatusFailed, saved.Status)
+	assert.Equal(t, 2, saved.Attempts)
+
+	assert.Equal(t, time.Millisecond*50, relay.backoff(1))
+	assert.Equal(t, time.Millisecond*200, relay.backoff(3))
+	assert.Equal(t, time.Second, relay.backoff(10))
+}
+
+func TestRelay_Claim(t *testing.T) {
+	db := initDB(t)
+	ctx := context.Background()
+	relay, err := NewRelay(db, &memPublisher{})
+	assert.NoError(t, err)
+
+	event, _ := NewEvent("user", "", "a")
+	assert.NoError(t, Save(ctx, db, event))
+
+	ok, err := relay.claim(ctx, event)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// claimed by another relay during the lease
+	ok, err = relay.claim(ctx, event)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	n, _ := relay.PublishPending(ctx)
+	assert.Equal(t, 0, n)
+}
+
+func TestRelay_StartAndCleanup(t *testing.T) {
+	db := initDB(t)
+	ctx := context.Background()
+	publisher := &memPublisher{}
+	relay, err := NewRelay(db, publisher, WithPollInterval(time.Millisecond*20),
+		WithCleanup(time.Millisecond*50, time.Millisecond*20))
+	assert.NoError(t, err)
+
+	relay.Start()
+	relay.Start() // no effect
+	event, _ := NewEvent("user", "", "a")
+	assert.NoError(t, Save(ctx, db, event))
+
+	assert.Eventually(t, func() bool {
+		return len(publisher.Events()) == 1
+	}, time.Second, time.Millisecond*10)
+	assert.Eventually(t, func() bool {
+		var count int64
+		db.Model(&Event{}).Count(&count)
+		return count == 0
+	}, time.Second, time.Millisecond*10)
+
+	relay.Stop()
+	relay.Stop()
+
+	_, err = NewRelay(nil, publisher)
+	assert.Error(t, err)
+}
+
+func TestPublisherFunc(t *testing.T) {
+	var topic string
+	p := PublisherFunc(func(_ context.Context, event *Event) error {
+		topic = event.Topic
+		return nil
+	})
+	assert.NoError(t, p.Publish(context.Background(), &Event{Topic: "user"}))
+	assert.Equal(t, "user", topic)
+}
+
+func TestKafkaPublisher(t *testing.T) {
+	mp := mocks.NewSyncProducer(t, nil)
+	mp.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(pm *sarama.ProducerMessage) error {
+		if pm.Topic != "user" || string(pm.Key.(sarama.StringEncoder)) != "1" {
+			return errors.New("unexpected message")
+		}
+		if len(pm.Headers) != 1 {
+			return errors.New("unexpected headers")
+		}
+		return nil
+	})
+
+	event, _ := NewEvent("user", "1", "created", map[string]string{"type": "created"})
+	p := NewKafkaPublisher(&kafka.SyncProducer{Producer: mp})
+	assert.NoError(t, p.Publish(context.Background(), event))
+}
+
+func TestRabbitmqHeaders(t *testing.T) {
+	event, _ := NewEvent("user", "1", "created", map[string]string{"type": "created"})
+	headers := toAMQPHeaders(event)
+	assert.Equal(t, "1", headers[rabbitmqKeyHeader])
+	assert.Equal(t, "created", headers["type"])
+
+	assert.NotNil(t, NewRabbitmqPublisher(nil))
+}
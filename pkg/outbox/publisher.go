@@ -0,0 +1,79 @@
+package outbox
+
+import (
+	"context"
+
+	"github.com/IBM/sarama"
+
+	"github.com/go-dev-frame/sponge/pkg/kafka"
+	"github.com/go-dev-frame/sponge/pkg/rabbitmq"
+)
+
+// rabbitmq has no message key, the key is carried by the header
+const rabbitmqKeyHeader = "x-outbox-key"
+
+// Publisher publishes the events of the outbox, an error means the event is not received by the broker
+// and it will be published again.
+type Publisher interface {
+	Publish(ctx context.Context, event *Event) error
+}
+
+// PublisherFunc is an adapter to allow the use of ordinary functions as Publisher.
+type PublisherFunc func(ctx context.Context, event *Event) error
+
+// Publish call f(ctx, event)
+func (f PublisherFunc) Publish(ctx context.Context, event *Event) error {
+	return f(ctx, event)
+}
+
+type kafkaPublisher struct {
+	producer *kafka.SyncProducer
+}
+
+// NewKafkaPublisher create a publisher of kafka, the topic of the event is the kafka topic,
+// the key of the event is the partition key.
+func NewKafkaPublisher(producer *kafka.SyncProducer) Publisher {
+	return &kafkaPublisher{producer: producer}
+}
+
+func (p *kafkaPublisher) Publish(ctx context.Context, event *Event) error {
+	msg := &sarama.ProducerMessage{
+		Topic: event.Topic,
+		Value: sarama.ByteEncoder(event.Payload),
+	}
+	if event.Key != "" {
+		msg.Key = sarama.StringEncoder(event.Key)
+	}
+	for k, v := range event.GetHeaders() {
+		msg.Headers = append(msg.Headers, sarama.RecordHeader{Key: []byte(k), Value: []byte(v)})
+	}
+	_, _, err := p.producer.SendMessageWithContext(ctx, msg)
+	return err
+}
+
+type rabbitmqPublisher struct {
+	producer *rabbitmq.Producer
+}
+
+// NewRabbitmqPublisher create a publisher of rabbitmq, the topic of the event is the routing key
+// of the topic exchange, it is ignored by the direct, fanout and headers exchanges. Enable the
+// publisher confirm of the producer to make sure the event is received by the broker.
+func NewRabbitmqPublisher(producer *rabbitmq.Producer) Publisher {
+	return &rabbitmqPublisher{producer: producer}
+}
+
+func (p *rabbitmqPublisher) Publish(ctx context.Context, event *Event) error {
+	return p.producer.PublishWithHeaders(ctx, event.Topic, toAMQPHeaders(event), event.Payload)
+}
+
+func toAMQPHeaders(event *Event) map[string]interface{} {
+	eventHeaders := event.GetHeaders()
+	headers := make(map[string]interface{}, len(eventHeaders)+1)
+	for k, v := range eventHeaders {
+		headers[k] = v
+	}
+	if event.Key != "" {
+		headers[rabbitmqKeyHeader] = event.Key
+	}
+	return headers
+}
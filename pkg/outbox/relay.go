@@ -0,0 +1,317 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// RelayOption set options.
+type RelayOption func(*relayOptions)
+
+type relayOptions struct {
+	pollInterval    time.Duration // default 1s
+	batchSize       int           // default 100
+	lease           time.Duration // default 30s
+	maxAttempts     int           // default 0, retry until published
+	retryBackoff    time.Duration // default 1s
+	maxRetryBackoff time.Duration // default 5m
+	retention       time.Duration // default 7 days
+	cleanupInterval time.Duration // default 1h
+
+	zapLogger *zap.Logger // default NewProduction
+}
+
+func (o *relayOptions) apply(opts ...RelayOption) {
+	for _, opt := range opts {
+		opt(o)
+	}
+}
+
+func defaultRelayOptions() *relayOptions {
+	zapLogger, _ := zap.NewProduction()
+	return &relayOptions{
+		pollInterval:    time.Second,
+		batchSize:       100,
+		lease:           time.Second * 30,
+		retryBackoff:    time.Second,
+		maxRetryBackoff: time.Minute * 5,
+		retention:       time.Hour * 24 * 7,
+		cleanupInterval: time.Hour,
+		zapLogger:       zapLogger,
+	}
+}
+
+// WithPollInterval set the interval of polling the pending events.
+func WithPollInterval(d time.Duration) RelayOption {
+	return func(o *relayOptions) {
+		if d > 0 {
+			o.pollInterval = d
+		}
+	}
+}
+
+// WithBatchSize set the max number of events published in one poll.
+func WithBatchSize(size int) RelayOption {
+	return func(o *relayOptions) {
+		if size > 0 {
+			o.batchSize = size
+		}
+	}
+}
+
+// WithLease set the time that a claimed event is not claimed by other relays, it should be
+// longer than the time of publishing a batch, the event is published again after the lease
+// if the relay exits before it is marked as published.
+func WithLease(d time.Duration) RelayOption {
+	return func(o *relayOptions) {
+		if d > 0 {
+			o.lease = d
+		}
+	}
+}
+
+// WithMaxAttempts set the max attempts of publishing an event, the event is marked as failed
+// and not published again when it is reached, 0 means retry until published.
+func WithMaxAttempts(attempts int) RelayOption {
+	return func(o *relayOptions) {
+		if attempts >= 0 {
+			o.maxAttempts = attempts
+		}
+	}
+}
+
+// WithRetryBackoff set the backoff of republishing a failed event, it is doubled after each
+// attempt and capped by maxBackoff.
+func WithRetryBackoff(backoff time.Duration, maxBackoff time.Duration) RelayOption {
+	return func(o *relayOptions) {
+		if backoff > 0 {
+			o.retryBackoff = backoff
+		}
+		if maxBackoff >= o.retryBackoff {
+			o.maxRetryBackoff = maxBackoff
+		}
+	}
+}
+
+// WithCleanup set the retention of the published events and the interval of deleting them,
+// retention 0 means the published events are not deleted.
+func WithCleanup(retention time.Duration, interval time.Duration) RelayOption {
+	return func(o *relayOptions) {
+		if retention >= 0 {
+			o.retention = retention
+		}
+		if interval > 0 {
+			o.cleanupInterval = interval
+		}
+	}
+}
+
+// WithLogger set logger.
+func WithLogger(zapLogger *zap.Logger) RelayOption {
+	return func(o *relayOptions) {
+		if zapLogger != nil {
+			o.zapLogger = zapLogger
+		}
+	}
+}
+
+// ------------------------------------------------------------------------------------------
+
+// Relay polls the pending events of the outbox table and publishes them in the order of id, a failed
+// event is published again after the backoff and does not block the following events. The delivery
+// is at-least-once, an event may be published more than once if the relay exits after publishing it,
+// so the consumers should be idempotent. Multiple relays can run at the same time, an event is claimed
+// by only one relay during the lease.
+type Relay struct {
+	db        *gorm.DB
+	publisher Publisher
+	opts      *relayOptions
+	zapLog    *zap.Logger
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewRelay create a relay, the table outbox_event is created if it does not exist.
+func NewRelay(db *gorm.DB, publisher Publisher, opts ...RelayOption) (*Relay, error) {
+	if db == nil || publisher == nil {
+		return nil, errors.New("db and publisher cannot be nil")
+	}
+	o := defaultRelayOptions()
+	o.apply(opts...)
+
+	if err := Migrate(db); err != nil {
+		return nil, err
+	}
+
+	return &Relay{
+		db:        db,
+		publisher: publisher,
+		opts:      o,
+		zapLog:    o.zapLogger,
+	}, nil
+}
+
+// Start run the relay in the background until Stop is called.
+func (r *Relay) Start() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.cancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.done = make(chan struct{})
+	go func() {
+		defer close(r.done)
+		r.Run(ctx)
+	}()
+}
+
+// Stop stop the relay started by Start and wait for the batch being published.
+func (r *Relay) Stop() {
+	r.mu.Lock()
+	cancel, done := r.cancel, r.done
+	r.cancel, r.done = nil, nil
+	r.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// Run polls and publishes the events until ctx is done.
+func (r *Relay) Run(ctx context.Context) {
+	pollTicker := time.NewTicker(r.opts.pollInterval)
+	defer pollTicker.Stop()
+	cleanupTicker := time.NewTicker(r.opts.cleanupInterval)
+	defer cleanupTicker.Stop()
+
+	for {
+		// publish the next batch immediately if the batch is full
+		for {
+			n, err := r.PublishPending(ctx)
+			if err != nil && ctx.Err() == nil {
+				r.zapLog.Warn("outbox relay publish pending events error", zap.Error(err))
+			}
+			if err != nil || n < r.opts.batchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-cleanupTicker.C:
+			if _, err := r.Cleanup(ctx); err != nil && ctx.Err() == nil {
+				r.zapLog.Warn("outbox relay cleanup events error", zap.Error(err))
+			}
+		case <-pollTicker.C:
+		}
+	}
+}
+
+// PublishPending publish a batch of the pending events, return the number of events claimed.
+func (r *Relay) PublishPending(ctx context.Context) (int, error) {
+	var events []*Event
+	err := r.db.WithContext(ctx).Where("status = ? AND next_retry_at <= ?", StatusPending, time.Now()).
+		Order("id ASC").Limit(r.opts.batchSize).Find(&events).Error
+	if err != nil {
+		return 0, err
+	}
+
+	claimed := 0
+	for _, event := range events {
+		if ctx.Err() != nil {
+			return claimed, ctx.Err()
+		}
+
+		ok, err := r.claim(ctx, event)
+		if err != nil {
+			return claimed, err
+		}
+		if !ok { // claimed by another relay
+			continue
+		}
+		claimed++
+
+		if err = r.publisher.Publish(ctx, event); err != nil {
+			r.zapLog.Warn("outbox relay publish event error", zap.Error(err),
+				zap.Uint64("id", event.ID), zap.String("topic", event.Topic), zap.Int("attempts", event.Attempts+1))
+			if err = r.markFailed(ctx, event, err); err != nil {
+				return claimed, err
+			}
+			continue
+		}
+		if err = r.markPublished(ctx, event); err != nil {
+			return claimed, err
+		}
+	}
+
+	return claimed, nil
+}
+
+// Cleanup delete the published events older than the retention, return the number of events deleted.
+func (r *Relay) Cleanup(ctx context.Context) (int64, error) {
+	if r.opts.retention == 0 {
+		return 0, nil
+	}
+	result := r.db.WithContext(ctx).Where("status = ? AND published_at < ?", StatusPublished, time.Now().Add(-r.opts.retention)).
+		Delete(&Event{})
+	return result.RowsAffected, result.Error
+}
+
+// the event is claimed by moving next_retry_at to the end of the lease, other relays skip it during the lease
+func (r *Relay) claim(ctx context.Context, event *Event) (bool, error) {
+	now := time.Now()
+	result := r.db.WithContext(ctx).Model(&Event{}).
+		Where("id = ? AND status = ? AND next_retry_at <= ?", event.ID, StatusPending, now).
+		Update("next_retry_at", now.Add(r.opts.lease))
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *Relay) markPublished(ctx context.Context, event *Event) error {
+	now := time.Now()
+	return r.db.WithContext(ctx).Model(&Event{}).Where("id = ?", event.ID).Updates(map[string]interface{}{
+		"status":       StatusPublished,
+		"attempts":     event.Attempts + 1,
+		"last_error":   "",
+		"published_at": &now,
+	}).Error
+}
+
+func (r *Relay) markFailed(ctx context.Context, event *Event, publishErr error) error {
+	attempts := event.Attempts + 1
+	status := StatusPending
+	if r.opts.maxAttempts > 0 && attempts >= r.opts.maxAttempts {
+		status = StatusFailed
+	}
+	return r.db.WithContext(ctx).Model(&Event{}).Where("id = ?", event.ID).Updates(map[string]interface{}{
+		"status":        status,
+		"attempts":      attempts,
+		"last_error":    publishErr.Error(),
+		"next_retry_at": time.Now().Add(r.backoff(attempts)),
+	}).Error
+}
+
+func (r *Relay) backoff(attempts int) time.Duration {
+	backoff := r.opts.retryBackoff
+	for i := 1; i < attempts && backoff < r.opts.maxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > r.opts.maxRetryBackoff {
+		backoff = r.opts.maxRetryBackoff
+	}
+	return backoff
+}
@@ -238,9 +238,12 @@ type Consumer struct {
 	count int64 // consumer success message number
 }
 
-// Handler message
+// Handler message, ctx contains the trace context and request id of the producer
 type Handler func(ctx context.Context, data []byte, tagID string) error
 
+// DeliveryHandler message handler with the delivery, the headers and other properties of the message are available
+type DeliveryHandler func(ctx context.Context, d *amqp.Delivery, tagID string) error
+
 //type Handler func(ctx context.Context, d *amqp.Delivery, isAutoAck bool) error
 
 // NewConsumer create a consumer
@@ -366,6 +369,13 @@ func (c *Consumer) consumeWithContext(ctx context.Context) (<-chan amqp.Delivery
 
 // Consume messages for loop in goroutine
 func (c *Consumer) Consume(ctx context.Context, handler Handler) {
+	c.ConsumeDelivery(ctx, func(ctx context.Context, d *amqp.Delivery, tagID string) error {
+		return handler(ctx, d.Body, tagID)
+	})
+}
+
+// ConsumeDelivery consume messages for loop in goroutine, the handler gets the whole delivery
+func (c *Consumer) ConsumeDelivery(ctx context.Context, handler DeliveryHandler) {
 	go func() {
 		ticker := time.NewTicker(time.Second * 2)
 		isFirst := true
@@ -386,6 +396,9 @@ func (c *Consumer) Consume(ctx context.Context, handler Handler) {
 			case <-c.connection.exit:
 				c.Close()
 				return
+			case <-ctx.Done():
+				c.Close()
+				return
 			}
 			ticker.Stop()
 
@@ -415,7 +428,7 @@ func (c *Consumer) Consume(ctx context.Context, handler Handler) {
 						break
 					}
 					tagID := strings.Join([]string{d.Exchange, c.QueueName, strconv.FormatUint(d.DeliveryTag, 10)}, "/")
-					err = handler(ctx, d.Body, tagID)
+					err = handler(DeliveryContext(ctx, &d), &d, tagID)
 					if err != nil {
 						c.zapLog.Warn("[rabbitmq consumer] handle message error", zap.String("err", err.Error()), zap.String("tagID", tagID))
 						continue
@@ -0,0 +1,59 @@
+package rabbitmq
+
+import (
+	"context"
+	"fmt"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+
+	"github.com/go-dev-frame/sponge/pkg/msgctx"
+)
+
+// DeliveryContext extract the trace context and request id from the message headers into ctx,
+// the ctx of the consumer handler has been extracted, there is no need to call it again.
+func DeliveryContext(ctx context.Context, d *amqp.Delivery) context.Context {
+	return msgctx.Extract(ctx, tableCarrier(d.Headers))
+}
+
+// injectContext copy the headers and inject the trace context and request id of ctx into it,
+// return nil if the headers are empty.
+func injectContext(ctx context.Context, headers map[string]interface{}) amqp.Table {
+	table := make(amqp.Table, len(headers)+2)
+	for k, v := range headers {
+		table[k] = v
+	}
+	msgctx.Inject(ctx, tableCarrier(table))
+	if len(table) == 0 {
+		return nil
+	}
+	return table
+}
+
+type tableCarrier amqp.Table
+
+func (c tableCarrier) Get(key string) string {
+	switch v := c[key].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func (c tableCarrier) Set(key string, value string) {
+	if c != nil {
+		c[key] = value
+	}
+}
+
+func (c tableCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
@@ -0,0 +1,47 @@
+package rabbitmq
+
+import (
+	"context"
+	"testing"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/go-dev-frame/sponge/pkg/msgctx"
+)
+
+func TestInjectContext(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+		SpanID:     trace.SpanID{1, 2, 3, 4, 5, 6, 7, 8},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+	ctx = msgctx.WithRequestID(ctx, "req-1")
+
+	headersKeys := map[string]interface{}{"x-delay": 1000}
+	table := injectContext(ctx, headersKeys)
+	assert.Len(t, headersKeys, 1)
+	assert.Equal(t, 1000, table["x-delay"])
+	assert.Equal(t, "req-1", table[msgctx.HeaderRequestIDKey])
+	assert.NotEmpty(t, table["traceparent"])
+
+	ctx = DeliveryContext(context.Background(), &amqp.Delivery{Headers: table})
+	assert.Equal(t, sc.TraceID(), trace.SpanContextFromContext(ctx).TraceID())
+	assert.Equal(t, "req-1", msgctx.RequestID(ctx))
+
+	// no trace context and request id
+	assert.Nil(t, injectContext(context.Background(), nil))
+	ctx = DeliveryContext(context.Background(), &amqp.Delivery{})
+	assert.Equal(t, "", msgctx.RequestID(ctx))
+
+	carrier := tableCarrier{"a": []byte("foo"), "b": 1}
+	assert.Equal(t, "foo", carrier.Get("a"))
+	assert.Equal(t, "1", carrier.Get("b"))
+	assert.Len(t, carrier.Keys(), 2)
+	tableCarrier(nil).Set("a", "b")
+}
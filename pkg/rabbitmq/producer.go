@@ -255,6 +255,7 @@ func (p *Producer) PublishDirect(ctx context.Context, body []byte) error {
 		false,
 		amqp.Publishing{
 			DeliveryMode: p.deliveryMode,
+			Headers:      injectContext(ctx, nil),
 			ContentType:  "text/plain",
 			Body:         body,
 		},
@@ -274,6 +275,7 @@ func (p *Producer) PublishFanout(ctx context.Context, body []byte) error {
 		false,
 		amqp.Publishing{
 			DeliveryMode: p.deliveryMode,
+			Headers:      injectContext(ctx, nil),
 			ContentType:  "text/plain",
 			Body:         body,
 		},
@@ -293,6 +295,7 @@ func (p *Producer) PublishTopic(ctx context.Context, topicKey string, body []byt
 		false,
 		amqp.Publishing{
 			DeliveryMode: p.deliveryMode,
+			Headers:      injectContext(ctx, nil),
 			ContentType:  "text/plain",
 			Body:         body,
 		},
@@ -312,7 +315,43 @@ func (p *Producer) PublishHeaders(ctx context.Context, headersKeys map[string]in
 		false,
 		amqp.Publishing{
 			DeliveryMode: p.deliveryMode,
-			Headers:      headersKeys,
+			Headers:      injectContext(ctx, headersKeys),
+			ContentType:  "text/plain",
+			Body:         body,
+		},
+	)
+}
+
+// PublishWithHeaders send message with the headers, the routingKey is used by the topic exchange,
+// the routing key of the exchange is used by the direct, fanout and headers exchanges, the headers keys
+// of the headers exchange are added to the headers.
+func (p *Producer) PublishWithHeaders(ctx context.Context, routingKey string, headers map[string]interface{}, body []byte) error {
+	switch p.Exchange.eType {
+	case exchangeTypeDirect, exchangeTypeFanout:
+		routingKey = p.Exchange.routingKey
+	case exchangeTypeHeaders:
+		routingKey = p.Exchange.routingKey
+		merged := make(map[string]interface{}, len(headers)+len(p.Exchange.headersKeys))
+		for k, v := range headers {
+			merged[k] = v
+		}
+		for k, v := range p.Exchange.headersKeys {
+			merged[k] = v
+		}
+		headers = merged
+	case exchangeTypeTopic:
+	default:
+		return fmt.Errorf("invalid exchange type (%s), only supports direct, fanout, topic and headers type", p.Exchange.eType)
+	}
+	return p.ch.PublishWithContext(
+		ctx,
+		p.Exchange.name,
+		routingKey,
+		p.mandatory,
+		false,
+		amqp.Publishing{
+			DeliveryMode: p.deliveryMode,
+			Headers:      injectContext(ctx, headers),
 			ContentType:  "text/plain",
 			Body:         body,
 		},
@@ -351,7 +390,7 @@ func (p *Producer) PublishDelayedMessage(ctx context.Context, delayTime time.Dur
 		false,
 		amqp.Publishing{
 			DeliveryMode: p.deliveryMode,
-			Headers:      headersKeys,
+			Headers:      injectContext(ctx, headersKeys),
 			ContentType:  "text/plain",
 			Body:         body,
 		},
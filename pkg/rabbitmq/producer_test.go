@@ -298,6 +298,29 @@ func TestPublishErr(t *testing.T) {
 	assert.Error(t, err)
 	err = p.PublishDelayedMessage(ctx, time.Second, []byte("data"))
 	assert.Error(t, err)
+	err = p.PublishWithHeaders(ctx, "", nil, []byte("data"))
+	assert.Error(t, err)
+}
+
+func TestPublishWithHeaders(t *testing.T) {
+	exchanges := []*Exchange{
+		NewDirectExchange("foo", "bar"),
+		NewTopicExchange("foo", "bar"),
+		NewFanoutExchange("foo"),
+		NewHeadersExchange("foo", HeadersTypeAll, map[string]interface{}{"hello": "world"}),
+	}
+	for _, exchange := range exchanges {
+		func() {
+			p := &Producer{
+				QueueName: "foo",
+				conn:      &amqp.Connection{},
+				ch:        &amqp.Channel{},
+				Exchange:  exchange,
+			}
+			defer func() { recover() }()
+			_ = p.PublishWithHeaders(context.Background(), "foo", map[string]interface{}{"key": "value"}, []byte("data"))
+		}()
+	}
 }
 
 func TestPublishDirect(t *testing.T) {
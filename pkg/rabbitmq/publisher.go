@@ -79,6 +79,7 @@ func (p *Publisher) Publish(ctx context.Context, body []byte) error {
 		false,
 		amqp.Publishing{
 			DeliveryMode: p.deliveryMode,
+			Headers:      injectContext(ctx, nil),
 			ContentType:  "text/plain",
 			Body:         body,
 		},
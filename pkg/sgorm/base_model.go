@@ -2,6 +2,7 @@
 package sgorm
 
 import (
+	"errors"
 	"reflect"
 	"time"
 
@@ -15,6 +16,9 @@ type DB = gorm.DB
 
 var ErrRecordNotFound = gorm.ErrRecordNotFound
 
+// ErrVersionConflict the record has been modified by others, the version of optimistic lock is changed
+var ErrVersionConflict = errors.New("record version conflict")
+
 const (
 	// DBDriverMysql mysql driver
 	DBDriverMysql = "mysql"
@@ -0,0 +1,331 @@
+// Package migrate is a schema migration runner based on gorm, the migrations are versioned sql files
+// in a directory, named <version>_<name>.up.sql and <version>_<name>.down.sql.
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultTableName = "schema_migrations"
+
+	upSuffix   = ".up.sql"
+	downSuffix = ".down.sql"
+
+	// VersionLayout the layout of migration version
+	VersionLayout = "20060102150405"
+)
+
+var (
+	fileNameRegexp    = regexp.MustCompile(`^(\d+)_([\w-]+)\.(up|down)\.sql$`)
+	invalidNameRegexp = regexp.MustCompile(`[^\w-]+`)
+)
+
+// Migration a versioned migration
+type Migration struct {
+	Version string
+	Name    string
+	Up      string // sql statements to upgrade
+	Down    string // sql statements to rollback
+}
+
+// Status the status of migration
+type Status struct {
+	Version   string     `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"appliedAt"`
+}
+
+type schemaMigration struct {
+	Version   string    `gorm:"column:version;type:varchar(32);primary_key"`
+	Name      string    `gorm:"column:name;type:varchar(255)"`
+	AppliedAt time.Time `gorm:"column:applied_at"`
+}
+
+// WriteFiles write the up and down sql statements to the migration files in dir, the version is the current time.
+func WriteFiles(dir string, name string, up []string, down []string) (upFile string, downFile string, err error) {
+	if len(up) == 0 {
+		return "", "", errors.New("no sql statements to write")
+	}
+	name = strings.Trim(invalidNameRegexp.ReplaceAllString(name, "_"), "_")
+	if name == "" {
+		name = "migration"
+	}
+	if err = os.MkdirAll(dir, 0766); err != nil {
+		return "", "", err
+	}
+
+	prefix := filepath.Join(dir, time.Now().Format(VersionLayout)+"_"+name)
+	upFile, downFile = prefix+upSuffix, prefix+downSuffix
+	if err = os.WriteFile(upFile, []byte(strings.Join(up, "\n\n")+"\n"), 0666); err != nil {
+		return "", "", err
+	}
+	if err = os.WriteFile(downFile, []byte(strings.Join(down, "\n\n")+"\n"), 0666); err != nil {
+		return "", "", err
+	}
+
+	return upFile, downFile, nil
+}
+
+// Load the migrations from dir, sorted by version in ascending order.
+func Load(dir string) ([]*Migration, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	migrationMap := make(map[string]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		matches := fileNameRegexp.FindStringSubmatch(entry.Name())
+		if len(matches) != 4 {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		version, name := matches[1], matches[2]
+		m, ok := migrationMap[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			migrationMap[version] = m
+		} else if m.Name != name {
+			return nil, fmt.Errorf("duplicate migration version %s (%s and %s)", version, m.Name, name)
+		}
+		if matches[3] == "up" {
+			m.Up = string(data)
+		} else {
+			m.Down = string(data)
+		}
+	}
+
+	migrations := make([]*Migration, 0, len(migrationMap))
+	for _, m := range migrationMap {
+		migrations = append(migrations, m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Migrator apply or rollback the migrations
+type Migrator struct {
+	db         *gorm.DB
+	tableName  string
+	migrations []*Migration
+}
+
+// New create a migrator, the migrations are loaded from dir
+func New(db *gorm.DB, dir string, opts ...Option) (*Migrator, error) {
+	migrations, err := Load(dir)
+	if err != nil {
+		return nil, err
+	}
+	return NewWithMigrations(db, migrations, opts...)
+}
+
+// NewWithMigrations create a migrator with the specified migrations
+func NewWithMigrations(db *gorm.DB, migrations []*Migration, opts ...Option) (*Migrator, error) {
+	if db == nil {
+		return nil, errors.New("db is nil")
+	}
+	o := defaultOptions()
+	o.apply(opts...)
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return &Migrator{
+		db:         db,
+		tableName:  o.tableName,
+		migrations: migrations,
+	}, nil
+}
+
+func (m *Migrator) init(ctx context.Context) error {
+	return m.db.WithContext(ctx).Table(m.tableName).AutoMigrate(&schemaMigration{})
+}
+
+func (m *Migrator) getApplied(ctx context.Context) (map[string]*schemaMigration, error) {
+	if err := m.init(ctx); err != nil {
+		return nil, err
+	}
+
+	var records []*schemaMigration
+	err := m.db.WithContext(ctx).Table(m.tableName).Order("version").Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make(map[string]*schemaMigration, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+	return applied, nil
+}
+
+// Status get the status of all migrations
+func (m *Migrator) Status(ctx context.Context) ([]*Status, error) {
+	applied, err := m.getApplied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]*Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := &Status{Version: migration.Version, Name: migration.Name}
+		if record, ok := applied[migration.Version]; ok {
+			status.Applied = true
+			appliedAt := record.AppliedAt
+			status.AppliedAt = &appliedAt
+		}
+		list = append(list, status)
+	}
+	return list, nil
+}
+
+// Up apply the pending migrations in ascending order of version, steps < 1 means all pending migrations,
+// return the applied migrations. The migration whose up sql has no executable statements is refused.
+func (m *Migrator) Up(ctx context.Context, steps int) ([]*Migration, error) {
+	applied, err := m.getApplied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []*Migration
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+		if steps > 0 && len(done) >= steps {
+			break
+		}
+		// a migration without executable statements is not recorded as applied, e.g. the changes are only comments
+		if len(SplitStatements(migration.Up)) == 0 {
+			return done, fmt.Errorf("apply migration %s_%s error: no executable sql statements", migration.Version, migration.Name)
+		}
+
+		err = m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := execStatements(tx, migration.Up); err != nil {
+				return err
+			}
+			record := &schemaMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}
+			return tx.Table(m.tableName).Create(record).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("apply migration %s_%s error: %v", migration.Version, migration.Name, err)
+		}
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+// Down rollback the applied migrations in descending order of version, steps < 1 means rollback the latest one,
+// return the rolled back migrations.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]*Migration, error) {
+	if steps < 1 {
+		steps = 1
+	}
+	applied, err := m.getApplied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []*Migration
+	for i := len(m.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+
+		err = m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := execStatements(tx, migration.Down); err != nil {
+				return err
+			}
+			return tx.Table(m.tableName).Where("version = ?", migration.Version).Delete(&schemaMigration{}).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("rollback migration %s_%s error: %v", migration.Version, migration.Name, err)
+		}
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+// note: the DDL statements of mysql cause an implicit commit, the transaction can't rollback them.
+func execStatements(tx *gorm.DB, sql string) error {
+	for _, stmt := range SplitStatements(sql) {
+		if err := tx.Exec(stmt).Error; err != nil {
+			return fmt.Errorf("%v, sql: %s", err, stmt)
+		}
+	}
+	return nil
+}
+
+// SplitStatements split the sql into statements by semicolon, the statements that contain only comments are ignored.
+func SplitStatements(sql string) []string {
+	var stmts []string
+	var quote rune
+	start := 0
+	runes := []rune(sql)
+
+	addStatement := func(s string) {
+		if stmt := trimLeadingComments(s); stmt != "" {
+			stmts = append(stmts, stmt)
+		}
+	}
+
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case c == ';':
+			addStatement(string(runes[start:i]))
+			start = i + 1
+		}
+	}
+	addStatement(string(runes[start:]))
+
+	return stmts
+}
+
+// remove the comment lines before the statement, return empty if there are only comments
+func trimLeadingComments(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "--") {
+			return strings.TrimSpace(strings.Join(lines[i:], "\n"))
+		}
+	}
+	return ""
+}
@@ -0,0 +1,121 @@
+package migrate
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-dev-frame/sponge/pkg/sgorm/sqlite"
+)
+
+func TestSplitStatements(t *testing.T) {
+	sql := `-- create table
+CREATE TABLE "user" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "name" text NOT NULL DEFAULT 'a;b' -- comment;
+);
+
+-- todo only comment;
+
+ALTER TABLE "user" ADD COLUMN "age" integer`
+
+	stmts := SplitStatements(sql)
+	assert.Len(t, stmts, 2)
+	assert.Contains(t, stmts[0], `DEFAULT 'a;b'`)
+	assert.Equal(t, `ALTER TABLE "user" ADD COLUMN "age" integer`, stmts[1])
+	assert.Empty(t, SplitStatements("-- only comment;\n"))
+}
+
+func TestWriteFilesAndLoad(t *testing.T) {
+	dir := t.TempDir()
+	upFile, downFile, err := WriteFiles(dir, "add user table", []string{"CREATE TABLE user (id integer);"}, []string{"DROP TABLE user;"})
+	assert.NoError(t, err)
+	assert.FileExists(t, upFile)
+	assert.FileExists(t, downFile)
+	_ = os.WriteFile(filepath.Join(dir, "readme.md"), []byte("ignored"), 0666)
+
+	migrations, err := Load(dir)
+	assert.NoError(t, err)
+	assert.Len(t, migrations, 1)
+	assert.Equal(t, "add_user_table", migrations[0].Name)
+	assert.Contains(t, migrations[0].Up, "CREATE TABLE")
+	assert.Contains(t, migrations[0].Down, "DROP TABLE")
+
+	_, _, err = WriteFiles(dir, "empty", nil, nil)
+	assert.Error(t, err)
+	_, err = Load(filepath.Join(dir, "not_exist"))
+	assert.Error(t, err)
+}
+
+func TestMigrator(t *testing.T) {
+	db, err := sqlite.Init(filepath.Join(t.TempDir(), "migrate.db"))
+	if err != nil {
+		t.Log(err)
+		return
+	}
+	defer sqlite.Close(db) //nolint
+
+	migrations := []*Migration{
+		{
+			Version: "20240102000000",
+			Name:    "add_age",
+			Up:      `ALTER TABLE "user" ADD COLUMN "age" integer NOT NULL DEFAULT 0;`,
+			Down:    `ALTER TABLE "user" DROP COLUMN "age";`,
+		},
+		{
+			Version: "20240101000000",
+			Name:    "create_user",
+			Up:      "CREATE TABLE \"user\" (\n    \"id\" integer PRIMARY KEY AUTOINCREMENT,\n    \"name\" text NOT NULL\n);",
+			Down:    `DROP TABLE IF EXISTS "user";`,
+		},
+	}
+	m, err := NewWithMigrations(db, migrations, WithTableName("my_migrations"))
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	done, err := m.Up(ctx, 1)
+	assert.NoError(t, err)
+	assert.Len(t, done, 1)
+	assert.Equal(t, "create_user", done[0].Name)
+
+	done, err = m.Up(ctx, 0)
+	assert.NoError(t, err)
+	assert.Len(t, done, 1)
+	assert.NoError(t, db.Exec(`INSERT INTO "user" ("name", "age") VALUES ('foo', 1)`).Error)
+
+	list, err := m.Status(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, list, 2)
+	assert.True(t, list[0].Applied && list[1].Applied)
+
+	done, err = m.Down(ctx, 0)
+	assert.NoError(t, err)
+	assert.Len(t, done, 1)
+	assert.Equal(t, "add_age", done[0].Name)
+	list, _ = m.Status(ctx)
+	assert.False(t, list[1].Applied)
+
+	// failed migration is not recorded
+	m, _ = NewWithMigrations(db, append(migrations, &Migration{Version: "20240103000000", Name: "bad", Up: "ALTER TABLE not_exist ADD COLUMN x int;"}), WithTableName("my_migrations"))
+	done, err = m.Up(ctx, 0)
+	assert.Error(t, err)
+	assert.Len(t, done, 1) // add_age is applied again, bad is failed
+	list, _ = m.Status(ctx)
+	assert.True(t, list[1].Applied)
+	assert.False(t, list[2].Applied)
+
+	// migration with only comments is refused and not recorded
+	m, _ = NewWithMigrations(db, append(migrations, &Migration{Version: "20240104000000", Name: "comment_only", Up: "-- rebuild table manually\n"}), WithTableName("my_migrations"))
+	done, err = m.Up(ctx, 0)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "no executable sql statements")
+	assert.Empty(t, done)
+	list, _ = m.Status(ctx)
+	assert.False(t, list[2].Applied)
+
+	_, err = NewWithMigrations(nil, nil)
+	assert.Error(t, err)
+}
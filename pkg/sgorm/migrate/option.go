@@ -0,0 +1,30 @@
+package migrate
+
+// Option set the migrator options.
+type Option func(*options)
+
+type options struct {
+	tableName string
+}
+
+func (o *options) apply(opts ...Option) {
+	for _, opt := range opts {
+		opt(o)
+	}
+}
+
+// default settings
+func defaultOptions() *options {
+	return &options{
+		tableName: defaultTableName,
+	}
+}
+
+// WithTableName set the name of table that records the applied migrations, default is schema_migrations
+func WithTableName(name string) Option {
+	return func(o *options) {
+		if name != "" {
+			o.tableName = name
+		}
+	}
+}
@@ -0,0 +1,474 @@
+package query
+
+import (
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	cursorTypeNull   = "n"
+	cursorTypeInt    = "i"
+	cursorTypeUint   = "u"
+	cursorTypeFloat  = "f"
+	cursorTypeBool   = "b"
+	cursorTypeTime   = "t"
+	cursorTypeString = "s"
+)
+
+// CursorInfo the cursor tokens of the adjacent pages, empty value means there is no adjacent page
+type CursorInfo struct {
+	Next string `json:"next"` // cursor of the next page
+	Prev string `json:"prev"` // cursor of the previous page
+}
+
+// cursor content, it is encoded as an opaque token
+type cursor struct {
+	Sort   string        `json:"s"`           // sort of the query that generated the cursor
+	Values []cursorValue `json:"v"`           // sort column values of the boundary record
+	Prev   bool          `json:"p,omitempty"` // true means turning to the previous page
+}
+
+type cursorValue struct {
+	Type  string `json:"t"`
+	Value string `json:"v,omitempty"`
+}
+
+type sortColumn struct {
+	name string
+	desc bool
+}
+
+// KeysetPage keyset (cursor) paging info, the records are located by the sort column values
+// of the cursor instead of offset, so the query performance does not degrade on later pages.
+// Note: sort columns should not be null, and it is recommended to add an index to them.
+type KeysetPage struct {
+	limit   int
+	sort    string
+	columns []sortColumn
+	cursor  *cursor // nil means the first page
+}
+
+// ConvertToKeysetPage converted to keyset page, the parameter keyColumns are unique columns
+// appended to the sort columns to make the order stable, default is id.
+func (p *Params) ConvertToKeysetPage(keyColumns ...string) (*KeysetPage, error) {
+	if len(keyColumns) == 0 {
+		keyColumns = []string{"id"}
+	}
+
+	columns, err := parseSortColumns(p.Sort, keyColumns)
+	if err != nil {
+		return nil, err
+	}
+	strs := make([]string, 0, len(columns))
+	for _, col := range columns {
+		if col.desc {
+			strs = append(strs, "-"+col.name)
+		} else {
+			strs = append(strs, col.name)
+		}
+	}
+
+	page := &KeysetPage{
+		limit:   NewPage(0, p.Limit, "").limit,
+		sort:    strings.Join(strs, ","),
+		columns: columns,
+	}
+
+	if p.Cursor != "" {
+		c, err := decodeCursor(p.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		if c.Sort != page.sort || len(c.Values) != len(columns) {
+			return nil, fmt.Errorf("cursor does not match the sort '%s'", page.sort)
+		}
+		page.cursor = c
+	}
+
+	return page, nil
+}
+
+// Limit number per page, query Limit()+1 records to determine whether there is a next page
+func (k *KeysetPage) Limit() int {
+	return k.limit
+}
+
+// Order get sort of the query, the direction is reversed when turning to the previous page
+func (k *KeysetPage) Order() string {
+	strs := make([]string, 0, len(k.columns))
+	for _, col := range k.columns {
+		if k.isDesc(col) {
+			strs = append(strs, col.name+" DESC")
+		} else {
+			strs = append(strs, col.name+" ASC")
+		}
+	}
+	return strings.Join(strs, ", ")
+}
+
+// Conditions get the gorm query conditions of the cursor, the query string is empty on the first page,
+// e.g. sort=-age, the conditions are "(age < ?) OR (age = ? AND id < ?)"
+func (k *KeysetPage) Conditions() (string, []interface{}) {
+	if k.cursor == nil {
+		return "", nil
+	}
+
+	var (
+		ors  = make([]string, 0, len(k.columns))
+		args []interface{}
+	)
+	for i, col := range k.columns {
+		ands := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			ands = append(ands, k.columns[j].name+" = ?")
+			args = append(args, k.cursor.Values[j].decode())
+		}
+		exp := " > ?"
+		if k.isDesc(col) {
+			exp = " < ?"
+		}
+		ands = append(ands, col.name+exp)
+		args = append(args, k.cursor.Values[i].decode())
+		ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+	}
+
+	return "(" + strings.Join(ors, " OR ") + ")", args
+}
+
+// Cursors trim the records to the page size, restore their order when turning to the previous page,
+// and return the cursors of adjacent pages. the parameter records must be a pointer to the slice
+// of records queried by Order(), Conditions() and Limit()+1.
+func (k *KeysetPage) Cursors(records interface{}) (*CursorInfo, error) {
+	rv := reflect.ValueOf(records)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Slice {
+		return nil, fmt.Errorf("records must be a pointer to slice, but got %T", records)
+	}
+	list := rv.Elem()
+
+	hasMore := list.Len() > k.limit
+	if hasMore {
+		list = list.Slice(0, k.limit)
+	}
+	isPrev := k.cursor != nil && k.cursor.Prev
+	if isPrev {
+		swap := reflect.Swapper(list.Interface())
+		for i, j := 0, list.Len()-1; i < j; i, j = i+1, j-1 {
+			swap(i, j)
+		}
+	}
+	rv.Elem().Set(list)
+
+	info := &CursorInfo{}
+	if list.Len() == 0 {
+		if k.cursor != nil { // allow turning back to the page where the cursor came from
+			token, err := encodeCursor(&cursor{Sort: k.sort, Values: k.cursor.Values, Prev: !isPrev})
+			if err != nil {
+				return nil, err
+			}
+			if isPrev {
+				info.Next = token
+			} else {
+				info.Prev = token
+			}
+		}
+		return info, nil
+	}
+
+	var err error
+	if hasMore || isPrev {
+		info.Next, err = k.encode(list.Index(list.Len()-1), false)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if (hasMore && isPrev) || (k.cursor != nil && !isPrev) {
+		info.Prev, err = k.encode(list.Index(0), true)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return info, nil
+}
+
+// Fields append the sort columns to the selected fields if they are not selected, so that Cursors
+// can get the values of the boundary record, empty fields means all fields are selected.
+func (k *KeysetPage) Fields(fields []string) []string {
+	if len(fields) == 0 {
+		return fields
+	}
+
+	exists := make(map[string]bool, len(fields))
+	selected := make([]string, 0, len(fields)+len(k.columns))
+	for _, field := range fields {
+		exists[field] = true
+		selected = append(selected, field)
+	}
+	for _, col := range k.columns {
+		if !exists[col.name] {
+			exists[col.name] = true
+			selected = append(selected, col.name)
+		}
+	}
+	return selected
+}
+
+func (k *KeysetPage) isDesc(col sortColumn) bool {
+	if k.cursor != nil && k.cursor.Prev {
+		return !col.desc
+	}
+	return col.desc
+}
+
+func (k *KeysetPage) encode(record reflect.Value, isPrev bool) (string, error) {
+	c := &cursor{Sort: k.sort, Prev: isPrev}
+	for _, col := range k.columns {
+		name := col.name
+		if i := strings.LastIndex(name, "."); i >= 0 {
+			name = name[i+1:]
+		}
+		value, ok := getColumnValue(record, name)
+		if !ok {
+			return "", fmt.Errorf("column '%s' not found in record %s", name, record.Type())
+		}
+		cv, err := newCursorValue(value)
+		if err != nil {
+			return "", err
+		}
+		c.Values = append(c.Values, cv)
+	}
+	return encodeCursor(c)
+}
+
+func parseSortColumns(sort string, keyColumns []string) ([]sortColumn, error) {
+	sort = strings.Replace(sort, " ", "", -1)
+	if sort == "" {
+		sort = "-" + strings.Join(keyColumns, ",-")
+	}
+
+	var (
+		columns []sortColumn
+		exists  = map[string]bool{}
+	)
+	for _, name := range strings.Split(sort, ",") {
+		col := sortColumn{name: name}
+		if strings.HasPrefix(name, "-") {
+			col = sortColumn{name: name[1:], desc: true}
+		}
+		if !columnRegexp.MatchString(col.name) {
+			return nil, fmt.Errorf("invalid sort column '%s'", col.name)
+		}
+		if exists[col.name] {
+			continue
+		}
+		exists[col.name] = true
+		columns = append(columns, col)
+	}
+
+	// append the key columns to make the order stable
+	desc := columns[len(columns)-1].desc
+	for _, name := range keyColumns {
+		if !columnRegexp.MatchString(name) {
+			return nil, fmt.Errorf("invalid key column '%s'", name)
+		}
+		if exists[name] {
+			continue
+		}
+		exists[name] = true
+		columns = append(columns, sortColumn{name: name, desc: desc})
+	}
+
+	return columns, nil
+}
+
+func encodeCursor(c *cursor) (string, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeCursor(token string) (*cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor '%s'", token)
+	}
+	c := &cursor{}
+	if err = json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("invalid cursor '%s'", token)
+	}
+	for _, v := range c.Values {
+		if err = v.check(); err != nil {
+			return nil, fmt.Errorf("invalid cursor '%s', %v", token, err)
+		}
+	}
+	return c, nil
+}
+
+func newCursorValue(value interface{}) (cursorValue, error) {
+	if valuer, ok := value.(driver.Valuer); ok {
+		v, err := valuer.Value()
+		if err != nil {
+			return cursorValue{}, err
+		}
+		value = v
+	}
+
+	rv := reflect.ValueOf(value)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return cursorValue{Type: cursorTypeNull}, nil
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return cursorValue{Type: cursorTypeNull}, nil
+	}
+
+	if t, ok := rv.Interface().(time.Time); ok {
+		return cursorValue{Type: cursorTypeTime, Value: t.Format(time.RFC3339Nano)}, nil
+	}
+	switch rv.Kind() { //nolint
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cursorValue{Type: cursorTypeInt, Value: strconv.FormatInt(rv.Int(), 10)}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return cursorValue{Type: cursorTypeUint, Value: strconv.FormatUint(rv.Uint(), 10)}, nil
+	case reflect.Float32, reflect.Float64:
+		return cursorValue{Type: cursorTypeFloat, Value: strconv.FormatFloat(rv.Float(), 'g', -1, 64)}, nil
+	case reflect.Bool:
+		return cursorValue{Type: cursorTypeBool, Value: strconv.FormatBool(rv.Bool())}, nil
+	case reflect.String:
+		return cursorValue{Type: cursorTypeString, Value: rv.String()}, nil
+	}
+	if b, ok := rv.Interface().([]byte); ok {
+		return cursorValue{Type: cursorTypeString, Value: string(b)}, nil
+	}
+
+	return cursorValue{Type: cursorTypeString, Value: fmt.Sprintf("%v", rv.Interface())}, nil
+}
+
+func (v cursorValue) check() error {
+	var err error
+	switch v.Type {
+	case cursorTypeNull, cursorTypeString:
+	case cursorTypeInt:
+		_, err = strconv.ParseInt(v.Value, 10, 64)
+	case cursorTypeUint:
+		_, err = strconv.ParseUint(v.Value, 10, 64)
+	case cursorTypeFloat:
+		_, err = strconv.ParseFloat(v.Value, 64)
+	case cursorTypeBool:
+		_, err = strconv.ParseBool(v.Value)
+	case cursorTypeTime:
+		_, err = time.Parse(time.RFC3339Nano, v.Value)
+	default:
+		err = fmt.Errorf("unknown value type '%s'", v.Type)
+	}
+	return err
+}
+
+// decode the value, it has been checked when decoding cursor
+func (v cursorValue) decode() interface{} {
+	switch v.Type {
+	case cursorTypeNull:
+		return nil
+	case cursorTypeInt:
+		i, _ := strconv.ParseInt(v.Value, 10, 64)
+		return i
+	case cursorTypeUint:
+		u, _ := strconv.ParseUint(v.Value, 10, 64)
+		return u
+	case cursorTypeFloat:
+		f, _ := strconv.ParseFloat(v.Value, 64)
+		return f
+	case cursorTypeBool:
+		b, _ := strconv.ParseBool(v.Value)
+		return b
+	case cursorTypeTime:
+		t, _ := time.Parse(time.RFC3339Nano, v.Value)
+		return t
+	}
+	return v.Value
+}
+
+// get the column value from the record, the column name of struct field is
+// taken from the gorm tag 'column', otherwise it is the snake case of field name.
+func getColumnValue(record reflect.Value, column string) (interface{}, bool) {
+	for record.Kind() == reflect.Ptr || record.Kind() == reflect.Interface {
+		if record.IsNil() {
+			return nil, false
+		}
+		record = record.Elem()
+	}
+
+	switch record.Kind() { //nolint
+	case reflect.Map:
+		if record.Type().Key().Kind() != reflect.String {
+			return nil, false
+		}
+		v := record.MapIndex(reflect.ValueOf(column).Convert(record.Type().Key()))
+		if !v.IsValid() {
+			return nil, false
+		}
+		return v.Interface(), true
+
+	case reflect.Struct:
+		rt := record.Type()
+		for i := 0; i < rt.NumField(); i++ {
+			field := rt.Field(i)
+			tag := field.Tag.Get("gorm")
+			if tag == "-" {
+				continue
+			}
+			if field.Anonymous || strings.Contains(tag, "embedded") {
+				if v, ok := getColumnValue(record.Field(i), column); ok {
+					return v, true
+				}
+				continue
+			}
+			if field.PkgPath != "" { // unexported field
+				continue
+			}
+			if getGormColumnName(field) == column {
+				return record.Field(i).Interface(), true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+func getGormColumnName(field reflect.StructField) string {
+	for _, setting := range strings.Split(field.Tag.Get("gorm"), ";") {
+		kv := strings.SplitN(setting, ":", 2)
+		if len(kv) == 2 && strings.EqualFold(strings.TrimSpace(kv[0]), "column") {
+			return strings.TrimSpace(kv[1])
+		}
+	}
+	return toSnakeCase(field.Name)
+}
+
+// convert field name to snake case, e.g. UserID --> user_id, HTTPCode --> http_code
+func toSnakeCase(name string) string {
+	runes := []rune(name)
+	builder := strings.Builder{}
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (!unicode.IsUpper(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				builder.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		builder.WriteRune(r)
+	}
+	return builder.String()
+}
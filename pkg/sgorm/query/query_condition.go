@@ -3,7 +3,12 @@ package query
 
 import (
 	"fmt"
+	"reflect"
+	"regexp"
 	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 const (
@@ -23,6 +28,20 @@ const (
 	Like = "like"
 	// In include
 	In = "in"
+	// NotIn not include
+	NotIn = "notin"
+	// Between in the range of two values, including the boundary values
+	Between = "between"
+	// IsNull is null, the value is ignored
+	IsNull = "isnull"
+	// NotNull is not null, the value is ignored
+	NotNull = "notnull"
+	// NotLike fuzzy lookup of not include
+	NotLike = "notlike"
+	// Prefix fuzzy lookup of prefix, e.g. value%
+	Prefix = "prefix"
+	// Suffix fuzzy lookup of suffix, e.g. %value
+	Suffix = "suffix"
 
 	// AND logic and
 	AND string = "and"
@@ -40,6 +59,14 @@ var expMap = map[string]string{
 	Like: " LIKE ",
 	In:   " IN ",
 
+	NotIn:   " NOT IN ",
+	Between: " BETWEEN ",
+	IsNull:  " IS NULL",
+	NotNull: " IS NOT NULL",
+	NotLike: " NOT LIKE ",
+	Prefix:  " LIKE ",
+	Suffix:  " LIKE ",
+
 	"=":  " = ",
 	"!=": " <> ",
 	">":  " > ",
@@ -66,26 +93,59 @@ type Params struct {
 	Limit int    `json:"limit" form:"limit" binding:"gte=1"`
 	Sort  string `json:"sort,omitempty" form:"sort" binding:""`
 
+	// cursor of keyset paging, it is returned by the previous query, empty means the first page, not required
+	Cursor string `json:"cursor,omitempty" form:"cursor"`
+
 	Columns []Column `json:"columns,omitempty" form:"columns"` // not required
 
+	// conditions of tree structure, it is combined with Columns by and, not required
+	Where *Group `json:"where,omitempty" form:"-"`
+
+	// fields to be returned, multiple fields separated by comma, empty means all fields, not required
+	Fields string `json:"fields,omitempty" form:"fields"`
+
 	// Deprecated: use Limit instead in sponge version v1.8.6, will remove in the future
 	Size int `json:"size" form:"size"`
 }
 
+// the max nesting depth of group
+const maxGroupDepth = 10
+
+var (
+	columnRegexp   = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*(\.[a-zA-Z_][a-zA-Z0-9_]*)?$`)
+	jsonPathRegexp = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
+)
+
 // Column query info
 type Column struct {
-	Name  string      `json:"name" form:"name"`   // column name
-	Exp   string      `json:"exp" form:"exp"`     // expressions, default value is "=", support =, !=, >, >=, <, <=, like, in
-	Value interface{} `json:"value" form:"value"` // column value
+	// column name, the path of json column is separated by ->>, e.g. profile->>address.city
+	Name  string      `json:"name" form:"name"`
+	Exp   string      `json:"exp" form:"exp"`     // expressions, default value is "=", support =, !=, >, >=, <, <=, like, in, notin, between, isnull, notnull, notlike, prefix, suffix
+	Value interface{} `json:"value" form:"value"` // column value, if exp=in, notin or between, multiple values are separated by commas
 	Logic string      `json:"logic" form:"logic"` // logical type, defaults to and when the value is null, with &(and), ||(or)
 }
 
+// Group conditions of tree structure, the columns and sub groups are combined by the logical type of group,
+// e.g. (a=1 OR b=2) AND (c=3 OR d=4) is expressed as
+// {"logic":"and","groups":[{"logic":"or","columns":[a=1,b=2]},{"logic":"or","columns":[c=3,d=4]}]}
+type Group struct {
+	Logic   string   `json:"logic,omitempty" form:"logic"`     // logical type, defaults to and when the value is null, with &(and), ||(or)
+	Columns []Column `json:"columns,omitempty" form:"columns"` // column conditions, the logical type of column is ignored
+	Groups  []Group  `json:"groups,omitempty" form:"groups"`   // sub groups
+}
+
 func (c *Column) checkValid() error {
 	if c.Name == "" {
 		return fmt.Errorf("field 'name' cannot be empty")
 	}
+	if _, _, err := parseColumnName(c.Name); err != nil {
+		return err
+	}
 	if c.Value == nil {
-		return fmt.Errorf("field 'value' cannot be nil")
+		exp := strings.ToLower(c.Exp)
+		if exp != IsNull && exp != NotNull {
+			return fmt.Errorf("field 'value' cannot be nil")
+		}
 	}
 	return nil
 }
@@ -95,22 +155,33 @@ func (c *Column) convert() error {
 	if c.Exp == "" {
 		c.Exp = Eq
 	}
-	if v, ok := expMap[strings.ToLower(c.Exp)]; ok { //nolint
+	exp := strings.ToLower(c.Exp)
+	if v, ok := expMap[exp]; ok { //nolint
 		c.Exp = v
-		if c.Exp == " LIKE " {
+		switch exp {
+		case Like, NotLike:
 			c.Value = fmt.Sprintf("%%%v%%", c.Value)
-		}
-		if c.Exp == " IN " {
-			val, ok := c.Value.(string)
-			if !ok {
-				return fmt.Errorf("invalid value type '%s'", c.Value)
+		case Prefix:
+			c.Value = fmt.Sprintf("%v%%", c.Value)
+		case Suffix:
+			c.Value = fmt.Sprintf("%%%v", c.Value)
+		case In, NotIn:
+			values, err := splitValues(c.Value)
+			if err != nil {
+				return err
+			}
+			c.Value = values
+		case Between:
+			values, err := splitValues(c.Value)
+			if err != nil {
+				return err
 			}
-			iVal := []interface{}{}
-			ss := strings.Split(val, ",")
-			for _, s := range ss {
-				iVal = append(iVal, s)
+			if len(values) != 2 {
+				return fmt.Errorf("exp type 'between' requires 2 values, but got %d", len(values))
 			}
-			c.Value = iVal
+			c.Value = values
+		case IsNull, NotNull:
+			c.Value = nil
 		}
 	} else {
 		return fmt.Errorf("unknown exp type '%s'", c.Exp)
@@ -137,12 +208,48 @@ func (p *Params) ConvertToPage() (order string, limit int, offset int) { //nolin
 	return //nolint
 }
 
-// ConvertToGormConditions conversion to gorm-compliant parameters based on the Columns parameter
-// ignore the logical type of the last column, whether it is a one-column or multi-column query
-func (p *Params) ConvertToGormConditions() (string, []interface{}, error) {
+// ConvertToGormConditions conversion to gorm-compliant parameters based on the Columns and Where parameters
+// ignore the logical type of the last column, whether it is a one-column or multi-column query,
+// if the whitelist is set, the columns of conditions and sort are checked by the whitelist.
+func (p *Params) ConvertToGormConditions(opts ...Option) (string, []interface{}, error) {
+	o := defaultOptions()
+	o.apply(opts...)
+	if w := o.whitelist; w != nil {
+		if err := w.checkSort(p.Sort); err != nil {
+			return "", nil, err
+		}
+		if err := w.checkColumns(p.Columns); err != nil {
+			return "", nil, err
+		}
+		if p.Where != nil {
+			if err := w.checkGroup(p.Where); err != nil {
+				return "", nil, err
+			}
+		}
+	}
+
+	str, args, err := convertColumns(p.Columns)
+	if err != nil || p.Where == nil {
+		return str, args, err
+	}
+
+	whereStr, whereArgs, err := p.Where.ConvertToGorm()
+	if err != nil {
+		return "", nil, err
+	}
+	if whereStr == "" {
+		return str, args, nil
+	}
+	if str == "" {
+		return whereStr, whereArgs, nil
+	}
+	return "(" + str + ") AND (" + whereStr + ")", append(args, whereArgs...), nil
+}
+
+func convertColumns(columns []Column) (string, []interface{}, error) {
 	str := ""
 	args := []interface{}{}
-	l := len(p.Columns)
+	l := len(columns)
 	if l == 0 {
 		return "", nil, nil
 	}
@@ -151,9 +258,9 @@ func (p *Params) ConvertToGormConditions() (string, []interface{}, error) {
 	if l == 1 {
 		isUseIN = false
 	}
-	field := p.Columns[0].Name
+	field := columns[0].Name
 
-	for i, column := range p.Columns {
+	for i, column := range columns {
 		if err := column.checkValid(); err != nil {
 			return "", nil, err
 		}
@@ -163,20 +270,17 @@ func (p *Params) ConvertToGormConditions() (string, []interface{}, error) {
 			return "", nil, err
 		}
 
-		symbol := "?"
-		if column.Exp == " IN " {
-			symbol = "(?)"
-		}
+		expr, exprArgs := column.toGormExpr()
 		if i == l-1 { // ignore the logical type of the last column
-			str += column.Name + column.Exp + symbol
+			str += expr
 		} else {
-			str += column.Name + column.Exp + symbol + column.Logic
+			str += expr + column.Logic
 		}
-		args = append(args, column.Value)
+		args = append(args, exprArgs...)
 
 		// when multiple columns are the same, determine whether the use of IN
 		if isUseIN {
-			if field != column.Name {
+			if field != column.Name || strings.Contains(column.Name, jsonPathSep) {
 				isUseIN = false
 				continue
 			}
@@ -194,6 +298,53 @@ func (p *Params) ConvertToGormConditions() (string, []interface{}, error) {
 	return str, args, nil
 }
 
+// ConvertToGorm conversion to gorm-compliant parameters based on the conditions of tree structure
+func (g *Group) ConvertToGorm() (string, []interface{}, error) {
+	return g.convertToGorm(1)
+}
+
+func (g *Group) convertToGorm(depth int) (string, []interface{}, error) {
+	if depth > maxGroupDepth {
+		return "", nil, fmt.Errorf("the nesting depth of group cannot exceed %d", maxGroupDepth)
+	}
+
+	logic := g.Logic
+	if logic == "" {
+		logic = AND
+	}
+	logic, ok := logicMap[strings.ToLower(logic)]
+	if !ok {
+		return "", nil, fmt.Errorf("unknown logic type '%s'", g.Logic)
+	}
+
+	exprs := []string{}
+	args := []interface{}{}
+	for _, column := range g.Columns {
+		if err := column.checkValid(); err != nil {
+			return "", nil, err
+		}
+		if err := column.convert(); err != nil {
+			return "", nil, err
+		}
+		expr, exprArgs := column.toGormExpr()
+		exprs = append(exprs, expr)
+		args = append(args, exprArgs...)
+	}
+	for i := range g.Groups {
+		expr, exprArgs, err := g.Groups[i].convertToGorm(depth + 1)
+		if err != nil {
+			return "", nil, err
+		}
+		if expr == "" { // ignore empty group
+			continue
+		}
+		exprs = append(exprs, "("+expr+")")
+		args = append(args, exprArgs...)
+	}
+
+	return strings.Join(exprs, logic), args, nil
+}
+
 // Conditions query conditions
 type Conditions struct {
 	Columns []Column `json:"columns" form:"columns" binding:"min=1"` // columns info
@@ -227,7 +378,122 @@ func (c *Conditions) CheckValid() error {
 
 // ConvertToGorm conversion to gorm-compliant parameters based on the Columns parameter
 // ignore the logical type of the last column, whether it is a one-column or multi-column query
-func (c *Conditions) ConvertToGorm() (string, []interface{}, error) {
+func (c *Conditions) ConvertToGorm(opts ...Option) (string, []interface{}, error) {
 	p := &Params{Columns: c.Columns}
-	return p.ConvertToGormConditions()
+	return p.ConvertToGormConditions(opts...)
+}
+
+// convert the column to gorm expression, the json path of column is converted to
+// the driver-aware expression when gorm builds the sql.
+func (c *Column) toGormExpr() (string, []interface{}) {
+	var (
+		name string
+		args []interface{}
+	)
+	column, path, _ := parseColumnName(c.Name)
+	if len(path) > 0 {
+		name = "?"
+		args = append(args, jsonPath{column: column, path: path})
+	} else {
+		name = column
+	}
+
+	switch c.Exp {
+	case expMap[In], expMap[NotIn]:
+		return name + c.Exp + "(?)", append(args, c.Value)
+	case expMap[Between]:
+		values, _ := c.Value.([]interface{})
+		return name + c.Exp + "? AND ?", append(args, values...)
+	case expMap[IsNull], expMap[NotNull]:
+		return name + c.Exp, args
+	}
+	return name + c.Exp + "?", append(args, c.Value)
+}
+
+func splitValues(value interface{}) ([]interface{}, error) {
+	if val, ok := value.(string); ok {
+		values := []interface{}{}
+		for _, s := range strings.Split(val, ",") {
+			values = append(values, s)
+		}
+		return values, nil
+	}
+
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, fmt.Errorf("invalid value type '%v'", value)
+	}
+	values := make([]interface{}, 0, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		values = append(values, rv.Index(i).Interface())
+	}
+	return values, nil
+}
+
+const jsonPathSep = "->>"
+
+// parse the column name, e.g. profile->>address.city, the column is profile, and the json path is [address, city],
+// the column name and json path keys only support letters, numbers and underscores to prevent sql injection.
+func parseColumnName(name string) (string, []string, error) {
+	column, pathStr, isJSON := strings.Cut(name, jsonPathSep)
+	if !columnRegexp.MatchString(column) {
+		return "", nil, fmt.Errorf("invalid column name '%s'", name)
+	}
+	if !isJSON {
+		return column, nil, nil
+	}
+
+	pathStr = strings.TrimPrefix(pathStr, "$.")
+	path := strings.Split(pathStr, ".")
+	for _, key := range path {
+		if !jsonPathRegexp.MatchString(key) {
+			return "", nil, fmt.Errorf("invalid json path of column name '%s'", name)
+		}
+	}
+	return column, path, nil
+}
+
+// jsonPath the value of json column by path, it is built according to the database driver
+type jsonPath struct {
+	column string
+	path   []string
+}
+
+// Build implements clause.Expression, mysql: `col`->>'$.a.b', postgresql: "col"#>>'{a,b}', sqlite: json_extract(`col`, '$.a.b')
+func (j jsonPath) Build(builder clause.Builder) {
+	driver := ""
+	if stmt, ok := builder.(*gorm.Statement); ok && stmt.DB != nil && stmt.Dialector != nil {
+		driver = stmt.Dialector.Name()
+	}
+
+	mysqlPath := "$"
+	for _, key := range j.path {
+		if isNumber(key) {
+			mysqlPath += "[" + key + "]"
+		} else {
+			mysqlPath += "." + key
+		}
+	}
+
+	switch driver {
+	case "postgres":
+		builder.WriteQuoted(j.column)
+		builder.WriteString("#>>'{" + strings.Join(j.path, ",") + "}'")
+	case "sqlite":
+		builder.WriteString("json_extract(")
+		builder.WriteQuoted(j.column)
+		builder.WriteString(", '" + mysqlPath + "')")
+	default: // mysql, tidb
+		builder.WriteQuoted(j.column)
+		builder.WriteString("->>'" + mysqlPath + "'")
+	}
+}
+
+func isNumber(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
 }
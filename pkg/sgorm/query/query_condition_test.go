@@ -1,11 +1,19 @@
 package query
 
 import (
+	"encoding/json"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 )
 
 func TestPage(t *testing.T) {
@@ -539,3 +547,340 @@ func TestConditions_checkValid(t *testing.T) {
 	err = c.CheckValid()
 	assert.NoError(t, err)
 }
+
+type cursorUser struct {
+	ID        uint64    `gorm:"column:id;primary_key"`
+	Age       int       `gorm:"column:age"`
+	CreatedAt time.Time // column name is created_at
+}
+
+func TestParams_ConvertToKeysetPage(t *testing.T) {
+	p := &Params{Limit: 2, Sort: "-age"}
+	page, err := p.ConvertToKeysetPage()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, page.Limit())
+	assert.Equal(t, "age DESC, id DESC", page.Order())
+	queryStr, args := page.Conditions()
+	assert.Empty(t, queryStr)
+	assert.Empty(t, args)
+
+	// first page, query Limit()+1 records
+	records := []*cursorUser{{ID: 5, Age: 30}, {ID: 3, Age: 30}, {ID: 9, Age: 20}}
+	info, err := page.Cursors(&records)
+	assert.NoError(t, err)
+	assert.Len(t, records, 2)
+	assert.NotEmpty(t, info.Next)
+	assert.Empty(t, info.Prev)
+
+	// next page
+	p.Cursor = info.Next
+	page, err = p.ConvertToKeysetPage()
+	assert.NoError(t, err)
+	assert.Equal(t, "age DESC, id DESC", page.Order())
+	queryStr, args = page.Conditions()
+	assert.Equal(t, "((age < ?) OR (age = ? AND id < ?))", queryStr)
+	assert.Equal(t, []interface{}{int64(30), int64(30), uint64(3)}, args)
+	records = []*cursorUser{{ID: 9, Age: 20}}
+	info, err = page.Cursors(&records)
+	assert.NoError(t, err)
+	assert.Len(t, records, 1)
+	assert.Empty(t, info.Next)
+	assert.NotEmpty(t, info.Prev)
+
+	// previous page, the order is reversed when querying
+	p.Cursor = info.Prev
+	page, err = p.ConvertToKeysetPage()
+	assert.NoError(t, err)
+	assert.Equal(t, "age ASC, id ASC", page.Order())
+	queryStr, args = page.Conditions()
+	assert.Equal(t, "((age > ?) OR (age = ? AND id > ?))", queryStr)
+	assert.Equal(t, []interface{}{int64(20), int64(20), uint64(9)}, args)
+	records = []*cursorUser{{ID: 3, Age: 30}, {ID: 5, Age: 30}}
+	info, err = page.Cursors(&records)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(5), records[0].ID)
+	assert.NotEmpty(t, info.Next)
+	assert.Empty(t, info.Prev)
+
+	// empty page
+	records = []*cursorUser{}
+	info, err = page.Cursors(&records)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, info.Next)
+
+	// cursor with time value
+	p = &Params{Limit: 1, Sort: "created_at"}
+	page, _ = p.ConvertToKeysetPage("id")
+	now := time.Now()
+	users := []cursorUser{{ID: 1, CreatedAt: now}, {ID: 2, CreatedAt: now}}
+	info, err = page.Cursors(&users)
+	assert.NoError(t, err)
+	p.Cursor = info.Next
+	page, err = p.ConvertToKeysetPage("id")
+	assert.NoError(t, err)
+	_, args = page.Conditions()
+	assert.True(t, now.Equal(args[0].(time.Time)))
+
+	// map records
+	maps := []map[string]interface{}{{"id": 1, "age": 1}, {"id": 2, "age": 2}}
+	page, _ = (&Params{Limit: 1}).ConvertToKeysetPage()
+	info, err = page.Cursors(&maps)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, info.Next)
+}
+
+func TestKeysetPage_Fields(t *testing.T) {
+	page, _ := (&Params{Sort: "-age"}).ConvertToKeysetPage()
+	assert.Equal(t, []string{"name", "age", "id"}, page.Fields([]string{"name"}))
+	assert.Equal(t, []string{"id", "age"}, page.Fields([]string{"id", "age"}))
+	assert.Empty(t, page.Fields(nil))
+}
+
+func TestParams_ConvertToKeysetPageError(t *testing.T) {
+	_, err := (&Params{Sort: "age;drop table"}).ConvertToKeysetPage()
+	assert.Error(t, err)
+	_, err = (&Params{}).ConvertToKeysetPage("id or 1=1")
+	assert.Error(t, err)
+	_, err = (&Params{Cursor: "not a cursor"}).ConvertToKeysetPage()
+	assert.Error(t, err)
+
+	page, _ := (&Params{Limit: 1, Sort: "age"}).ConvertToKeysetPage()
+	records := []*cursorUser{{ID: 1}, {ID: 2}}
+	info, _ := page.Cursors(&records)
+	_, err = (&Params{Limit: 1, Sort: "-age"}).ConvertToKeysetPage()
+	assert.NoError(t, err)
+	_, err = (&Params{Limit: 1, Sort: "-age", Cursor: info.Next}).ConvertToKeysetPage()
+	assert.Error(t, err)
+
+	_, err = page.Cursors(records)
+	assert.Error(t, err)
+	type noAge struct{ ID int }
+	list := []noAge{{ID: 1}, {ID: 2}}
+	_, err = page.Cursors(&list)
+	assert.Error(t, err)
+}
+
+func Test_toSnakeCase(t *testing.T) {
+	assert.Equal(t, "id", toSnakeCase("ID"))
+	assert.Equal(t, "user_id", toSnakeCase("UserID"))
+	assert.Equal(t, "http_code", toSnakeCase("HTTPCode"))
+	assert.Equal(t, "created_at", toSnakeCase("CreatedAt"))
+}
+
+func TestParams_ConvertToGormConditionsWithOperators(t *testing.T) {
+	tests := []struct {
+		name    string
+		columns []Column
+		want    string
+		want1   []interface{}
+		wantErr bool
+	}{
+		{
+			name:    "between",
+			columns: []Column{{Name: "age", Exp: Between, Value: "10,20"}},
+			want:    "age BETWEEN ? AND ?",
+			want1:   []interface{}{"10", "20"},
+		},
+		{
+			name:    "between with slice value",
+			columns: []Column{{Name: "age", Exp: Between, Value: []interface{}{10, 20}}},
+			want:    "age BETWEEN ? AND ?",
+			want1:   []interface{}{10, 20},
+		},
+		{
+			name:    "not in",
+			columns: []Column{{Name: "name", Exp: NotIn, Value: "foo,bar"}},
+			want:    "name NOT IN (?)",
+			want1:   []interface{}{[]interface{}{"foo", "bar"}},
+		},
+		{
+			name:    "is null and not null",
+			columns: []Column{{Name: "deleted_at", Exp: IsNull}, {Name: "email", Exp: NotNull, Logic: OR}},
+			want:    "deleted_at IS NULL AND email IS NOT NULL",
+			want1:   []interface{}{},
+		},
+		{
+			name:    "not like, prefix and suffix",
+			columns: []Column{{Name: "name", Exp: NotLike, Value: "foo"}, {Name: "name", Exp: Prefix, Value: "foo"}, {Name: "email", Exp: Suffix, Value: "@bar.com"}},
+			want:    "name NOT LIKE ? AND name LIKE ? AND email LIKE ?",
+			want1:   []interface{}{"%foo%", "foo%", "%@bar.com"},
+		},
+		{
+			name:    "between error",
+			columns: []Column{{Name: "age", Exp: Between, Value: "10"}},
+			wantErr: true,
+		},
+		{
+			name:    "nil value error",
+			columns: []Column{{Name: "age", Exp: Eq}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid column name",
+			columns: []Column{{Name: "age=1 or 1", Value: 1}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid json path",
+			columns: []Column{{Name: "profile->>a'b", Value: 1}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := &Params{Columns: tt.columns}
+			got, got1, err := params.ConvertToGormConditions()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ConvertToGormConditions() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.want1, got1)
+		})
+	}
+}
+
+func TestParams_ConvertToGormConditionsWithJSONPath(t *testing.T) {
+	params := &Params{Columns: []Column{
+		{Name: "profile->>address.city", Value: "foo"},
+		{Name: "profile->>$.tags.0", Exp: Prefix, Value: "bar"},
+	}}
+	queryStr, args, err := params.ConvertToGormConditions()
+	assert.NoError(t, err)
+	assert.Equal(t, "? = ? AND ? LIKE ?", queryStr)
+
+	sqlDB, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sqlDB.Close()
+
+	dialectors := map[string]gorm.Dialector{
+		"mysql":    mysql.New(mysql.Config{Conn: sqlDB, SkipInitializeWithVersion: true}),
+		"postgres": postgres.New(postgres.Config{Conn: sqlDB}),
+		"sqlite":   sqlite.Open(":memory:"),
+	}
+	wants := map[string]string{
+		"mysql":    "WHERE `profile`->>'$.address.city' = ? AND `profile`->>'$.tags[0]' LIKE ?",
+		"postgres": `WHERE "profile"#>>'{address,city}' = $1 AND "profile"#>>'{tags,0}' LIKE $2`,
+		"sqlite":   "WHERE json_extract(`profile`, '$.address.city') = ? AND json_extract(`profile`, '$.tags[0]') LIKE ?",
+	}
+	for name, dialector := range dialectors {
+		db, err := gorm.Open(dialector, &gorm.Config{DryRun: true, Logger: logger.Discard})
+		if err != nil {
+			t.Fatal(err)
+		}
+		stmt := db.Table("user").Where(queryStr, args...).Find(&[]map[string]interface{}{}).Statement
+		assert.Contains(t, stmt.SQL.String(), wants[name], name)
+		assert.Equal(t, []interface{}{"foo", "bar%"}, stmt.Vars, name)
+	}
+}
+
+func TestParams_ConvertToGormConditionsWithGroup(t *testing.T) {
+	// (a=1 OR b=2) AND (c=3 OR (d>4 AND e IS NULL))
+	data := `{"where":{"logic":"and","groups":[
+		{"logic":"or","columns":[{"name":"a","value":1},{"name":"b","value":2}]},
+		{"logic":"||","columns":[{"name":"c","value":3}],"groups":[{"columns":[{"name":"d","exp":">","value":4},{"name":"e","exp":"isnull"}]}]}
+	]}}`
+	params := &Params{}
+	err := json.Unmarshal([]byte(data), params)
+	assert.NoError(t, err)
+	queryStr, args, err := params.ConvertToGormConditions()
+	assert.NoError(t, err)
+	assert.Equal(t, "(a = ? OR b = ?) AND (c = ? OR (d > ? AND e IS NULL))", queryStr)
+	assert.Equal(t, []interface{}{1.0, 2.0, 3.0, 4.0}, args)
+
+	// combine with flat columns
+	params.Columns = []Column{{Name: "name", Value: "foo", Logic: OR}, {Name: "age", Value: 10}}
+	queryStr, args, err = params.ConvertToGormConditions()
+	assert.NoError(t, err)
+	assert.Equal(t, "(name = ? OR age = ?) AND ((a = ? OR b = ?) AND (c = ? OR (d > ? AND e IS NULL)))", queryStr)
+	assert.Equal(t, []interface{}{"foo", 10, 1.0, 2.0, 3.0, 4.0}, args)
+
+	// empty group
+	params = &Params{Columns: []Column{{Name: "name", Value: "foo"}}, Where: &Group{Groups: []Group{{}}}}
+	queryStr, args, err = params.ConvertToGormConditions()
+	assert.NoError(t, err)
+	assert.Equal(t, "name = ?", queryStr)
+	assert.Equal(t, []interface{}{"foo"}, args)
+
+	// error
+	params = &Params{Where: &Group{Logic: "xor", Columns: []Column{{Name: "a", Value: 1}}}}
+	_, _, err = params.ConvertToGormConditions()
+	assert.Error(t, err)
+	params = &Params{Where: &Group{Groups: []Group{{Columns: []Column{{Name: "a", Exp: "unknown", Value: 1}}}}}}
+	_, _, err = params.ConvertToGormConditions()
+	assert.Error(t, err)
+	group := &Group{Columns: []Column{{Name: "a", Value: 1}}}
+	for i := 0; i < maxGroupDepth; i++ {
+		group = &Group{Groups: []Group{*group}}
+	}
+	_, _, err = group.ConvertToGorm()
+	assert.Error(t, err)
+}
+
+func TestParams_Whitelist(t *testing.T) {
+	whitelist := &Whitelist{
+		Filter: []string{"id", "name", "profile"},
+		Sort:   []string{"id", "created_at"},
+		Return: []string{"id", "name", "email"},
+	}
+	opt := WithWhitelist(whitelist)
+
+	params := &Params{
+		Sort:    "-created_at,id",
+		Columns: []Column{{Name: "name", Value: "foo"}, {Name: "profile->>address.city", Value: "bar"}},
+		Where:   &Group{Groups: []Group{{Columns: []Column{{Name: "id", Value: 1}}}}},
+	}
+	_, _, err := params.ConvertToGormConditions(opt)
+	assert.NoError(t, err)
+
+	params.Sort = "-age"
+	_, _, err = params.ConvertToGormConditions(opt)
+	assert.Error(t, err)
+
+	params.Sort = ""
+	params.Columns = append(params.Columns, Column{Name: "password", Value: "foo"})
+	_, _, err = params.ConvertToGormConditions(opt)
+	assert.Error(t, err)
+
+	params.Columns = nil
+	params.Where.Groups[0].Columns[0].Name = "password"
+	_, _, err = params.ConvertToGormConditions(opt)
+	assert.Error(t, err)
+
+	conditions := &Conditions{Columns: []Column{{Name: "password", Value: "foo"}}}
+	_, _, err = conditions.ConvertToGorm(opt)
+	assert.Error(t, err)
+	_, _, err = conditions.ConvertToGorm()
+	assert.NoError(t, err)
+}
+
+func TestParams_ConvertToFields(t *testing.T) {
+	opt := WithWhitelist(&Whitelist{Return: []string{"id", "name", "email"}})
+
+	fields, err := (&Params{}).ConvertToFields()
+	assert.NoError(t, err)
+	assert.Empty(t, fields)
+
+	fields, err = (&Params{}).ConvertToFields(opt)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"id", "name", "email"}, fields)
+
+	fields, err = (&Params{Fields: "id, name"}).ConvertToFields(opt)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"id", "name"}, fields)
+
+	fields, err = (&Params{Fields: "age,password"}).ConvertToFields()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"age", "password"}, fields)
+
+	_, err = (&Params{Fields: "id,password"}).ConvertToFields(opt)
+	assert.Error(t, err)
+	_, err = (&Params{Fields: "count(*)"}).ConvertToFields()
+	assert.Error(t, err)
+}
@@ -0,0 +1,132 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Whitelist columns allowed to be used by the query parameters, an empty list means no restriction
+type Whitelist struct {
+	Filter []string // columns allowed in query conditions
+	Sort   []string // columns allowed in sort
+	Return []string // columns allowed to be returned, it is also the default fields when the parameter fields is empty
+}
+
+// Option set the options of converting query parameters
+type Option func(*options)
+
+type options struct {
+	whitelist *Whitelist
+}
+
+func (o *options) apply(opts ...Option) {
+	for _, opt := range opts {
+		opt(o)
+	}
+}
+
+func defaultOptions() *options {
+	return &options{}
+}
+
+// WithWhitelist set the whitelist of columns
+func WithWhitelist(w *Whitelist) Option {
+	return func(o *options) {
+		o.whitelist = w
+	}
+}
+
+func isAllowed(list []string, name string) bool {
+	if len(list) == 0 {
+		return true
+	}
+	for _, v := range list {
+		if v == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *Whitelist) checkFilter(name string) error {
+	if w == nil {
+		return nil
+	}
+	column, _, err := parseColumnName(name)
+	if err != nil {
+		return err
+	}
+	if !isAllowed(w.Filter, column) {
+		return fmt.Errorf("column '%s' is not allowed to filter", column)
+	}
+	return nil
+}
+
+// the sort value used by the generated dao to skip counting, it is not checked by whitelist
+const ignoreCountSort = "ignore count"
+
+func (w *Whitelist) checkSort(sort string) error {
+	if w == nil || sort == "" || sort == ignoreCountSort {
+		return nil
+	}
+	for _, name := range strings.Split(strings.ReplaceAll(sort, " ", ""), ",") {
+		name = strings.TrimPrefix(name, "-")
+		if !isAllowed(w.Sort, name) {
+			return fmt.Errorf("column '%s' is not allowed to sort", name)
+		}
+	}
+	return nil
+}
+
+func (w *Whitelist) checkColumns(columns []Column) error {
+	for _, column := range columns {
+		if err := w.checkFilter(column.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *Whitelist) checkGroup(g *Group) error {
+	if err := w.checkColumns(g.Columns); err != nil {
+		return err
+	}
+	for i := range g.Groups {
+		if err := w.checkGroup(&g.Groups[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ConvertToFields converted to the fields to be returned based on the Fields parameter,
+// if the Fields parameter is empty, the returnable columns of whitelist are returned,
+// an empty result means all fields are returned.
+func (p *Params) ConvertToFields(opts ...Option) ([]string, error) {
+	o := defaultOptions()
+	o.apply(opts...)
+	var returnColumns []string
+	if o.whitelist != nil {
+		returnColumns = o.whitelist.Return
+	}
+
+	fieldsStr := strings.ReplaceAll(p.Fields, " ", "")
+	if fieldsStr == "" {
+		return returnColumns, nil
+	}
+
+	fields := []string{}
+	for _, field := range strings.Split(fieldsStr, ",") {
+		if field == "" {
+			continue
+		}
+		if !columnRegexp.MatchString(field) {
+			return nil, fmt.Errorf("invalid field name '%s'", field)
+		}
+		if !isAllowed(returnColumns, field) {
+			return nil, fmt.Errorf("field '%s' is not allowed to return", field)
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
@@ -3,6 +3,7 @@ package parser
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 	"text/template"
 
@@ -30,6 +31,26 @@ type CrudInfo struct {
 	PrimaryKeyColumnName string `json:"PrimaryKeyColumnName"` // primary key, example: id
 	IsCommonType         bool   `json:"isCommonType"`         // custom primary key name and type
 	IsStandardPrimaryKey bool   `json:"isStandardPrimaryKey"` // standard primary key id
+
+	IsCompositeKey bool              `json:"isCompositeKey"`        // composite primary key or not
+	PrimaryKeys    []*PrimaryKeyInfo `json:"primaryKeys,omitempty"` // columns of composite primary key
+
+	IsSoftDelete bool `json:"isSoftDelete"` // records are soft deleted by column deleted_at or not
+
+	VersionColumnName      string `json:"versionColumnName,omitempty"`      // version column of optimistic lock, example: version
+	VersionColumnNameCamel string `json:"versionColumnNameCamel,omitempty"` // version column, camel case, example: Version
+}
+
+// PrimaryKeyInfo column info of composite primary key
+type PrimaryKeyInfo struct {
+	ColumnName           string `json:"columnName"`           // column name, example: tenant_id
+	ColumnNameCamel      string `json:"columnNameCamel"`      // column name, camel case, example: TenantID
+	ColumnNameCamelFCL   string `json:"columnNameCamelFCL"`   // column name, camel case and first character lower, example: tenantID
+	GoType               string `json:"goType"`               // go type, example: string, uint64
+	GoTypeFCU            string `json:"goTypeFCU"`            // go type, first character upper, example: String, Uint64
+	ProtoType            string `json:"protoType"`            // proto type, example: string, uint64
+	IsStringType         bool   `json:"isStringType"`         // go type is string or not
+	IsStandardPrimaryKey bool   `json:"isStandardPrimaryKey"` // column name is id
 }
 
 func isDesiredGoType(t string) bool {
@@ -62,18 +83,58 @@ func setCrudInfo(field tmplField) *CrudInfo {
 	}
 }
 
+// the names of composite primary key are joined by And, example: tenant_id, code --> TenantIDAndCode
+func setCompositeCrudInfo(fields []tmplField) *CrudInfo {
+	var names, columnNames []string
+	info := &CrudInfo{IsCompositeKey: true}
+	for _, field := range fields {
+		names = append(names, field.Name)
+		columnNames = append(columnNames, field.ColName)
+		info.PrimaryKeys = append(info.PrimaryKeys, &PrimaryKeyInfo{
+			ColumnName:           field.ColName,
+			ColumnNameCamel:      field.Name,
+			ColumnNameCamelFCL:   customFirstLetterToLower(field.Name),
+			GoType:               field.GoType,
+			GoTypeFCU:            firstLetterToUpper(field.GoType),
+			ProtoType:            simpleGoTypeToProtoType(field.GoType),
+			IsStringType:         field.GoType == "string",
+			IsStandardPrimaryKey: field.ColName == "id",
+		})
+	}
+
+	name := strings.Join(names, "And")
+	pluralName := inflection.Plural(name)
+	info.ColumnName = strings.Join(columnNames, ",")
+	info.ColumnNameCamel = name
+	info.ColumnNameCamelFCL = customFirstLetterToLower(name)
+	info.ColumnNamePluralCamel = customEndOfLetterToLower(name, pluralName)
+	info.ColumnNamePluralCamelFCL = customFirstLetterToLower(customEndOfLetterToLower(name, pluralName))
+	info.PrimaryKeyColumnName = info.ColumnName
+
+	return info
+}
+
 func newCrudInfo(data tmplData) *CrudInfo {
 	if len(data.Fields) == 0 {
 		return nil
 	}
 
 	var info *CrudInfo
+	var primaryKeyFields []tmplField
 	for _, field := range data.Fields {
 		if field.IsPrimaryKey {
-			info = setCrudInfo(field)
-			break
+			primaryKeyFields = append(primaryKeyFields, field)
 		}
 	}
+	if len(primaryKeyFields) > 1 {
+		// the columns of composite primary key are in the order of key, not the order of table columns
+		sort.SliceStable(primaryKeyFields, func(i, j int) bool {
+			return primaryKeyFields[i].primaryKeyIndex < primaryKeyFields[j].primaryKeyIndex
+		})
+		info = setCompositeCrudInfo(primaryKeyFields)
+	} else if len(primaryKeyFields) == 1 {
+		info = setCrudInfo(primaryKeyFields[0])
+	}
 
 	// if not found primary key, find the first xxx_id column as primary key
 	if info == nil {
@@ -125,10 +186,156 @@ func (info *CrudInfo) CheckCommonType() bool {
 	return info.IsCommonType
 }
 
-func (info *CrudInfo) isIDPrimaryKey() bool {
+// CheckSoftDelete check if the records are soft deleted
+func (info *CrudInfo) CheckSoftDelete() bool {
+	if info == nil {
+		return false
+	}
+	return info.IsSoftDelete
+}
+
+// CheckOptimisticLock check if the record is updated by optimistic lock of version column
+func (info *CrudInfo) CheckOptimisticLock() bool {
+	if info == nil {
+		return false
+	}
+	return info.VersionColumnName != ""
+}
+
+// CheckCompositeKey check if it is a composite primary key
+func (info *CrudInfo) CheckCompositeKey() bool {
 	if info == nil {
 		return false
 	}
+	return info.IsCompositeKey
+}
+
+// GetPrimaryKeys get the columns of primary key, there is only one column if it is not a composite primary key
+func (info *CrudInfo) GetPrimaryKeys() []*PrimaryKeyInfo {
+	if info.IsCompositeKey {
+		return info.PrimaryKeys
+	}
+	return []*PrimaryKeyInfo{{
+		ColumnName:           info.ColumnName,
+		ColumnNameCamel:      info.ColumnNameCamel,
+		ColumnNameCamelFCL:   info.ColumnNameCamelFCL,
+		GoType:               info.GoType,
+		GoTypeFCU:            info.GoTypeFCU,
+		ProtoType:            info.ProtoType,
+		IsStringType:         info.IsStringType,
+		IsStandardPrimaryKey: info.IsStandardPrimaryKey,
+	}}
+}
+
+// GetKeyParams function parameters of primary key, example: tenantID uint64, code string
+func (info *CrudInfo) GetKeyParams() string {
+	var params []string
+	for _, key := range info.GetPrimaryKeys() {
+		params = append(params, key.ColumnNameCamelFCL+" "+key.GoType)
+	}
+	return strings.Join(params, ", ")
+}
+
+// GetKeyArgs function arguments of primary key, example: tenantID, code
+func (info *CrudInfo) GetKeyArgs() string {
+	var args []string
+	for _, key := range info.GetPrimaryKeys() {
+		args = append(args, key.ColumnNameCamelFCL)
+	}
+	return strings.Join(args, ", ")
+}
+
+// GetKeyFieldArgs function arguments of primary key from struct fields, example: table.TenantID, table.Code
+func (info *CrudInfo) GetKeyFieldArgs(obj string) string {
+	var args []string
+	for _, key := range info.GetPrimaryKeys() {
+		args = append(args, obj+"."+key.ColumnNameCamel)
+	}
+	return strings.Join(args, ", ")
+}
+
+// GetKeyProtoFieldArgs function arguments of primary key from proto message fields, example: req.TenantID, req.Code
+func (info *CrudInfo) GetKeyProtoFieldArgs(obj string) string {
+	var args []string
+	for _, key := range info.GetPrimaryKeys() {
+		args = append(args, obj+"."+key.GetProtoFieldName())
+	}
+	return strings.Join(args, ", ")
+}
+
+// GetKeyWhere gorm where conditions of primary key, example: "tenant_id = ? AND code = ?", tenantID, code
+func (info *CrudInfo) GetKeyWhere() string {
+	var conditions []string
+	for _, key := range info.GetPrimaryKeys() {
+		conditions = append(conditions, key.ColumnName+" = ?")
+	}
+	return fmt.Sprintf(`"%s", %s`, strings.Join(conditions, " AND "), info.GetKeyArgs())
+}
+
+// GetKeyFieldWhere gorm where conditions of primary key from struct fields,
+// example: "tenant_id = ? AND code = ?", table.TenantID, table.Code
+func (info *CrudInfo) GetKeyFieldWhere(obj string) string {
+	var conditions []string
+	for _, key := range info.GetPrimaryKeys() {
+		conditions = append(conditions, key.ColumnName+" = ?")
+	}
+	return fmt.Sprintf(`"%s", %s`, strings.Join(conditions, " AND "), info.GetKeyFieldArgs(obj))
+}
+
+// GetKeyLogFields logger fields of primary key, example: logger.Any("tenantID", tenantID), logger.Any("code", code)
+func (info *CrudInfo) GetKeyLogFields() string {
+	var fields []string
+	for _, key := range info.GetPrimaryKeys() {
+		fields = append(fields, fmt.Sprintf(`logger.Any("%s", %s)`, key.ColumnNameCamelFCL, key.ColumnNameCamelFCL))
+	}
+	return strings.Join(fields, ", ")
+}
+
+// GetKeyStr convert primary key to string, values of composite primary key are joined by colon,
+// example: utils.Uint64ToStr(tenantID) + ":" + code
+func (info *CrudInfo) GetKeyStr() string {
+	var values []string
+	for _, key := range info.GetPrimaryKeys() {
+		if key.IsStringType {
+			values = append(values, key.ColumnNameCamelFCL)
+		} else {
+			values = append(values, fmt.Sprintf("utils.%sToStr(%s)", key.GoTypeFCU, key.ColumnNameCamelFCL))
+		}
+	}
+	return strings.Join(values, ` + ":" + `)
+}
+
+// GetKeySort default sort of primary key, example: -tenant_id,-code
+func (info *CrudInfo) GetKeySort() string {
+	var columns []string
+	for _, key := range info.GetPrimaryKeys() {
+		columns = append(columns, "-"+key.ColumnName)
+	}
+	return strings.Join(columns, ",")
+}
+
+// GetKeyRoutePath gin route path of primary key, example: /:tenantID/:code
+func (info *CrudInfo) GetKeyRoutePath() string {
+	path := ""
+	for _, key := range info.GetPrimaryKeys() {
+		path += "/:" + key.ColumnNameCamelFCL
+	}
+	return path
+}
+
+// GetKeySwaggerPath swagger route path of primary key, example: {tenantID}/{code}
+func (info *CrudInfo) GetKeySwaggerPath() string {
+	var paths []string
+	for _, key := range info.GetPrimaryKeys() {
+		paths = append(paths, "{"+key.ColumnNameCamelFCL+"}")
+	}
+	return strings.Join(paths, "/")
+}
+
+func (info *CrudInfo) isIDPrimaryKey() bool {
+	if info == nil || info.IsCompositeKey {
+		return false
+	}
 	if info.ColumnName == "id" && (info.GoType == "uint64" ||
 		info.GoType == "int64" ||
 		info.GoType == "uint" ||
@@ -144,20 +351,49 @@ func (info *CrudInfo) GetGRPCProtoValidation() string {
 	if info == nil {
 		return ""
 	}
-	if info.ProtoType == "string" {
-		return `[(validate.rules).string.min_len = 1]`
-	}
-	return fmt.Sprintf(`[(validate.rules).%s.gt = 0]`, info.ProtoType)
+	return getGRPCProtoValidation(info.ProtoType)
 }
 
 func (info *CrudInfo) GetWebProtoValidation() string {
 	if info == nil {
 		return ""
 	}
-	if info.ProtoType == "string" {
-		return fmt.Sprintf(`[(validate.rules).string.min_len = 1, (tagger.tags) = "uri:\"%s\""]`, info.ColumnNameCamelFCL)
+	return getWebProtoValidation(info.ProtoType, info.ColumnNameCamelFCL)
+}
+
+func (key *PrimaryKeyInfo) GetGRPCProtoValidation() string {
+	return getGRPCProtoValidation(key.ProtoType)
+}
+
+func (key *PrimaryKeyInfo) GetWebProtoValidation() string {
+	return getWebProtoValidation(key.ProtoType, key.ColumnNameCamelFCL)
+}
+
+// GetProtoFieldName field name of primary key in the go struct generated by proto
+func (key *PrimaryKeyInfo) GetProtoFieldName() string {
+	if key.IsStandardPrimaryKey {
+		return "Id"
+	}
+	return key.ColumnNameCamel
+}
+
+// AddOne counter
+func (key *PrimaryKeyInfo) AddOne(i int) int {
+	return i + 1
+}
+
+func getGRPCProtoValidation(protoType string) string {
+	if protoType == "string" {
+		return `[(validate.rules).string.min_len = 1]`
+	}
+	return fmt.Sprintf(`[(validate.rules).%s.gt = 0]`, protoType)
+}
+
+func getWebProtoValidation(protoType string, name string) string {
+	if protoType == "string" {
+		return fmt.Sprintf(`[(validate.rules).string.min_len = 1, (tagger.tags) = "uri:\"%s\""]`, name)
 	}
-	return fmt.Sprintf(`[(validate.rules).%s.gt = 0, (tagger.tags) = "uri:\"%s\""]`, info.ProtoType, info.ColumnNameCamelFCL)
+	return fmt.Sprintf(`[(validate.rules).%s.gt = 0, (tagger.tags) = "uri:\"%s\""]`, protoType, name)
 }
 
 func getCommonHandlerStructCodes(data tmplData, jsonNamedType int) (string, error) {
@@ -218,6 +454,7 @@ func getCommonServiceStructCode(data tmplData) (string, error) {
 
 func getCommonProtoFileCode(data tmplData, jsonNamedType int, isWebProto bool, isExtendedAPI bool) (string, error) {
 	data.Fields = goTypeToProto(data.Fields, jsonNamedType, true)
+	data.Relations = relationsToProto(data.Relations, jsonNamedType)
 
 	var err error
 	builder := strings.Builder{}
@@ -252,8 +489,10 @@ func getCommonProtoFileCode(data tmplData, jsonNamedType int, isWebProto bool, i
 		return "", fmt.Errorf("handle protoMessageUpdateCommonTmpl error: %v", err)
 	}
 	if !isWebProto {
-		srcStr := fmt.Sprintf(`, (tagger.tags) = "uri:\"%s\""`, getProtoFieldName(data.Fields))
-		protoMessageUpdateCode = strings.ReplaceAll(protoMessageUpdateCode, srcStr, "")
+		for _, name := range getProtoFieldNames(data.Fields) {
+			srcStr := fmt.Sprintf(`, (tagger.tags) = "uri:\"%s\""`, name)
+			protoMessageUpdateCode = strings.ReplaceAll(protoMessageUpdateCode, srcStr, "")
+		}
 	}
 
 	protoMessageDetailCode, err := tmplExecuteWithFilter2(data, protoMessageDetailCommonTmpl, columnID, columnCreatedAt, columnUpdatedAt)
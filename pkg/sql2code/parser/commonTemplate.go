@@ -90,11 +90,15 @@ Notes for defining message fields:
 // protoMessageCreateCode
 
 message Create{{.TableName}}Reply {
-  {{.CrudInfo.ProtoType}} {{.CrudInfo.ColumnNameCamelFCL}} = 1;
+{{- range $i, $v := .CrudInfo.GetPrimaryKeys}}
+  {{$v.ProtoType}} {{$v.ColumnNameCamelFCL}} = {{$v.AddOne $i}};
+{{- end}}
 }
 
 message Delete{{.TableName}}By{{.CrudInfo.ColumnNameCamel}}Request {
-  {{.CrudInfo.ProtoType}} {{.CrudInfo.ColumnNameCamelFCL}} = 1 {{.CrudInfo.GetGRPCProtoValidation}};
+{{- range $i, $v := .CrudInfo.GetPrimaryKeys}}
+  {{$v.ProtoType}} {{$v.ColumnNameCamelFCL}} = {{$v.AddOne $i}} {{$v.GetGRPCProtoValidation}};
+{{- end}}
 }
 
 message Delete{{.TableName}}By{{.CrudInfo.ColumnNameCamel}}Reply {
@@ -110,7 +114,9 @@ message Update{{.TableName}}By{{.CrudInfo.ColumnNameCamel}}Reply {
 // protoMessageDetailCode
 
 message Get{{.TableName}}By{{.CrudInfo.ColumnNameCamel}}Request {
-  {{.CrudInfo.ProtoType}} {{.CrudInfo.ColumnNameCamelFCL}} = 1 {{.CrudInfo.GetGRPCProtoValidation}};
+{{- range $i, $v := .CrudInfo.GetPrimaryKeys}}
+  {{$v.ProtoType}} {{$v.ColumnNameCamelFCL}} = {{$v.AddOne $i}} {{$v.GetGRPCProtoValidation}};
+{{- end}}
 }
 
 message Get{{.TableName}}By{{.CrudInfo.ColumnNameCamel}}Reply {
@@ -201,11 +207,15 @@ Notes for defining message fields:
 // protoMessageCreateCode
 
 message Create{{.TableName}}Reply {
-  {{.CrudInfo.ProtoType}} {{.CrudInfo.ColumnNameCamelFCL}} = 1;
+{{- range $i, $v := .CrudInfo.GetPrimaryKeys}}
+  {{$v.ProtoType}} {{$v.ColumnNameCamelFCL}} = {{$v.AddOne $i}};
+{{- end}}
 }
 
 message Delete{{.TableName}}By{{.CrudInfo.ColumnNameCamel}}Request {
-  {{.CrudInfo.ProtoType}} {{.CrudInfo.ColumnNameCamelFCL}} = 1 {{.CrudInfo.GetGRPCProtoValidation}};
+{{- range $i, $v := .CrudInfo.GetPrimaryKeys}}
+  {{$v.ProtoType}} {{$v.ColumnNameCamelFCL}} = {{$v.AddOne $i}} {{$v.GetGRPCProtoValidation}};
+{{- end}}
 }
 
 message Delete{{.TableName}}By{{.CrudInfo.ColumnNameCamel}}Reply {
@@ -221,7 +231,9 @@ message Update{{.TableName}}By{{.CrudInfo.ColumnNameCamel}}Reply {
 // protoMessageDetailCode
 
 message Get{{.TableName}}By{{.CrudInfo.ColumnNameCamel}}Request {
-  {{.CrudInfo.ProtoType}} {{.CrudInfo.ColumnNameCamelFCL}} = 1 {{.CrudInfo.GetGRPCProtoValidation}};
+{{- range $i, $v := .CrudInfo.GetPrimaryKeys}}
+  {{$v.ProtoType}} {{$v.ColumnNameCamelFCL}} = {{$v.AddOne $i}} {{$v.GetGRPCProtoValidation}};
+{{- end}}
 }
 
 message Get{{.TableName}}By{{.CrudInfo.ColumnNameCamel}}Reply {
@@ -303,14 +315,14 @@ service {{.TName}} {
   // delete {{.TName}} by {{.CrudInfo.ColumnNameCamelFCL}}
   rpc DeleteBy{{.CrudInfo.ColumnNameCamel}}(Delete{{.TableName}}By{{.CrudInfo.ColumnNameCamel}}Request) returns (Delete{{.TableName}}By{{.CrudInfo.ColumnNameCamel}}Reply) {
     option (google.api.http) = {
-      delete: "/api/v1/{{.TName}}/left_curly_bracket{{.CrudInfo.ColumnNameCamelFCL}}right_curly_bracket"
+      delete: "/api/v1/{{.TName}}/{{.CrudInfo.GetKeySwaggerPath}}"
     };
   }
 
   // update {{.TName}} by {{.CrudInfo.ColumnNameCamelFCL}}
   rpc UpdateBy{{.CrudInfo.ColumnNameCamel}}(Update{{.TableName}}By{{.CrudInfo.ColumnNameCamel}}Request) returns (Update{{.TableName}}By{{.CrudInfo.ColumnNameCamel}}Reply) {
     option (google.api.http) = {
-      put: "/api/v1/{{.TName}}/left_curly_bracket{{.CrudInfo.ColumnNameCamelFCL}}right_curly_bracket"
+      put: "/api/v1/{{.TName}}/{{.CrudInfo.GetKeySwaggerPath}}"
       body: "*"
     };
   }
@@ -318,7 +330,7 @@ service {{.TName}} {
   // get {{.TName}} by {{.CrudInfo.ColumnNameCamelFCL}}
   rpc GetBy{{.CrudInfo.ColumnNameCamel}}(Get{{.TableName}}By{{.CrudInfo.ColumnNameCamel}}Request) returns (Get{{.TableName}}By{{.CrudInfo.ColumnNameCamel}}Reply) {
     option (google.api.http) = {
-      get: "/api/v1/{{.TName}}/left_curly_bracket{{.CrudInfo.ColumnNameCamelFCL}}right_curly_bracket"
+      get: "/api/v1/{{.TName}}/{{.CrudInfo.GetKeySwaggerPath}}"
     };
   }
 
@@ -388,11 +400,15 @@ If used to generate code that supports the HTTP protocol, notes for defining mes
 // protoMessageCreateCode
 
 message Create{{.TableName}}Reply {
-  {{.CrudInfo.ProtoType}} {{.CrudInfo.ColumnNameCamelFCL}} = 1;
+{{- range $i, $v := .CrudInfo.GetPrimaryKeys}}
+  {{$v.ProtoType}} {{$v.ColumnNameCamelFCL}} = {{$v.AddOne $i}};
+{{- end}}
 }
 
 message Delete{{.TableName}}By{{.CrudInfo.ColumnNameCamel}}Request {
-  {{.CrudInfo.ProtoType}} {{.CrudInfo.ColumnNameCamelFCL}} = 1 {{.CrudInfo.GetWebProtoValidation}};
+{{- range $i, $v := .CrudInfo.GetPrimaryKeys}}
+  {{$v.ProtoType}} {{$v.ColumnNameCamelFCL}} = {{$v.AddOne $i}} {{$v.GetWebProtoValidation}};
+{{- end}}
 }
 
 message Delete{{.TableName}}By{{.CrudInfo.ColumnNameCamel}}Reply {
@@ -408,7 +424,9 @@ message Update{{.TableName}}By{{.CrudInfo.ColumnNameCamel}}Reply {
 // protoMessageDetailCode
 
 message Get{{.TableName}}By{{.CrudInfo.ColumnNameCamel}}Request {
-  {{.CrudInfo.ProtoType}} {{.CrudInfo.ColumnNameCamelFCL}} = 1 {{.CrudInfo.GetWebProtoValidation}};
+{{- range $i, $v := .CrudInfo.GetPrimaryKeys}}
+  {{$v.ProtoType}} {{$v.ColumnNameCamelFCL}} = {{$v.AddOne $i}} {{$v.GetWebProtoValidation}};
+{{- end}}
 }
 
 message Get{{.TableName}}By{{.CrudInfo.ColumnNameCamel}}Reply {
@@ -524,14 +542,14 @@ service {{.TName}} {
   // delete {{.TName}} by {{.CrudInfo.ColumnNameCamelFCL}}
   rpc DeleteBy{{.CrudInfo.ColumnNameCamel}}(Delete{{.TableName}}By{{.CrudInfo.ColumnNameCamel}}Request) returns (Delete{{.TableName}}By{{.CrudInfo.ColumnNameCamel}}Reply) {
     option (google.api.http) = {
-      delete: "/api/v1/{{.TName}}/left_curly_bracket{{.CrudInfo.ColumnNameCamelFCL}}right_curly_bracket"
+      delete: "/api/v1/{{.TName}}/{{.CrudInfo.GetKeySwaggerPath}}"
     };
   }
 
   // update {{.TName}} by {{.CrudInfo.ColumnNameCamelFCL}}
   rpc UpdateBy{{.CrudInfo.ColumnNameCamel}}(Update{{.TableName}}By{{.CrudInfo.ColumnNameCamel}}Request) returns (Update{{.TableName}}By{{.CrudInfo.ColumnNameCamel}}Reply) {
     option (google.api.http) = {
-      put: "/api/v1/{{.TName}}/left_curly_bracket{{.CrudInfo.ColumnNameCamelFCL}}right_curly_bracket"
+      put: "/api/v1/{{.TName}}/{{.CrudInfo.GetKeySwaggerPath}}"
       body: "*"
     };
   }
@@ -539,7 +557,7 @@ service {{.TName}} {
   // get {{.TName}} by {{.CrudInfo.ColumnNameCamelFCL}}
   rpc GetBy{{.CrudInfo.ColumnNameCamel}}(Get{{.TableName}}By{{.CrudInfo.ColumnNameCamel}}Request) returns (Get{{.TableName}}By{{.CrudInfo.ColumnNameCamel}}Reply) {
     option (google.api.http) = {
-      get: "/api/v1/{{.TName}}/left_curly_bracket{{.CrudInfo.ColumnNameCamelFCL}}right_curly_bracket"
+      get: "/api/v1/{{.TName}}/{{.CrudInfo.GetKeySwaggerPath}}"
     };
   }
 
@@ -578,11 +596,15 @@ If used to generate code that supports the HTTP protocol, notes for defining mes
 // protoMessageCreateCode
 
 message Create{{.TableName}}Reply {
-  {{.CrudInfo.ProtoType}} {{.CrudInfo.ColumnNameCamelFCL}} = 1;
+{{- range $i, $v := .CrudInfo.GetPrimaryKeys}}
+  {{$v.ProtoType}} {{$v.ColumnNameCamelFCL}} = {{$v.AddOne $i}};
+{{- end}}
 }
 
 message Delete{{.TableName}}By{{.CrudInfo.ColumnNameCamel}}Request {
-  {{.CrudInfo.ProtoType}} {{.CrudInfo.ColumnNameCamelFCL}} = 1 {{.CrudInfo.GetWebProtoValidation}};
+{{- range $i, $v := .CrudInfo.GetPrimaryKeys}}
+  {{$v.ProtoType}} {{$v.ColumnNameCamelFCL}} = {{$v.AddOne $i}} {{$v.GetWebProtoValidation}};
+{{- end}}
 }
 
 message Delete{{.TableName}}By{{.CrudInfo.ColumnNameCamel}}Reply {
@@ -598,7 +620,9 @@ message Update{{.TableName}}By{{.CrudInfo.ColumnNameCamel}}Reply {
 // protoMessageDetailCode
 
 message Get{{.TableName}}By{{.CrudInfo.ColumnNameCamel}}Request {
-  {{.CrudInfo.ProtoType}} {{.CrudInfo.ColumnNameCamelFCL}} = 1 {{.CrudInfo.GetWebProtoValidation}};
+{{- range $i, $v := .CrudInfo.GetPrimaryKeys}}
+  {{$v.ProtoType}} {{$v.ColumnNameCamelFCL}} = {{$v.AddOne $i}} {{$v.GetWebProtoValidation}};
+{{- end}}
 }
 
 message Get{{.TableName}}By{{.CrudInfo.ColumnNameCamel}}Reply {
@@ -634,6 +658,17 @@ message List{{.TableName}}Reply {
 {{- range $i, $v := .Fields}}
 	{{$v.GoType}} {{$v.JSONName}} = {{$v.AddOne $i}}; {{if $v.Comment}} // {{$v.Comment}}{{end}}
 {{- end}}
+{{- range $i, $r := .ProtoRelations}}
+	{{$r.ProtoType}} {{$r.JSONName}} = {{$.RelationIndex $i}}; // {{$r.Comment}}
+{{- end}}
+{{- range .ProtoNestedMessages}}
+
+	message {{.ModelName}} {
+	{{- range $i, $v := .Fields}}
+		{{$v.GoType}} {{$v.JSONName}} = {{$v.AddOne $i}}; {{if $v.Comment}} // {{$v.Comment}}{{end}}
+	{{- end}}
+	}
+{{- end}}
 }`
 
 	serviceStructCommonTmpl    *template.Template
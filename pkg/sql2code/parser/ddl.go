@@ -0,0 +1,979 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ddl.go converts the DDL of postgresql and sqlite to mysql DDL, so that the same parser
+// can be used for all the sql databases, the result is the same as the DDL obtained from the database.
+
+type ddlColumn struct {
+	Name          string
+	Type          string // type in source database, example: varchar(50), timestamp with time zone
+	MysqlType     string // type converted to mysql, example: varchar(50), timestamp
+	NotNull       bool
+	Default       string // default value in mysql syntax, empty means no default value
+	AutoIncrement bool
+	Comment       string
+	IsPrimaryKey  bool
+	IsUnique      bool
+}
+
+type ddlTable struct {
+	Name        string
+	Comment     string
+	Columns     []*ddlColumn
+	PrimaryKeys []string
+	UniqueKeys  [][]string
+	ForeignKeys []*ForeignKey
+}
+
+func (t *ddlTable) getColumn(name string) *ddlColumn {
+	for _, col := range t.Columns {
+		if col.Name == name {
+			return col
+		}
+	}
+	return nil
+}
+
+func (t *ddlTable) toMysqlDDL() string {
+	lines := make([]string, 0, len(t.Columns)+len(t.UniqueKeys)+len(t.ForeignKeys)+1)
+
+	for _, col := range t.Columns {
+		line := fmt.Sprintf("    `%s` %s", col.Name, col.MysqlType)
+		if col.NotNull || col.IsPrimaryKey {
+			line += " not null"
+		} else {
+			line += " null"
+		}
+		if col.AutoIncrement {
+			line += " auto_increment"
+		}
+		if col.Default != "" {
+			line += " default " + col.Default
+		}
+		if col.Comment != "" {
+			line += " comment " + quoteMysqlString(col.Comment)
+		}
+		lines = append(lines, line)
+	}
+
+	primaryKeys := t.PrimaryKeys
+	if len(primaryKeys) == 0 {
+		for _, col := range t.Columns {
+			if col.IsPrimaryKey {
+				primaryKeys = append(primaryKeys, col.Name)
+			}
+		}
+	}
+	if len(primaryKeys) > 0 {
+		lines = append(lines, fmt.Sprintf("    PRIMARY KEY (%s)", joinMysqlNames(primaryKeys)))
+	}
+
+	for _, col := range t.Columns {
+		if col.IsUnique && !col.IsPrimaryKey {
+			lines = append(lines, fmt.Sprintf("    UNIQUE (%s)", joinMysqlNames([]string{col.Name})))
+		}
+	}
+	for _, keys := range t.UniqueKeys {
+		lines = append(lines, fmt.Sprintf("    UNIQUE (%s)", joinMysqlNames(keys)))
+	}
+
+	for _, fk := range t.ForeignKeys {
+		line := "    "
+		if fk.ConstraintKey != "" {
+			line += fmt.Sprintf("CONSTRAINT `%s` ", fk.ConstraintKey)
+		}
+		line += fmt.Sprintf("FOREIGN KEY (`%s`) REFERENCES `%s` (`%s`)", fk.ColumnName, fk.RefTableName, fk.RefColumnName)
+		lines = append(lines, line)
+	}
+
+	tableOption := ""
+	if t.Comment != "" {
+		tableOption = " comment " + quoteMysqlString(t.Comment)
+	}
+
+	return fmt.Sprintf("CREATE TABLE `%s` (\n%s\n)%s;", t.Name, strings.Join(lines, ",\n"), tableOption)
+}
+
+func joinMysqlNames(names []string) string {
+	var quoted []string
+	for _, name := range names {
+		quoted = append(quoted, "`"+name+"`")
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func quoteMysqlString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// ConvertPostgresqlDDL convert the postgresql DDL to mysql DDL, return mysql DDL and postgresql field types (table.name:type),
+// the field types are keyed by table because the columns of the same name in different tables may have different types.
+// support create table, comment on table/column, alter table add constraint and alter column set default,
+// the other statements such as create index, create sequence are ignored.
+func ConvertPostgresqlDDL(ddl string) (string, map[string]string, error) {
+	tables, err := parseDDLTables(ddl, DBDriverPostgresql)
+	if err != nil {
+		return "", nil, err
+	}
+
+	pgTypeMap := make(map[string]string)
+	sqls := make([]string, 0, len(tables))
+	for _, table := range tables {
+		for _, col := range table.Columns {
+			pgTypeMap[tableFieldKey(table.Name, col.Name)] = getType(newPGFieldByType(col.Type))
+		}
+		sqls = append(sqls, table.toMysqlDDL())
+	}
+
+	return strings.Join(sqls, "\n\n"), pgTypeMap, nil
+}
+
+// ConvertSqliteDDL convert the sqlite DDL to mysql DDL, the other statements except create table are ignored.
+func ConvertSqliteDDL(ddl string) (string, error) {
+	tables, err := parseDDLTables(ddl, DBDriverSqlite)
+	if err != nil {
+		return "", err
+	}
+
+	sqls := make([]string, 0, len(tables))
+	for _, table := range tables {
+		sqls = append(sqls, table.toMysqlDDL())
+	}
+
+	return strings.Join(sqls, "\n\n"), nil
+}
+
+func parseDDLTables(ddl string, dbDriver string) ([]*ddlTable, error) {
+	var tables []*ddlTable
+	getTable := func(name string) *ddlTable {
+		for _, table := range tables {
+			if table.Name == name {
+				return table
+			}
+		}
+		return nil
+	}
+
+	for _, stmt := range splitDDLStatements(ddl) {
+		tokens := tokenizeDDL(stmt)
+		if len(tokens) < 2 {
+			continue
+		}
+
+		switch {
+		case tokens[0].is("CREATE") && hasKeywordBefore(tokens, "TABLE", "("):
+			table, err := parseCreateTable(tokens, dbDriver)
+			if err != nil {
+				return nil, err
+			}
+			if table != nil {
+				tables = append(tables, table)
+			}
+
+		case tokens[0].is("CREATE") && hasKeywordBefore(tokens, "INDEX", "("):
+			parseCreateUniqueIndex(tokens, getTable)
+
+		case tokens[0].is("COMMENT") && tokens[1].is("ON"):
+			parseCommentOn(tokens, getTable)
+
+		case tokens[0].is("ALTER") && tokens[1].is("TABLE"):
+			parseAlterTable(tokens, getTable, dbDriver)
+		}
+	}
+
+	if len(tables) == 0 {
+		return nil, fmt.Errorf("no create table statement found in %s DDL", dbDriver)
+	}
+
+	return tables, nil
+}
+
+// CREATE [TEMP|UNLOGGED ...] TABLE [IF NOT EXISTS] name ( definitions ) [options]
+func parseCreateTable(tokens []*ddlToken, dbDriver string) (*ddlTable, error) {
+	i := indexOfKeyword(tokens, "TABLE") + 1
+	if i < len(tokens) && tokens[i].is("IF") {
+		i += 3 // IF NOT EXISTS
+	}
+	name, i := parseQualifiedName(tokens, i)
+	if name == "" || i >= len(tokens) || tokens[i].kind != tokenGroup {
+		return nil, nil // example: create table ... as select ...
+	}
+
+	table := &ddlTable{Name: name}
+	for _, def := range splitTopLevel(tokens[i].value, ',') {
+		defTokens := tokenizeDDL(def)
+		if len(defTokens) == 0 {
+			continue
+		}
+
+		if isTableConstraint(defTokens) {
+			parseTableConstraint(table, defTokens)
+			continue
+		}
+
+		col, fk := parseColumnDefinition(def, defTokens, dbDriver)
+		if col == nil {
+			return nil, fmt.Errorf("unable to parse column definition '%s' in table %s", strings.TrimSpace(def), name)
+		}
+		table.Columns = append(table.Columns, col)
+		if fk != nil {
+			fk.TableName = name
+			table.ForeignKeys = append(table.ForeignKeys, fk)
+		}
+	}
+
+	for _, key := range table.PrimaryKeys {
+		if col := table.getColumn(key); col != nil {
+			col.IsPrimaryKey = true
+			if len(table.PrimaryKeys) > 1 {
+				col.AutoIncrement = false // composite primary key can't be auto increment
+			}
+		}
+	}
+
+	return table, nil
+}
+
+var columnConstraintKeywords = map[string]struct{}{
+	"CONSTRAINT": {}, "NOT": {}, "NULL": {}, "DEFAULT": {}, "PRIMARY": {}, "UNIQUE": {}, "REFERENCES": {},
+	"CHECK": {}, "GENERATED": {}, "COLLATE": {}, "AUTOINCREMENT": {}, "COMMENT": {}, "AUTO_INCREMENT": {},
+}
+
+func isColumnConstraintKeyword(t *ddlToken) bool {
+	if t.kind != tokenWord {
+		return false
+	}
+	_, ok := columnConstraintKeywords[strings.ToUpper(t.text)]
+	return ok
+}
+
+func parseColumnDefinition(def string, tokens []*ddlToken, dbDriver string) (*ddlColumn, *ForeignKey) {
+	if tokens[0].kind != tokenWord && tokens[0].kind != tokenQuoted {
+		return nil, nil
+	}
+	col := &ddlColumn{Name: tokens[0].value}
+
+	// the type is composed of all tokens until the first constraint keyword
+	i := 1
+	for i < len(tokens) && !isColumnConstraintKeyword(tokens[i]) {
+		i++
+	}
+	if i > 1 {
+		col.Type = strings.ToLower(strings.Join(strings.Fields(def[tokens[1].start:tokens[i-1].end]), " "))
+	}
+
+	var fk *ForeignKey
+	for i < len(tokens) {
+		t := tokens[i]
+		i++
+		switch strings.ToUpper(t.text) {
+		case "CONSTRAINT":
+			i++ // skip constraint name
+		case "NOT":
+			if i < len(tokens) && tokens[i].is("NULL") {
+				col.NotNull = true
+				i++
+			}
+		case "PRIMARY":
+			col.IsPrimaryKey = true
+			if i < len(tokens) && tokens[i].is("KEY") {
+				i++
+			}
+		case "UNIQUE":
+			col.IsUnique = true
+		case "AUTOINCREMENT", "AUTO_INCREMENT":
+			col.AutoIncrement = true
+		case "DEFAULT":
+			j := i
+			for j < len(tokens) && !isColumnConstraintKeyword(tokens[j]) {
+				j++
+			}
+			if j > i {
+				value, isAutoIncrement := convertDefaultValue(def[tokens[i].start:tokens[j-1].end])
+				col.Default = value
+				col.AutoIncrement = col.AutoIncrement || isAutoIncrement
+			}
+			i = j
+		case "GENERATED":
+			for i < len(tokens) && !isColumnConstraintKeyword(tokens[i]) {
+				if tokens[i].is("IDENTITY") {
+					col.AutoIncrement = true
+				}
+				i++
+			}
+		case "REFERENCES":
+			refTable, j := parseQualifiedName(tokens, i)
+			refColumn := columnID
+			if j < len(tokens) && tokens[j].kind == tokenGroup {
+				if names := parseColumnNames(tokens[j].value); len(names) > 0 {
+					refColumn = names[0]
+				}
+				j++
+			}
+			if refTable != "" {
+				fk = &ForeignKey{ColumnName: col.Name, RefTableName: refTable, RefColumnName: refColumn}
+			}
+			i = j
+		case "COMMENT":
+			if i < len(tokens) && tokens[i].kind == tokenString {
+				col.Comment = tokens[i].value
+				i++
+			}
+		}
+	}
+
+	if col.Type == "" {
+		if dbDriver != DBDriverSqlite {
+			return nil, nil
+		}
+		col.Type = "text" // the type can be omitted in sqlite
+	}
+
+	switch dbDriver {
+	case DBDriverSqlite:
+		col.MysqlType = getSqliteMysqlType(col.Type)
+		if col.IsPrimaryKey && strings.HasPrefix(col.MysqlType, "int") {
+			col.MysqlType = "bigint"
+		}
+	default:
+		col.MysqlType = getPostgresqlMysqlType(newPGFieldByType(col.Type))
+		if isPostgresqlSerialType(col.Type) {
+			col.AutoIncrement = true
+		}
+	}
+	if col.AutoIncrement {
+		col.Default = ""
+	}
+
+	return col, fk
+}
+
+func isTableConstraint(tokens []*ddlToken) bool {
+	switch strings.ToUpper(tokens[0].text) {
+	case "CONSTRAINT", "CHECK", "EXCLUDE":
+		return tokens[0].kind == tokenWord
+	case "PRIMARY", "FOREIGN":
+		return len(tokens) > 1 && tokens[1].is("KEY")
+	case "UNIQUE":
+		return len(tokens) > 1 && (tokens[1].kind == tokenGroup || tokens[1].is("KEY") || tokens[1].is("INDEX"))
+	}
+	return false
+}
+
+// [CONSTRAINT name] PRIMARY KEY (a, b) | UNIQUE (a) | FOREIGN KEY (a) REFERENCES t (b)
+func parseTableConstraint(table *ddlTable, tokens []*ddlToken) {
+	constraintName := ""
+	i := 0
+	if tokens[0].is("CONSTRAINT") && len(tokens) > 1 {
+		constraintName = tokens[1].value
+		i = 2
+	}
+	if i >= len(tokens) {
+		return
+	}
+
+	groupAfter := func(start int) ([]string, int) {
+		for j := start; j < len(tokens); j++ {
+			if tokens[j].kind == tokenGroup {
+				return parseColumnNames(tokens[j].value), j + 1
+			}
+		}
+		return nil, len(tokens)
+	}
+
+	switch strings.ToUpper(tokens[i].text) {
+	case "PRIMARY":
+		table.PrimaryKeys, _ = groupAfter(i)
+	case "UNIQUE":
+		if keys, _ := groupAfter(i); len(keys) > 0 {
+			if len(keys) == 1 {
+				if col := table.getColumn(keys[0]); col != nil {
+					col.IsUnique = true
+					return
+				}
+			}
+			table.UniqueKeys = append(table.UniqueKeys, keys)
+		}
+	case "FOREIGN":
+		keys, j := groupAfter(i)
+		if len(keys) == 0 || j >= len(tokens) || !tokens[j].is("REFERENCES") {
+			return
+		}
+		refTable, j := parseQualifiedName(tokens, j+1)
+		refColumns := []string{columnID}
+		if j < len(tokens) && tokens[j].kind == tokenGroup {
+			refColumns = parseColumnNames(tokens[j].value)
+		}
+		if refTable == "" || len(refColumns) == 0 {
+			return
+		}
+		// composite foreign key is not supported, the association joined on a part of the key is wrong
+		if len(keys) > 1 || len(refColumns) > 1 {
+			return
+		}
+		table.ForeignKeys = append(table.ForeignKeys, &ForeignKey{
+			TableName:     table.Name,
+			ColumnName:    keys[0],
+			RefTableName:  refTable,
+			RefColumnName: refColumns[0],
+			ConstraintKey: constraintName,
+		})
+	}
+}
+
+// COMMENT ON TABLE name IS 'text' | COMMENT ON COLUMN table.column IS 'text'
+func parseCommentOn(tokens []*ddlToken, getTable func(string) *ddlTable) {
+	if len(tokens) < 5 {
+		return
+	}
+	names, i := parseNameParts(tokens, 3)
+	if len(names) == 0 || i+1 >= len(tokens) || !tokens[i].is("IS") || tokens[i+1].kind != tokenString {
+		return
+	}
+	comment := tokens[i+1].value
+
+	switch {
+	case tokens[2].is("TABLE"):
+		if table := getTable(names[len(names)-1]); table != nil {
+			table.Comment = comment
+		}
+	case tokens[2].is("COLUMN") && len(names) >= 2:
+		if table := getTable(names[len(names)-2]); table != nil {
+			if col := table.getColumn(names[len(names)-1]); col != nil {
+				col.Comment = comment
+			}
+		}
+	}
+}
+
+// ALTER TABLE [ONLY] [IF EXISTS] name ADD [CONSTRAINT name] ... | ALTER [COLUMN] name SET DEFAULT expr
+func parseAlterTable(tokens []*ddlToken, getTable func(string) *ddlTable, dbDriver string) {
+	i := 2
+	for i < len(tokens) && (tokens[i].is("ONLY") || tokens[i].is("IF") || tokens[i].is("EXISTS")) {
+		i++
+	}
+	name, i := parseQualifiedName(tokens, i)
+	table := getTable(name)
+	if table == nil || i >= len(tokens) {
+		return
+	}
+
+	switch {
+	case tokens[i].is("ADD"):
+		rest := tokens[i+1:]
+		if len(rest) > 0 && isTableConstraint(rest) {
+			parseTableConstraint(table, rest)
+			for _, key := range table.PrimaryKeys {
+				if col := table.getColumn(key); col != nil {
+					col.IsPrimaryKey = true
+				}
+			}
+		}
+
+	case tokens[i].is("ALTER"):
+		i++
+		if i < len(tokens) && tokens[i].is("COLUMN") {
+			i++
+		}
+		if i+3 >= len(tokens) || !tokens[i+1].is("SET") || !tokens[i+2].is("DEFAULT") {
+			return
+		}
+		col := table.getColumn(tokens[i].value)
+		if col == nil {
+			return
+		}
+		value, isAutoIncrement := convertDefaultValue(tokens[i+3].stmt[tokens[i+3].start:tokens[len(tokens)-1].end])
+		if isAutoIncrement {
+			col.AutoIncrement = true
+			col.Default = ""
+		} else if dbDriver == DBDriverPostgresql {
+			col.Default = value
+		}
+	}
+}
+
+// CREATE UNIQUE INDEX name ON table (column), a unique index of single column is regarded as unique column
+func parseCreateUniqueIndex(tokens []*ddlToken, getTable func(string) *ddlTable) {
+	if !tokens[1].is("UNIQUE") {
+		return
+	}
+	i := indexOfKeyword(tokens, "ON")
+	if i < 0 {
+		return
+	}
+	i++
+	if i < len(tokens) && tokens[i].is("ONLY") {
+		i++
+	}
+	name, i := parseQualifiedName(tokens, i)
+	table := getTable(name)
+	for ; table != nil && i < len(tokens); i++ {
+		if tokens[i].kind == tokenGroup {
+			keys := parseColumnNames(tokens[i].value)
+			if len(keys) == 1 {
+				if col := table.getColumn(keys[0]); col != nil {
+					col.IsUnique = true
+				}
+			}
+			return
+		}
+	}
+}
+
+var (
+	castSuffixRegexp = regexp.MustCompile(`(?i)::[a-z_ ]+(\(\d+(,\s*\d+)?\))?(\[\])?$`)
+	numberRegexp     = regexp.MustCompile(`^[-+]?\d+(\.\d+)?$`)
+)
+
+// convert the default value expression to mysql syntax, return empty value if it is not supported.
+func convertDefaultValue(expr string) (string, bool) {
+	expr = strings.TrimSpace(expr)
+	for {
+		trimmed := strings.TrimSpace(castSuffixRegexp.ReplaceAllString(expr, ""))
+		if strings.HasPrefix(trimmed, "(") && strings.HasSuffix(trimmed, ")") && len(splitTopLevel(trimmed[1:len(trimmed)-1], ',')) == 1 &&
+			matchParenthesis(trimmed) == len(trimmed)-1 {
+			trimmed = strings.TrimSpace(trimmed[1 : len(trimmed)-1])
+		}
+		if trimmed == expr {
+			break
+		}
+		expr = trimmed
+	}
+
+	lower := strings.ToLower(expr)
+	switch {
+	case expr == "":
+		return "", false
+	case strings.HasPrefix(lower, "nextval("):
+		return "", true
+	case strings.HasPrefix(expr, "'"):
+		if value, end := readQuoted(expr, 0, '\''); end == len(expr)-1 {
+			return quoteMysqlString(value), false
+		}
+	case numberRegexp.MatchString(expr):
+		return expr, false
+	case lower == "true" || lower == "false":
+		return strings.ToUpper(expr), false
+	case lower == "now()" || strings.HasPrefix(lower, "current_timestamp") || strings.HasPrefix(lower, "localtimestamp") ||
+		strings.Contains(lower, "'now'"):
+		return "CURRENT_TIMESTAMP", false
+	}
+
+	return "", false
+}
+
+// convert the postgresql type in DDL to PGField, the type name is the same as the type obtained from db (pg_type.typname)
+func newPGFieldByType(pgType string) *PGField {
+	typeName, length := splitTypeLength(pgType)
+	if strings.HasSuffix(typeName, "[]") || strings.HasSuffix(typeName, " array") {
+		return &PGField{Type: "_" + strings.TrimSuffix(strings.TrimSuffix(typeName, "[]"), " array")}
+	}
+
+	switch typeName {
+	case "int", "integer", "serial", "serial4":
+		typeName = "int4"
+	case "bigint", "bigserial", "serial8":
+		typeName = "int8"
+	case "smallint", "smallserial", "serial2":
+		typeName = "int2"
+	case "boolean":
+		typeName = "bool"
+	case "real":
+		typeName = "float4"
+	case "float", "double precision":
+		typeName = "float8"
+	case "decimal":
+		typeName = "numeric"
+	case "character varying":
+		typeName = "varchar"
+	case "character", "char":
+		typeName = "bpchar"
+	case "timestamp with time zone":
+		typeName = "timestamptz"
+	case "timestamp without time zone":
+		typeName = "timestamp"
+	case "time with time zone":
+		typeName = "timetz"
+	case "time without time zone":
+		typeName = "time"
+	}
+
+	field := &PGField{Type: typeName}
+	if length > 0 && (typeName == "varchar" || typeName == "bpchar") {
+		field.Lengthvar = length + 4
+	}
+	return field
+}
+
+func isPostgresqlSerialType(pgType string) bool {
+	switch pgType {
+	case "serial", "serial4", "bigserial", "serial8", "smallserial", "serial2":
+		return true
+	}
+	return false
+}
+
+// get the mysql type of postgresql type, the types not supported by PGField are converted first
+func getPostgresqlMysqlType(field *PGField) string {
+	switch {
+	case strings.HasPrefix(field.Type, "_"):
+		return "text"
+	case field.Type == "int4":
+		return "int"
+	case field.Type == "float8":
+		return "double"
+	case field.Type == "timestamptz":
+		return "timestamp"
+	case field.Type == "timetz":
+		return "time"
+	case field.Type == "uuid":
+		return "varchar(36)"
+	case field.Type == "bytea":
+		return "blob"
+	}
+
+	f := *field
+	return f.getMysqlType()
+}
+
+// get the mysql type of sqlite type according to the type affinity of sqlite
+func getSqliteMysqlType(sqliteType string) string {
+	typeName, length := splitTypeLength(sqliteType)
+	upper := strings.ToUpper(typeName)
+
+	switch {
+	case strings.Contains(upper, "BIGINT"):
+		return "bigint"
+	case strings.Contains(upper, "TINYINT"), strings.Contains(upper, "BOOL"):
+		return "tinyint"
+	case strings.Contains(upper, "INT"):
+		return "int"
+	case strings.Contains(upper, "CHAR"), strings.Contains(upper, "CLOB"):
+		if length > 0 {
+			return fmt.Sprintf("varchar(%d)", length)
+		}
+		return "varchar(255)"
+	case strings.Contains(upper, "TEXT"):
+		return "text"
+	case strings.Contains(upper, "BLOB"):
+		return "blob"
+	case strings.Contains(upper, "REAL"), strings.Contains(upper, "FLOA"), strings.Contains(upper, "DOUB"):
+		return "double"
+	case strings.Contains(upper, "DATETIME"), strings.Contains(upper, "TIMESTAMP"):
+		return "datetime"
+	case strings.Contains(upper, "DATE"):
+		return "date"
+	case strings.Contains(upper, "TIME"):
+		return "time"
+	case strings.Contains(upper, "JSON"):
+		return "json"
+	case strings.Contains(upper, "DECIMAL"), strings.Contains(upper, "NUMERIC"):
+		return "decimal"
+	}
+
+	return "varchar(100)"
+}
+
+// split type name and the first length parameter, example: varchar(50) --> varchar, 50
+func splitTypeLength(typ string) (string, int) {
+	start := strings.Index(typ, "(")
+	if start < 0 {
+		return strings.TrimSpace(typ), 0
+	}
+	end := strings.Index(typ[start:], ")")
+	if end < 0 {
+		return strings.TrimSpace(typ[:start]), 0
+	}
+	params := strings.Split(typ[start+1:start+end], ",")
+	length, _ := strconv.Atoi(strings.TrimSpace(params[0]))
+	name := strings.TrimSpace(typ[:start]) + typ[start+end+1:] // example: timestamp(6) with time zone
+	return strings.Join(strings.Fields(name), " "), length
+}
+
+// ------------------------------------------------------------------------------------------
+
+const (
+	tokenWord   = iota + 1 // keyword, identifier or number
+	tokenQuoted            // quoted identifier, example: "name", `name`, [name]
+	tokenString            // string literal, example: 'text'
+	tokenGroup             // content in parentheses
+	tokenSymbol            // other symbols, example: . , ::
+)
+
+type ddlToken struct {
+	kind  int
+	text  string // raw text
+	value string // unquoted value of identifier and string, inner text of group
+	start int    // start position in statement
+	end   int    // end position in statement
+	stmt  string
+}
+
+func (t *ddlToken) is(keyword string) bool {
+	return t.kind == tokenWord && strings.EqualFold(t.text, keyword)
+}
+
+func tokenizeDDL(stmt string) []*ddlToken {
+	var tokens []*ddlToken
+	add := func(kind int, start int, end int, value string) {
+		tokens = append(tokens, &ddlToken{kind: kind, text: stmt[start:end], value: value, start: start, end: end, stmt: stmt})
+	}
+
+	for i := 0; i < len(stmt); {
+		c := stmt[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '\'':
+			value, end := readQuoted(stmt, i, '\'')
+			add(tokenString, i, end+1, value)
+			i = end + 1
+		case (c == 'E' || c == 'e') && i+1 < len(stmt) && stmt[i+1] == '\'':
+			value, end := readQuoted(stmt, i+1, '\'')
+			add(tokenString, i, end+1, value)
+			i = end + 1
+		case c == '"' || c == '`':
+			value, end := readQuoted(stmt, i, c)
+			add(tokenQuoted, i, end+1, value)
+			i = end + 1
+		case c == '[':
+			end := strings.IndexByte(stmt[i:], ']')
+			if end < 0 {
+				end = len(stmt) - i - 1
+			}
+			if i > 0 && len(tokens) > 0 && tokens[len(tokens)-1].end == i && end == 1 {
+				// array type suffix, example: text[]
+				last := tokens[len(tokens)-1]
+				last.end = i + 2
+				last.text = stmt[last.start:last.end]
+				last.value += "[]"
+			} else {
+				add(tokenQuoted, i, i+end+1, stmt[i+1:i+end])
+			}
+			i += end + 1
+		case c == '(':
+			end := matchParenthesis(stmt[i:])
+			if end < 0 {
+				end = len(stmt) - i - 1
+			}
+			add(tokenGroup, i, i+end+1, stmt[i+1:i+end])
+			i += end + 1
+		case isWordChar(c):
+			j := i
+			for j < len(stmt) && isWordChar(stmt[j]) {
+				j++
+			}
+			add(tokenWord, i, j, stmt[i:j])
+			i = j
+		case c == ':' && i+1 < len(stmt) && stmt[i+1] == ':':
+			add(tokenSymbol, i, i+2, "::")
+			i += 2
+		default:
+			add(tokenSymbol, i, i+1, string(c))
+			i++
+		}
+	}
+
+	return tokens
+}
+
+func isWordChar(c byte) bool {
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c >= 0x80
+}
+
+// read the quoted text starting at position start, return the unquoted value and the position of the closing quote.
+func readQuoted(s string, start int, quote byte) (string, int) {
+	var b strings.Builder
+	for i := start + 1; i < len(s); i++ {
+		if s[i] == quote {
+			if i+1 < len(s) && s[i+1] == quote { // escaped quote, example: 'it''s'
+				b.WriteByte(quote)
+				i++
+				continue
+			}
+			return b.String(), i
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String(), len(s) - 1
+}
+
+// return the position of the matching parenthesis, s must start with '('
+func matchParenthesis(s string) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\'', '"', '`':
+			_, i = readQuoted(s, i, s[i])
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// split the text by separator which is not in parentheses or quotes
+func splitTopLevel(s string, sep byte) []string {
+	var parts []string
+	depth, last := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\'', '"', '`':
+			_, i = readQuoted(s, i, s[i])
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case sep:
+			if depth == 0 {
+				parts = append(parts, s[last:i])
+				last = i + 1
+			}
+		}
+	}
+	if strings.TrimSpace(s[last:]) != "" {
+		parts = append(parts, s[last:])
+	}
+	return parts
+}
+
+// split the DDL into statements, comments are removed
+func splitDDLStatements(ddl string) []string {
+	var (
+		stmts []string
+		b     strings.Builder
+	)
+	flush := func() {
+		if stmt := strings.TrimSpace(b.String()); stmt != "" {
+			stmts = append(stmts, stmt)
+		}
+		b.Reset()
+	}
+
+	for i := 0; i < len(ddl); i++ {
+		c := ddl[i]
+		switch {
+		case c == '-' && i+1 < len(ddl) && ddl[i+1] == '-':
+			end := strings.IndexByte(ddl[i:], '\n')
+			if end < 0 {
+				end = len(ddl) - i
+			}
+			i += end - 1
+			b.WriteByte(' ')
+		case c == '/' && i+1 < len(ddl) && ddl[i+1] == '*':
+			end := strings.Index(ddl[i+2:], "*/")
+			if end < 0 {
+				end = len(ddl) - i - 2
+			}
+			i += end + 3
+			b.WriteByte(' ')
+		case c == '\'' || c == '"' || c == '`':
+			_, end := readQuoted(ddl, i, c)
+			b.WriteString(ddl[i : end+1])
+			i = end
+		case c == '$':
+			// dollar quoted string, example: $$text$$, $tag$text$tag$
+			end := strings.IndexByte(ddl[i+1:], '$')
+			if end >= 0 && isDollarTag(ddl[i+1:i+1+end]) {
+				tag := ddl[i : i+end+2]
+				closeIndex := strings.Index(ddl[i+len(tag):], tag)
+				if closeIndex >= 0 {
+					b.WriteString(ddl[i : i+len(tag)+closeIndex+len(tag)])
+					i += len(tag) + closeIndex + len(tag) - 1
+					continue
+				}
+			}
+			b.WriteByte(c)
+		case c == ';':
+			flush()
+		default:
+			b.WriteByte(c)
+		}
+	}
+	flush()
+
+	return stmts
+}
+
+func isDollarTag(tag string) bool {
+	for i := 0; i < len(tag); i++ {
+		c := tag[i]
+		if !(c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (i > 0 && c >= '0' && c <= '9')) {
+			return false
+		}
+	}
+	return true
+}
+
+func indexOfKeyword(tokens []*ddlToken, keyword string) int {
+	for i, t := range tokens {
+		if t.is(keyword) {
+			return i
+		}
+	}
+	return -1
+}
+
+// the keyword appears before the first group token or the symbol
+func hasKeywordBefore(tokens []*ddlToken, keyword string, symbol string) bool {
+	for _, t := range tokens {
+		if t.kind == tokenGroup || t.text == symbol {
+			return false
+		}
+		if t.is(keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// parse the name such as schema.table, "schema"."table", return the last part and the next position
+func parseQualifiedName(tokens []*ddlToken, i int) (string, int) {
+	names, next := parseNameParts(tokens, i)
+	if len(names) == 0 {
+		return "", next
+	}
+	return names[len(names)-1], next
+}
+
+func parseNameParts(tokens []*ddlToken, i int) ([]string, int) {
+	var names []string
+	for i < len(tokens) {
+		t := tokens[i]
+		if t.kind != tokenWord && t.kind != tokenQuoted {
+			break
+		}
+		names = append(names, t.value)
+		i++
+		if i < len(tokens) && tokens[i].text == "." {
+			i++
+			continue
+		}
+		break
+	}
+	return names, i
+}
+
+// parse column names in parentheses, example: "a", b ASC, c --> a, b, c
+func parseColumnNames(s string) []string {
+	var names []string
+	for _, part := range splitTopLevel(s, ',') {
+		tokens := tokenizeDDL(part)
+		if len(tokens) > 0 && (tokens[0].kind == tokenWord || tokens[0].kind == tokenQuoted) {
+			names = append(names, tokens[0].value)
+		}
+	}
+	return names
+}
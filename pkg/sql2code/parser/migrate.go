@@ -0,0 +1,888 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/zhufuyi/sqlparser/ast"
+	"github.com/zhufuyi/sqlparser/dependency/types"
+	"github.com/zhufuyi/sqlparser/parser"
+
+	"github.com/go-dev-frame/sponge/pkg/sgorm/sqlite"
+)
+
+// TableSchema table structure, used to compare the differences between two versions of table
+type TableSchema struct {
+	Name        string          `json:"name"`
+	Comment     string          `json:"comment"`
+	Columns     []*ColumnSchema `json:"columns"`
+	PrimaryKeys []string        `json:"primaryKeys"`
+	Indexes     []*IndexSchema  `json:"indexes"`
+
+	noIndexInfo bool // the indexes are not obtained, example: the table structure obtained from postgresql or sqlite db
+}
+
+// ColumnSchema column structure
+type ColumnSchema struct {
+	Name          string `json:"name"`
+	Type          string `json:"type"` // type in source database, example: varchar(50), bigint unsigned
+	NotNull       bool   `json:"notNull"`
+	Default       string `json:"default"` // default value in sql syntax, empty means no default value
+	AutoIncrement bool   `json:"autoIncrement"`
+	OnUpdate      string `json:"onUpdate"` // only for mysql, example: CURRENT_TIMESTAMP
+	Comment       string `json:"comment"`
+}
+
+// IndexSchema index structure
+type IndexSchema struct {
+	Name    string   `json:"name"`
+	Columns []string `json:"columns"`
+	Unique  bool     `json:"unique"`
+}
+
+func (t *TableSchema) getColumn(name string) *ColumnSchema {
+	for _, col := range t.Columns {
+		if col.Name == name {
+			return col
+		}
+	}
+	return nil
+}
+
+func (t *TableSchema) getIndex(name string) *IndexSchema {
+	for _, index := range t.Indexes {
+		if index.Name == name {
+			return index
+		}
+	}
+	return nil
+}
+
+// ParseTableSchemas parse the create table statements of DDL to table structures,
+// dbDriver supports mysql, tidb, postgresql and sqlite, default is mysql.
+func ParseTableSchemas(ddl string, dbDriver string) ([]*TableSchema, error) {
+	dbDriver = strings.ToLower(dbDriver)
+	switch dbDriver {
+	case "", DBDriverMysql, DBDriverTidb:
+		return parseMysqlTableSchemas(ddl)
+	case DBDriverPostgresql, DBDriverSqlite:
+		tables, err := parseDDLTables(ddl, dbDriver)
+		if err != nil {
+			return nil, err
+		}
+		schemas := make([]*TableSchema, 0, len(tables))
+		for _, table := range tables {
+			schemas = append(schemas, table.toTableSchema())
+		}
+		return schemas, nil
+	}
+
+	return nil, fmt.Errorf("unsupported database driver %s, only mysql, tidb, postgresql and sqlite are supported", dbDriver)
+}
+
+// GetTableSchema get the table structure from db, return nil if the table does not exist.
+func GetTableSchema(dbDriver string, dsn string, tableName string) (*TableSchema, error) {
+	switch strings.ToLower(dbDriver) {
+	case DBDriverMysql, DBDriverTidb:
+		ddl, err := GetMysqlTableInfo(dsn, tableName)
+		if err != nil {
+			if strings.Contains(err.Error(), "doesn't exist") || strings.Contains(err.Error(), "not found") {
+				return nil, nil
+			}
+			return nil, err
+		}
+		tables, err := parseMysqlTableSchemas(ddl)
+		if err != nil || len(tables) == 0 {
+			return nil, err
+		}
+		return tables[0], nil
+
+	case DBDriverPostgresql:
+		fields, err := GetPostgresqlTableInfo(dsn, tableName)
+		if err != nil || len(fields) == 0 {
+			return nil, err
+		}
+		return pgFieldsToTableSchema(tableName, fields), nil
+
+	case DBDriverSqlite:
+		return getSqliteTableSchema(dsn, tableName)
+	}
+
+	return nil, fmt.Errorf("unsupported database driver %s, only mysql, tidb, postgresql and sqlite are supported", dbDriver)
+}
+
+func parseMysqlTableSchemas(ddl string) ([]*TableSchema, error) {
+	stmts, err := parser.New().Parse(ddl, "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	var tables []*TableSchema
+	for _, stmt := range stmts {
+		ct, ok := stmt.(*ast.CreateTableStmt)
+		if !ok {
+			continue
+		}
+
+		table := &TableSchema{Name: ct.Table.Name.String()}
+		for _, o := range ct.Options {
+			if o.Tp == ast.TableOptionComment {
+				table.Comment = o.StrValue
+			}
+		}
+
+		for _, col := range ct.Cols {
+			column := &ColumnSchema{
+				Name: col.Name.Name.String(),
+				Type: normalizeMysqlType(col.Tp.InfoSchemaStr()),
+			}
+			for _, o := range col.Options {
+				switch o.Tp {
+				case ast.ColumnOptionPrimaryKey:
+					table.PrimaryKeys = []string{column.Name}
+					column.NotNull = true
+				case ast.ColumnOptionNotNull:
+					column.NotNull = true
+				case ast.ColumnOptionAutoIncrement:
+					column.AutoIncrement = true
+				case ast.ColumnOptionDefaultValue:
+					column.Default = getSQLDefaultValue(o.Expr)
+				case ast.ColumnOptionOnUpdate:
+					column.OnUpdate = getSQLDefaultValue(o.Expr)
+				case ast.ColumnOptionUniqKey:
+					table.Indexes = append(table.Indexes, &IndexSchema{Name: column.Name, Columns: []string{column.Name}, Unique: true})
+				case ast.ColumnOptionComment:
+					column.Comment = o.Expr.GetDatum().GetString()
+				}
+			}
+			table.Columns = append(table.Columns, column)
+		}
+
+		for _, con := range ct.Constraints {
+			keys := make([]string, 0, len(con.Keys))
+			for _, key := range con.Keys {
+				keys = append(keys, key.Column.Name.String())
+			}
+			if len(keys) == 0 {
+				continue
+			}
+			switch con.Tp {
+			case ast.ConstraintPrimaryKey:
+				table.PrimaryKeys = keys
+				for _, key := range keys {
+					if col := table.getColumn(key); col != nil {
+						col.NotNull = true
+					}
+				}
+			case ast.ConstraintKey, ast.ConstraintIndex, ast.ConstraintUniq, ast.ConstraintUniqKey, ast.ConstraintUniqIndex:
+				name := con.Name
+				if name == "" {
+					name = keys[0] // the default index name of mysql is the first column name
+				}
+				isUnique := con.Tp == ast.ConstraintUniq || con.Tp == ast.ConstraintUniqKey || con.Tp == ast.ConstraintUniqIndex
+				table.Indexes = append(table.Indexes, &IndexSchema{Name: name, Columns: keys, Unique: isUnique})
+			}
+		}
+
+		tables = append(tables, table)
+	}
+
+	if len(tables) == 0 {
+		return nil, errors.New("no create table statement found in DDL")
+	}
+
+	return tables, nil
+}
+
+var intDisplayWidthRegexp = regexp.MustCompile(`^(tinyint|smallint|mediumint|int|integer|bigint)\(\d+\)`)
+
+// remove the display width of integer type, except tinyint(1), example: bigint(20) unsigned --> bigint unsigned
+func normalizeMysqlType(t string) string {
+	t = strings.ToLower(strings.Join(strings.Fields(t), " "))
+	if strings.HasPrefix(t, "tinyint(1)") {
+		return t
+	}
+	return intDisplayWidthRegexp.ReplaceAllString(t, "$1")
+}
+
+// get the default value in sql syntax, string is quoted, example: 'abc', 0, NULL, CURRENT_TIMESTAMP
+func getSQLDefaultValue(expr ast.ExprNode) string {
+	if funcExpr, ok := expr.(*ast.FuncCallExpr); ok {
+		return strings.ToUpper(funcExpr.FnName.O)
+	}
+
+	datum := expr.GetDatum()
+	switch datum.Kind() {
+	case types.KindNull:
+		return "NULL"
+	case types.KindString, types.KindBytes:
+		return quoteMysqlString(datum.GetString())
+	}
+	return fmt.Sprintf("%v", datum.GetValue())
+}
+
+func (t *ddlTable) toTableSchema() *TableSchema {
+	table := &TableSchema{Name: t.Name, Comment: t.Comment}
+	for _, col := range t.Columns {
+		table.Columns = append(table.Columns, &ColumnSchema{
+			Name:          col.Name,
+			Type:          col.Type,
+			NotNull:       col.NotNull || col.IsPrimaryKey,
+			Default:       col.Default,
+			AutoIncrement: col.AutoIncrement,
+			Comment:       col.Comment,
+		})
+		if col.IsPrimaryKey && len(t.PrimaryKeys) == 0 {
+			table.PrimaryKeys = append(table.PrimaryKeys, col.Name)
+		}
+		if col.IsUnique && !col.IsPrimaryKey {
+			table.Indexes = append(table.Indexes, newUniqueIndexSchema(t.Name, []string{col.Name}))
+		}
+	}
+	if len(t.PrimaryKeys) > 0 {
+		table.PrimaryKeys = t.PrimaryKeys
+	}
+	for _, keys := range t.UniqueKeys {
+		table.Indexes = append(table.Indexes, newUniqueIndexSchema(t.Name, keys))
+	}
+	return table
+}
+
+// the default unique constraint name of postgresql, example: user_email_key
+func newUniqueIndexSchema(tableName string, columns []string) *IndexSchema {
+	return &IndexSchema{
+		Name:    tableName + "_" + strings.Join(columns, "_") + "_key",
+		Columns: columns,
+		Unique:  true,
+	}
+}
+
+func pgFieldsToTableSchema(tableName string, fields PGFields) *TableSchema {
+	table := &TableSchema{Name: tableName, noIndexInfo: true}
+	for _, field := range fields {
+		col := &ColumnSchema{
+			Name:    field.Name,
+			Type:    getType(field),
+			NotNull: field.Notnull,
+			Comment: field.Comment,
+		}
+		col.Default, col.AutoIncrement = convertDefaultValue(field.Default)
+		table.Columns = append(table.Columns, col)
+		if field.IsPrimaryKey {
+			table.PrimaryKeys = append(table.PrimaryKeys, field.Name)
+		}
+	}
+	return table
+}
+
+func getSqliteTableSchema(dbFile string, tableName string) (*TableSchema, error) {
+	db, err := sqlite.Init(dbFile)
+	if err != nil {
+		return nil, err
+	}
+	defer sqlite.Close(db) //nolint
+
+	var fields SqliteFields
+	err = db.Raw(fmt.Sprintf("PRAGMA table_info('%s')", tableName)).Scan(&fields).Error
+	if err != nil || len(fields) == 0 {
+		return nil, err
+	}
+
+	table := &TableSchema{Name: tableName, noIndexInfo: true}
+	pks := map[int]string{}
+	for _, field := range fields {
+		col := &ColumnSchema{
+			Name:    field.Name,
+			Type:    strings.ToLower(field.Type),
+			NotNull: field.Notnull == 1 || field.Pk > 0,
+		}
+		col.Default, _ = convertDefaultValue(field.DefaultValue)
+		table.Columns = append(table.Columns, col)
+		if field.Pk > 0 {
+			pks[field.Pk] = field.Name
+		}
+	}
+	for i := 1; i <= len(pks); i++ {
+		table.PrimaryKeys = append(table.PrimaryKeys, pks[i])
+	}
+	return table, nil
+}
+
+// ------------------------------------------------------------------------------------------
+
+// SchemaDiff the sql statements to migrate the schema
+type SchemaDiff struct {
+	Up   []string `json:"up"`   // statements to upgrade the old schema to the new schema
+	Down []string `json:"down"` // statements to rollback the new schema to the old schema
+}
+
+// IsEmpty whether there is no difference
+func (d *SchemaDiff) IsEmpty() bool {
+	return d == nil || len(d.Up) == 0
+}
+
+// DiffDDL compare two versions of DDL and return the migration statements, dbDriver supports mysql, tidb, postgresql and sqlite.
+func DiffDDL(oldDDL string, newDDL string, dbDriver string) (*SchemaDiff, error) {
+	var oldTables []*TableSchema
+	if strings.TrimSpace(oldDDL) != "" {
+		var err error
+		oldTables, err = ParseTableSchemas(oldDDL, dbDriver)
+		if err != nil {
+			return nil, fmt.Errorf("parse old DDL error: %v", err)
+		}
+	}
+	newTables, err := ParseTableSchemas(newDDL, dbDriver)
+	if err != nil {
+		return nil, fmt.Errorf("parse new DDL error: %v", err)
+	}
+
+	return DiffTableSchemas(oldTables, newTables, dbDriver)
+}
+
+type migrationStep struct {
+	up   []string
+	down []string
+}
+
+// DiffTableSchemas compare the table structures and return the migration statements,
+// the column renaming is regarded as dropping the old column and adding the new column.
+// An error is returned if the changes can't be expressed by the database, e.g. modifying column in sqlite.
+func DiffTableSchemas(oldTables []*TableSchema, newTables []*TableSchema, dbDriver string) (*SchemaDiff, error) {
+	d := newSQLDialect(dbDriver)
+	oldTableMap := make(map[string]*TableSchema, len(oldTables))
+	for _, table := range oldTables {
+		oldTableMap[table.Name] = table
+	}
+	newTableMap := make(map[string]*TableSchema, len(newTables))
+	for _, table := range newTables {
+		newTableMap[table.Name] = table
+	}
+
+	var steps []*migrationStep
+	for _, table := range newTables {
+		oldTable, ok := oldTableMap[table.Name]
+		if !ok {
+			steps = append(steps, &migrationStep{up: d.createTable(table), down: []string{d.dropTable(table.Name)}})
+			continue
+		}
+		steps = append(steps, diffTable(d, oldTable, table)...)
+	}
+	for _, table := range oldTables {
+		if _, ok := newTableMap[table.Name]; !ok {
+			steps = append(steps, &migrationStep{up: []string{d.dropTable(table.Name)}, down: d.createTable(table)})
+		}
+	}
+
+	if err := d.unsupported(); err != nil {
+		return nil, err
+	}
+
+	diff := &SchemaDiff{}
+	for _, step := range steps {
+		diff.Up = append(diff.Up, step.up...)
+	}
+	for i := len(steps) - 1; i >= 0; i-- {
+		diff.Down = append(diff.Down, steps[i].down...)
+	}
+	return diff, nil
+}
+
+// the order of upgrade: drop indexes, drop primary key, add columns, modify columns, drop columns, add primary key, add indexes,
+// the order of rollback is reversed.
+func diffTable(d sqlDialect, oldTable *TableSchema, newTable *TableSchema) []*migrationStep {
+	var steps []*migrationStep
+	add := func(up []string, down []string) {
+		if len(up) > 0 {
+			steps = append(steps, &migrationStep{up: up, down: down})
+		}
+	}
+	name := newTable.Name
+	isCompareIndex := !oldTable.noIndexInfo && !newTable.noIndexInfo
+	isPrimaryKeyChanged := strings.Join(oldTable.PrimaryKeys, ",") != strings.Join(newTable.PrimaryKeys, ",")
+
+	if isCompareIndex {
+		for _, index := range oldTable.Indexes {
+			if newIndex := newTable.getIndex(index.Name); newIndex == nil || !isSameIndex(index, newIndex) {
+				add([]string{d.dropIndex(name, index)}, []string{d.addIndex(name, index)})
+			}
+		}
+	}
+	if isPrimaryKeyChanged && len(oldTable.PrimaryKeys) > 0 {
+		add([]string{d.dropPrimaryKey(name)}, []string{d.addPrimaryKey(name, oldTable.PrimaryKeys)})
+	}
+
+	for i, col := range newTable.Columns {
+		if oldTable.getColumn(col.Name) == nil {
+			after := ""
+			if i > 0 {
+				after = newTable.Columns[i-1].Name
+			}
+			add(d.addColumn(name, col, after), []string{d.dropColumn(name, col.Name)})
+		}
+	}
+	for _, col := range newTable.Columns {
+		if oldCol := oldTable.getColumn(col.Name); oldCol != nil && !d.isSameColumn(oldCol, col) {
+			add(d.modifyColumn(name, oldCol, col), d.modifyColumn(name, col, oldCol))
+		}
+	}
+	for i, col := range oldTable.Columns {
+		if newTable.getColumn(col.Name) == nil {
+			after := ""
+			if i > 0 {
+				after = oldTable.Columns[i-1].Name
+			}
+			add([]string{d.dropColumn(name, col.Name)}, d.addColumn(name, col, after))
+		}
+	}
+
+	if isPrimaryKeyChanged && len(newTable.PrimaryKeys) > 0 {
+		add([]string{d.addPrimaryKey(name, newTable.PrimaryKeys)}, []string{d.dropPrimaryKey(name)})
+	}
+	if isCompareIndex {
+		for _, index := range newTable.Indexes {
+			if oldIndex := oldTable.getIndex(index.Name); oldIndex == nil || !isSameIndex(oldIndex, index) {
+				add([]string{d.addIndex(name, index)}, []string{d.dropIndex(name, index)})
+			}
+		}
+	}
+	if oldTable.Comment != newTable.Comment {
+		add(d.commentTable(name, newTable.Comment), d.commentTable(name, oldTable.Comment))
+	}
+
+	return steps
+}
+
+func isSameIndex(a *IndexSchema, b *IndexSchema) bool {
+	return a.Unique == b.Unique && strings.Join(a.Columns, ",") == strings.Join(b.Columns, ",")
+}
+
+// ------------------------------------------------------------------------------------------
+
+type sqlDialect interface {
+	createTable(table *TableSchema) []string
+	dropTable(tableName string) string
+	addColumn(tableName string, col *ColumnSchema, after string) []string
+	dropColumn(tableName string, colName string) string
+	modifyColumn(tableName string, from *ColumnSchema, to *ColumnSchema) []string
+	isSameColumn(a *ColumnSchema, b *ColumnSchema) bool
+	addIndex(tableName string, index *IndexSchema) string
+	dropIndex(tableName string, index *IndexSchema) string
+	addPrimaryKey(tableName string, keys []string) string
+	dropPrimaryKey(tableName string) string
+	commentTable(tableName string, comment string) []string
+	unsupported() error // the changes that can't be expressed by the database
+}
+
+func newSQLDialect(dbDriver string) sqlDialect {
+	switch strings.ToLower(dbDriver) {
+	case DBDriverPostgresql:
+		return &postgresqlDialect{}
+	case DBDriverSqlite:
+		return &sqliteDialect{}
+	}
+	return &mysqlDialect{}
+}
+
+// normalize the default value for comparison, example: '0' --> 0, null --> empty
+func normalizeDefaultValue(col *ColumnSchema) string {
+	v := col.Default
+	if strings.EqualFold(v, "NULL") {
+		if col.NotNull {
+			return v
+		}
+		return ""
+	}
+	if strings.HasPrefix(v, "'") && strings.HasSuffix(v, "'") && len(v) > 1 && numberRegexp.MatchString(v[1:len(v)-1]) {
+		return v[1 : len(v)-1]
+	}
+	if strings.EqualFold(v, "true") {
+		return "1"
+	}
+	if strings.EqualFold(v, "false") {
+		return "0"
+	}
+	return v
+}
+
+func joinQuotedNames(names []string, quote string) string {
+	quoted := make([]string, 0, len(names))
+	for _, name := range names {
+		quoted = append(quoted, quote+name+quote)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func quoteStandardString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// mysql and tidb
+
+type mysqlDialect struct{}
+
+func (d *mysqlDialect) columnDefinition(col *ColumnSchema) string {
+	def := fmt.Sprintf("`%s` %s", col.Name, col.Type)
+	if col.NotNull {
+		def += " NOT NULL"
+	} else {
+		def += " NULL"
+	}
+	if col.AutoIncrement {
+		def += " AUTO_INCREMENT"
+	}
+	if col.Default != "" {
+		def += " DEFAULT " + col.Default
+	}
+	if col.OnUpdate != "" {
+		def += " ON UPDATE " + col.OnUpdate
+	}
+	if col.Comment != "" {
+		def += " COMMENT " + quoteMysqlString(col.Comment)
+	}
+	return def
+}
+
+func (d *mysqlDialect) createTable(table *TableSchema) []string {
+	lines := make([]string, 0, len(table.Columns)+len(table.Indexes)+1)
+	for _, col := range table.Columns {
+		lines = append(lines, "    "+d.columnDefinition(col))
+	}
+	if len(table.PrimaryKeys) > 0 {
+		lines = append(lines, fmt.Sprintf("    PRIMARY KEY (%s)", joinQuotedNames(table.PrimaryKeys, "`")))
+	}
+	for _, index := range table.Indexes {
+		keyType := "KEY"
+		if index.Unique {
+			keyType = "UNIQUE KEY"
+		}
+		lines = append(lines, fmt.Sprintf("    %s `%s` (%s)", keyType, index.Name, joinQuotedNames(index.Columns, "`")))
+	}
+	tableOption := ""
+	if table.Comment != "" {
+		tableOption = " COMMENT=" + quoteMysqlString(table.Comment)
+	}
+	return []string{fmt.Sprintf("CREATE TABLE `%s` (\n%s\n)%s;", table.Name, strings.Join(lines, ",\n"), tableOption)}
+}
+
+func (d *mysqlDialect) dropTable(tableName string) string {
+	return fmt.Sprintf("DROP TABLE IF EXISTS `%s`;", tableName)
+}
+
+func (d *mysqlDialect) addColumn(tableName string, col *ColumnSchema, after string) []string {
+	position := " FIRST"
+	if after != "" {
+		position = fmt.Sprintf(" AFTER `%s`", after)
+	}
+	return []string{fmt.Sprintf("ALTER TABLE `%s` ADD COLUMN %s%s;", tableName, d.columnDefinition(col), position)}
+}
+
+func (d *mysqlDialect) dropColumn(tableName string, colName string) string {
+	return fmt.Sprintf("ALTER TABLE `%s` DROP COLUMN `%s`;", tableName, colName)
+}
+
+func (d *mysqlDialect) modifyColumn(tableName string, _ *ColumnSchema, to *ColumnSchema) []string {
+	return []string{fmt.Sprintf("ALTER TABLE `%s` MODIFY COLUMN %s;", tableName, d.columnDefinition(to))}
+}
+
+func (d *mysqlDialect) isSameColumn(a *ColumnSchema, b *ColumnSchema) bool {
+	return normalizeMysqlType(a.Type) == normalizeMysqlType(b.Type) &&
+		a.NotNull == b.NotNull &&
+		a.AutoIncrement == b.AutoIncrement &&
+		normalizeDefaultValue(a) == normalizeDefaultValue(b) &&
+		strings.EqualFold(a.OnUpdate, b.OnUpdate) &&
+		a.Comment == b.Comment
+}
+
+func (d *mysqlDialect) addIndex(tableName string, index *IndexSchema) string {
+	indexType := "INDEX"
+	if index.Unique {
+		indexType = "UNIQUE INDEX"
+	}
+	return fmt.Sprintf("ALTER TABLE `%s` ADD %s `%s` (%s);", tableName, indexType, index.Name, joinQuotedNames(index.Columns, "`"))
+}
+
+func (d *mysqlDialect) dropIndex(tableName string, index *IndexSchema) string {
+	return fmt.Sprintf("ALTER TABLE `%s` DROP INDEX `%s`;", tableName, index.Name)
+}
+
+func (d *mysqlDialect) addPrimaryKey(tableName string, keys []string) string {
+	return fmt.Sprintf("ALTER TABLE `%s` ADD PRIMARY KEY (%s);", tableName, joinQuotedNames(keys, "`"))
+}
+
+func (d *mysqlDialect) dropPrimaryKey(tableName string) string {
+	return fmt.Sprintf("ALTER TABLE `%s` DROP PRIMARY KEY;", tableName)
+}
+
+func (d *mysqlDialect) commentTable(tableName string, comment string) []string {
+	return []string{fmt.Sprintf("ALTER TABLE `%s` COMMENT=%s;", tableName, quoteMysqlString(comment))}
+}
+
+func (d *mysqlDialect) unsupported() error {
+	return nil
+}
+
+// postgresql
+
+type postgresqlDialect struct{}
+
+// the type name used for comparison, it is the same as the type obtained from db (pg_type.typname)
+func (d *postgresqlDialect) typeName(t string) string {
+	return getType(newPGFieldByType(strings.ToLower(t)))
+}
+
+// the serial type can only be used to create column
+func (d *postgresqlDialect) columnType(col *ColumnSchema) string {
+	t := strings.ToLower(col.Type)
+	if !col.AutoIncrement || isPostgresqlSerialType(t) {
+		return col.Type
+	}
+	switch d.typeName(t) {
+	case "int2":
+		return "smallserial"
+	case "int4":
+		return "serial"
+	case "int8":
+		return "bigserial"
+	}
+	return col.Type
+}
+
+func (d *postgresqlDialect) columnDefinition(col *ColumnSchema) string {
+	def := fmt.Sprintf(`"%s" %s`, col.Name, d.columnType(col))
+	if col.NotNull {
+		def += " NOT NULL"
+	}
+	if col.Default != "" && !col.AutoIncrement {
+		def += " DEFAULT " + col.Default
+	}
+	return def
+}
+
+func (d *postgresqlDialect) commentColumn(tableName string, col *ColumnSchema) string {
+	comment := "NULL"
+	if col.Comment != "" {
+		comment = quoteStandardString(col.Comment)
+	}
+	return fmt.Sprintf(`COMMENT ON COLUMN "%s"."%s" IS %s;`, tableName, col.Name, comment)
+}
+
+func (d *postgresqlDialect) createTable(table *TableSchema) []string {
+	lines := make([]string, 0, len(table.Columns)+len(table.Indexes)+1)
+	for _, col := range table.Columns {
+		lines = append(lines, "    "+d.columnDefinition(col))
+	}
+	if len(table.PrimaryKeys) > 0 {
+		lines = append(lines, fmt.Sprintf("    PRIMARY KEY (%s)", joinQuotedNames(table.PrimaryKeys, `"`)))
+	}
+	for _, index := range table.Indexes {
+		if index.Unique {
+			lines = append(lines, fmt.Sprintf(`    CONSTRAINT "%s" UNIQUE (%s)`, index.Name, joinQuotedNames(index.Columns, `"`)))
+		}
+	}
+
+	sqls := []string{fmt.Sprintf("CREATE TABLE \"%s\" (\n%s\n);", table.Name, strings.Join(lines, ",\n"))}
+	for _, index := range table.Indexes {
+		if !index.Unique {
+			sqls = append(sqls, d.addIndex(table.Name, index))
+		}
+	}
+	if table.Comment != "" {
+		sqls = append(sqls, d.commentTable(table.Name, table.Comment)...)
+	}
+	for _, col := range table.Columns {
+		if col.Comment != "" {
+			sqls = append(sqls, d.commentColumn(table.Name, col))
+		}
+	}
+	return sqls
+}
+
+func (d *postgresqlDialect) dropTable(tableName string) string {
+	return fmt.Sprintf(`DROP TABLE IF EXISTS "%s";`, tableName)
+}
+
+func (d *postgresqlDialect) addColumn(tableName string, col *ColumnSchema, _ string) []string {
+	sqls := []string{fmt.Sprintf(`ALTER TABLE "%s" ADD COLUMN %s;`, tableName, d.columnDefinition(col))}
+	if col.Comment != "" {
+		sqls = append(sqls, d.commentColumn(tableName, col))
+	}
+	return sqls
+}
+
+func (d *postgresqlDialect) dropColumn(tableName string, colName string) string {
+	return fmt.Sprintf(`ALTER TABLE "%s" DROP COLUMN "%s";`, tableName, colName)
+}
+
+func (d *postgresqlDialect) modifyColumn(tableName string, from *ColumnSchema, to *ColumnSchema) []string {
+	var sqls []string
+	prefix := fmt.Sprintf(`ALTER TABLE "%s" ALTER COLUMN "%s"`, tableName, to.Name)
+	if d.typeName(from.Type) != d.typeName(to.Type) {
+		t := to.Type
+		if isPostgresqlSerialType(strings.ToLower(t)) {
+			t = d.typeName(t)
+		}
+		sqls = append(sqls, fmt.Sprintf(`%s TYPE %s USING "%s"::%s;`, prefix, t, to.Name, t))
+	}
+	if from.NotNull != to.NotNull {
+		if to.NotNull {
+			sqls = append(sqls, prefix+" SET NOT NULL;")
+		} else {
+			sqls = append(sqls, prefix+" DROP NOT NULL;")
+		}
+	}
+	if from.AutoIncrement == to.AutoIncrement && normalizeDefaultValue(from) != normalizeDefaultValue(to) {
+		if to.Default == "" {
+			sqls = append(sqls, prefix+" DROP DEFAULT;")
+		} else {
+			sqls = append(sqls, prefix+" SET DEFAULT "+to.Default+";")
+		}
+	}
+	if from.Comment != to.Comment {
+		sqls = append(sqls, d.commentColumn(tableName, to))
+	}
+	return sqls
+}
+
+func (d *postgresqlDialect) isSameColumn(a *ColumnSchema, b *ColumnSchema) bool {
+	return len(d.modifyColumn("", a, b)) == 0
+}
+
+func (d *postgresqlDialect) addIndex(tableName string, index *IndexSchema) string {
+	if index.Unique {
+		return fmt.Sprintf(`ALTER TABLE "%s" ADD CONSTRAINT "%s" UNIQUE (%s);`, tableName, index.Name, joinQuotedNames(index.Columns, `"`))
+	}
+	return fmt.Sprintf(`CREATE INDEX "%s" ON "%s" (%s);`, index.Name, tableName, joinQuotedNames(index.Columns, `"`))
+}
+
+func (d *postgresqlDialect) dropIndex(tableName string, index *IndexSchema) string {
+	if index.Unique {
+		return fmt.Sprintf(`ALTER TABLE "%s" DROP CONSTRAINT "%s";`, tableName, index.Name)
+	}
+	return fmt.Sprintf(`DROP INDEX IF EXISTS "%s";`, index.Name)
+}
+
+func (d *postgresqlDialect) addPrimaryKey(tableName string, keys []string) string {
+	return fmt.Sprintf(`ALTER TABLE "%s" ADD PRIMARY KEY (%s);`, tableName, joinQuotedNames(keys, `"`))
+}
+
+// the default primary key constraint name of postgresql is <table>_pkey
+func (d *postgresqlDialect) dropPrimaryKey(tableName string) string {
+	return fmt.Sprintf(`ALTER TABLE "%s" DROP CONSTRAINT "%s_pkey";`, tableName, tableName)
+}
+
+func (d *postgresqlDialect) commentTable(tableName string, comment string) []string {
+	value := "NULL"
+	if comment != "" {
+		value = quoteStandardString(comment)
+	}
+	return []string{fmt.Sprintf(`COMMENT ON TABLE "%s" IS %s;`, tableName, value)}
+}
+
+func (d *postgresqlDialect) unsupported() error {
+	return nil
+}
+
+// sqlite, it does not support modifying column and primary key, the table must be rebuilt manually
+
+type sqliteDialect struct {
+	unsupportedChanges []string
+}
+
+func (d *sqliteDialect) addUnsupported(change string) {
+	for _, c := range d.unsupportedChanges {
+		if c == change {
+			return
+		}
+	}
+	d.unsupportedChanges = append(d.unsupportedChanges, change)
+}
+
+func (d *sqliteDialect) columnDefinition(col *ColumnSchema, isPrimaryKey bool) string {
+	def := fmt.Sprintf(`"%s" %s`, col.Name, col.Type)
+	if isPrimaryKey {
+		def += " PRIMARY KEY"
+		if col.AutoIncrement {
+			def += " AUTOINCREMENT"
+		}
+	}
+	if col.NotNull && !isPrimaryKey {
+		def += " NOT NULL"
+	}
+	if col.Default != "" {
+		def += " DEFAULT " + col.Default
+	}
+	return def
+}
+
+func (d *sqliteDialect) createTable(table *TableSchema) []string {
+	lines := make([]string, 0, len(table.Columns)+len(table.Indexes)+1)
+	isSinglePrimaryKey := len(table.PrimaryKeys) == 1
+	for _, col := range table.Columns {
+		lines = append(lines, "    "+d.columnDefinition(col, isSinglePrimaryKey && table.PrimaryKeys[0] == col.Name))
+	}
+	if len(table.PrimaryKeys) > 1 {
+		lines = append(lines, fmt.Sprintf("    PRIMARY KEY (%s)", joinQuotedNames(table.PrimaryKeys, `"`)))
+	}
+
+	sqls := []string{fmt.Sprintf("CREATE TABLE \"%s\" (\n%s\n);", table.Name, strings.Join(lines, ",\n"))}
+	for _, index := range table.Indexes {
+		sqls = append(sqls, d.addIndex(table.Name, index))
+	}
+	return sqls
+}
+
+func (d *sqliteDialect) dropTable(tableName string) string {
+	return fmt.Sprintf(`DROP TABLE IF EXISTS "%s";`, tableName)
+}
+
+func (d *sqliteDialect) addColumn(tableName string, col *ColumnSchema, _ string) []string {
+	return []string{fmt.Sprintf(`ALTER TABLE "%s" ADD COLUMN %s;`, tableName, d.columnDefinition(col, false))}
+}
+
+func (d *sqliteDialect) dropColumn(tableName string, colName string) string {
+	return fmt.Sprintf(`ALTER TABLE "%s" DROP COLUMN "%s";`, tableName, colName)
+}
+
+func (d *sqliteDialect) modifyColumn(tableName string, _ *ColumnSchema, to *ColumnSchema) []string {
+	d.addUnsupported(fmt.Sprintf(`modifying column "%s" of table "%s"`, to.Name, tableName))
+	return nil
+}
+
+func (d *sqliteDialect) isSameColumn(a *ColumnSchema, b *ColumnSchema) bool {
+	return strings.EqualFold(a.Type, b.Type) && a.NotNull == b.NotNull && normalizeDefaultValue(a) == normalizeDefaultValue(b)
+}
+
+func (d *sqliteDialect) addIndex(tableName string, index *IndexSchema) string {
+	indexType := "INDEX"
+	if index.Unique {
+		indexType = "UNIQUE INDEX"
+	}
+	return fmt.Sprintf(`CREATE %s "%s" ON "%s" (%s);`, indexType, index.Name, tableName, joinQuotedNames(index.Columns, `"`))
+}
+
+func (d *sqliteDialect) dropIndex(_ string, index *IndexSchema) string {
+	return fmt.Sprintf(`DROP INDEX IF EXISTS "%s";`, index.Name)
+}
+
+func (d *sqliteDialect) addPrimaryKey(tableName string, _ []string) string {
+	d.addUnsupported(fmt.Sprintf(`changing primary key of table "%s"`, tableName))
+	return ""
+}
+
+func (d *sqliteDialect) dropPrimaryKey(tableName string) string {
+	d.addUnsupported(fmt.Sprintf(`changing primary key of table "%s"`, tableName))
+	return ""
+}
+
+func (d *sqliteDialect) commentTable(_ string, _ string) []string {
+	return nil // sqlite does not support comment
+}
+
+func (d *sqliteDialect) unsupported() error {
+	if len(d.unsupportedChanges) == 0 {
+		return nil
+	}
+	return fmt.Errorf("sqlite does not support %s, rebuild the tables manually", strings.Join(d.unsupportedChanges, ", "))
+}
@@ -15,7 +15,7 @@ type Option func(*options)
 
 type options struct {
 	DBDriver       string
-	FieldTypes     map[string]string // name:type
+	FieldTypes     map[string]string // name:type or table.name:type
 	Charset        string
 	Collation      string
 	JSONTag        bool
@@ -28,17 +28,34 @@ type options struct {
 	GormType       bool
 	ForceTableName bool
 	IsEmbed        bool // is gorm.Model embedded
+	IsSoftDelete   bool // soft delete records by column deleted_at
 	IsWebProto     bool // true: proto file include router path and swagger info, false: normal proto file without router and swagger
 	IsExtendedAPI  bool // true: extended api (9 api), false: basic api (5 api)
+	IsRelation     bool // true: generate association code from foreign keys
+	ForeignKeys    []*ForeignKey
+	VersionColumn  string // version column name of optimistic lock
 
 	IsCustomTemplate bool // true: custom extend template, false: sponge template
 }
 
 var defaultOptions = options{
-	DBDriver:   "mysql",
-	FieldTypes: map[string]string{},
-	NullStyle:  NullInSql,
-	Package:    "model",
+	DBDriver:      "mysql",
+	FieldTypes:    map[string]string{},
+	NullStyle:     NullInSql,
+	Package:       "model",
+	VersionColumn: "version",
+}
+
+// get the field type of the column in the table, the type keyed by table.name takes precedence over name
+func (o *options) getFieldType(tableName string, colName string) string {
+	if fieldType, ok := o.FieldTypes[tableFieldKey(tableName, colName)]; ok {
+		return fieldType
+	}
+	return o.FieldTypes[colName]
+}
+
+func tableFieldKey(tableName string, colName string) string {
+	return tableName + "." + colName
 }
 
 // WithDBDriver set db driver
@@ -137,6 +154,14 @@ func WithEmbed() Option {
 	}
 }
 
+// WithSoftDelete soft delete records by column deleted_at, the column is mapped to gorm.DeletedAt,
+// it is always enabled when gorm.Model is embedded.
+func WithSoftDelete() Option {
+	return func(o *options) {
+		o.IsSoftDelete = true
+	}
+}
+
 // WithWebProto set proto file type
 func WithWebProto() Option {
 	return func(o *options) {
@@ -151,6 +176,30 @@ func WithExtendedAPI() Option {
 	}
 }
 
+// WithRelation generate the association fields, dao code and nested protobuf messages based on foreign keys
+func WithRelation() Option {
+	return func(o *options) {
+		o.IsRelation = true
+	}
+}
+
+// WithForeignKeys set the foreign keys obtained from database, they are merged with the foreign keys in sql
+func WithForeignKeys(fks []*ForeignKey) Option {
+	return func(o *options) {
+		o.ForeignKeys = fks
+	}
+}
+
+// WithVersionColumn set the version column name of optimistic lock, default is version,
+// the update of record is compare-and-swap on this column if the table has it.
+func WithVersionColumn(colName string) Option {
+	return func(o *options) {
+		if colName != "" {
+			o.VersionColumn = colName
+		}
+	}
+}
+
 // WithCustomTemplate set custom template
 func WithCustomTemplate() Option {
 	return func(o *options) {
@@ -39,6 +39,10 @@ const (
 	CodeTypeCrudInfo = "crud_info"
 	// CodeTypeTableInfo table info json data
 	CodeTypeTableInfo = "table_info"
+	// CodeTypeDAORelation dao code for preloading the associated records
+	CodeTypeDAORelation = "dao_relation"
+	// CodeTypeWhitelist dao code of columns whitelist for query parameters
+	CodeTypeWhitelist = "whitelist"
 
 	// DBDriverMysql mysql driver
 	DBDriverMysql = "mysql"
@@ -80,6 +84,26 @@ func ParseSQL(sql string, options ...Option) (map[string]string, error) {
 	if err != nil {
 		return nil, err
 	}
+
+	tables := make([]*tableData, 0, len(stmts))
+	tableFields := make(map[string][]tmplField)
+	for _, stmt := range stmts {
+		if ct, ok := stmt.(*ast.CreateTableStmt); ok {
+			table, err2 := newTableData(ct, opt)
+			if err2 != nil {
+				return nil, err2
+			}
+			tables = append(tables, table)
+			tableFields[table.data.RawTableName] = table.data.Fields
+		}
+	}
+	if opt.IsRelation {
+		fks := mergeForeignKeys(parseForeignKeys(stmts), opt.ForeignKeys)
+		for _, table := range tables {
+			table.data.Relations = newRelations(table.data, fks, tableFields, opt)
+		}
+	}
+
 	modelStructCodes := make([]string, 0, len(stmts))
 	updateFieldsCodes := make([]string, 0, len(stmts))
 	handlerStructCodes := make([]string, 0, len(stmts))
@@ -90,24 +114,28 @@ func ParseSQL(sql string, options ...Option) (map[string]string, error) {
 	tableNames := make([]string, 0, len(stmts))
 	primaryKeysCodes := make([]string, 0, len(stmts))
 	tableInfoCodes := make([]string, 0, len(stmts))
-	for _, stmt := range stmts {
-		if ct, ok := stmt.(*ast.CreateTableStmt); ok {
-			code, err2 := makeCode(ct, opt)
-			if err2 != nil {
-				return nil, err2
-			}
-			modelStructCodes = append(modelStructCodes, code.modelStruct)
-			updateFieldsCodes = append(updateFieldsCodes, code.updateFields)
-			handlerStructCodes = append(handlerStructCodes, code.handlerStruct)
-			protoFileCodes = append(protoFileCodes, code.protoFile)
-			serviceStructCodes = append(serviceStructCodes, code.serviceStruct)
-			modelJSONCodes = append(modelJSONCodes, code.modelJSON)
-			tableNames = append(tableNames, toCamel(ct.Table.Name.String()))
-			primaryKeysCodes = append(primaryKeysCodes, code.crudInfo)
-			tableInfoCodes = append(tableInfoCodes, string(code.tableInfo))
-			for _, s := range code.importPaths {
-				importPath[s] = struct{}{}
-			}
+	relationDaoCodes := make([]string, 0, len(stmts))
+	whitelistCodes := make([]string, 0, len(stmts))
+	for _, table := range tables {
+		code, err2 := makeCode(table.data, table.importPath, opt)
+		if err2 != nil {
+			return nil, err2
+		}
+		modelStructCodes = append(modelStructCodes, code.modelStruct)
+		updateFieldsCodes = append(updateFieldsCodes, code.updateFields)
+		handlerStructCodes = append(handlerStructCodes, code.handlerStruct)
+		protoFileCodes = append(protoFileCodes, code.protoFile)
+		serviceStructCodes = append(serviceStructCodes, code.serviceStruct)
+		modelJSONCodes = append(modelJSONCodes, code.modelJSON)
+		tableNames = append(tableNames, toCamel(table.data.RawTableName))
+		primaryKeysCodes = append(primaryKeysCodes, code.crudInfo)
+		tableInfoCodes = append(tableInfoCodes, string(code.tableInfo))
+		whitelistCodes = append(whitelistCodes, code.whitelist)
+		if code.relationDao != "" {
+			relationDaoCodes = append(relationDaoCodes, code.relationDao)
+		}
+		for _, s := range code.importPaths {
+			importPath[s] = struct{}{}
 		}
 	}
 
@@ -137,6 +165,10 @@ func ParseSQL(sql string, options ...Option) (map[string]string, error) {
 		TableName:         strings.Join(tableNames, ", "),
 		CodeTypeCrudInfo:  strings.Join(primaryKeysCodes, "||||"),
 		CodeTypeTableInfo: strings.Join(tableInfoCodes, "||||"),
+		CodeTypeWhitelist: strings.Join(whitelistCodes, "\n"),
+	}
+	if len(relationDaoCodes) > 0 {
+		codesMap[CodeTypeDAORelation] = strings.Join(relationDaoCodes, "\n\n")
 	}
 
 	return codesMap, nil
@@ -154,12 +186,14 @@ type tmplData struct {
 	SubStructs      string // sub structs for model
 	ProtoSubStructs string // sub structs for protobuf
 	DBDriver        string
+	Relations       []tmplRelation // associations obtained from foreign keys
 
 	CrudInfo *CrudInfo
 }
 
 type tmplField struct {
 	IsPrimaryKey bool   // is primary key
+	IsIndexed    bool   // is the first column of unique key or index
 	ColName      string // table column name
 	Name         string // convert to camel case
 	GoType       string // convert to go type
@@ -168,7 +202,8 @@ type tmplField struct {
 	JSONName     string
 	DBDriver     string
 
-	rewriterField *rewriterField
+	primaryKeyIndex int // position in the composite primary key
+	rewriterField   *rewriterField
 }
 
 type rewriterField struct {
@@ -176,6 +211,30 @@ type rewriterField struct {
 	path   string
 }
 
+func (d tmplData) hasColumn(colName string) bool {
+	for _, field := range d.Fields {
+		if field.ColName == colName {
+			return true
+		}
+	}
+	return false
+}
+
+// getVersionField get the version column of optimistic lock, the column must be a non-null integer
+func (d tmplData) getVersionField(colName string) *tmplField {
+	for _, field := range d.Fields {
+		if field.ColName != colName || field.IsPrimaryKey {
+			continue
+		}
+		switch field.GoType {
+		case "int", "int32", "int64", "uint", "uint32", "uint64":
+			return &field
+		}
+		return nil
+	}
+	return nil
+}
+
 func (d tmplData) isCommonStyle(isEmbed bool) bool {
 	if d.DBDriver != DBDriverMongodb && !isEmbed && !d.CrudInfo.isIDPrimaryKey() {
 		return true
@@ -311,6 +370,17 @@ func getProtoFieldName(fields []tmplField) string {
 	return ""
 }
 
+// get the names of all primary key fields, there are multiple fields for composite primary key
+func getProtoFieldNames(fields []tmplField) []string {
+	var names []string
+	for _, field := range fields {
+		if field.IsPrimaryKey || field.ColName == "id" {
+			names = append(names, field.JSONName)
+		}
+	}
+	return names
+}
+
 const (
 	__mysqlModel__ = "__mysqlModel__" //nolint
 	__type__       = "__type__"       //nolint
@@ -359,10 +429,17 @@ type codeText struct {
 	serviceStruct string
 	crudInfo      string
 	tableInfo     []byte
+	relationDao   string
+	whitelist     string
+}
+
+type tableData struct {
+	data       tmplData
+	importPath []string
 }
 
 // nolint
-func makeCode(stmt *ast.CreateTableStmt, opt options) (*codeText, error) {
+func newTableData(stmt *ast.CreateTableStmt, opt options) (*tableData, error) {
 	importPath := make([]string, 0, 1)
 	data := tmplData{
 		TableNamePrefix: opt.TablePrefix,
@@ -401,12 +478,21 @@ func makeCode(stmt *ast.CreateTableStmt, opt options) (*codeText, error) {
 	}
 
 	isPrimaryKey := make(map[string]bool)
+	primaryKeyIndex := make(map[string]int)
+	isIndexed := make(map[string]bool)
 	for _, con := range stmt.Constraints {
 		if con.Tp == ast.ConstraintPrimaryKey {
-			isPrimaryKey[con.Keys[0].Column.String()] = true
+			for i, key := range con.Keys { // composite primary key
+				isPrimaryKey[key.Column.String()] = true
+				primaryKeyIndex[key.Column.String()] = i
+			}
 		}
-		if con.Tp == ast.ConstraintForeignKey {
-			// TODO: foreign key support
+		switch con.Tp {
+		case ast.ConstraintKey, ast.ConstraintIndex, ast.ConstraintUniq, ast.ConstraintUniqKey, ast.ConstraintUniqIndex:
+			// only the first column of index can be used alone
+			if len(con.Keys) > 0 && con.Keys[0].Column != nil {
+				isIndexed[con.Keys[0].Column.String()] = true
+			}
 		}
 	}
 
@@ -440,13 +526,17 @@ func makeCode(stmt *ast.CreateTableStmt, opt options) (*codeText, error) {
 			case DBDriverMysql, DBDriverTidb, DBDriverSqlite:
 				gormTag.WriteString(col.Tp.InfoSchemaStr())
 			case DBDriverPostgresql:
-				gormTag.WriteString(opt.FieldTypes[colName])
+				gormTag.WriteString(opt.getFieldType(data.RawTableName, colName))
 			}
 		}
 		if isPrimaryKey[colName] {
 			field.IsPrimaryKey = true
+			field.primaryKeyIndex = primaryKeyIndex[colName]
 			gormTag.WriteString(";primary_key")
 		}
+		if isIndexed[colName] {
+			field.IsIndexed = true
+		}
 		isNotNull := false
 		canNull := false
 		for _, o := range col.Options {
@@ -467,6 +557,7 @@ func makeCode(stmt *ast.CreateTableStmt, opt options) (*codeText, error) {
 				}
 			case ast.ColumnOptionUniqKey:
 				gormTag.WriteString(";unique")
+				field.IsIndexed = true
 			case ast.ColumnOptionNull:
 				//gormTag.WriteString(";NULL")
 				canNull = true
@@ -491,7 +582,7 @@ func makeCode(stmt *ast.CreateTableStmt, opt options) (*codeText, error) {
 				tags = append(tags, "json", jsonName)
 			}
 			field.Tag = makeTagStr(tags)
-			field.GoType = opt.FieldTypes[colName]
+			field.GoType = opt.getFieldType(data.RawTableName, colName)
 			if field.GoType == "time.Time" {
 				importPath = append(importPath, "time")
 			}
@@ -519,7 +610,7 @@ func makeCode(stmt *ast.CreateTableStmt, opt options) (*codeText, error) {
 			field.GoType = goType
 			field.rewriterField = rrField
 			if opt.DBDriver == DBDriverPostgresql {
-				if opt.FieldTypes[colName] == "bool" {
+				if opt.getFieldType(data.RawTableName, colName) == "bool" {
 					field.GoType = "bool" // rewritten type
 				}
 			}
@@ -541,7 +632,20 @@ func makeCode(stmt *ast.CreateTableStmt, opt options) (*codeText, error) {
 
 	data.CrudInfo = newCrudInfo(data)
 	data.CrudInfo.IsCommonType = data.isCommonStyle(opt.IsEmbed)
+	data.CrudInfo.IsSoftDelete = opt.IsEmbed || opt.DBDriver == DBDriverMongodb || (opt.IsSoftDelete && data.hasColumn(columnDeletedAt))
+	// the compare-and-swap update is not generated by the common templates, the version column is updated as usual
+	if opt.DBDriver != DBDriverMongodb && !data.CrudInfo.IsCommonType {
+		if field := data.getVersionField(opt.VersionColumn); field != nil {
+			data.CrudInfo.VersionColumnName = field.ColName
+			data.CrudInfo.VersionColumnNameCamel = field.Name
+		}
+	}
 
+	return &tableData{data: data, importPath: importPath}, nil
+}
+
+// nolint
+func makeCode(data tmplData, importPath []string, opt options) (*codeText, error) {
 	if opt.IsCustomTemplate {
 		tableInfo := newTableInfo(data)
 		return &codeText{tableInfo: tableInfo.getCode()}, nil
@@ -562,9 +666,17 @@ func makeCode(stmt *ast.CreateTableStmt, opt options) (*codeText, error) {
 		return nil, err
 	}
 
+	relationDaoCode, err := getRelationDaoCode(data, opt.IsEmbed)
+	if err != nil {
+		return nil, err
+	}
+
 	handlerStructCode := ""
 	serviceStructCode := ""
 	protoFileCode := ""
+	if data.CrudInfo.CheckCompositeKey() {
+		opt.IsExtendedAPI = false // the extended api is not supported for composite primary key
+	}
 	if data.isCommonStyle(opt.IsEmbed) {
 		handlerStructCode, err = getCommonHandlerStructCodes(data, opt.JSONNamedType)
 		if err != nil {
@@ -593,6 +705,11 @@ func makeCode(stmt *ast.CreateTableStmt, opt options) (*codeText, error) {
 		}
 	}
 
+	whitelistCode, err := getWhitelistCode(data)
+	if err != nil {
+		return nil, err
+	}
+
 	return &codeText{
 		importPaths:   importPaths,
 		modelStruct:   modelStructCode,
@@ -602,6 +719,8 @@ func makeCode(stmt *ast.CreateTableStmt, opt options) (*codeText, error) {
 		protoFile:     protoFileCode,
 		serviceStruct: serviceStructCode,
 		crudInfo:      data.CrudInfo.getCode(),
+		relationDao:   relationDaoCode,
+		whitelist:     whitelistCode,
 	}, nil
 }
 
@@ -644,12 +763,7 @@ func getModelStructCode(data tmplData, importPaths []string, isEmbed bool, jsonN
 		if isHaveTimeType {
 			newImportPaths = importPaths
 		} else {
-			for _, path := range importPaths {
-				if path == "time" { //nolint
-					continue
-				}
-				newImportPaths = append(newImportPaths, path)
-			}
+			newImportPaths = filterImportPath(importPaths, "time")
 		}
 		newImportPaths = append(newImportPaths, "github.com/go-dev-frame/sponge/pkg/sgorm")
 	} else {
@@ -662,12 +776,18 @@ func getModelStructCode(data tmplData, importPaths []string, isEmbed bool, jsonN
 				}
 
 			default:
+				// soft delete by gorm
+				if field.ColName == columnDeletedAt && data.CrudInfo.CheckSoftDelete() {
+					data.Fields[i].GoType = "gorm.DeletedAt"
+					importPaths = append(importPaths, "gorm.io/gorm")
+					continue
+				}
 				if strings.Contains(field.GoType, "time.Time") {
 					data.Fields[i].GoType = "*time.Time"
 					continue
 				}
 				// force conversion of ID field to uint64 type
-				if field.Name == "ID" {
+				if field.Name == "ID" && !data.CrudInfo.CheckCompositeKey() {
 					data.Fields[i].GoType = "uint64"
 					if data.isCommonStyle(isEmbed) {
 						data.Fields[i].GoType = data.CrudInfo.GoType
@@ -684,7 +804,20 @@ func getModelStructCode(data tmplData, importPaths []string, isEmbed bool, jsonN
 				}
 			}
 		}
-		newImportPaths = importPaths
+
+		// filter time package name if the time column is only deleted_at
+		isHaveTimeType := false
+		for _, field := range data.Fields {
+			if strings.Contains(field.GoType, "time.Time") {
+				isHaveTimeType = true
+				break
+			}
+		}
+		if isHaveTimeType {
+			newImportPaths = importPaths
+		} else {
+			newImportPaths = filterImportPath(importPaths, "time")
+		}
 	}
 
 	builder := strings.Builder{}
@@ -720,6 +853,17 @@ func getModelStructCode(data tmplData, importPaths []string, isEmbed bool, jsonN
 	return structCode, newImportPaths, nil
 }
 
+func filterImportPath(importPaths []string, filterPath string) []string {
+	var newImportPaths []string
+	for _, path := range importPaths {
+		if path == filterPath {
+			continue
+		}
+		newImportPaths = append(newImportPaths, path)
+	}
+	return newImportPaths
+}
+
 func getModelCode(data modelCodes) (string, error) {
 	builder := strings.Builder{}
 	err := modelTmpl.Execute(&builder, data)
@@ -745,6 +889,9 @@ func getUpdateFieldsCode(data tmplData, isEmbed bool) (string, error) {
 		if isIgnoreFields(field.ColName, falseColumns...) || field.ColName == columnID || field.ColName == _columnID {
 			continue
 		}
+		if data.CrudInfo.CheckOptimisticLock() && field.ColName == data.CrudInfo.VersionColumnName {
+			continue // the version column is increased by optimistic lock
+		}
 		switch field.DBDriver {
 		case DBDriverMysql, DBDriverTidb, DBDriverPostgresql:
 			if field.rewriterField != nil {
@@ -765,6 +912,67 @@ func getUpdateFieldsCode(data tmplData, isEmbed bool) (string, error) {
 	return buf.String(), nil
 }
 
+// the column names containing these words are regarded as sensitive columns
+var sensitiveColumnWords = []string{"password", "passwd", "pwd", "secret", "salt", "private_key"}
+
+func isSensitiveColumn(colName string) bool {
+	name := strings.ToLower(colName)
+	for _, word := range sensitiveColumnWords {
+		if strings.Contains(name, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// getWhitelistCode generate the whitelist of columns for query parameters, the sensitive columns
+// are excluded, if the table has indexes other than the primary key, only the indexed columns are
+// allowed to filter and sort.
+func getWhitelistCode(data tmplData) (string, error) {
+	var returnColumns, indexedColumns []string
+	hasIndex := false
+	for _, field := range data.Fields {
+		colName := field.ColName
+		if colName == columnDeletedAt || isSensitiveColumn(colName) {
+			continue
+		}
+		if colName == _columnID {
+			colName = columnID
+		}
+		returnColumns = append(returnColumns, colName)
+		isPrimaryKey := field.IsPrimaryKey || colName == columnID ||
+			(data.CrudInfo != nil && field.ColName == data.CrudInfo.PrimaryKeyColumnName)
+		if field.IsIndexed || isPrimaryKey {
+			indexedColumns = append(indexedColumns, colName)
+		}
+		if field.IsIndexed {
+			hasIndex = true
+		}
+	}
+
+	filterColumns := returnColumns
+	if hasIndex {
+		filterColumns = indexedColumns
+	}
+
+	builder := strings.Builder{}
+	err := whitelistTmpl.Execute(&builder, map[string]interface{}{
+		"TName":     data.TName,
+		"Filter":    filterColumns,
+		"Sort":      filterColumns,
+		"Return":    returnColumns,
+		"IsIndexed": hasIndex,
+	})
+	if err != nil {
+		return "", fmt.Errorf("whitelistTmpl.Execute error: %v", err)
+	}
+	code, err := format.Source([]byte(builder.String()))
+	if err != nil {
+		return "", fmt.Errorf("whitelistTmpl format.Source error: %v", err)
+	}
+	return string(code), nil
+}
+
 func getHandlerStructCodes(data tmplData, jsonNamedType int) (string, error) {
 	newFields := []tmplField{}
 	for _, field := range data.Fields {
@@ -850,6 +1058,7 @@ func getModelJSONCode(data tmplData) (string, error) {
 
 func getProtoFileCode(data tmplData, jsonNamedType int, isWebProto bool, isExtendedAPI bool) (string, error) {
 	data.Fields = goTypeToProto(data.Fields, jsonNamedType, false)
+	data.Relations = relationsToProto(data.Relations, jsonNamedType)
 
 	var err error
 	builder := strings.Builder{}
@@ -1,13 +1,19 @@
 package parser
 
 import (
+	"encoding/json"
 	"fmt"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/jinzhu/inflection"
 	"github.com/stretchr/testify/assert"
 	"github.com/zhufuyi/sqlparser/dependency/mysql"
 	"github.com/zhufuyi/sqlparser/dependency/types"
+	"github.com/zhufuyi/sqlparser/parser"
+
+	"github.com/go-dev-frame/sponge/pkg/sgorm/sqlite"
 )
 
 func TestParseSQL(t *testing.T) {
@@ -396,6 +402,44 @@ func TestConvertToSQLByPgFields(t *testing.T) {
 	t.Log(sql, tps)
 }
 
+func TestConvertToSQLByPgFieldsCompositeKey(t *testing.T) {
+	fields := []*PGField{
+		{Name: "code", Type: "varchar", Lengthvar: 36, Notnull: true, IsPrimaryKey: true, PkOrdinal: 2},
+		{Name: "tenant_id", Type: "bigint", Notnull: true, IsPrimaryKey: true, PkOrdinal: 1},
+		{Name: "name", Type: "text"},
+	}
+	sql, _ := ConvertToSQLByPgFields("tenant_user", fields)
+	assert.Contains(t, sql, "PRIMARY KEY (`tenant_id`, `code`)")
+
+	// no primary key, the column id is used
+	sql, _ = ConvertToSQLByPgFields("foobar", []*PGField{{Name: "name", Type: "text"}, {Name: "id", Type: "bigint"}})
+	assert.Contains(t, sql, "PRIMARY KEY (`id`)")
+	sql, _ = ConvertToSQLByPgFields("foobar", []*PGField{{Name: "name", Type: "text"}})
+	assert.NotContains(t, sql, "PRIMARY KEY")
+}
+
+func TestGetSqliteTableInfoCompositeKey(t *testing.T) {
+	dbFile := filepath.Join(t.TempDir(), "test.db")
+	db, err := sqlite.Init(dbFile)
+	assert.NoError(t, err)
+	err = db.Exec(`create table tenant_user (code text not null, tenant_id integer not null, name text,
+primary key (tenant_id, code));`).Error
+	assert.NoError(t, err)
+	_ = sqlite.Close(db)
+
+	sql, err := GetSqliteTableInfo(dbFile, "tenant_user")
+	assert.NoError(t, err)
+	assert.Contains(t, sql, "PRIMARY KEY (`tenant_id`, `code`)")
+
+	codes, err := ParseSQL(sql, WithDBDriver(DBDriverSqlite))
+	assert.NoError(t, err)
+	crudInfo := &CrudInfo{}
+	err = json.Unmarshal([]byte(codes[CodeTypeCrudInfo]), crudInfo)
+	assert.NoError(t, err)
+	assert.True(t, crudInfo.CheckCompositeKey())
+	assert.Equal(t, "TenantIDAndCode", crudInfo.ColumnNameCamel)
+}
+
 func Test_PGField_getMysqlType(t *testing.T) {
 	fields := []*PGField{
 		{Type: "smallint"},
@@ -673,3 +717,510 @@ func Test_customEndOfLetterToLower(t *testing.T) {
 		t.Log(customEndOfLetterToLower(name, inflection.Plural(name)))
 	}
 }
+
+func TestParseSQLWithRelation(t *testing.T) {
+	sql := `create table user (
+    id         bigint unsigned auto_increment,
+    name       varchar(50) not null comment 'username',
+    created_at datetime    null,
+    primary key (id)
+);
+create table user_profile (
+    id      bigint unsigned auto_increment,
+    user_id bigint unsigned not null,
+    bio     varchar(255)    null comment 'biography',
+    primary key (id),
+    unique key (user_id),
+    constraint fk_profile_user foreign key (user_id) references user (id)
+);
+create table user_order (
+    id      varchar(36)     not null,
+    user_id bigint unsigned not null references user (id),
+    amount  int             not null,
+    primary key (id)
+);
+create table category (
+    id        bigint unsigned auto_increment,
+    parent_id bigint unsigned null,
+    name      varchar(50)     not null,
+    primary key (id),
+    foreign key (parent_id) references category (id)
+);`
+
+	codes, err := ParseSQL(sql, WithJSONTag(1), WithNullStyle(NullDisable), WithWebProto(), WithRelation())
+	assert.NoError(t, err)
+	model := codes[CodeTypeModel]
+	assert.Contains(t, model, `UserProfile *UserProfile `+"`"+`gorm:"foreignKey:UserID;references:ID" json:"userProfile,omitempty"`)
+	assert.Contains(t, model, `UserOrders  []*UserOrder `+"`"+`gorm:"foreignKey:UserID;references:ID" json:"userOrders,omitempty"`)
+	assert.Contains(t, model, `User *User `+"`"+`gorm:"foreignKey:UserID;references:ID" json:"user,omitempty"`)
+	assert.Contains(t, model, `Parent   *Category`)
+	assert.Contains(t, model, `Children []*Category`)
+	assert.Contains(t, codes[CodeTypeProto], "repeated UserOrder userOrders = 5;")
+	assert.Contains(t, codes[CodeTypeProto], "message UserOrder {")
+	assert.Contains(t, codes[CodeTypeDAORelation], `Preload("UserProfile").Preload("UserOrders")`)
+	assert.Contains(t, codes[CodeTypeDAORelation], "GetByIDWithRelations(ctx context.Context, id string) (*model.UserOrder, error)")
+
+	// foreign keys are ignored by default
+	codes, err = ParseSQL(sql, WithJSONTag(1))
+	assert.NoError(t, err)
+	assert.NotContains(t, codes[CodeTypeModel], "foreignKey")
+	assert.Empty(t, codes[CodeTypeDAORelation])
+
+	// foreign keys obtained from db
+	fks := []*ForeignKey{{TableName: "order_item", ColumnName: "order_id", RefTableName: "user_order", RefColumnName: "id"}}
+	codes, err = ParseSQL(sql, WithJSONTag(0), WithRelation(), WithForeignKeys(fks))
+	assert.NoError(t, err)
+	assert.Contains(t, codes[CodeTypeModel], `OrderItems []*OrderItem `+"`"+`gorm:"foreignKey:OrderID;references:ID" json:"order_items,omitempty"`)
+}
+
+func TestParseSQLWithCompositeForeignKey(t *testing.T) {
+	sql := `create table tenant_user (
+    tenant_id bigint unsigned not null,
+    code      varchar(32)     not null,
+    primary key (tenant_id, code)
+);
+create table tenant_order (
+    id        bigint unsigned auto_increment,
+    tenant_id bigint unsigned not null,
+    user_code varchar(32)     not null,
+    primary key (id),
+    constraint fk_order_user foreign key (tenant_id, user_code) references tenant_user (tenant_id, code)
+);`
+
+	// composite foreign key is not supported, no relation is generated
+	stmts, err := parser.New().Parse(sql, "", "")
+	assert.NoError(t, err)
+	assert.Empty(t, parseForeignKeys(stmts))
+	codes, err := ParseSQL(sql, WithJSONTag(1), WithRelation())
+	assert.NoError(t, err)
+	assert.NotContains(t, codes[CodeTypeModel], "foreignKey")
+	assert.Empty(t, codes[CodeTypeDAORelation])
+
+	ddl := `CREATE TABLE tenant_user (
+    tenant_id bigint NOT NULL,
+    code      varchar(32) NOT NULL,
+    PRIMARY KEY (tenant_id, code)
+);
+CREATE TABLE tenant_order (
+    id        bigserial PRIMARY KEY,
+    tenant_id bigint NOT NULL,
+    user_code varchar(32) NOT NULL,
+    CONSTRAINT fk_order_user FOREIGN KEY (tenant_id, user_code) REFERENCES tenant_user (tenant_id, code)
+);`
+	sql, _, err = ConvertPostgresqlDDL(ddl)
+	assert.NoError(t, err)
+	assert.NotContains(t, sql, "FOREIGN KEY")
+
+	dbFile := filepath.Join(t.TempDir(), "test.db")
+	db, err := sqlite.Init(dbFile)
+	if err != nil {
+		t.Log(err)
+		return
+	}
+	err = db.Exec(`create table tenant_user (tenant_id integer not null, code text not null, primary key (tenant_id, code));
+create table tenant_order (id integer primary key autoincrement, tenant_id integer not null, user_code text not null,
+    foreign key (tenant_id, user_code) references tenant_user (tenant_id, code));`).Error
+	assert.NoError(t, err)
+	_ = sqlite.Close(db)
+	fks, err := GetSqliteForeignKeys(dbFile, "tenant_user")
+	assert.NoError(t, err)
+	assert.Empty(t, fks)
+}
+
+func TestParseSQLWithCompositeKey(t *testing.T) {
+	sql := `create table tenant_user (
+    tenant_id  bigint unsigned not null,
+    code       varchar(32)     not null,
+    name       varchar(50)     null,
+    primary key (tenant_id, code)
+);`
+
+	codes, err := ParseSQL(sql, WithJSONTag(1), WithWebProto(), WithExtendedAPI())
+	assert.NoError(t, err)
+
+	crudInfo := &CrudInfo{}
+	err = json.Unmarshal([]byte(codes[CodeTypeCrudInfo]), crudInfo)
+	assert.NoError(t, err)
+	assert.True(t, crudInfo.CheckCompositeKey())
+	assert.True(t, crudInfo.CheckCommonType())
+	assert.Equal(t, "TenantIDAndCode", crudInfo.ColumnNameCamel)
+	assert.Equal(t, "tenantID uint64, code string", crudInfo.GetKeyParams())
+	assert.Equal(t, "tenantID, code", crudInfo.GetKeyArgs())
+	assert.Equal(t, `"tenant_id = ? AND code = ?", tenantID, code`, crudInfo.GetKeyWhere())
+	assert.Equal(t, `"tenant_id = ? AND code = ?", table.TenantID, table.Code`, crudInfo.GetKeyFieldWhere("table"))
+	assert.Equal(t, `utils.Uint64ToStr(tenantID) + ":" + code`, crudInfo.GetKeyStr())
+	assert.Equal(t, "req.TenantID, req.Code", crudInfo.GetKeyProtoFieldArgs("req"))
+	assert.Equal(t, "/:tenantID/:code", crudInfo.GetKeyRoutePath())
+	assert.Equal(t, "{tenantID}/{code}", crudInfo.GetKeySwaggerPath())
+
+	proto := codes[CodeTypeProto]
+	assert.Contains(t, proto, `delete: "/api/v1/tenantUser/{tenantID}/{code}"`)
+	assert.Contains(t, proto, "rpc GetByTenantIDAndCode(GetTenantUserByTenantIDAndCodeRequest)")
+	assert.NotContains(t, proto, "DeleteByTenantIDAndCodes") // extended api is ignored
+	assert.Contains(t, codes[CodeTypeModel], `gorm:"column:code;primary_key"`)
+
+	// non-integer primary key
+	codes, err = ParseSQL("create table device (uuid varchar(36) not null, name varchar(50) null, primary key (uuid));", WithJSONTag(1))
+	assert.NoError(t, err)
+	crudInfo = &CrudInfo{}
+	err = json.Unmarshal([]byte(codes[CodeTypeCrudInfo]), crudInfo)
+	assert.NoError(t, err)
+	assert.False(t, crudInfo.CheckCompositeKey())
+	assert.True(t, crudInfo.CheckCommonType())
+	assert.Equal(t, "uuid string", crudInfo.GetKeyParams())
+	assert.Equal(t, "uuid", crudInfo.GetKeyStr())
+}
+
+func TestParseSQLWithWhitelist(t *testing.T) {
+	sql := `create table user (
+    id         bigint unsigned auto_increment,
+    name       varchar(50)  not null,
+    password   varchar(100) not null,
+    email      varchar(50)  not null unique,
+    age        int          not null,
+    created_at datetime     null,
+    deleted_at datetime     null,
+    primary key (id),
+    key idx_name_age (name, age)
+);
+create table device (
+    uuid       varchar(36) not null primary key,
+    api_secret varchar(64) not null,
+    status     int         not null
+);`
+
+	codes, err := ParseSQL(sql, WithJSONTag(1), WithEmbed())
+	assert.NoError(t, err)
+	code := codes[CodeTypeWhitelist]
+	assert.Contains(t, code, "var userWhitelist = &query.Whitelist{")
+	assert.Contains(t, code, `Filter: []string{"id", "name", "email"}`)
+	assert.Contains(t, code, `Return: []string{"id", "name", "email", "age", "created_at"}`)
+	assert.Contains(t, code, "var deviceWhitelist = &query.Whitelist{")
+	assert.Contains(t, code, `Filter: []string{"uuid", "status"}`)
+	assert.NotContains(t, code, "password")
+	assert.NotContains(t, code, "api_secret")
+}
+
+func TestParseSQLWithSoftDelete(t *testing.T) {
+	sql := `create table user (
+    id         bigint unsigned auto_increment,
+    name       varchar(50) not null,
+    created_at datetime    null,
+    updated_at datetime    null,
+    deleted_at datetime    null,
+    primary key (id)
+);`
+
+	// deleted_at is a normal time column if soft delete is not enabled
+	codes, err := ParseSQL(sql, WithJSONTag(1), WithExtendedAPI())
+	assert.NoError(t, err)
+	crudInfo := &CrudInfo{}
+	err = json.Unmarshal([]byte(codes[CodeTypeCrudInfo]), crudInfo)
+	assert.NoError(t, err)
+	assert.False(t, crudInfo.CheckSoftDelete())
+	assert.NotContains(t, codes[CodeTypeModel], "gorm.DeletedAt")
+	assert.NotContains(t, codes[CodeTypeProto], "RestoreByID")
+
+	codes, err = ParseSQL(sql, WithJSONTag(1), WithExtendedAPI(), WithSoftDelete())
+	assert.NoError(t, err)
+	crudInfo = &CrudInfo{}
+	err = json.Unmarshal([]byte(codes[CodeTypeCrudInfo]), crudInfo)
+	assert.NoError(t, err)
+	assert.True(t, crudInfo.CheckSoftDelete())
+	assert.Contains(t, codes[CodeTypeModel], "gorm.DeletedAt")
+	assert.Contains(t, codes[CodeTypeProto], "rpc RestoreByID")
+	assert.Contains(t, codes[CodeTypeProto], "rpc ListDeleted")
+	assert.Contains(t, codes[CodeTypeProto], "rpc PurgeByID")
+
+	codes, err = ParseSQL(sql, WithJSONTag(1), WithExtendedAPI(), WithSoftDelete(), WithWebProto())
+	assert.NoError(t, err)
+	assert.Contains(t, codes[CodeTypeProto], `put: "/api/v1/user/{id}/restore"`)
+
+	codes, err = ParseSQL(strings.ReplaceAll(sql, "deleted_at datetime    null,", ""), WithJSONTag(1), WithExtendedAPI(), WithSoftDelete())
+	assert.NoError(t, err)
+	crudInfo = &CrudInfo{}
+	err = json.Unmarshal([]byte(codes[CodeTypeCrudInfo]), crudInfo)
+	assert.NoError(t, err)
+	assert.False(t, crudInfo.CheckSoftDelete())
+	assert.NotContains(t, codes[CodeTypeModel], "DeletedAt")
+	assert.NotContains(t, codes[CodeTypeProto], "RestoreByID")
+
+	codes, err = ParseSQL(strings.ReplaceAll(sql, "deleted_at datetime    null,", ""), WithJSONTag(1), WithEmbed())
+	assert.NoError(t, err)
+	crudInfo = &CrudInfo{}
+	err = json.Unmarshal([]byte(codes[CodeTypeCrudInfo]), crudInfo)
+	assert.NoError(t, err)
+	assert.True(t, crudInfo.CheckSoftDelete())
+}
+
+func TestParseSQLWithOptimisticLock(t *testing.T) {
+	sql := `create table user (
+    id         bigint unsigned auto_increment,
+    name       varchar(50) not null,
+    version    bigint      not null default 0,
+    revision   int         not null default 0,
+    created_at datetime    null,
+    primary key (id)
+);`
+
+	codes, err := ParseSQL(sql, WithJSONTag(1))
+	assert.NoError(t, err)
+	crudInfo := &CrudInfo{}
+	err = json.Unmarshal([]byte(codes[CodeTypeCrudInfo]), crudInfo)
+	assert.NoError(t, err)
+	assert.True(t, crudInfo.CheckOptimisticLock())
+	assert.Equal(t, "version", crudInfo.VersionColumnName)
+	assert.Equal(t, "Version", crudInfo.VersionColumnNameCamel)
+	assert.NotContains(t, codes[CodeTypeDAO], `update["version"]`)
+	assert.Contains(t, codes[CodeTypeDAO], `update["revision"]`)
+
+	codes, err = ParseSQL(sql, WithJSONTag(1), WithVersionColumn("revision"))
+	assert.NoError(t, err)
+	crudInfo = &CrudInfo{}
+	err = json.Unmarshal([]byte(codes[CodeTypeCrudInfo]), crudInfo)
+	assert.NoError(t, err)
+	assert.Equal(t, "revision", crudInfo.VersionColumnName)
+	assert.Contains(t, codes[CodeTypeDAO], `update["version"]`)
+	assert.NotContains(t, codes[CodeTypeDAO], `update["revision"]`)
+
+	// the version column must be an integer
+	codes, err = ParseSQL(sql, WithJSONTag(1), WithVersionColumn("name"))
+	assert.NoError(t, err)
+	crudInfo = &CrudInfo{}
+	err = json.Unmarshal([]byte(codes[CodeTypeCrudInfo]), crudInfo)
+	assert.NoError(t, err)
+	assert.False(t, crudInfo.CheckOptimisticLock())
+
+	// the common templates do not update by compare-and-swap, the version column is updated as usual
+	codes, err = ParseSQL(strings.ReplaceAll(sql, "bigint unsigned auto_increment", "varchar(32) not null"), WithJSONTag(1))
+	assert.NoError(t, err)
+	crudInfo = &CrudInfo{}
+	err = json.Unmarshal([]byte(codes[CodeTypeCrudInfo]), crudInfo)
+	assert.NoError(t, err)
+	assert.True(t, crudInfo.CheckCommonType())
+	assert.False(t, crudInfo.CheckOptimisticLock())
+	assert.Contains(t, codes[CodeTypeDAO], `update["version"]`)
+
+	var nilInfo *CrudInfo
+	assert.False(t, nilInfo.CheckOptimisticLock())
+}
+
+func Test_mergeForeignKeys(t *testing.T) {
+	fk := &ForeignKey{TableName: "user_order", ColumnName: "user_id", RefTableName: "user", RefColumnName: "id"}
+	selfFk := &ForeignKey{TableName: "category", ColumnName: "parent_id", RefTableName: "category", RefColumnName: "id"}
+	fks := mergeForeignKeys([]*ForeignKey{fk, nil, selfFk}, []*ForeignKey{fk, {TableName: "foo"}})
+	assert.Equal(t, 2, len(fks))
+	assert.True(t, fks[1].IsSelfRefer)
+}
+
+func TestGetForeignKeys(t *testing.T) {
+	fks, err := GetMysqlForeignKeys("root:123456@(192.168.3.37:3306)/account", "user_order")
+	t.Log(err, fks)
+
+	dsn := "host=192.168.3.37 port=5432 user=root password=123456 dbname=account sslmode=disable"
+	fks, err = GetPostgresqlForeignKeys(dsn, "user_order")
+	t.Log(err, fks)
+
+	dbFile := filepath.Join(t.TempDir(), "test.db")
+	db, err := sqlite.Init(dbFile)
+	if err != nil {
+		t.Log(err)
+		return
+	}
+	err = db.Exec(`create table user (id integer primary key autoincrement, name text);
+create table user_profile (id integer primary key autoincrement, user_id integer not null unique references user(id));
+create table user_order (id integer primary key autoincrement, user_id integer not null references user(id));`).Error
+	assert.NoError(t, err)
+	_ = sqlite.Close(db)
+
+	fks, err = GetSqliteForeignKeys(dbFile, "user")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(fks))
+	for _, fk := range fks {
+		assert.Equal(t, fk.TableName == "user_profile", fk.IsUnique, fk.TableName)
+	}
+}
+
+func TestConvertPostgresqlDDL(t *testing.T) {
+	ddl := `-- postgresql ddl
+CREATE TABLE IF NOT EXISTS public."user" (
+    id         bigserial PRIMARY KEY,
+    name       character varying(50) NOT NULL DEFAULT ''::character varying,
+    tags       text[],
+    is_vip     boolean DEFAULT false,
+    created_at timestamp with time zone DEFAULT now(),
+    CONSTRAINT user_name_key UNIQUE (name)
+);
+COMMENT ON TABLE public."user" IS 'user table';
+COMMENT ON COLUMN public."user".name IS 'user''s name';
+
+CREATE TABLE user_role (
+    user_id bigint NOT NULL REFERENCES "user"(id),
+    role_id integer NOT NULL,
+    PRIMARY KEY (user_id, role_id)
+);
+CREATE FUNCTION set_time() RETURNS trigger AS $$ BEGIN NEW.created_at = now(); RETURN NEW; END; $$ LANGUAGE plpgsql;`
+
+	sql, fieldTypes, err := ConvertPostgresqlDDL(ddl)
+	assert.NoError(t, err)
+	assert.Contains(t, sql, "`id` bigint not null auto_increment")
+	assert.Contains(t, sql, "`name` varchar(50) not null default '' comment 'user''s name'")
+	assert.Contains(t, sql, "PRIMARY KEY (`user_id`, `role_id`)")
+	assert.Contains(t, sql, ") comment 'user table';")
+	assert.Equal(t, "varchar(50)", fieldTypes["user.name"])
+	assert.Equal(t, "timestamptz", fieldTypes["user.created_at"])
+
+	codes, err := ParseSQL(sql, WithDBDriver(DBDriverPostgresql), WithFieldTypes(fieldTypes), WithGormType())
+	assert.NoError(t, err)
+	assert.Contains(t, codes[CodeTypeModel], `gorm:"column:user_id;type:int8;primary_key"`)
+	assert.Contains(t, codes[CodeTypeModel], `gorm:"column:role_id;type:int4;primary_key"`)
+
+	_, _, err = ConvertPostgresqlDDL("create index idx_name on t (name);")
+	assert.Error(t, err)
+}
+
+func TestConvertPostgresqlDDLSameColumnName(t *testing.T) {
+	ddl := `CREATE TABLE "order" (
+    id      uuid PRIMARY KEY,
+    is_paid boolean NOT NULL DEFAULT false
+);
+CREATE TABLE item (
+    id      serial PRIMARY KEY,
+    is_paid smallint NOT NULL DEFAULT 0
+);`
+
+	sql, fieldTypes, err := ConvertPostgresqlDDL(ddl)
+	assert.NoError(t, err)
+	assert.Equal(t, "uuid", fieldTypes["order.id"])
+	assert.Equal(t, "bool", fieldTypes["order.is_paid"])
+	assert.Equal(t, "int4", fieldTypes["item.id"])
+	assert.Equal(t, "int2", fieldTypes["item.is_paid"])
+
+	codes, err := ParseSQL(sql, WithDBDriver(DBDriverPostgresql), WithFieldTypes(fieldTypes), WithGormType())
+	assert.NoError(t, err)
+	orderModel, itemModel := codes[CodeTypeModel], codes[CodeTypeModel]
+	if i := strings.Index(codes[CodeTypeModel], "type Item struct"); i > 0 {
+		orderModel, itemModel = codes[CodeTypeModel][:i], codes[CodeTypeModel][i:]
+	}
+	assert.Contains(t, orderModel, `gorm:"column:id;type:uuid;primary_key"`)
+	assert.Regexp(t, `IsPaid\s+bool\s+`, orderModel)
+	assert.Contains(t, itemModel, `gorm:"column:id;type:int4;primary_key`)
+	assert.NotRegexp(t, `IsPaid\s+bool\s+`, itemModel)
+}
+
+func TestConvertSqliteDDL(t *testing.T) {
+	ddl := `CREATE TABLE "order" (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    sn         VARCHAR(20) NOT NULL UNIQUE,
+    note,
+    amount     REAL DEFAULT 1.5,
+    created_at DATETIME DEFAULT (datetime('now')),
+    user_id    INTEGER REFERENCES user(id)
+);`
+
+	sql, err := ConvertSqliteDDL(ddl)
+	assert.NoError(t, err)
+	assert.Contains(t, sql, "`id` bigint not null auto_increment")
+	assert.Contains(t, sql, "`note` text null")
+	assert.Contains(t, sql, "`created_at` datetime null default CURRENT_TIMESTAMP")
+	assert.Contains(t, sql, "UNIQUE (`sn`)")
+	assert.Contains(t, sql, "FOREIGN KEY (`user_id`) REFERENCES `user` (`id`)")
+
+	codes, err := ParseSQL(sql, WithDBDriver(DBDriverSqlite))
+	assert.NoError(t, err)
+	assert.Contains(t, codes[CodeTypeModel], "Amount")
+}
+
+func TestDiffDDL(t *testing.T) {
+	oldSQL := "create table user (id bigint(20) unsigned not null auto_increment, name varchar(50) not null default '' comment 'name', age int default '0', email varchar(100), primary key (id), key idx_name (name), unique key uk_email (email)) comment 'user';"
+	newSQL := "create table user (id bigint unsigned auto_increment, name varchar(100) not null default '' comment 'user name', age int default 0, phone varchar(20) null comment 'phone', primary key (id), key idx_name (name, phone)) comment 'user table'; create table tag (id bigint unsigned auto_increment primary key, name varchar(20) not null unique);"
+
+	diff, err := DiffDDL(oldSQL, newSQL, DBDriverMysql)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"ALTER TABLE `user` DROP INDEX `idx_name`;",
+		"ALTER TABLE `user` DROP INDEX `uk_email`;",
+		"ALTER TABLE `user` ADD COLUMN `phone` varchar(20) NULL COMMENT 'phone' AFTER `age`;",
+		"ALTER TABLE `user` MODIFY COLUMN `name` varchar(100) NOT NULL DEFAULT '' COMMENT 'user name';",
+		"ALTER TABLE `user` DROP COLUMN `email`;",
+		"ALTER TABLE `user` ADD INDEX `idx_name` (`name`, `phone`);",
+		"ALTER TABLE `user` COMMENT='user table';",
+		"CREATE TABLE `tag` (\n    `id` bigint unsigned NOT NULL AUTO_INCREMENT,\n    `name` varchar(20) NOT NULL,\n    PRIMARY KEY (`id`),\n    UNIQUE KEY `name` (`name`)\n);",
+	}, diff.Up)
+	assert.Equal(t, "DROP TABLE IF EXISTS `tag`;", diff.Down[0])
+	assert.Equal(t, "ALTER TABLE `user` ADD INDEX `idx_name` (`name`);", diff.Down[len(diff.Down)-1])
+
+	diff, err = DiffDDL(oldSQL, oldSQL, DBDriverMysql)
+	assert.NoError(t, err)
+	assert.True(t, diff.IsEmpty())
+
+	oldPG := "create table users (id bigserial primary key, name varchar(50) not null, age integer default 0, email text unique); comment on column users.name is 'name';"
+	newPG := "create table users (id bigserial primary key, name varchar(100) not null, age int, phone varchar(20) not null default ''); comment on column users.name is 'user name';"
+	diff, err = DiffDDL(oldPG, newPG, DBDriverPostgresql)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		`ALTER TABLE "users" DROP CONSTRAINT "users_email_key";`,
+		`ALTER TABLE "users" ADD COLUMN "phone" varchar(20) NOT NULL DEFAULT '';`,
+		`ALTER TABLE "users" ALTER COLUMN "name" TYPE varchar(100) USING "name"::varchar(100);`,
+		`COMMENT ON COLUMN "users"."name" IS 'user name';`,
+		`ALTER TABLE "users" ALTER COLUMN "age" DROP DEFAULT;`,
+		`ALTER TABLE "users" DROP COLUMN "email";`,
+	}, diff.Up)
+	assert.Contains(t, diff.Down, `ALTER TABLE "users" ALTER COLUMN "age" SET DEFAULT 0;`)
+
+	diff, err = DiffDDL("", "create table users (id integer primary key autoincrement, name text not null unique);", DBDriverSqlite)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"CREATE TABLE \"users\" (\n    \"id\" integer PRIMARY KEY AUTOINCREMENT,\n    \"name\" text NOT NULL\n);",
+		`CREATE UNIQUE INDEX "users_name_key" ON "users" ("name");`,
+	}, diff.Up)
+	assert.Equal(t, []string{`DROP TABLE IF EXISTS "users";`}, diff.Down)
+
+	// sqlite does not support modifying column and primary key
+	_, err = DiffDDL("create table users (id integer primary key, name text);", "create table users (id integer primary key, name varchar(10));", DBDriverSqlite)
+	assert.EqualError(t, err, `sqlite does not support modifying column "name" of table "users", rebuild the tables manually`)
+	_, err = DiffDDL("create table users (id integer primary key, name text);", "create table users (id integer, name text primary key);", DBDriverSqlite)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `changing primary key of table "users"`)
+	diff, err = DiffDDL("create table users (id integer primary key, name text);", "create table users (id integer primary key, name text, age integer);", DBDriverSqlite)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{`ALTER TABLE "users" ADD COLUMN "age" integer;`}, diff.Up)
+
+	_, err = DiffDDL("", "create table t (id int);", "oracle")
+	assert.Error(t, err)
+	_, err = DiffDDL("create table", "create table t (id int);", DBDriverMysql)
+	assert.Error(t, err)
+}
+
+func TestGetTableSchema(t *testing.T) {
+	dbFile := filepath.Join(t.TempDir(), "test.db")
+	db, err := sqlite.Init(dbFile)
+	if err != nil {
+		t.Log(err)
+		return
+	}
+	err = db.Exec("create table users (id integer primary key autoincrement, name varchar(50) not null default 'foo', age integer)").Error
+	assert.NoError(t, err)
+	_ = sqlite.Close(db)
+
+	table, err := GetTableSchema(DBDriverSqlite, dbFile, "users")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"id"}, table.PrimaryKeys)
+	assert.Equal(t, &ColumnSchema{Name: "name", Type: "varchar(50)", NotNull: true, Default: "'foo'"}, table.Columns[1])
+
+	// the table does not exist
+	table, err = GetTableSchema(DBDriverSqlite, dbFile, "not_exist")
+	assert.NoError(t, err)
+	assert.Nil(t, table)
+
+	// the same as DDL
+	tables, err := ParseTableSchemas("create table users (id integer primary key autoincrement, name varchar(50) not null default 'foo', age integer);", DBDriverSqlite)
+	assert.NoError(t, err)
+	table, _ = GetTableSchema(DBDriverSqlite, dbFile, "users")
+	diff, err := DiffTableSchemas([]*TableSchema{table}, tables, DBDriverSqlite)
+	assert.NoError(t, err)
+	assert.True(t, diff.IsEmpty())
+
+	_, err = GetTableSchema("oracle", "", "user")
+	assert.Error(t, err)
+}
@@ -2,6 +2,7 @@ package parser
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"gorm.io/driver/postgres"
@@ -37,9 +38,12 @@ func ConvertToSQLByPgFields(tableName string, fields PGFields) (string, map[stri
 		fieldStr += fmt.Sprintf("    `%s` %s %s comment '%s',\n", field.Name, sqlType, notnullStr, field.Comment)
 	}
 
-	primaryField := fields.getPrimaryField()
-	if primaryField != nil {
-		fieldStr += fmt.Sprintf("    PRIMARY KEY (`%s`)\n", primaryField.Name)
+	if primaryFields := fields.getPrimaryFields(); len(primaryFields) > 0 {
+		names := make([]string, 0, len(primaryFields))
+		for _, field := range primaryFields {
+			names = append(names, "`"+field.Name+"`")
+		}
+		fieldStr += fmt.Sprintf("    PRIMARY KEY (%s)\n", strings.Join(names, ", "))
 	} else {
 		fieldStr = strings.TrimSuffix(fieldStr, ",\n")
 	}
@@ -56,6 +60,8 @@ type PGField struct {
 	Lengthvar    int    `gorm:"column:lengthvar;" json:"lengthvar"`
 	Notnull      bool   `gorm:"column:notnull;" json:"notnull"`
 	IsPrimaryKey bool   `gorm:"column:is_primary_key;" json:"is_primary_key"`
+	PkOrdinal    int    `gorm:"column:pk_ordinal;" json:"pk_ordinal"` // position in the primary key, starting from 1
+	Default      string `gorm:"column:default_value;" json:"default_value"`
 }
 
 // nolint
@@ -105,36 +111,25 @@ func (field *PGField) getMysqlType() string {
 
 type PGFields []*PGField
 
-func (fields PGFields) getPrimaryField() *PGField {
-	var f *PGField
+// the columns of primary key in the order of key, if the table has no primary key, the column id is used.
+func (fields PGFields) getPrimaryFields() []*PGField {
+	var pkFields []*PGField
 	for _, field := range fields {
-		if field.IsPrimaryKey || field.Name == "id" {
-			f = field
-			return f
+		if field.IsPrimaryKey {
+			pkFields = append(pkFields, field)
 		}
 	}
-	/*
-		// if no primary key, find the first xxx_id field
-		if f == nil {
-			for _, field := range fields {
-				if strings.HasSuffix(field.Name, "_id") {
-					f = field
-					f.IsPrimaryKey = true
-					return f
-				}
+	if len(pkFields) == 0 {
+		for _, field := range fields {
+			if field.Name == "id" {
+				return []*PGField{field}
 			}
 		}
+		return nil
+	}
 
-		// if no xxx_id field, find the first field
-		if f == nil {
-			for _, field := range fields {
-				f = field
-				f.IsPrimaryKey = true
-				return f
-			}
-		}
-	*/
-	return f
+	sort.SliceStable(pkFields, func(i, j int) bool { return pkFields[i].PkOrdinal < pkFields[j].PkOrdinal })
+	return pkFields
 }
 
 func getPostgresqlTableFields(db *gorm.DB, tableName string) (PGFields, error) {
@@ -148,14 +143,18 @@ func getPostgresqlTableFields(db *gorm.DB, tableName string) (PGFields, error) {
     CASE
         WHEN pk.constraint_type = 'PRIMARY KEY' THEN true
         ELSE false
-        END AS is_primary_key
+        END AS is_primary_key,
+    COALESCE(pk.ordinal_position, 0) AS pk_ordinal,
+    COALESCE(pg_get_expr(d.adbin, d.adrelid), '') AS default_value
 FROM pg_class c
          JOIN pg_attribute a ON a.attrelid = c.oid
          LEFT JOIN pg_description b ON a.attrelid = b.objoid AND a.attnum = b.objsubid
+         LEFT JOIN pg_attrdef d ON a.attrelid = d.adrelid AND a.attnum = d.adnum
          JOIN pg_type t ON a.atttypid = t.oid
          LEFT JOIN (
     SELECT
         kcu.column_name,
+        kcu.ordinal_position,
         con.constraint_type
     FROM information_schema.table_constraints con
              JOIN information_schema.key_column_usage kcu
@@ -165,6 +164,7 @@ FROM pg_class c
 ) AS pk ON a.attname = pk.column_name
 WHERE c.relname = '%s'
   AND a.attnum > 0
+  AND NOT a.attisdropped
 ORDER BY a.attnum;`, tableName, tableName)
 
 	var fields PGFields
@@ -0,0 +1,489 @@
+package parser
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/jinzhu/inflection"
+	"github.com/zhufuyi/sqlparser/ast"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/go-dev-frame/sponge/pkg/sgorm/sqlite"
+)
+
+// relation types between tables
+const (
+	RelationBelongsTo = "belongs_to"
+	RelationHasOne    = "has_one"
+	RelationHasMany   = "has_many"
+)
+
+// ForeignKey foreign key information, a child table column references a parent table column
+type ForeignKey struct {
+	TableName     string `json:"tableName" gorm:"column:table_name"`          // child table name, example: order
+	ColumnName    string `json:"columnName" gorm:"column:column_name"`        // child column name, example: user_id
+	RefTableName  string `json:"refTableName" gorm:"column:ref_table_name"`   // parent table name, example: user
+	RefColumnName string `json:"refColumnName" gorm:"column:ref_column_name"` // parent column name, example: id
+	IsUnique      bool   `json:"isUnique" gorm:"column:is_unique"`            // the child column is unique, parent has one child
+	IsSelfRefer   bool   `json:"isSelfRefer" gorm:"-"`                        // child table and parent table are the same
+	ConstraintKey string `json:"constraintKey" gorm:"column:constraint_key"`  // constraint name, may be empty
+}
+
+type tmplRelation struct {
+	Type      string // relation type, belongs_to, has_one, has_many
+	Name      string // association field name, example: User, Orders
+	ModelName string // model name of the associated table, example: User, Order
+	GoType    string // example: *User, []*Order
+	Tag       string // gorm and json tag
+	JSONName  string // json name of association field
+	Comment   string
+
+	// fields of the associated table, they are used to generate nested protobuf message,
+	// empty if the associated table is not in the same sql.
+	Fields []tmplField
+}
+
+// IsMany the association field is slice type
+func (r tmplRelation) IsMany() bool {
+	return r.Type == RelationHasMany
+}
+
+// ProtoType type name in protobuf
+func (r tmplRelation) ProtoType() string {
+	if r.IsMany() {
+		return "repeated " + r.ModelName
+	}
+	return r.ModelName
+}
+
+// AddOne counter
+func (r tmplRelation) AddOne(i int) int {
+	return i + 1
+}
+
+// RelationIndex field index of association in protobuf message, it follows the column fields
+func (d tmplData) RelationIndex(i int) int {
+	return len(d.Fields) + i + 1
+}
+
+// ProtoRelations associations that can be expressed as nested protobuf messages
+func (d tmplData) ProtoRelations() []tmplRelation {
+	var relations []tmplRelation
+	for _, r := range d.Relations {
+		if len(r.Fields) > 0 {
+			relations = append(relations, r)
+		}
+	}
+	return relations
+}
+
+// ProtoNestedMessages the associated tables that are defined as nested protobuf messages, each table only once
+func (d tmplData) ProtoNestedMessages() []tmplRelation {
+	var relations []tmplRelation
+	exists := make(map[string]struct{})
+	for _, r := range d.ProtoRelations() {
+		if _, ok := exists[r.ModelName]; ok {
+			continue
+		}
+		exists[r.ModelName] = struct{}{}
+		relations = append(relations, r)
+	}
+	return relations
+}
+
+func relationsToProto(relations []tmplRelation, jsonNamedType int) []tmplRelation {
+	var newRelations []tmplRelation
+	for _, r := range relations {
+		r.Fields = goTypeToProto(r.Fields, jsonNamedType, true)
+		newRelations = append(newRelations, r)
+	}
+	return newRelations
+}
+
+// parse the foreign keys defined in create table statements,
+// both table constraint "FOREIGN KEY (a) REFERENCES t(b)" and column option "a int REFERENCES t(b)" are supported.
+func parseForeignKeys(stmts []ast.StmtNode) []*ForeignKey {
+	var fks []*ForeignKey
+	for _, stmt := range stmts {
+		ct, ok := stmt.(*ast.CreateTableStmt)
+		if !ok {
+			continue
+		}
+		tableName := ct.Table.Name.String()
+		uniqueColumns := getUniqueColumns(ct)
+
+		for _, con := range ct.Constraints {
+			if con.Tp != ast.ConstraintForeignKey || con.Refer == nil || con.Refer.Table == nil {
+				continue
+			}
+			// composite foreign key is not supported, the association joined on a part of the key is wrong
+			if len(con.Keys) != 1 || len(con.Refer.IndexColNames) != 1 {
+				continue
+			}
+			colName := con.Keys[0].Column.String()
+			fks = append(fks, &ForeignKey{
+				TableName:     tableName,
+				ColumnName:    colName,
+				RefTableName:  con.Refer.Table.Name.String(),
+				RefColumnName: con.Refer.IndexColNames[0].Column.String(),
+				IsUnique:      uniqueColumns[colName],
+				ConstraintKey: con.Name,
+			})
+		}
+
+		for _, col := range ct.Cols {
+			for _, o := range col.Options {
+				if o.Tp != ast.ColumnOptionReference || o.Refer == nil || o.Refer.Table == nil {
+					continue
+				}
+				refColName := columnID
+				if len(o.Refer.IndexColNames) > 0 {
+					refColName = o.Refer.IndexColNames[0].Column.String()
+				}
+				colName := col.Name.Name.String()
+				fks = append(fks, &ForeignKey{
+					TableName:     tableName,
+					ColumnName:    colName,
+					RefTableName:  o.Refer.Table.Name.String(),
+					RefColumnName: refColName,
+					IsUnique:      uniqueColumns[colName],
+				})
+			}
+		}
+	}
+
+	return fks
+}
+
+// the columns that the value is unique in the table
+func getUniqueColumns(ct *ast.CreateTableStmt) map[string]bool {
+	columns := make(map[string]bool)
+	for _, con := range ct.Constraints {
+		switch con.Tp {
+		case ast.ConstraintPrimaryKey, ast.ConstraintUniq, ast.ConstraintUniqKey, ast.ConstraintUniqIndex:
+			if len(con.Keys) == 1 {
+				columns[con.Keys[0].Column.String()] = true
+			}
+		}
+	}
+	for _, col := range ct.Cols {
+		for _, o := range col.Options {
+			if o.Tp == ast.ColumnOptionUniqKey || o.Tp == ast.ColumnOptionPrimaryKey {
+				columns[col.Name.Name.String()] = true
+			}
+		}
+	}
+	return columns
+}
+
+// merge foreign keys and remove duplicates
+func mergeForeignKeys(fkGroups ...[]*ForeignKey) []*ForeignKey {
+	var fks []*ForeignKey
+	exists := make(map[string]struct{})
+	for _, group := range fkGroups {
+		for _, fk := range group {
+			if fk == nil || fk.TableName == "" || fk.RefTableName == "" {
+				continue
+			}
+			key := fk.TableName + "." + fk.ColumnName + "->" + fk.RefTableName + "." + fk.RefColumnName
+			if _, ok := exists[key]; ok {
+				continue
+			}
+			exists[key] = struct{}{}
+			fk.IsSelfRefer = fk.TableName == fk.RefTableName
+			fks = append(fks, fk)
+		}
+	}
+	return fks
+}
+
+func getModelName(rawTableName string, tablePrefix string) string {
+	if tablePrefix != "" && strings.HasPrefix(rawTableName, tablePrefix) {
+		return toCamel(rawTableName[len(tablePrefix):])
+	}
+	return toCamel(rawTableName)
+}
+
+func getGoFieldName(colName string, columnPrefix string) string {
+	if columnPrefix != "" && strings.HasPrefix(colName, columnPrefix) {
+		colName = colName[len(columnPrefix):]
+	}
+	return toCamel(colName)
+}
+
+func getRelationJSONName(name string, jsonNamedType int) string {
+	if jsonNamedType == 0 { // snake case
+		return customToSnake(name)
+	}
+	return customFirstLetterToLower(name) // camel case (default)
+}
+
+// newRelations make the association fields of the table, tableFields is fields of all tables in sql, key is raw table name
+func newRelations(data tmplData, fks []*ForeignKey, tableFields map[string][]tmplField, opt options) []tmplRelation {
+	if data.DBDriver == DBDriverMongodb || len(fks) == 0 {
+		return nil
+	}
+
+	var relations []tmplRelation
+	usedNames := make(map[string]struct{})
+	for _, field := range data.Fields {
+		usedNames[field.Name] = struct{}{}
+	}
+	uniqueName := func(name string, suffix string) string {
+		if _, ok := usedNames[name]; ok {
+			name += suffix
+		}
+		usedNames[name] = struct{}{}
+		return name
+	}
+
+	for _, fk := range fks {
+		// the table is child, belongs to parent
+		if fk.TableName == data.RawTableName {
+			modelName := getModelName(fk.RefTableName, opt.TablePrefix)
+			name := modelName
+			if strings.HasSuffix(fk.ColumnName, "_id") && len(fk.ColumnName) > 3 {
+				name = getGoFieldName(strings.TrimSuffix(fk.ColumnName, "_id"), opt.ColumnPrefix)
+			}
+			name = uniqueName(name, "Info")
+			relations = append(relations, tmplRelation{
+				Type:      RelationBelongsTo,
+				Name:      name,
+				ModelName: modelName,
+				GoType:    "*" + modelName,
+				Tag: fmt.Sprintf(`gorm:"foreignKey:%s;references:%s" json:"%s,omitempty"`,
+					getGoFieldName(fk.ColumnName, opt.ColumnPrefix), toCamel(fk.RefColumnName), getRelationJSONName(name, opt.JSONNamedType)),
+				JSONName: getRelationJSONName(name, opt.JSONNamedType),
+				Comment:  fmt.Sprintf("belongs to %s, foreign key %s", fk.RefTableName, fk.ColumnName),
+				Fields:   tableFields[fk.RefTableName],
+			})
+		}
+
+		// the table is parent, has one or many children
+		if fk.RefTableName == data.RawTableName {
+			modelName := getModelName(fk.TableName, opt.TablePrefix)
+			relation := tmplRelation{
+				Type:      RelationHasOne,
+				ModelName: modelName,
+				Fields:    tableFields[fk.TableName],
+			}
+			if fk.IsUnique {
+				relation.Name = modelName
+				relation.GoType = "*" + modelName
+			} else {
+				relation.Type = RelationHasMany
+				relation.Name = customEndOfLetterToLower(modelName, inflection.Plural(modelName))
+				relation.GoType = "[]*" + modelName
+			}
+			if fk.IsSelfRefer {
+				relation.Name = "Children"
+			}
+			relation.Name = uniqueName(relation.Name, "List")
+			relation.JSONName = getRelationJSONName(relation.Name, opt.JSONNamedType)
+			relation.Tag = fmt.Sprintf(`gorm:"foreignKey:%s;references:%s" json:"%s,omitempty"`,
+				getGoFieldName(fk.ColumnName, opt.ColumnPrefix), toCamel(fk.RefColumnName), relation.JSONName)
+			relation.Comment = fmt.Sprintf("%s %s, foreign key %s.%s",
+				strings.ReplaceAll(relation.Type, "_", " "), fk.TableName, fk.TableName, fk.ColumnName)
+			relations = append(relations, relation)
+		}
+	}
+
+	return relations
+}
+
+func getRelationDaoCode(data tmplData, isEmbed bool) (string, error) {
+	if len(data.Relations) == 0 {
+		return "", nil
+	}
+
+	// the standard primary key id is forced to uint64
+	if !data.isCommonStyle(isEmbed) && data.CrudInfo != nil {
+		info := *data.CrudInfo
+		info.GoType = "uint64"
+		data.CrudInfo = &info
+	}
+
+	buf := new(bytes.Buffer)
+	err := relationDaoTmpl.Execute(buf, data)
+	if err != nil {
+		return "", fmt.Errorf("relationDaoTmpl.Execute error: %v", err)
+	}
+	return buf.String(), nil
+}
+
+// GetMysqlForeignKeys get the foreign keys where the table is child or parent from mysql,
+// the composite foreign keys are not supported and ignored
+func GetMysqlForeignKeys(dsn string, tableName string) ([]*ForeignKey, error) {
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("GetMysqlForeignKeys error, %v", err)
+	}
+	defer db.Close() //nolint
+
+	rows, err := db.Query(`SELECT
+    k.TABLE_NAME, k.COLUMN_NAME, k.REFERENCED_TABLE_NAME, k.REFERENCED_COLUMN_NAME, k.CONSTRAINT_NAME,
+    EXISTS(SELECT 1 FROM information_schema.STATISTICS s
+        WHERE s.TABLE_SCHEMA = k.TABLE_SCHEMA AND s.TABLE_NAME = k.TABLE_NAME AND s.COLUMN_NAME = k.COLUMN_NAME
+        AND s.NON_UNIQUE = 0 AND s.SEQ_IN_INDEX = 1
+        AND NOT EXISTS(SELECT 1 FROM information_schema.STATISTICS s2
+            WHERE s2.TABLE_SCHEMA = s.TABLE_SCHEMA AND s2.TABLE_NAME = s.TABLE_NAME AND s2.INDEX_NAME = s.INDEX_NAME AND s2.SEQ_IN_INDEX > 1)
+    ) AS is_unique
+FROM information_schema.KEY_COLUMN_USAGE k
+WHERE k.TABLE_SCHEMA = DATABASE() AND k.REFERENCED_TABLE_NAME IS NOT NULL
+    AND (k.TABLE_NAME = ? OR k.REFERENCED_TABLE_NAME = ?)
+    AND NOT EXISTS(SELECT 1 FROM information_schema.KEY_COLUMN_USAGE k2
+        WHERE k2.CONSTRAINT_SCHEMA = k.CONSTRAINT_SCHEMA AND k2.TABLE_NAME = k.TABLE_NAME
+        AND k2.CONSTRAINT_NAME = k.CONSTRAINT_NAME AND k2.ORDINAL_POSITION > 1)
+ORDER BY k.TABLE_NAME, k.ORDINAL_POSITION`, tableName, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("query foreign keys error, %v", err)
+	}
+	defer rows.Close() //nolint
+
+	var fks []*ForeignKey
+	for rows.Next() {
+		fk := &ForeignKey{}
+		err = rows.Scan(&fk.TableName, &fk.ColumnName, &fk.RefTableName, &fk.RefColumnName, &fk.ConstraintKey, &fk.IsUnique)
+		if err != nil {
+			return nil, err
+		}
+		fks = append(fks, fk)
+	}
+
+	return mergeForeignKeys(fks), rows.Err()
+}
+
+// GetPostgresqlForeignKeys get the foreign keys where the table is child or parent from postgresql,
+// the composite foreign keys are not supported and ignored
+func GetPostgresqlForeignKeys(dsn string, tableName string) ([]*ForeignKey, error) {
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("GetPostgresqlForeignKeys error: %v", err)
+	}
+	defer closeDB(db)
+
+	query := `SELECT
+    cl.relname AS table_name,
+    a.attname AS column_name,
+    rcl.relname AS ref_table_name,
+    ra.attname AS ref_column_name,
+    con.conname AS constraint_key,
+    EXISTS(SELECT 1 FROM pg_index i
+        WHERE i.indrelid = con.conrelid AND i.indisunique AND i.indnatts = 1 AND i.indkey[0] = con.conkey[1]
+    ) AS is_unique
+FROM pg_constraint con
+         JOIN pg_class cl ON cl.oid = con.conrelid
+         JOIN pg_class rcl ON rcl.oid = con.confrelid
+         JOIN pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = con.conkey[1]
+         JOIN pg_attribute ra ON ra.attrelid = con.confrelid AND ra.attnum = con.confkey[1]
+WHERE con.contype = 'f'
+  AND array_length(con.conkey, 1) = 1
+  AND (cl.relname = ? OR rcl.relname = ?)
+ORDER BY cl.relname;`
+
+	var fks []*ForeignKey
+	err = db.Raw(query, tableName, tableName).Scan(&fks).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get foreign keys: %v", err)
+	}
+
+	return mergeForeignKeys(fks), nil
+}
+
+// GetSqliteForeignKeys get the foreign keys where the table is child or parent from sqlite,
+// the composite foreign keys are not supported and ignored
+func GetSqliteForeignKeys(dbFile string, tableName string) ([]*ForeignKey, error) {
+	db, err := sqlite.Init(dbFile)
+	if err != nil {
+		return nil, err
+	}
+	defer sqlite.Close(db) //nolint
+
+	var tables []string
+	err = db.Raw("SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%'").Scan(&tables).Error
+	if err != nil {
+		return nil, err
+	}
+
+	type sqliteForeignKey struct {
+		ID    int    `gorm:"column:id"` // the columns of a composite foreign key have the same id
+		Table string `gorm:"column:table"`
+		From  string `gorm:"column:from"`
+		To    string `gorm:"column:to"`
+	}
+
+	var fks []*ForeignKey
+	for _, table := range tables {
+		var sfks []sqliteForeignKey
+		err = db.Raw(fmt.Sprintf("PRAGMA foreign_key_list('%s')", table)).Scan(&sfks).Error
+		if err != nil {
+			return nil, err
+		}
+		columnCount := make(map[int]int)
+		for _, sfk := range sfks {
+			columnCount[sfk.ID]++
+		}
+		var uniqueColumns map[string]bool
+		for _, sfk := range sfks {
+			if table != tableName && sfk.Table != tableName {
+				continue
+			}
+			if columnCount[sfk.ID] > 1 { // composite foreign key
+				continue
+			}
+			if uniqueColumns == nil {
+				if uniqueColumns, err = getSqliteUniqueColumns(db, table); err != nil {
+					return nil, err
+				}
+			}
+			if sfk.To == "" {
+				sfk.To = columnID
+			}
+			fks = append(fks, &ForeignKey{
+				TableName:     table,
+				ColumnName:    sfk.From,
+				RefTableName:  sfk.Table,
+				RefColumnName: sfk.To,
+				IsUnique:      uniqueColumns[sfk.From],
+			})
+		}
+	}
+
+	return mergeForeignKeys(fks), nil
+}
+
+// getSqliteUniqueColumns get the columns that have a single-column unique index
+func getSqliteUniqueColumns(db *gorm.DB, table string) (map[string]bool, error) {
+	type sqliteIndex struct {
+		Name   string `gorm:"column:name"`
+		Unique bool   `gorm:"column:unique"`
+	}
+	type sqliteIndexColumn struct {
+		Name string `gorm:"column:name"`
+	}
+
+	var indexes []sqliteIndex
+	err := db.Raw(fmt.Sprintf("PRAGMA index_list('%s')", table)).Scan(&indexes).Error
+	if err != nil {
+		return nil, err
+	}
+
+	uniqueColumns := make(map[string]bool)
+	for _, index := range indexes {
+		if !index.Unique {
+			continue
+		}
+		var columns []sqliteIndexColumn
+		err = db.Raw(fmt.Sprintf("PRAGMA index_info('%s')", index.Name)).Scan(&columns).Error
+		if err != nil {
+			return nil, err
+		}
+		if len(columns) == 1 {
+			uniqueColumns[columns[0].Name] = true
+		}
+	}
+	return uniqueColumns, nil
+}
@@ -2,6 +2,7 @@ package parser
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/go-dev-frame/sponge/pkg/sgorm/sqlite"
@@ -60,36 +61,26 @@ func (field *SqliteField) getMysqlType() string {
 // SqliteFields sqlite fields
 type SqliteFields []*SqliteField
 
-func (fields SqliteFields) getPrimaryField() *SqliteField {
-	var f *SqliteField
+// the columns of primary key in the order of key, pk is the position in the primary key, 0 means not a key column,
+// if the table has no primary key, the column id is used.
+func (fields SqliteFields) getPrimaryFields() []*SqliteField {
+	var pkFields []*SqliteField
 	for _, field := range fields {
-		if field.Pk == 1 || field.Name == "id" {
-			f = field
-			return f
+		if field.Pk > 0 {
+			pkFields = append(pkFields, field)
 		}
 	}
-	/*
-		// if no primary key, find the first xxx_id field
-		if f == nil {
-			for _, field := range fields {
-				if strings.HasSuffix(field.Name, "_id") {
-					f = field
-					f.Pk = 1
-					return f
-				}
+	if len(pkFields) == 0 {
+		for _, field := range fields {
+			if field.Name == "id" {
+				return []*SqliteField{field}
 			}
 		}
+		return nil
+	}
 
-		// if no xxx_id field, find the first field
-		if f == nil {
-			for _, field := range fields {
-				f = field
-				f.Pk = 1
-				return f
-			}
-		}
-	*/
-	return f
+	sort.SliceStable(pkFields, func(i, j int) bool { return pkFields[i].Pk < pkFields[j].Pk })
+	return pkFields
 }
 
 func convertToSQLBySqliteFields(tableName string, fields SqliteFields) string {
@@ -106,9 +97,12 @@ func convertToSQLBySqliteFields(tableName string, fields SqliteFields) string {
 		fieldStr += fmt.Sprintf("    `%s` %s %s comment '%s',\n", field.Name, field.getMysqlType(), notnullStr, "")
 	}
 
-	primaryField := fields.getPrimaryField()
-	if primaryField != nil {
-		fieldStr += fmt.Sprintf("    PRIMARY KEY (`%s`)\n", primaryField.Name)
+	if primaryFields := fields.getPrimaryFields(); len(primaryFields) > 0 {
+		names := make([]string, 0, len(primaryFields))
+		for _, field := range primaryFields {
+			names = append(names, "`"+field.Name+"`")
+		}
+		fieldStr += fmt.Sprintf("    PRIMARY KEY (%s)\n", strings.Join(names, ", "))
 	} else {
 		fieldStr = strings.TrimSuffix(fieldStr, ",\n")
 	}
@@ -17,6 +17,11 @@ type {{.TableName}} struct {
 {{- range .Fields}}
 	{{.Name}} {{.GoType}} {{if .Tag}}` + "`{{.Tag}}`" + `{{end}}{{if .Comment}} // {{.Comment}}{{end}}
 {{- end}}
+{{- if .Relations}}
+{{range .Relations}}
+	{{.Name}} {{.GoType}} ` + "`{{.Tag}}`" + ` // {{.Comment}}
+{{- end}}
+{{- end}}
 }
 {{if .NameFunc}}
 // TableName table name
@@ -121,6 +126,20 @@ service {{.TName}} {
 
   // list {{.TName}} by last id
   rpc ListByLastID(List{{.TableName}}ByLastIDRequest) returns (List{{.TableName}}ByLastIDReply) {}
+
+  // list {{.TName}} by cursor
+  rpc ListByCursor(List{{.TableName}}ByCursorRequest) returns (List{{.TableName}}ByCursorReply) {}
+{{- if .CrudInfo.CheckSoftDelete}}
+
+  // restore soft deleted {{.TName}} by id
+  rpc RestoreByID(Restore{{.TableName}}ByIDRequest) returns (Restore{{.TableName}}ByIDReply) {}
+
+  // list of soft deleted {{.TName}} by query parameters
+  rpc ListDeleted(ListDeleted{{.TableName}}Request) returns (ListDeleted{{.TableName}}Reply) {}
+
+  // permanently delete {{.TName}} by id, including soft deleted record
+  rpc PurgeByID(Purge{{.TableName}}ByIDRequest) returns (Purge{{.TableName}}ByIDReply) {}
+{{- end}}
 }
 
 
@@ -205,6 +224,46 @@ message List{{.TableName}}ByLastIDRequest {
 message List{{.TableName}}ByLastIDReply {
   repeated {{.TableName}} {{.TName}}s = 1;
 }
+
+message List{{.TableName}}ByCursorRequest {
+  string cursor = 1; // cursor of the adjacent page returned by the previous query, empty means the first page
+  uint32 limit = 2 [(validate.rules).uint32.gt = 0]; // limit size per page
+  string sort = 3; // sort by column name of table, default is -id, the - sign indicates descending order.
+  repeated api.types.Column columns = 4; // query conditions
+}
+
+message List{{.TableName}}ByCursorReply {
+  repeated {{.TableName}} {{.TName}}s = 1;
+  string nextCursor = 2; // cursor of the next page, empty means there is no next page
+  string prevCursor = 3; // cursor of the previous page, empty means there is no previous page
+}
+{{- if .CrudInfo.CheckSoftDelete}}
+
+message Restore{{.TableName}}ByIDRequest {
+  // deleteTableByIDRequestFieldCode
+}
+
+message Restore{{.TableName}}ByIDReply {
+
+}
+
+message ListDeleted{{.TableName}}Request {
+  api.types.Params params = 1;
+}
+
+message ListDeleted{{.TableName}}Reply {
+  int64 total = 1;
+  repeated {{.TableName}} {{.TName}}s = 2;
+}
+
+message Purge{{.TableName}}ByIDRequest {
+  // deleteTableByIDRequestFieldCode
+}
+
+message Purge{{.TableName}}ByIDReply {
+
+}
+{{- end}}
 `
 
 	protoFileSimpleTmpl    *template.Template
@@ -406,6 +465,39 @@ service {{.TName}} {
       get: "/api/v1/{{.TName}}/list"
     };
   }
+
+  // list {{.TName}} by cursor
+  rpc ListByCursor(List{{.TableName}}ByCursorRequest) returns (List{{.TableName}}ByCursorReply) {
+    option (google.api.http) = {
+      post: "/api/v1/{{.TName}}/list/cursor"
+      body: "*"
+    };
+  }
+{{- if .CrudInfo.CheckSoftDelete}}
+
+  // restore soft deleted {{.TName}} by id
+  rpc RestoreByID(Restore{{.TableName}}ByIDRequest) returns (Restore{{.TableName}}ByIDReply) {
+    option (google.api.http) = {
+      put: "/api/v1/{{.TName}}/{id}/restore"
+      body: "*"
+    };
+  }
+
+  // list of soft deleted {{.TName}} by query parameters
+  rpc ListDeleted(ListDeleted{{.TableName}}Request) returns (ListDeleted{{.TableName}}Reply) {
+    option (google.api.http) = {
+      post: "/api/v1/{{.TName}}/list/deleted"
+      body: "*"
+    };
+  }
+
+  // permanently delete {{.TName}} by id, including soft deleted record
+  rpc PurgeByID(Purge{{.TableName}}ByIDRequest) returns (Purge{{.TableName}}ByIDReply) {
+    option (google.api.http) = {
+      delete: "/api/v1/{{.TName}}/{id}/purge"
+    };
+  }
+{{- end}}
 }
 
 
@@ -503,6 +595,46 @@ message List{{.TableName}}ByLastIDRequest {
 message List{{.TableName}}ByLastIDReply {
   repeated {{.TableName}} {{.TName}}s = 1;
 }
+
+message List{{.TableName}}ByCursorRequest {
+  string cursor = 1; // cursor of the adjacent page returned by the previous query, empty means the first page
+  uint32 limit = 2 [(validate.rules).uint32.gt = 0]; // limit size per page
+  string sort = 3; // sort by column name of table, default is -id, the - sign indicates descending order.
+  repeated api.types.Column columns = 4; // query conditions
+}
+
+message List{{.TableName}}ByCursorReply {
+  repeated {{.TableName}} {{.TName}}s = 1;
+  string nextCursor = 2; // cursor of the next page, empty means there is no next page
+  string prevCursor = 3; // cursor of the previous page, empty means there is no previous page
+}
+{{- if .CrudInfo.CheckSoftDelete}}
+
+message Restore{{.TableName}}ByIDRequest {
+  // deleteTableByIDRequestFieldCode
+}
+
+message Restore{{.TableName}}ByIDReply {
+
+}
+
+message ListDeleted{{.TableName}}Request {
+  api.types.Params params = 1;
+}
+
+message ListDeleted{{.TableName}}Reply {
+  int64 total = 1;
+  repeated {{.TableName}} {{.TName}}s = 2;
+}
+
+message Purge{{.TableName}}ByIDRequest {
+  // deleteTableByIDRequestFieldCode
+}
+
+message Purge{{.TableName}}ByIDReply {
+
+}
+{{- end}}
 `
 
 	protoFileForSimpleWebTmpl    *template.Template
@@ -680,6 +812,17 @@ message List{{.TableName}}Reply {
 {{- range $i, $v := .Fields}}
 	{{$v.GoType}} {{$v.JSONName}} = {{$v.AddOne $i}}; {{if $v.Comment}} // {{$v.Comment}}{{end}}
 {{- end}}
+{{- range $i, $r := .ProtoRelations}}
+	{{$r.ProtoType}} {{$r.JSONName}} = {{$.RelationIndex $i}}; // {{$r.Comment}}
+{{- end}}
+{{- range .ProtoNestedMessages}}
+
+	message {{.ModelName}} {
+	{{- range $i, $v := .Fields}}
+		{{$v.GoType}} {{$v.JSONName}} = {{$v.AddOne $i}}; {{if $v.Comment}} // {{$v.Comment}}{{end}}
+	{{- end}}
+	}
+{{- end}}
 }`
 
 	serviceStructTmpl    *template.Template
@@ -719,6 +862,79 @@ message List{{.TableName}}Reply {
 				}
 				return cli.UpdateByID(ctx, req)`
 
+	relationDaoTmpl    *template.Template
+	relationDaoTmplRaw = `var _ {{.TableName}}RelationDao = (*{{.TName}}Dao)(nil)
+
+// {{.TableName}}RelationDao defining the dao interface for querying {{.TName}} with the associated records
+type {{.TableName}}RelationDao interface {
+	GetBy{{.CrudInfo.ColumnNameCamel}}WithRelations(ctx context.Context, {{.CrudInfo.GetKeyParams}}) (*model.{{.TableName}}, error)
+	GetByColumnsWithRelations(ctx context.Context, params *query.Params) ([]*model.{{.TableName}}, int64, error)
+}
+
+// New{{.TableName}}RelationDao creating the relation dao interface, the associated records are not cached
+func New{{.TableName}}RelationDao(db *gorm.DB) {{.TableName}}RelationDao {
+	return &{{.TName}}Dao{db: db}
+}
+
+// preload the associated records:{{range .Relations}} {{.Name}}{{end}}
+func (d *{{.TName}}Dao) preloadRelations(db *gorm.DB) *gorm.DB {
+	return db{{range .Relations}}.Preload("{{.Name}}"){{end}}
+}
+
+// GetBy{{.CrudInfo.ColumnNameCamel}}WithRelations get a record by {{.CrudInfo.ColumnNameCamelFCL}}, and preload the associated records
+func (d *{{.TName}}Dao) GetBy{{.CrudInfo.ColumnNameCamel}}WithRelations(ctx context.Context, {{.CrudInfo.GetKeyParams}}) (*model.{{.TableName}}, error) {
+	record := &model.{{.TableName}}{}
+	err := d.preloadRelations(d.db.WithContext(ctx)).Where({{.CrudInfo.GetKeyWhere}}).First(record).Error
+	return record, err
+}
+
+// GetByColumnsWithRelations get paging records by column information, and preload the associated records
+func (d *{{.TName}}Dao) GetByColumnsWithRelations(ctx context.Context, params *query.Params) ([]*model.{{.TableName}}, int64, error) {
+	queryStr, args, err := params.ConvertToGormConditions(query.WithWhitelist({{.TName}}Whitelist))
+	if err != nil {
+		return nil, 0, errors.New("query params error: " + err.Error())
+	}
+	fields, err := params.ConvertToFields(query.WithWhitelist({{.TName}}Whitelist))
+	if err != nil {
+		return nil, 0, errors.New("query params error: " + err.Error())
+	}
+
+	var total int64
+	if params.Sort != "ignore count" { // determine if count is required
+		err = d.db.WithContext(ctx).Model(&model.{{.TableName}}{}).Where(queryStr, args...).Count(&total).Error
+		if err != nil {
+			return nil, 0, err
+		}
+		if total == 0 {
+			return nil, total, nil
+		}
+	}
+
+	records := []*model.{{.TableName}}{}
+	order, limit, offset := params.ConvertToPage()
+	db := d.preloadRelations(d.db.WithContext(ctx))
+	if len(fields) > 0 {
+		db = db.Select(fields)
+	}
+	err = db.Order(order).Limit(limit).Offset(offset).Where(queryStr, args...).Find(&records).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return records, total, err
+}
+`
+
+	whitelistTmpl    *template.Template
+	whitelistTmplRaw = `// {{.TName}}Whitelist columns allowed to be used by the query parameters of {{.TName}},
+// the sensitive columns are excluded, modify the columns as needed.
+var {{.TName}}Whitelist = &query.Whitelist{
+	Filter: []string{ {{- range $i, $v := .Filter}}{{if $i}}, {{end}}"{{$v}}"{{end -}} }, // columns allowed in query conditions{{if .IsIndexed}}, only indexed columns{{end}}
+	Sort:   []string{ {{- range $i, $v := .Sort}}{{if $i}}, {{end}}"{{$v}}"{{end -}} }, // columns allowed in sort{{if .IsIndexed}}, only indexed columns{{end}}
+	Return: []string{ {{- range $i, $v := .Return}}{{if $i}}, {{end}}"{{$v}}"{{end -}} }, // columns allowed to be returned
+}
+`
+
 	tmplParseOnce sync.Once
 )
 
@@ -794,6 +1010,14 @@ func initTemplate() {
 		if err != nil {
 			errSum = errors.Wrap(errSum, "serviceStructTmplRaw:"+err.Error())
 		}
+		relationDaoTmpl, err = template.New("relationDao").Parse(relationDaoTmplRaw)
+		if err != nil {
+			errSum = errors.Wrap(errSum, "relationDaoTmplRaw:"+err.Error())
+		}
+		whitelistTmpl, err = template.New("whitelist").Parse(whitelistTmplRaw)
+		if err != nil {
+			errSum = errors.Wrap(errSum, "whitelistTmplRaw:"+err.Error())
+		}
 
 		if errSum != nil {
 			panic(errSum)
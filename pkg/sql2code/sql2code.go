@@ -25,11 +25,14 @@ type Args struct {
 	DBTable    string            // table name
 	fieldTypes map[string]string // field name:type
 
+	foreignKeys []*parser.ForeignKey // foreign keys obtained from db
+
 	Package        string // specify the package name (only valid for model types)
 	GormType       bool   // whether to display the gorm type name (only valid for model type codes)
 	JSONTag        bool   // does it include a json tag
 	JSONNamedType  int    // json field naming type, 0: snake case such as my_field_name, 1: camel sase, such as myFieldName
 	IsEmbed        bool   // is gorm.Model embedded
+	IsSoftDelete   bool   // soft delete records by column deleted_at, it is always true if gorm.Model is embedded
 	IsWebProto     bool   // proto file type, true: include router path and swagger info, false: normal proto file without router and swagger
 	CodeType       string // specify the different types of code to be generated, namely model (default), json, dao, handler, proto
 	ForceTableName bool
@@ -39,7 +42,9 @@ type Args struct {
 	ColumnPrefix   string
 	NoNullType     bool
 	NullStyle      string
-	IsExtendedAPI  bool // true: generate extended api (9 api), false: generate basic api (5 api)
+	IsExtendedAPI  bool   // true: generate extended api (9 api), false: generate basic api (5 api)
+	IsRelation     bool   // whether to generate association code based on foreign keys
+	VersionColumn  string // version column name of optimistic lock, default is version
 
 	IsCustomTemplate bool // whether to use custom template, default is false
 }
@@ -59,7 +64,7 @@ func (a *Args) checkValid() error {
 
 	if a.DBDriver == "" {
 		a.DBDriver = parser.DBDriverMysql
-	} else if a.DBDriver == parser.DBDriverSqlite {
+	} else if a.DBDriver == parser.DBDriverSqlite && a.SQL == "" && a.DDLFile == "" {
 		if !gofile.IsExists(a.DBDsn) {
 			return fmt.Errorf("sqlite db file %s not found in local host", a.DBDsn)
 		}
@@ -78,14 +83,22 @@ func getSQL(args *Args) (string, map[string]string, error) {
 	sql := ""
 	dbDriverName := strings.ToLower(args.DBDriver)
 	if args.DDLFile != "" {
-		if dbDriverName != parser.DBDriverMysql {
-			return sql, nil, fmt.Errorf("not support driver %s for parsing the sql file, only mysql is supported", args.DBDriver)
-		}
 		b, err := os.ReadFile(args.DDLFile)
 		if err != nil {
 			return sql, nil, fmt.Errorf("read %s failed, %s", args.DDLFile, err)
 		}
-		return string(b), nil, nil
+
+		switch dbDriverName {
+		case parser.DBDriverMysql, parser.DBDriverTidb:
+			return string(b), nil, nil
+		case parser.DBDriverPostgresql:
+			return parser.ConvertPostgresqlDDL(string(b))
+		case parser.DBDriverSqlite:
+			sqlStr, err := parser.ConvertSqliteDDL(string(b))
+			return sqlStr, nil, err
+		default:
+			return sql, nil, fmt.Errorf("not support driver %s for parsing the sql file, only mysql, tidb, postgresql and sqlite are supported", args.DBDriver)
+		}
 	} else if args.DBDsn != "" {
 		if args.DBTable == "" {
 			return sql, nil, errors.New("miss database table")
@@ -123,6 +136,24 @@ func getSQL(args *Args) (string, map[string]string, error) {
 	return sql, nil, errors.New("no SQL input(-sql|-f|-db-dsn)")
 }
 
+// get the foreign keys related to the table from db, the table may be child or parent
+func getForeignKeys(args *Args) ([]*parser.ForeignKey, error) {
+	if args.SQL != "" || args.DDLFile != "" || args.DBDsn == "" {
+		return nil, nil // foreign keys are parsed from sql
+	}
+
+	switch strings.ToLower(args.DBDriver) {
+	case parser.DBDriverMysql, parser.DBDriverTidb:
+		return parser.GetMysqlForeignKeys(utils.AdaptiveMysqlDsn(args.DBDsn), args.DBTable)
+	case parser.DBDriverPostgresql:
+		return parser.GetPostgresqlForeignKeys(utils.AdaptivePostgresqlDsn(args.DBDsn), args.DBTable)
+	case parser.DBDriverSqlite:
+		return parser.GetSqliteForeignKeys(args.DBDsn, args.DBTable)
+	}
+
+	return nil, nil
+}
+
 func setOptions(args *Args) []parser.Option {
 	var opts []parser.Option
 
@@ -154,6 +185,9 @@ func setOptions(args *Args) []parser.Option {
 	if args.IsEmbed {
 		opts = append(opts, parser.WithEmbed())
 	}
+	if args.IsSoftDelete {
+		opts = append(opts, parser.WithSoftDelete())
+	}
 	if args.IsWebProto {
 		opts = append(opts, parser.WithWebProto())
 	}
@@ -186,6 +220,12 @@ func setOptions(args *Args) []parser.Option {
 	if args.IsCustomTemplate {
 		opts = append(opts, parser.WithCustomTemplate())
 	}
+	if args.IsRelation {
+		opts = append(opts, parser.WithRelation(), parser.WithForeignKeys(args.foreignKeys))
+	}
+	if args.VersionColumn != "" {
+		opts = append(opts, parser.WithVersionColumn(args.VersionColumn))
+	}
 
 	return opts
 }
@@ -224,6 +264,12 @@ func Generate(args *Args) (map[string]string, error) {
 	if sql == "" {
 		return nil, fmt.Errorf("get sql from %s error, maybe the table %s doesn't exist", args.DBDriver, args.DBTable)
 	}
+	if args.IsRelation {
+		args.foreignKeys, err = getForeignKeys(args)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	opt := setOptions(args)
 
@@ -48,6 +48,22 @@ func TestGenerateOne(t *testing.T) {
 			}},
 			wantErr: false,
 		},
+		{
+			name: "postgresql sql from file",
+			args: args{args: &Args{
+				DDLFile:  "test_postgresql.sql",
+				DBDriver: "postgresql",
+			}},
+			wantErr: false,
+		},
+		{
+			name: "sqlite sql from file",
+			args: args{args: &Args{
+				DDLFile:  "test_sqlite.sql",
+				DBDriver: "sqlite",
+			}},
+			wantErr: false,
+		},
 		//{
 		//	name: "sql from db",
 		//	args: args{args: &Args{
@@ -146,6 +162,10 @@ func TestGenerateError(t *testing.T) {
 	_, err = Generate(a)
 	assert.Error(t, err)
 
+	a = &Args{DDLFile: "test.sql", DBDriver: "mongodb"}
+	_, err = Generate(a)
+	assert.Error(t, err)
+
 	a = &Args{DBDsn: "root:123456@(127.0.0.1:3306)/test"}
 	_, err = Generate(a)
 	assert.Error(t, err)
@@ -0,0 +1,22 @@
+create table "user"
+(
+    id         bigserial primary key,
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone,
+    deleted_at timestamp with time zone,
+    name       varchar(50)  not null,
+    password   varchar(100) not null,
+    email      varchar(50)  not null,
+    phone      bigint       not null,
+    age        smallint     not null,
+    gender     smallint     not null default 3,
+    constraint user_email_uindex
+        unique (email)
+);
+
+comment on column "user".name is 'username';
+comment on column "user".password is 'password';
+comment on column "user".email is 'email';
+comment on column "user".phone is 'phone number';
+comment on column "user".age is 'age';
+comment on column "user".gender is 'gender, 1:male, 2:female, 3:unknown';
@@ -0,0 +1,13 @@
+create table user
+(
+    id         integer primary key autoincrement,
+    created_at datetime,
+    updated_at datetime,
+    deleted_at datetime,
+    name       varchar(50)  not null,
+    password   varchar(100) not null,
+    email      varchar(50)  not null unique,
+    phone      integer      not null,
+    age        tinyint      not null,
+    gender     tinyint      not null default 3
+);
@@ -45,6 +45,47 @@ func StrToUint64E(str string) (uint64, error) {
 	return strconv.ParseUint(str, 10, 64)
 }
 
+// StrToInt32 string to int32
+func StrToInt32(str string) int32 {
+	v, _ := strconv.ParseInt(str, 10, 32)
+	return int32(v)
+}
+
+// StrToInt32E string to int32 with error
+func StrToInt32E(str string) (int32, error) {
+	v, err := strconv.ParseInt(str, 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	return int32(v), nil
+}
+
+// StrToInt64 string to int64
+func StrToInt64(str string) int64 {
+	v, _ := strconv.ParseInt(str, 10, 64)
+	return v
+}
+
+// StrToInt64E string to int64 with error
+func StrToInt64E(str string) (int64, error) {
+	return strconv.ParseInt(str, 10, 64)
+}
+
+// StrToUint string to uint
+func StrToUint(str string) uint {
+	v, _ := strconv.ParseUint(str, 10, 64)
+	return uint(v)
+}
+
+// StrToUintE string to uint with error
+func StrToUintE(str string) (uint, error) {
+	v, err := strconv.ParseUint(str, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return uint(v), nil
+}
+
 // StrToFloat32 string to float32
 func StrToFloat32(str string) float32 {
 	v, _ := strconv.ParseFloat(str, 32)
@@ -86,6 +127,21 @@ func Int64ToStr(v int64) string {
 	return strconv.FormatInt(v, 10)
 }
 
+// Int32ToStr int32 to string
+func Int32ToStr(v int32) string {
+	return strconv.FormatInt(int64(v), 10)
+}
+
+// Uint32ToStr uint32 to string
+func Uint32ToStr(v uint32) string {
+	return strconv.FormatUint(uint64(v), 10)
+}
+
+// UintToStr uint to string
+func UintToStr(v uint) string {
+	return strconv.FormatUint(uint64(v), 10)
+}
+
 // ProtoInt32ToInt convert proto int32 to int
 func ProtoInt32ToInt(v int32) int {
 	return int(v)
@@ -66,6 +66,32 @@ func TestStrToUint64E(t *testing.T) {
 	assert.Equal(t, uint64(1), val)
 }
 
+func TestStrToInt32AndInt64(t *testing.T) {
+	assert.Equal(t, int32(-1), StrToInt32("-1"))
+	v1, err := StrToInt32E("1")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), v1)
+
+	assert.Equal(t, int64(-1), StrToInt64("-1"))
+	v2, err := StrToInt64E("1")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), v2)
+
+	assert.Equal(t, uint(1), StrToUint("1"))
+	v3, err := StrToUintE("1")
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), v3)
+
+	_, err = StrToUintE("-1")
+	assert.Error(t, err)
+}
+
+func TestInt32AndUintToStr(t *testing.T) {
+	assert.Equal(t, "-1", Int32ToStr(-1))
+	assert.Equal(t, "1", Uint32ToStr(1))
+	assert.Equal(t, "1", UintToStr(1))
+}
+
 func TestUint64ToStr(t *testing.T) {
 	val := Uint64ToStr(1)
 	assert.Equal(t, "1", val)